@@ -12,6 +12,10 @@ const (
 	ReconcileSuccessCondition string = "ReconcileSuccess"
 	// ReadyCondition indicates the cluster is ready to receive traffic.
 	ReadyCondition string = "Ready"
+	// CARotationCondition indicates a rotation of the mTLS certificate authorities is in progress.
+	CARotationCondition string = "CARotation"
+	// PersistenceReachableCondition indicates the cluster datastores passed the preflight checks.
+	PersistenceReachableCondition string = "PersistenceReachable"
 )
 
 const (
@@ -27,6 +31,16 @@ const (
 	ServicesNotReadyReason string = "ServicesNotReady"
 	// PersistenceReconciliationFailedReason signals an error while reconciling persistence.
 	PersistenceReconciliationFailedReason string = "PersistenceReconciliationFailed"
+	// PersistencePlanOnlyReason signals schema updates have been planned and are waiting for plan only mode to be disabled.
+	PersistencePlanOnlyReason string = "PersistencePlanOnly"
+	// PersistenceReachableReason signals all datastores passed the preflight checks.
+	PersistenceReachableReason string = "PersistenceReachable"
+	// PersistenceUnreachableReason signals at least one datastore failed the preflight checks.
+	PersistenceUnreachableReason string = "PersistenceUnreachable"
+	// PersistenceCheckingReason signals the datastores preflight checks are running.
+	PersistenceCheckingReason string = "PersistenceChecking"
+	// ManagedDatastoresNotReadyReason signals the database clusters of managed datastores are not ready yet.
+	ManagedDatastoresNotReadyReason string = "ManagedDatastoresNotReady"
 	// ResourcesReconciliationFailedReason signals an error while reconciling cluster resources.
 	ResourcesReconciliationFailedReason string = "ResoucesReconciliationFailed"
 	// TemporalClusterValidationFailedReason signals an error while validation desired cluster version.
@@ -35,6 +49,18 @@ const (
 	TemporalNamespaceCreatedReason string = "TemporalNamespaceCreated"
 	// TemporalScheduleCreatedReason signals a successful schedule creation.
 	TemporalScheduleCreatedReason string = "TemporalScheduleCreated"
+	// MTLSReconciliationFailedReason signals an error while reconciling mTLS certificates authorities.
+	MTLSReconciliationFailedReason string = "MTLSReconciliationFailed"
+	// CAUpToDateReason signals all mTLS certificates are issued by the currently trusted certificate authorities.
+	CAUpToDateReason string = "CAUpToDate"
+	// CABundlePublishedReason signals a CA bundle containing both the previous and the new root CA
+	// has been published and is being rolled out to the cluster pods.
+	CABundlePublishedReason string = "CABundlePublished"
+	// CertificatesReissuingReason signals certificates issued by a previous CA are being reissued.
+	CertificatesReissuingReason string = "CertificatesReissuing"
+	// PreviousCADroppedReason signals the previous root CA has been removed from the CA bundle
+	// and the new bundle is being rolled out to the cluster pods.
+	PreviousCADroppedReason string = "PreviousCADropped"
 )
 
 // SetTemporalClusterReconcileSuccess sets the ReconcileSuccessCondition status for a temporal cluster.
@@ -82,6 +108,38 @@ func SetTemporalClusterReady(c *TemporalCluster, status metav1.ConditionStatus,
 	apimeta.SetStatusCondition(&c.Status.Conditions, condition)
 }
 
+// GetTemporalClusterCARotationCondition returns the CA rotation condition for the provided cluster if found.
+func GetTemporalClusterCARotationCondition(c *TemporalCluster) (*metav1.Condition, bool) {
+	condition := apimeta.FindStatusCondition(c.Status.Conditions, CARotationCondition)
+	return condition, condition != nil
+}
+
+// SetTemporalClusterCARotation sets the CARotationCondition status for a temporal cluster.
+func SetTemporalClusterCARotation(c *TemporalCluster, status metav1.ConditionStatus, reason, message string) {
+	condition := metav1.Condition{
+		Type:               CARotationCondition,
+		LastTransitionTime: metav1.Now(),
+		ObservedGeneration: c.GetGeneration(),
+		Reason:             reason,
+		Status:             status,
+		Message:            message,
+	}
+	apimeta.SetStatusCondition(&c.Status.Conditions, condition)
+}
+
+// SetTemporalClusterPersistenceReachable sets the PersistenceReachableCondition status for a temporal cluster.
+func SetTemporalClusterPersistenceReachable(c *TemporalCluster, status metav1.ConditionStatus, reason, message string) {
+	condition := metav1.Condition{
+		Type:               PersistenceReachableCondition,
+		LastTransitionTime: metav1.Now(),
+		ObservedGeneration: c.GetGeneration(),
+		Reason:             reason,
+		Status:             status,
+		Message:            message,
+	}
+	apimeta.SetStatusCondition(&c.Status.Conditions, condition)
+}
+
 // SetTemporalNamespaceReady sets the ReadyCondition status for a temporal namespace.
 func SetTemporalNamespaceReady(c *TemporalNamespace, status metav1.ConditionStatus, reason, message string) {
 	condition := metav1.Condition{
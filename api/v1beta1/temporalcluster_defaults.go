@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/alexandrevilain/temporal-operator/pkg/version"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 )
@@ -33,6 +34,13 @@ const (
 	defaultTemporalUIImage   = "temporalio/ui"
 
 	defaultTemporalAdmintoolsImage = "temporalio/admin-tools"
+
+	defaultCodecServerPort          = 8081
+	defaultCodecServerKeysMountPath = "/etc/codec-server/keys"
+
+	defaultSpiffeHelperImage = "ghcr.io/spiffe/spiffe-helper:0.8.0"
+	defaultSpiffeCSIDriver   = "csi.spiffe.io"
+	defaultSpireSocketName   = "spire-agent.sock"
 )
 
 // Default set default fields values.
@@ -83,7 +91,7 @@ func (c *TemporalCluster) Default() {
 	}
 	// Frontend specs
 	if c.Spec.Services.Frontend == nil {
-		c.Spec.Services.Frontend = new(ServiceSpec)
+		c.Spec.Services.Frontend = new(FrontendServiceSpec)
 	}
 	if c.Spec.Services.Frontend.Replicas == nil {
 		c.Spec.Services.Frontend.Replicas = ptr.To[int32](1)
@@ -97,6 +105,11 @@ func (c *TemporalCluster) Default() {
 	if c.Spec.Services.Frontend.HTTPPort == nil {
 		c.Spec.Services.Frontend.HTTPPort = ptr.To[int32](7243)
 	}
+	if c.Spec.Services.Frontend.Expose != nil && c.Spec.Services.Frontend.Expose.Service != nil {
+		if c.Spec.Services.Frontend.Expose.Service.Type == "" {
+			c.Spec.Services.Frontend.Expose.Service.Type = corev1.ServiceTypeLoadBalancer
+		}
+	}
 	// Internal Frontend specs
 	if c.Spec.Services.InternalFrontend.IsEnabled() {
 		if c.Spec.Services.InternalFrontend.Replicas == nil {
@@ -205,6 +218,18 @@ func (c *TemporalCluster) Default() {
 		c.Spec.UI.Replicas = ptr.To[int32](1)
 	}
 
+	if c.Spec.CodecServer != nil {
+		if c.Spec.CodecServer.Port == nil {
+			c.Spec.CodecServer.Port = ptr.To[int32](defaultCodecServerPort)
+		}
+		if c.Spec.CodecServer.Replicas == nil {
+			c.Spec.CodecServer.Replicas = ptr.To[int32](1)
+		}
+		if c.Spec.CodecServer.KeysMountPath == "" {
+			c.Spec.CodecServer.KeysMountPath = defaultCodecServerKeysMountPath
+		}
+	}
+
 	if c.Spec.AdminTools == nil {
 		c.Spec.AdminTools = new(TemporalAdminToolsSpec)
 	}
@@ -223,7 +248,7 @@ func (c *TemporalCluster) Default() {
 		}
 	}
 
-	if c.MTLSWithCertManagerEnabled() {
+	if c.MTLSWithCertificatesEnabled() {
 		if c.Spec.MTLS.CertificatesDuration == nil {
 			c.Spec.MTLS.CertificatesDuration = &CertificatesDurationSpec{}
 		}
@@ -244,6 +269,21 @@ func (c *TemporalCluster) Default() {
 		}
 	}
 
+	if c.MTLSWithSpireEnabled() && c.Spec.MTLS.Spire != nil {
+		if c.Spec.MTLS.Spire.HelperImage == "" {
+			c.Spec.MTLS.Spire.HelperImage = defaultSpiffeHelperImage
+		}
+		if c.Spec.MTLS.Spire.WorkloadAPI == nil {
+			c.Spec.MTLS.Spire.WorkloadAPI = &SpireWorkloadAPISpec{}
+		}
+		if c.Spec.MTLS.Spire.WorkloadAPI.CSIDriver == "" && c.Spec.MTLS.Spire.WorkloadAPI.HostPath == "" {
+			c.Spec.MTLS.Spire.WorkloadAPI.CSIDriver = defaultSpiffeCSIDriver
+		}
+		if c.Spec.MTLS.Spire.WorkloadAPI.SocketName == "" {
+			c.Spec.MTLS.Spire.WorkloadAPI.SocketName = defaultSpireSocketName
+		}
+	}
+
 	if c.Spec.Metrics.IsEnabled() {
 		if c.Spec.Metrics.Prometheus != nil {
 			if c.Spec.Metrics.Prometheus.ListenPort == nil {
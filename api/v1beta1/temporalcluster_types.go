@@ -20,7 +20,9 @@ package v1beta1
 import (
 	"fmt"
 	"path"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alexandrevilain/temporal-operator/pkg/version"
 	"github.com/gocql/gocql"
@@ -28,10 +30,13 @@ import (
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"go.temporal.io/server/common/primitives"
 	"golang.org/x/exp/slices"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 // LogSpec contains the temporal logging configuration.
@@ -100,9 +105,379 @@ type ServiceSpec struct {
 	// InitContainers adds a list of init containers to the service's deployment.
 	// +optional
 	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+	// Autoscaling enables horizontal autoscaling of the service.
+	// When set, the operator no longer manages the deployment replicas.
+	// +optional
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+	// PodDisruptionBudget configures the PodDisruptionBudget of the service.
+	// By default, a PodDisruptionBudget with maxUnavailable set to 1 is created.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+	// TopologySpreadConstraints describes how the service pods ought to spread across topology domains.
+	// If a constraint has no label selector, the service pods selector is used.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// Affinity is the scheduling constraints of the service pods.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// NodeSelector is a selector which must match a node's labels for the service pods to be scheduled on that node.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations are the service pods tolerations.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// PriorityClassName is the priority class of the service pods.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// Probes allows overriding the probes of the service container.
+	// By default, readiness and startup probes use the gRPC health check of the service.
+	// +optional
+	Probes *ServiceProbesSpec `json:"probes,omitempty"`
+	// Shutdown configures the graceful shutdown of the service pods.
+	// +optional
+	Shutdown *ServiceShutdownSpec `json:"shutdown,omitempty"`
+	// WorkloadKind is the kind of workload running the service pods.
+	// StatefulSet gives the service pods stable names and ordinal-based rollouts.
+	// It's only supported for the history and matching services.
+	// When changed, the previous workload is kept until the new one is ready.
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet
+	// +optional
+	WorkloadKind WorkloadKind `json:"workloadKind,omitempty"`
+	// Image overrides the temporal server docker image for the service.
+	// Default to the cluster image.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Version overrides the temporal version for the service.
+	// It must be within one minor version of the other services versions.
+	// Persistence schemas are only upgraded for the cluster version.
+	// Default to the cluster version.
+	// +optional
+	Version *version.Version `json:"version,omitempty"`
+	// Canary runs a few pods of the service with another image, version or pod template
+	// next to the stable pods. Canary pods join the same membership ring.
+	// +optional
+	Canary *ServiceCanarySpec `json:"canary,omitempty"`
 	// ServiceAccountOverride
 }
 
+// CanaryAction is the action applied to a service canary.
+// +kubebuilder:validation:Enum=Observe;Promote;Abort
+type CanaryAction string
+
+const (
+	// ObserveCanaryAction runs the canary pods next to the stable pods.
+	ObserveCanaryAction CanaryAction = "Observe"
+	// PromoteCanaryAction rolls the canary configuration out to all the service pods,
+	// then tears the canary pods down.
+	PromoteCanaryAction CanaryAction = "Promote"
+	// AbortCanaryAction tears the canary pods down.
+	AbortCanaryAction CanaryAction = "Abort"
+)
+
+// ServiceCanarySpec defines the canary pods of a service.
+type ServiceCanarySpec struct {
+	// Replicas is the number of canary pods. They are taken from the service replicas,
+	// or from the autoscaling minimum replicas when the service is autoscaled. Default to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Image is the temporal server docker image of the canary pods.
+	// Default to the service image.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Version is the temporal version of the canary pods.
+	// Default to the service version.
+	// +optional
+	Version *version.Version `json:"version,omitempty"`
+	// Template overrides the canary pods template.
+	// It's applied on top of the service overrides.
+	// +optional
+	Template *PodTemplateSpecOverride `json:"template,omitempty"`
+	// Duration is the time the canary pods have to stay ready before the canary is reported as healthy.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// Action is the action applied to the canary. Default to Observe.
+	// +optional
+	Action CanaryAction `json:"action,omitempty"`
+}
+
+// GetReplicas returns the number of canary pods.
+func (c *ServiceCanarySpec) GetReplicas() int32 {
+	if c == nil || c.Replicas == nil {
+		return 1
+	}
+	return *c.Replicas
+}
+
+// GetAction returns the action applied to the canary.
+func (c *ServiceCanarySpec) GetAction() CanaryAction {
+	if c == nil || c.Action == "" {
+		return ObserveCanaryAction
+	}
+	return c.Action
+}
+
+// IsObserved returns true if the canary pods run next to the stable pods.
+func (c *ServiceCanarySpec) IsObserved() bool {
+	return c != nil && c.GetAction() == ObserveCanaryAction
+}
+
+// IsPromoted returns true if the canary configuration is rolled out to all the service pods.
+func (c *ServiceCanarySpec) IsPromoted() bool {
+	return c != nil && c.GetAction() == PromoteCanaryAction
+}
+
+// WithCanary returns a copy of the service spec running the canary image and version.
+func (s *ServiceSpec) WithCanary() *ServiceSpec {
+	spec := s.DeepCopy()
+	if s.Canary == nil {
+		return spec
+	}
+	if s.Canary.Image != "" {
+		spec.Image = s.Canary.Image
+	}
+	if s.Canary.Version != nil {
+		spec.Version = s.Canary.Version
+	}
+	spec.Canary = nil
+	return spec
+}
+
+// WorkloadKind is the kind of workload running a temporal service.
+type WorkloadKind string
+
+const (
+	// DeploymentWorkloadKind runs the service using a Deployment.
+	DeploymentWorkloadKind WorkloadKind = "Deployment"
+	// StatefulSetWorkloadKind runs the service using a StatefulSet.
+	StatefulSetWorkloadKind WorkloadKind = "StatefulSet"
+)
+
+// GetWorkloadKind returns the kind of workload running the service pods.
+func (s *ServiceSpec) GetWorkloadKind() WorkloadKind {
+	if s == nil || s.WorkloadKind == "" {
+		return DeploymentWorkloadKind
+	}
+	return s.WorkloadKind
+}
+
+// ServiceProbesSpec contains the probes of a service container.
+// When a probe is set, it replaces the one computed by the operator.
+type ServiceProbesSpec struct {
+	// Liveness is the service container liveness probe.
+	// +optional
+	Liveness *corev1.Probe `json:"liveness,omitempty"`
+	// Readiness is the service container readiness probe.
+	// +optional
+	Readiness *corev1.Probe `json:"readiness,omitempty"`
+	// Startup is the service container startup probe.
+	// +optional
+	Startup *corev1.Probe `json:"startup,omitempty"`
+}
+
+// ServiceShutdownSpec configures the graceful shutdown of a service.
+type ServiceShutdownSpec struct {
+	// PreStopDelay is the time the service container waits in its preStop hook before being stopped,
+	// leaving time for the pod to be removed from the service endpoints.
+	// Set it to 0 to disable the preStop hook. Default to 5s.
+	// +optional
+	PreStopDelay *metav1.Duration `json:"preStopDelay,omitempty"`
+	// TerminationGracePeriod overrides the pods termination grace period.
+	// By default, it's computed from the preStop delay and the temporal shutdown drain settings
+	// set in the dynamic config (e.g. frontend.shutdownDrainDuration).
+	// +optional
+	TerminationGracePeriod *metav1.Duration `json:"terminationGracePeriod,omitempty"`
+}
+
+// PodDisruptionBudgetSpec defines the PodDisruptionBudget of a service.
+// MinAvailable and MaxUnavailable are mutually exclusive.
+type PodDisruptionBudgetSpec struct {
+	// Enabled defines if a PodDisruptionBudget is created for the service. Default to true.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// MinAvailable is the number or percentage of pods that must remain available during an eviction.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+	// MaxUnavailable is the number or percentage of pods that can be unavailable during an eviction.
+	// Default to 1 if MinAvailable is not set.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// IsEnabled returns true if a PodDisruptionBudget should be created.
+func (p *PodDisruptionBudgetSpec) IsEnabled() bool {
+	return p == nil || p.Enabled == nil || *p.Enabled
+}
+
+// AutoscalingPrometheusMetric is a predefined Temporal metric the service can be scaled on.
+// +kubebuilder:validation:Enum=TaskQueueBacklog;RequestRate
+type AutoscalingPrometheusMetric string
+
+const (
+	// TaskQueueBacklogMetric scales the matching service on the approximate task queues backlog.
+	TaskQueueBacklogMetric AutoscalingPrometheusMetric = "TaskQueueBacklog"
+	// RequestRateMetric scales the frontend services on their requests rate per second.
+	RequestRateMetric AutoscalingPrometheusMetric = "RequestRate"
+)
+
+// AutoscalingPrometheusSpec defines a Prometheus metric the service is scaled on.
+// It requires KEDA to be installed in the cluster.
+type AutoscalingPrometheusSpec struct {
+	// ServerAddress is the address of the Prometheus server.
+	ServerAddress string `json:"serverAddress"`
+	// Metric is a predefined Temporal metric to scale on.
+	// TaskQueueBacklog is only available for the matching service,
+	// RequestRate for the frontend and internal frontend services.
+	// +optional
+	Metric AutoscalingPrometheusMetric `json:"metric,omitempty"`
+	// Query is a custom PromQL query to scale on. It takes precedence over Metric.
+	// +optional
+	Query string `json:"query,omitempty"`
+	// Threshold is the target value of the query result per replica.
+	Threshold string `json:"threshold"`
+	// AuthenticationRef references a KEDA TriggerAuthentication used to authenticate against Prometheus.
+	// +optional
+	AuthenticationRef *corev1.LocalObjectReference `json:"authenticationRef,omitempty"`
+}
+
+// AutoscalingSpec defines the horizontal autoscaling of a service.
+// The operator creates an HorizontalPodAutoscaler, or a KEDA ScaledObject if KEDA is installed in the cluster.
+type AutoscalingSpec struct {
+	// MinReplicas is the lower limit for the number of replicas. Default to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the upper limit for the number of replicas.
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+	// Metrics contains the specifications used to compute the desired replica count.
+	// When KEDA is used, only Resource metrics are supported.
+	// Default to a 80% average CPU utilization if no metric nor prometheus trigger is provided.
+	// +optional
+	Metrics []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
+	// Prometheus scales the service on Prometheus metrics. Requires KEDA.
+	// +optional
+	Prometheus []AutoscalingPrometheusSpec `json:"prometheus,omitempty"`
+	// Behavior configures the scaling behavior of the target in both Up and Down directions.
+	// +optional
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+}
+
+// FrontendExternalServiceSpec defines a Service exposing the frontend outside of the Kubernetes cluster.
+type FrontendExternalServiceSpec struct {
+	// Type is the type of the Service. Defaults to LoadBalancer.
+	// +kubebuilder:validation:Enum=LoadBalancer;NodePort
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// Annotations allows custom annotations on the Service, e.g. to configure the cloud provider's load balancer.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// LoadBalancerClass is the class of the load balancer implementation the Service belongs to.
+	// +optional
+	LoadBalancerClass *string `json:"loadBalancerClass,omitempty"`
+	// LoadBalancerSourceRanges restricts the client IPs allowed to reach the load balancer.
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+	// ExternalTrafficPolicy describes how nodes distribute the external traffic they receive.
+	// +optional
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+	// NodePort is the port exposing the frontend gRPC port on each node when type is NodePort.
+	// If unset, it's allocated by Kubernetes.
+	// +optional
+	NodePort *int32 `json:"nodePort,omitempty"`
+	// Hostnames is the list of external DNS names resolving to the Service.
+	// They are added to the frontend mTLS certificate.
+	// +optional
+	Hostnames []string `json:"hostnames,omitempty"`
+}
+
+// FrontendIngressSpec defines an Ingress routing gRPC traffic to the frontend.
+type FrontendIngressSpec struct {
+	// Annotations allows custom annotations on the ingress resource.
+	// They take precedence over the gRPC annotations set by the operator.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// IngressClassName is the name of the IngressClass the deployed ingress resource should use.
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// Hosts is the list of hosts the ingress should use.
+	// They are added to the frontend mTLS certificate.
+	Hosts []string `json:"hosts"`
+	// TLS configuration.
+	// +optional
+	TLS []networkingv1.IngressTLS `json:"tls,omitempty"`
+}
+
+// FrontendRouteSpec defines a Gateway API route to the frontend.
+type FrontendRouteSpec struct {
+	// Annotations allows custom annotations on the route.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// ParentRefs references the Gateways the route attaches to.
+	ParentRefs []gatewayv1.ParentReference `json:"parentRefs"`
+	// Hostnames is the list of hostnames the route matches.
+	// They are added to the frontend mTLS certificate.
+	// +optional
+	Hostnames []gatewayv1.Hostname `json:"hostnames,omitempty"`
+}
+
+// FrontendExposeSpec defines how the frontend is exposed outside of the Kubernetes cluster.
+type FrontendExposeSpec struct {
+	// Service creates a LoadBalancer or NodePort Service for the frontend.
+	// +optional
+	Service *FrontendExternalServiceSpec `json:"service,omitempty"`
+	// Ingress creates an Ingress routing gRPC traffic to the frontend.
+	// The generated annotations target ingress-nginx.
+	// If frontend mTLS is enabled, TLS is passed through to the frontend.
+	// +optional
+	Ingress *FrontendIngressSpec `json:"ingress,omitempty"`
+	// GRPCRoute creates a Gateway API GRPCRoute to the frontend.
+	// TLS is terminated by the Gateway, so it can't be used with frontend mTLS.
+	// +optional
+	GRPCRoute *FrontendRouteSpec `json:"grpcRoute,omitempty"`
+	// TLSRoute creates a Gateway API TLSRoute passing TLS connections through to the frontend.
+	// Requires frontend mTLS to be enabled.
+	// +optional
+	TLSRoute *FrontendRouteSpec `json:"tlsRoute,omitempty"`
+	// HTTPRoute creates a Gateway API HTTPRoute to the frontend HTTP API port.
+	// Requires the frontend HTTP port to be enabled.
+	// +optional
+	HTTPRoute *GatewayHTTPRouteSpec `json:"httpRoute,omitempty"`
+}
+
+// Hostnames returns the external hostnames the frontend is reachable with.
+func (e *FrontendExposeSpec) Hostnames() []string {
+	if e == nil {
+		return nil
+	}
+
+	hostnames := []string{}
+	if e.Service != nil {
+		hostnames = append(hostnames, e.Service.Hostnames...)
+	}
+	if e.Ingress != nil {
+		hostnames = append(hostnames, e.Ingress.Hosts...)
+	}
+	for _, route := range []*FrontendRouteSpec{e.GRPCRoute, e.TLSRoute} {
+		if route == nil {
+			continue
+		}
+		for _, hostname := range route.Hostnames {
+			hostnames = append(hostnames, string(hostname))
+		}
+	}
+
+	return hostnames
+}
+
+// FrontendServiceSpec contains temporal frontend service specifications.
+type FrontendServiceSpec struct {
+	ServiceSpec `json:",inline"`
+	// Expose allows exposing the frontend outside of the Kubernetes cluster.
+	// +optional
+	Expose *FrontendExposeSpec `json:"expose,omitempty"`
+}
+
 // InternalFrontendServiceSpec contains temporal internal frontend service specifications.
 type InternalFrontendServiceSpec struct {
 	ServiceSpec `json:",inline"`
@@ -120,7 +495,7 @@ func (s *InternalFrontendServiceSpec) IsEnabled() bool {
 type ServicesSpec struct {
 	// Frontend service custom specifications.
 	// +optional
-	Frontend *ServiceSpec `json:"frontend,omitempty"`
+	Frontend *FrontendServiceSpec `json:"frontend,omitempty"`
 	// Internal Frontend service custom specifications.
 	// Only compatible with temporal >= 1.20.0
 	// +optional
@@ -144,7 +519,10 @@ type ServicesSpec struct {
 func (s *ServicesSpec) GetServiceSpec(name primitives.ServiceName) (*ServiceSpec, error) {
 	switch name {
 	case primitives.FrontendService:
-		return s.Frontend, nil
+		if s.Frontend == nil {
+			return nil, nil
+		}
+		return &s.Frontend.ServiceSpec, nil
 	case primitives.InternalFrontendService:
 		if s.InternalFrontend == nil {
 			return &ServiceSpec{}, nil
@@ -253,6 +631,52 @@ type SQLSpec struct {
 	// GCPServiceAccount is the service account to use to authenticate with GCP CloudSQL.
 	// +optional
 	GCPServiceAccount *string `json:"gcpServiceAccount,omitempty"`
+	// Auth configures the authentication with short-lived cloud provider tokens instead of a static password.
+	// When set, the services connect to the datastore through a local proxy sidecar authenticating each connection
+	// with a fresh token, and the schema jobs generate the token before connecting.
+	// +optional
+	Auth *SQLAuthSpec `json:"auth,omitempty"`
+}
+
+// SQLAuthSpec defines how to authenticate with a SQL datastore using cloud provider tokens.
+// Exactly one of awsIAM, azureAD or gcpIAM must be set.
+type SQLAuthSpec struct {
+	// AWSIAM authenticates using Amazon RDS IAM database authentication.
+	// The services and schema jobs service accounts are annotated for IAM roles for service accounts (IRSA).
+	// +optional
+	AWSIAM *SQLAWSIAMAuthSpec `json:"awsIAM,omitempty"`
+	// AzureAD authenticates using Microsoft Entra ID (Azure AD) tokens.
+	// The services and schema jobs use Azure workload identity.
+	// +optional
+	AzureAD *SQLAzureADAuthSpec `json:"azureAD,omitempty"`
+	// GCPIAM authenticates using Cloud SQL IAM database authentication.
+	// The services and schema jobs service accounts are annotated for GKE workload identity.
+	// +optional
+	GCPIAM *SQLGCPIAMAuthSpec `json:"gcpIAM,omitempty"`
+}
+
+// SQLAWSIAMAuthSpec configures Amazon RDS IAM database authentication.
+type SQLAWSIAMAuthSpec struct {
+	// Region is the AWS region of the database.
+	Region string `json:"region"`
+	// Role is the ARN of the IAM role allowed to connect to the database, assumed by the pods.
+	Role string `json:"role"`
+}
+
+// SQLAzureADAuthSpec configures Microsoft Entra ID (Azure AD) authentication.
+type SQLAzureADAuthSpec struct {
+	// ClientID is the client ID of the managed identity federated with the service accounts.
+	ClientID string `json:"clientId"`
+	// TenantID is the tenant of the managed identity.
+	// Defaults to the tenant configured in the workload identity webhook.
+	// +optional
+	TenantID string `json:"tenantId,omitempty"`
+}
+
+// SQLGCPIAMAuthSpec configures Cloud SQL IAM database authentication.
+type SQLGCPIAMAuthSpec struct {
+	// ServiceAccount is the email of the GCP service account allowed to connect to the database.
+	ServiceAccount string `json:"serviceAccount"`
 }
 
 // DatastoreTLSSpec contains datastore TLS connections specifications.
@@ -295,7 +719,13 @@ type ElasticsearchSpec struct {
 	// +kubebuilder:validation:Pattern=`^https?:\/\/.+$`
 	URL string `json:"url"`
 	// Username is the username to be used for the connection.
+	// Prefer auth.basic.username, it can't be set along with auth.
+	// +optional
 	Username string `json:"username"`
+	// Auth configures how the services and the schema jobs authenticate to elasticsearch.
+	// Defaults to basic authentication using the username and the datastore passwordSecretRef.
+	// +optional
+	Auth *ElasticsearchAuthSpec `json:"auth,omitempty"`
 	// Indices holds visibility index names.
 	Indices ElasticsearchIndices `json:"indices"`
 	// LogLevel defines the temporal cluster's es client logger level.
@@ -312,6 +742,57 @@ type ElasticsearchSpec struct {
 	EnableHealthcheck bool `json:"enableHealthcheck"`
 }
 
+// GetUsername returns the username used for basic authentication.
+func (s *ElasticsearchSpec) GetUsername() string {
+	if s.Auth == nil {
+		return s.Username
+	}
+	if s.Auth.Basic != nil {
+		return s.Auth.Basic.Username
+	}
+	return ""
+}
+
+// ElasticsearchAuthSpec configures the elasticsearch authentication.
+// Exactly one of basic, apiKey or awsRequestSigning must be set.
+type ElasticsearchAuthSpec struct {
+	// Basic authenticates using a username and the password held by the datastore passwordSecretRef.
+	// +optional
+	Basic *ElasticsearchBasicAuthSpec `json:"basic,omitempty"`
+	// APIKey authenticates using an elasticsearch API key.
+	// The temporal elasticsearch client doesn't support API keys: the services connect to elasticsearch
+	// through a sidecar proxy adding the key to each request.
+	// +optional
+	APIKey *ElasticsearchAPIKeyAuthSpec `json:"apiKey,omitempty"`
+	// AWSRequestSigning signs requests using AWS Signature Version 4, for Amazon OpenSearch Service domains.
+	// +optional
+	AWSRequestSigning *ElasticsearchAWSRequestSigningSpec `json:"awsRequestSigning,omitempty"`
+}
+
+// ElasticsearchBasicAuthSpec configures elasticsearch basic authentication.
+type ElasticsearchBasicAuthSpec struct {
+	// Username is the username to be used for the connection.
+	Username string `json:"username"`
+}
+
+// ElasticsearchAPIKeyAuthSpec configures elasticsearch API key authentication.
+type ElasticsearchAPIKeyAuthSpec struct {
+	// SecretRef is the reference to the secret holding the encoded API key,
+	// as returned by the elasticsearch create API key API. The key defaults to "apiKey".
+	SecretRef SecretKeyReference `json:"secretRef"`
+}
+
+// ElasticsearchAWSRequestSigningSpec configures AWS request signing.
+type ElasticsearchAWSRequestSigningSpec struct {
+	// Region is the AWS region of the domain.
+	Region string `json:"region"`
+	// Role is the ARN of the IAM role allowed to access the domain, assumed by the pods.
+	// The services and schema jobs service accounts are annotated for IAM roles for service accounts (IRSA).
+	// Defaults to the credentials resolved by the AWS SDK default chain.
+	// +optional
+	Role string `json:"role,omitempty"`
+}
+
 // CassandraConsistencySpec sets the consistency level for regular & serial queries to Cassandra.
 type CassandraConsistencySpec struct {
 	// Consistency sets the default consistency level.
@@ -399,9 +880,54 @@ type DatastoreSpec struct {
 	// TLS is an optional option to connect to the datastore using TLS.
 	// +optional
 	TLS *DatastoreTLSSpec `json:"tls,omitempty"`
+	// Managed references, or creates, a database cluster managed by another operator.
+	// The connection details, the password secret and the CA are derived from the database cluster,
+	// sql, cassandra, elasticsearch, passwordSecretRef and tls must not be set.
+	// +optional
+	Managed *ManagedDatastoreSpec `json:"managed,omitempty"`
 	// SkipCreate instructs the operator to skip creating the database for SQL datastores or to skip creating keyspace for Cassandra. Use this option if your database or keyspace has already been provisioned by an administrator.
 	// +optional
 	SkipCreate bool `json:"skipCreate"`
+	// Backup configures a backup taken before updating the datastore schema.
+	// Schema updates are only applied once the backup succeeded.
+	// +optional
+	Backup *DatastoreBackupSpec `json:"backup,omitempty"`
+	// PasswordRotation configures how password secret changes are rolled out to the cluster services.
+	// +optional
+	PasswordRotation *DatastorePasswordRotationSpec `json:"passwordRotation,omitempty"`
+}
+
+// DatastorePasswordRotationSpec configures how password secret changes are rolled out.
+type DatastorePasswordRotationSpec struct {
+	// DualCredential indicates the datastore accepts both the previous and the new password during rotations.
+	// When enabled, the new password is verified by the preflight checks before any pod is rolled out.
+	// +optional
+	DualCredential bool `json:"dualCredential"`
+}
+
+// IsDualCredentialPasswordRotation returns true if password rotations are verified before being rolled out.
+func (s *DatastoreSpec) IsDualCredentialPasswordRotation() bool {
+	return s.PasswordRotation != nil && s.PasswordRotation.DualCredential
+}
+
+// IsManaged returns true if the datastore connects to a database cluster managed by another operator.
+func (s *DatastoreSpec) IsManaged() bool {
+	return s.Managed != nil
+}
+
+// IsSQLTokenAuth returns true if the datastore authenticates with cloud provider tokens.
+func (s *DatastoreSpec) IsSQLTokenAuth() bool {
+	return s.SQL != nil && s.SQL.Auth != nil
+}
+
+// IsElasticsearchAPIKeyAuth returns true if the datastore authenticates to elasticsearch using an API key.
+func (s *DatastoreSpec) IsElasticsearchAPIKeyAuth() bool {
+	return s.Elasticsearch != nil && s.Elasticsearch.Auth != nil && s.Elasticsearch.Auth.APIKey != nil
+}
+
+// IsElasticsearchAWSRequestSigning returns true if the datastore signs elasticsearch requests using AWS credentials.
+func (s *DatastoreSpec) IsElasticsearchAWSRequestSigning() bool {
+	return s.Elasticsearch != nil && s.Elasticsearch.Auth != nil && s.Elasticsearch.Auth.AWSRequestSigning != nil
 }
 
 // LowerCaseName returns the datastore name in lower case.
@@ -429,6 +955,9 @@ func (s *DatastoreSpec) GetType() DatastoreType {
 	if s.Cassandra != nil {
 		return CassandraDatastore
 	}
+	if s.Managed != nil {
+		return s.Managed.GetType()
+	}
 	return UnknownDatastore
 }
 
@@ -485,6 +1014,144 @@ func (s *DatastoreSpec) GetPasswordEnvVarName() string {
 	return fmt.Sprintf("TEMPORAL_%s_DATASTORE_PASSWORD", storeName)
 }
 
+// GetAPIKeyEnvVarName crafts the environment variable name holding the datastore API key.
+func (s *DatastoreSpec) GetAPIKeyEnvVarName() string {
+	storeName := slug.Make(s.Name)
+	storeName = strings.ToUpper(storeName)
+	return fmt.Sprintf("TEMPORAL_%s_DATASTORE_API_KEY", storeName)
+}
+
+// ManagedDatastoreSpec references a database cluster managed by another operator.
+// Exactly one of cloudNativePG, perconaXtraDB or k8ssandra must be set.
+type ManagedDatastoreSpec struct {
+	// CloudNativePG references a CloudNativePG postgresql.cnpg.io/v1 Cluster.
+	// The datastore connects to the read-write service as the application database owner, using TLS.
+	// +optional
+	CloudNativePG *ManagedClusterReference `json:"cloudNativePG,omitempty"`
+	// PerconaXtraDB references a Percona XtraDB pxc.percona.com/v1 PerconaXtraDBCluster.
+	// The datastore connects to the cluster endpoint as root, using TLS.
+	// +optional
+	PerconaXtraDB *ManagedClusterReference `json:"perconaXtraDB,omitempty"`
+	// K8ssandra references a K8ssandra k8ssandra.io/v1alpha1 K8ssandraCluster.
+	// The datastore connects to the first datacenter as the Cassandra superuser.
+	// +optional
+	K8ssandra *ManagedClusterReference `json:"k8ssandra,omitempty"`
+	// Database is the name of the database, or the keyspace for K8ssandra, used by the datastore.
+	Database string `json:"database"`
+}
+
+// GetType returns the type of the managed datastore.
+func (s *ManagedDatastoreSpec) GetType() DatastoreType {
+	switch {
+	case s.CloudNativePG != nil:
+		return PostgresSQL12Datastore
+	case s.PerconaXtraDB != nil:
+		return MySQL8Datastore
+	case s.K8ssandra != nil:
+		return CassandraDatastore
+	}
+	return UnknownDatastore
+}
+
+// ManagedClusterReference references, or creates, a database cluster managed by another operator.
+type ManagedClusterReference struct {
+	// Name is the name of the database cluster, in the TemporalCluster namespace.
+	Name string `json:"name"`
+	// Create instructs the operator to create the database cluster if it doesn't exist.
+	// The created cluster isn't owned by the TemporalCluster: it's neither updated nor deleted with it.
+	// +optional
+	Create *ManagedClusterCreateSpec `json:"create,omitempty"`
+}
+
+// ManagedClusterCreateSpec configures the database cluster created by the operator.
+type ManagedClusterCreateSpec struct {
+	// Spec is merged, as a JSON merge patch, into the default spec of the created database cluster.
+	// +optional
+	Spec *apiextensionsv1.JSON `json:"spec,omitempty"`
+}
+
+// DatastoreBackupSpec configures the backup taken before updating a datastore schema.
+// Exactly one of job, preset or volumeSnapshot must be set.
+type DatastoreBackupSpec struct {
+	// Job runs a user-provided job to backup the datastore.
+	// +optional
+	Job *DatastoreBackupJobSpec `json:"job,omitempty"`
+	// Preset runs pg_dump, mysqldump or nodetool snapshot, depending on the datastore type.
+	// +optional
+	Preset *DatastoreBackupPresetSpec `json:"preset,omitempty"`
+	// VolumeSnapshot takes a VolumeSnapshot of the datastore volume.
+	// +optional
+	VolumeSnapshot *DatastoreBackupVolumeSnapshotSpec `json:"volumeSnapshot,omitempty"`
+}
+
+// DatastoreBackupJobSpec is a user-provided backup job.
+type DatastoreBackupJobSpec struct {
+	// Template describes the backup job pods. Its spec is a k8s.io/api/core/v1.PodSpec.
+	// The datastore password environment variable is added to each container.
+	Template PodTemplateSpecOverride `json:"template"`
+	// Location describes where the job stores the backup.
+	// It's reported in the datastore status once the backup succeeded.
+	// +optional
+	Location string `json:"location,omitempty"`
+}
+
+// DatastoreBackupPresetSpec configures a backup using the datastore native tool:
+// pg_dump for PostgreSQL, mysqldump for MySQL and nodetool snapshot for Cassandra.
+type DatastoreBackupPresetSpec struct {
+	// Image is the image providing the backup tool.
+	// Defaults to "postgres:17" for PostgreSQL, "mysql:8.4" for MySQL and "cassandra:4.1" for Cassandra.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// PersistentVolumeClaim writes the SQL dump to a volume claim.
+	// +optional
+	PersistentVolumeClaim *BackupPersistentVolumeClaimDestination `json:"persistentVolumeClaim,omitempty"`
+	// S3 uploads the SQL dump to an S3-compatible object storage.
+	// +optional
+	S3 *BackupS3Destination `json:"s3,omitempty"`
+	// JMXPort is the port nodetool uses to reach the Cassandra nodes.
+	// Defaults to 7199.
+	// +optional
+	JMXPort *int32 `json:"jmxPort,omitempty"`
+}
+
+// BackupPersistentVolumeClaimDestination is a volume claim storing backups.
+type BackupPersistentVolumeClaimDestination struct {
+	// ClaimName is the name of the volume claim, in the cluster namespace.
+	ClaimName string `json:"claimName"`
+	// Path is the directory of the volume the dumps are written to.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// BackupS3Destination is an S3-compatible object storage storing backups.
+type BackupS3Destination struct {
+	// URL is the bucket URL the dumps are uploaded to, for instance "s3://backups/temporal".
+	URL string `json:"url"`
+	// Region is the aws s3 region.
+	// +optional
+	Region string `json:"region,omitempty"`
+	// Use Endpoint if you want to use s3-compatible object storage.
+	// +optional
+	Endpoint *string `json:"endpoint,omitempty"`
+	// Use credentials if you want to use aws credentials from secret.
+	// +optional
+	Credentials *S3Credentials `json:"credentials,omitempty"`
+	// Image is the image providing the aws cli used to upload dumps.
+	// Defaults to "amazon/aws-cli:2.17.0".
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// DatastoreBackupVolumeSnapshotSpec configures a VolumeSnapshot of the datastore volume.
+type DatastoreBackupVolumeSnapshotSpec struct {
+	// PersistentVolumeClaimName is the name of the datastore volume claim, in the cluster namespace.
+	PersistentVolumeClaimName string `json:"persistentVolumeClaimName"`
+	// VolumeSnapshotClassName is the VolumeSnapshotClass used to take the snapshot.
+	// Defaults to the cluster default class.
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+}
+
 // TemporalPersistenceSpec contains temporal persistence specifications.
 type TemporalPersistenceSpec struct {
 	// DefaultStore holds the default datastore specs.
@@ -498,6 +1165,29 @@ type TemporalPersistenceSpec struct {
 	// AdvancedVisibilityStore holds the advanced visibility datastore specs.
 	// +optional
 	AdvancedVisibilityStore *DatastoreSpec `json:"advancedVisibilityStore,omitempty"`
+	// PlanOnly makes the operator plan schema updates instead of applying them.
+	// Planned migrations are reported in status.persistence.<store>.plannedMigrations
+	// and the cluster isn't upgraded until plan only mode is disabled.
+	// It can also be enabled using the "operator.temporal.io/persistence-plan-only" annotation.
+	// +optional
+	PlanOnly bool `json:"planOnly,omitempty"`
+	// VisibilityMigration migrates the cluster visibility from the visibility store to the secondary visibility store.
+	// The operator writes to both stores, then reads from the secondary store and finally promotes it
+	// as the cluster visibility store. Progress is reported in status.persistence.visibilityMigration.
+	// Requires the secondary visibility store to be set.
+	// +optional
+	VisibilityMigration *VisibilityMigrationSpec `json:"visibilityMigration,omitempty"`
+}
+
+// VisibilityMigrationSpec defines a migration from the visibility store to the secondary visibility store.
+type VisibilityMigrationSpec struct {
+	// SoakPeriod is the time spent in the dual write and the read from secondary phases
+	// before moving to the next phase. The dual write phase should last at least the namespaces retention period
+	// so the secondary store holds all the visibility records.
+	SoakPeriod metav1.Duration `json:"soakPeriod"`
+	// Paused holds the migration in its current phase.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
 }
 
 func (p *TemporalPersistenceSpec) GetDatastores() []*DatastoreSpec {
@@ -546,6 +1236,111 @@ type TemporalUIIngressSpec struct {
 	TLS []networkingv1.IngressTLS `json:"tls,omitempty" protobuf:"bytes,2,rep,name=tls"`
 }
 
+// GatewayHTTPRouteSpec contains all configurations options for a Gateway API HTTPRoute.
+type GatewayHTTPRouteSpec struct {
+	// Annotations allows custom annotations on the HTTPRoute resource.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// ParentRefs references the Gateways the route attaches to.
+	// +kubebuilder:validation:MinItems=1
+	ParentRefs []gatewayv1.ParentReference `json:"parentRefs"`
+	// Hostnames is the list of hostnames the route matches.
+	// +optional
+	Hostnames []gatewayv1.Hostname `json:"hostnames,omitempty"`
+	// PathMatches is the list of paths the route matches.
+	// Default to a "/" prefix match.
+	// +optional
+	PathMatches []gatewayv1.HTTPPathMatch `json:"pathMatches,omitempty"`
+}
+
+// TemporalUIAuthSpec contains the OIDC authentication configuration of the UI.
+type TemporalUIAuthSpec struct {
+	// ProviderURL is the URL of the OIDC provider.
+	ProviderURL string `json:"providerURL"`
+	// IssuerURL is the issuer URL of the OIDC provider, if it differs from the provider URL.
+	// +optional
+	IssuerURL string `json:"issuerURL,omitempty"`
+	// ClientID is the OIDC client ID of the UI.
+	ClientID string `json:"clientID"`
+	// ClientSecretRef references the secret key holding the OIDC client secret.
+	ClientSecretRef *corev1.SecretKeySelector `json:"clientSecretRef"`
+	// CallbackURL is the URL the OIDC provider redirects to after login.
+	// Usually "https://<ui host>/auth/sso/callback".
+	CallbackURL string `json:"callbackURL"`
+	// Scopes is the list of scopes requested to the OIDC provider.
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
+	// Label is the label of the login button.
+	// +optional
+	Label string `json:"label,omitempty"`
+	// UseIDTokenAsBearer uses the ID token instead of the access token as bearer token.
+	// Requires UI version >= 2.15.0.
+	// +optional
+	UseIDTokenAsBearer bool `json:"useIDTokenAsBearer,omitempty"`
+	// MaxSessionDuration is the maximum duration of a UI session.
+	// Requires UI version >= 2.26.0.
+	// +optional
+	MaxSessionDuration *metav1.Duration `json:"maxSessionDuration,omitempty"`
+}
+
+// TemporalUICodecSpec contains the remote codec configuration of the UI.
+type TemporalUICodecSpec struct {
+	// Endpoint is the URL of the remote codec server used to decode payloads.
+	// Defaults to the codec server deployed by the operator, if enabled.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+	// PassAccessToken sends the user access token to the codec server.
+	// +optional
+	PassAccessToken bool `json:"passAccessToken,omitempty"`
+	// IncludeCredentials includes cross-origin credentials in requests to the codec server.
+	// Requires UI version >= 2.16.0.
+	// +optional
+	IncludeCredentials bool `json:"includeCredentials,omitempty"`
+}
+
+// TemporalUIFeaturesSpec contains the UI features configuration.
+type TemporalUIFeaturesSpec struct {
+	// DisableWriteActions disables all the actions modifying workflows from the UI.
+	// +optional
+	DisableWriteActions bool `json:"disableWriteActions,omitempty"`
+	// WorkflowTerminateDisabled disables terminating workflows from the UI.
+	// Requires UI version >= 2.13.0.
+	// +optional
+	WorkflowTerminateDisabled bool `json:"workflowTerminateDisabled,omitempty"`
+	// WorkflowCancelDisabled disables canceling workflows from the UI.
+	// Requires UI version >= 2.13.0.
+	// +optional
+	WorkflowCancelDisabled bool `json:"workflowCancelDisabled,omitempty"`
+	// WorkflowSignalDisabled disables signaling workflows from the UI.
+	// Requires UI version >= 2.13.0.
+	// +optional
+	WorkflowSignalDisabled bool `json:"workflowSignalDisabled,omitempty"`
+	// WorkflowResetDisabled disables resetting workflows from the UI.
+	// Requires UI version >= 2.13.0.
+	// +optional
+	WorkflowResetDisabled bool `json:"workflowResetDisabled,omitempty"`
+	// BatchActionsDisabled disables batch actions from the UI.
+	// Requires UI version >= 2.13.0.
+	// +optional
+	BatchActionsDisabled bool `json:"batchActionsDisabled,omitempty"`
+	// StartWorkflowDisabled disables starting workflows from the UI.
+	// Requires UI version >= 2.25.0.
+	// +optional
+	StartWorkflowDisabled bool `json:"startWorkflowDisabled,omitempty"`
+	// HideWorkflowQueryErrors hides the errors returned by workflow queries.
+	// +optional
+	HideWorkflowQueryErrors bool `json:"hideWorkflowQueryErrors,omitempty"`
+	// ShowTemporalSystemNamespace shows the temporal-system namespace in the UI.
+	// +optional
+	ShowTemporalSystemNamespace bool `json:"showTemporalSystemNamespace,omitempty"`
+	// FeedbackURL is the URL of the feedback link displayed in the UI.
+	// +optional
+	FeedbackURL string `json:"feedbackURL,omitempty"`
+	// CORSOrigins is the list of origins allowed to make cross-origin requests to the UI server.
+	// +optional
+	CORSOrigins []string `json:"corsOrigins,omitempty"`
+}
+
 // TemporalUISpec defines parameters for the temporal UI within a Temporal cluster deployment.
 type TemporalUISpec struct {
 	// Enabled defines if the operator should deploy the web ui alongside the cluster.
@@ -572,9 +1367,75 @@ type TemporalUISpec struct {
 	// If lived empty, no ingress configuration will be created and the UI will only by available trough ClusterIP service.
 	// +optional
 	Ingress *TemporalUIIngressSpec `json:"ingress,omitempty"`
+	// Gateway is an optional Gateway API HTTPRoute configuration for the UI.
+	// If lived empty, no HTTPRoute will be created.
+	// +optional
+	Gateway *GatewayHTTPRouteSpec `json:"gateway,omitempty"`
 	// Service is an optional service resource configuration for the UI.
 	// +optional
 	Service *ObjectMetaOverride `json:"service,omitempty"`
+	// Auth configures the UI OIDC authentication.
+	// If lived empty, authentication is disabled.
+	// +optional
+	Auth *TemporalUIAuthSpec `json:"auth,omitempty"`
+	// Codec configures the remote codec server the UI uses to decode payloads.
+	// +optional
+	Codec *TemporalUICodecSpec `json:"codec,omitempty"`
+	// Features configures the UI features.
+	// +optional
+	Features *TemporalUIFeaturesSpec `json:"features,omitempty"`
+}
+
+// CodecServerSpec defines parameters for the codec server deployed alongside a Temporal cluster.
+// The codec server decodes payloads for the UI.
+type CodecServerSpec struct {
+	// Enabled defines if the operator should deploy the codec server alongside the cluster.
+	// +optional
+	Enabled bool `json:"enabled"`
+	// Image defines the codec server docker image, including its tag.
+	Image string `json:"image"`
+	// Args are arguments passed to the codec server container.
+	// +optional
+	Args []string `json:"args,omitempty"`
+	// Env are environment variables set on the codec server container.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// Port is the port the codec server listens on. Default to 8081.
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+	// Number of desired replicas for the codec server. Default to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Compute Resources required by the codec server.
+	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// KeysSecretRef references the secret holding the codec server key material.
+	// The secret is mounted in the codec server container at KeysMountPath.
+	// +optional
+	KeysSecretRef *corev1.LocalObjectReference `json:"keysSecretRef,omitempty"`
+	// KeysMountPath is the path the keys secret is mounted at. Default to "/etc/codec-server/keys".
+	// +optional
+	KeysMountPath string `json:"keysMountPath,omitempty"`
+	// Endpoint is the URL the UI uses to reach the codec server.
+	// As the codec server is called by the users' browsers, it should be reachable from outside the cluster.
+	// Defaults to the first ingress host, or to the codec server service URL if no ingress is configured.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+	// CORSOrigins is the list of additional origins allowed to call the codec server.
+	// The UI origins are always allowed.
+	// +optional
+	CORSOrigins []string `json:"corsOrigins,omitempty"`
+	// Ingress is an optional ingress configuration for the codec server.
+	// +optional
+	Ingress *TemporalUIIngressSpec `json:"ingress,omitempty"`
+	// Overrides adds some overrides to the resources deployed for the codec server.
+	// +optional
+	Overrides *ServiceSpecOverride `json:"overrides,omitempty"`
+	// Service is an optional service resource configuration for the codec server.
+	// +optional
+	Service *ObjectMetaOverride `json:"service,omitempty"`
 }
 
 // TemporalAdminToolsSpec defines parameters for the temporal admin tools within a Temporal cluster deployment.
@@ -604,8 +1465,14 @@ type MTLSProvider string
 const (
 	// CertManagerMTLSProvider uses cert-manager to manage mTLS certificate.
 	CertManagerMTLSProvider MTLSProvider = "cert-manager"
-	LinkerdMTLSProvider     MTLSProvider = "linkerd"
-	IstioMTLSProvider       MTLSProvider = "istio"
+	// OperatorMTLSProvider lets the operator issue and renew mTLS certificates itself.
+	OperatorMTLSProvider MTLSProvider = "operator"
+	// SpireMTLSProvider uses SPIFFE identities (SVIDs) issued by SPIRE.
+	SpireMTLSProvider MTLSProvider = "spire"
+	// LinkerdMTLSProvider delegates mTLS to the linkerd service mesh.
+	LinkerdMTLSProvider MTLSProvider = "linkerd"
+	// IstioMTLSProvider delegates mTLS to the istio service mesh.
+	IstioMTLSProvider MTLSProvider = "istio"
 )
 
 // FrontendMTLSSpec defines parameters for the temporal encryption in transit with mTLS.
@@ -662,6 +1529,84 @@ func (InternodeMTLSSpec) GetCertificateMountPath() string {
 	return "/etc/temporal/config/certs/cluster/internode"
 }
 
+// SpireWorkloadAPISpec defines how the SPIRE agent Workload API socket is mounted in the pods.
+type SpireWorkloadAPISpec struct {
+	// CSIDriver is the name of the SPIFFE CSI driver mounting the Workload API socket.
+	// Defaults to csi.spiffe.io.
+	// +optional
+	CSIDriver string `json:"csiDriver,omitempty"`
+	// HostPath is the path of the directory holding the Workload API socket on the nodes.
+	// If set, the socket is mounted using a hostPath volume instead of the SPIFFE CSI driver.
+	// +optional
+	HostPath string `json:"hostPath,omitempty"`
+	// SocketName is the name of the Workload API socket file.
+	// Defaults to spire-agent.sock.
+	// +optional
+	SocketName string `json:"socketName,omitempty"`
+}
+
+// SpireMTLSSpec defines parameters for internode mTLS using SPIFFE identities issued by SPIRE.
+// Peers are trusted at the trust domain level: any workload holding an SVID of the trust domain is accepted.
+type SpireMTLSSpec struct {
+	// TrustDomain is the SPIFFE trust domain of the cluster's workloads.
+	TrustDomain string `json:"trustDomain"`
+	// WorkloadAPI defines how the SPIRE agent Workload API socket is mounted in the pods.
+	// +optional
+	WorkloadAPI *SpireWorkloadAPISpec `json:"workloadAPI,omitempty"` //nolint:tagliatelle
+	// HelperImage is the spiffe-helper image used to write the SVIDs and the trust bundle to the pods filesystem.
+	// Defaults to ghcr.io/spiffe/spiffe-helper:0.8.0.
+	// +optional
+	HelperImage string `json:"helperImage,omitempty"`
+}
+
+// LinkerdMTLSSpec defines parameters for mTLS using the linkerd service mesh.
+type LinkerdMTLSSpec struct {
+	// AuthorizationPolicies defines if the operator should create linkerd Server, AuthorizationPolicy
+	// and MeshTLSAuthentication resources restricting who can reach the cluster's ports.
+	// Internode ports only accept the cluster's own service accounts.
+	// +optional
+	AuthorizationPolicies bool `json:"authorizationPolicies"`
+	// FrontendIdentities is the list of linkerd identities allowed to reach the frontend
+	// in addition to the cluster's own service accounts.
+	// Identities look like "<serviceaccount>.<namespace>.serviceaccount.identity.linkerd.<trust-domain>",
+	// "*" can be used as a prefix wildcard, or alone to allow all meshed clients.
+	// +optional
+	FrontendIdentities []string `json:"frontendIdentities,omitempty"`
+}
+
+// IstioAmbientSpec defines parameters for running the cluster in istio ambient mode.
+type IstioAmbientSpec struct {
+	// Enabled defines if the cluster's pods should be captured by the ambient data plane instead of sidecars.
+	// +optional
+	Enabled bool `json:"enabled"`
+	// LabelNamespace defines if the operator should enroll the cluster's namespace in the ambient mesh.
+	// Otherwise the pods are enrolled individually.
+	// +optional
+	LabelNamespace bool `json:"labelNamespace"`
+	// Waypoint defines if the operator should deploy a waypoint proxy for the cluster's frontend.
+	// Requires the Gateway API to be available in the cluster.
+	// +optional
+	Waypoint bool `json:"waypoint"`
+}
+
+// IstioMTLSSpec defines parameters for mTLS using the istio service mesh.
+type IstioMTLSSpec struct {
+	// AuthorizationPolicies defines if the operator should create istio AuthorizationPolicy resources
+	// restricting who can reach the cluster's ports.
+	// Internode ports only accept the cluster's own service accounts.
+	// +optional
+	AuthorizationPolicies bool `json:"authorizationPolicies"`
+	// FrontendPrincipals is the list of principals allowed to reach the frontend
+	// in addition to the cluster's own service accounts.
+	// Principals look like "<trust-domain>/ns/<namespace>/sa/<serviceaccount>",
+	// "*" can be used as a prefix or suffix wildcard, or alone to allow all authenticated clients.
+	// +optional
+	FrontendPrincipals []string `json:"frontendPrincipals,omitempty"`
+	// Ambient allows running the cluster in istio ambient mode.
+	// +optional
+	Ambient *IstioAmbientSpec `json:"ambient,omitempty"`
+}
+
 // CertificatesDurationSpec defines parameters for the temporal mTLS certificates duration.
 type CertificatesDurationSpec struct {
 	// RootCACertificate is the 'duration' (i.e. lifetime) of the Root CA Certificate.
@@ -690,30 +1635,30 @@ type CertificatesDurationSpec struct {
 type MTLSSpec struct {
 	// Provider defines the tool used to manage mTLS certificates.
 	// +kubebuilder:default=cert-manager
-	// +kubebuilder:validation:Enum=cert-manager;linkerd;istio
+	// +kubebuilder:validation:Enum=cert-manager;operator;spire;linkerd;istio
 	// +optional
 	Provider MTLSProvider `json:"provider"`
 	// Internode allows configuration of the internode traffic encryption.
-	// Useless if mTLS provider is not cert-manager.
+	// Useless if mTLS provider is not cert-manager, operator or spire.
 	// +optional
 	Internode *InternodeMTLSSpec `json:"internode,omitempty"`
 	// Frontend allows configuration of the frontend's public endpoint traffic encryption.
-	// Useless if mTLS provider is not cert-manager.
+	// Useless if mTLS provider is not cert-manager, operator or spire.
 	// +optional
 	Frontend *FrontendMTLSSpec `json:"frontend,omitempty"`
 	// CertificatesDuration allows configuration of maximum certificates lifetime.
-	// Useless if mTLS provider is not cert-manager.
+	// Useless if mTLS provider is not cert-manager or operator.
 	// +optional
 	CertificatesDuration *CertificatesDurationSpec `json:"certificatesDuration,omitempty"`
 	// RefreshInterval defines interval between refreshes of certificates in the cluster components.
 	// Defaults to 1 hour.
-	// Useless if mTLS provider is not cert-manager.
+	// Useless if mTLS provider is not cert-manager, operator or spire.
 	// +optional
 	RefreshInterval *metav1.Duration `json:"refreshInterval"`
 	// RenewBefore is defines how long before the currently issued certificate's expiry
-	// cert-manager should renew the certificate. The default is 2/3 of the
+	// cert-manager (or the operator) should renew the certificate. The default is 2/3 of the
 	// issued certificate's duration. Minimum accepted value is 5 minutes.
-	// Useless if mTLS provider is not cert-manager.
+	// Useless if mTLS provider is not cert-manager or operator.
 	// +optional
 	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
 	// PermissiveMetrics allows insecure HTTP requests to the metrics endpoint.
@@ -721,6 +1666,18 @@ type MTLSSpec struct {
 	// Useless if mTLS provider is not istio
 	// +optional
 	PermissiveMetrics bool `json:"permissiveMetrics"`
+	// Spire allows configuration of the SPIFFE identities used for mTLS.
+	// Required if mTLS provider is spire.
+	// +optional
+	Spire *SpireMTLSSpec `json:"spire,omitempty"`
+	// Linkerd allows configuration of the linkerd authorization policies.
+	// Useless if mTLS provider is not linkerd.
+	// +optional
+	Linkerd *LinkerdMTLSSpec `json:"linkerd,omitempty"`
+	// Istio allows configuration of the istio authorization policies and ambient mode.
+	// Useless if mTLS provider is not istio.
+	// +optional
+	Istio *IstioMTLSSpec `json:"istio,omitempty"`
 }
 
 func (m *MTLSSpec) InternodeEnabled() bool {
@@ -1046,6 +2003,9 @@ type TemporalClusterSpec struct {
 	// UI allows configuration of the optional temporal web ui deployed alongside the cluster.
 	// +optional
 	UI *TemporalUISpec `json:"ui,omitempty"`
+	// CodecServer allows configuration of the optional codec server deployed alongside the cluster.
+	// +optional
+	CodecServer *CodecServerSpec `json:"codecServer,omitempty"`
 	// AdminTools allows configuration of the optional admin tool pod deployed alongside the cluster.
 	// +optional
 	AdminTools *TemporalAdminToolsSpec `json:"admintools,omitempty"`
@@ -1074,6 +2034,44 @@ type ServiceStatus struct {
 	Version string `json:"version"`
 	// Ready defines if the service is ready.
 	Ready bool `json:"ready"`
+	// Canary is the status of the service canary.
+	// +optional
+	Canary *CanaryStatus `json:"canary,omitempty"`
+}
+
+// CanaryPhase is the phase of a service canary.
+type CanaryPhase string
+
+const (
+	// ProgressingCanaryPhase means the canary pods are not ready yet.
+	ProgressingCanaryPhase CanaryPhase = "Progressing"
+	// ObservingCanaryPhase means the canary pods are ready for less than the canary duration.
+	ObservingCanaryPhase CanaryPhase = "Observing"
+	// HealthyCanaryPhase means the canary pods are ready for at least the canary duration.
+	HealthyCanaryPhase CanaryPhase = "Healthy"
+	// PromotingCanaryPhase means the canary configuration is being rolled out to all the service pods.
+	PromotingCanaryPhase CanaryPhase = "Promoting"
+	// PromotedCanaryPhase means the canary configuration is rolled out to all the service pods.
+	PromotedCanaryPhase CanaryPhase = "Promoted"
+	// AbortedCanaryPhase means the canary pods were torn down.
+	AbortedCanaryPhase CanaryPhase = "Aborted"
+	// BlockedCanaryPhase means the canary pods can't run as the service stable workload would also select them.
+	// Workloads created before pods had a track label keep their selector, as it's immutable.
+	BlockedCanaryPhase CanaryPhase = "Blocked"
+)
+
+// CanaryStatus contains the current status of a service canary.
+type CanaryStatus struct {
+	// Phase is the current phase of the canary.
+	Phase CanaryPhase `json:"phase"`
+	// Version is the observed version of the canary pods.
+	// +optional
+	Version string `json:"version,omitempty"`
+	// Ready defines if the canary pods are ready.
+	Ready bool `json:"ready"`
+	// ReadySince is the time since which the canary pods are ready.
+	// +optional
+	ReadySince *metav1.Time `json:"readySince,omitempty"`
 }
 
 // DatastoreStatus contains the current status of a datastore.
@@ -1088,6 +2086,140 @@ type DatastoreStatus struct {
 	// SchemaVersion report the current schema version.
 	// +optional
 	SchemaVersion *version.Version `json:"schemaVersion,omitempty"`
+	// LastSchemaOperation reports the outcome of the last schema operation run on the datastore.
+	// +optional
+	LastSchemaOperation *SchemaOperationStatus `json:"lastSchemaOperation,omitempty"`
+	// PlannedMigrations reports the schema migrations planned for the cluster version, in plan only mode.
+	// +optional
+	PlannedMigrations *SchemaMigrationPlan `json:"plannedMigrations,omitempty"`
+	// LastBackup reports the last backup taken before a schema update.
+	// +optional
+	LastBackup *DatastoreBackupStatus `json:"lastBackup,omitempty"`
+	// Preflight reports the outcome of the last connectivity preflight checks run against the datastore.
+	// +optional
+	Preflight *DatastorePreflightStatus `json:"preflight,omitempty"`
+	// Managed reports the database cluster a managed datastore connects to.
+	// +optional
+	Managed *ManagedDatastoreStatus `json:"managed,omitempty"`
+}
+
+// ManagedDatastoreStatus reports the database cluster a managed datastore connects to.
+type ManagedDatastoreStatus struct {
+	// Kind is the kind of the database cluster.
+	Kind string `json:"kind"`
+	// Name is the name of the database cluster.
+	Name string `json:"name"`
+	// Ready indicates if the database cluster is ready.
+	Ready bool `json:"ready"`
+	// Address is the address the datastore connects to.
+	// +optional
+	Address string `json:"address,omitempty"`
+	// Secrets lists the database cluster secrets the datastore connection details are read from.
+	// +optional
+	Secrets []string `json:"secrets,omitempty"`
+}
+
+// DatastorePreflightStatus reports the connectivity preflight checks run against a datastore.
+type DatastorePreflightStatus struct {
+	// Hash identifies the datastore spec and secrets the checks ran against.
+	Hash string `json:"hash"`
+	// Passed indicates if all checks passed.
+	Passed bool `json:"passed"`
+	// Checks lists the checks which ran, in order.
+	// +optional
+	Checks []PreflightCheck `json:"checks,omitempty"`
+	// Error is the error which made the checks fail.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// PreflightCheck is the outcome of a single datastore connectivity check.
+type PreflightCheck struct {
+	// Name is the check name: dns, tcp, tls, authentication or privileges.
+	Name string `json:"name"`
+	// Passed indicates if the check passed.
+	Passed bool `json:"passed"`
+	// Message details the check outcome.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// DatastoreBackupStatus reports a backup taken before a schema update.
+type DatastoreBackupStatus struct {
+	// Version is the cluster version the backup was taken before upgrading to.
+	Version *version.Version `json:"version"`
+	// Location is where the backup is stored.
+	Location string `json:"location"`
+	// CompletedAt is the time the backup completed.
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// SchemaMigrationPlan lists the schema migrations an update to a cluster version would apply.
+type SchemaMigrationPlan struct {
+	// Version is the cluster version the migrations were planned for.
+	Version *version.Version `json:"version"`
+	// SchemaVersion is the schema version read from the datastore when planning.
+	// +optional
+	SchemaVersion string `json:"schemaVersion,omitempty"`
+	// Migrations lists the planned migrations, in order.
+	// +optional
+	Migrations []SchemaMigration `json:"migrations,omitempty"`
+}
+
+// SchemaMigration is a versioned schema migration.
+type SchemaMigration struct {
+	// Version is the schema version the migration upgrades to.
+	Version string `json:"version"`
+	// Directory is the versioned schema directory holding the migration.
+	Directory string `json:"directory"`
+	// Description is the migration description.
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Files lists the files applied by the migration, in order.
+	// +optional
+	Files []string `json:"files,omitempty"`
+}
+
+// SchemaOperation is an operation run by the schema runner on a datastore.
+// +kubebuilder:validation:Enum=create;setup;update;plan;check
+type SchemaOperation string
+
+const (
+	// CreateSchemaOperation creates the database or the keyspace.
+	CreateSchemaOperation SchemaOperation = "create"
+	// SetupSchemaOperation sets up the initial schema.
+	SetupSchemaOperation SchemaOperation = "setup"
+	// UpdateSchemaOperation upgrades the schema to the cluster version.
+	UpdateSchemaOperation SchemaOperation = "update"
+	// PlanSchemaOperation lists the migrations an update would apply, without applying them.
+	PlanSchemaOperation SchemaOperation = "plan"
+	// CheckSchemaOperation runs the connectivity preflight checks against the datastore.
+	CheckSchemaOperation SchemaOperation = "check"
+)
+
+// SchemaOperationStatus reports the outcome of a schema operation.
+type SchemaOperationStatus struct {
+	// Operation is the operation which ran.
+	Operation SchemaOperation `json:"operation"`
+	// SchemaVersion is the schema version read from the datastore once the operation completed.
+	// +optional
+	SchemaVersion string `json:"schemaVersion,omitempty"`
+	// AppliedVersions lists the schema versions applied by the operation, in order.
+	// +optional
+	AppliedVersions []string `json:"appliedVersions,omitempty"`
+	// Duration is the time the operation took.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// PlannedMigrations lists the migrations planned by a plan operation.
+	// +optional
+	PlannedMigrations []SchemaMigration `json:"plannedMigrations,omitempty"`
+	// Checks lists the preflight checks run by a check operation.
+	// +optional
+	Checks []PreflightCheck `json:"checks,omitempty"`
+	// Error is the error returned by the operation, if it failed.
+	// +optional
+	Error string `json:"error,omitempty"`
 }
 
 // TemporalPersistenceStatus contains temporal persistence status.
@@ -1102,6 +2234,47 @@ type TemporalPersistenceStatus struct {
 	// AdvancedVisibilityStore holds the advanced visibility datastore status.
 	// +optional
 	AdvancedVisibilityStore *DatastoreStatus `json:"advancedVisibilityStore,omitempty"`
+	// VisibilityMigration holds the visibility migration status.
+	// +optional
+	VisibilityMigration *VisibilityMigrationStatus `json:"visibilityMigration,omitempty"`
+}
+
+// VisibilityMigrationPhase is the phase of a visibility migration.
+type VisibilityMigrationPhase string
+
+const (
+	// SecondarySetupVisibilityMigrationPhase means the secondary visibility store schema is being set up.
+	SecondarySetupVisibilityMigrationPhase VisibilityMigrationPhase = "SecondarySetup"
+	// DualWriteVisibilityMigrationPhase means visibility records are written to both stores
+	// and read from the visibility store.
+	DualWriteVisibilityMigrationPhase VisibilityMigrationPhase = "DualWrite"
+	// ReadSecondaryVisibilityMigrationPhase means visibility records are written to both stores
+	// and read from the secondary visibility store.
+	ReadSecondaryVisibilityMigrationPhase VisibilityMigrationPhase = "ReadSecondary"
+	// PromotedVisibilityMigrationPhase means the secondary visibility store is used as the only visibility store.
+	PromotedVisibilityMigrationPhase VisibilityMigrationPhase = "Promoted"
+)
+
+// VisibilityMigrationPhaseStatus reports when a visibility migration phase started and completed.
+type VisibilityMigrationPhaseStatus struct {
+	// Phase is the visibility migration phase.
+	Phase VisibilityMigrationPhase `json:"phase"`
+	// StartedAt is the time the phase started.
+	StartedAt metav1.Time `json:"startedAt"`
+	// CompletedAt is the time the phase completed.
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// VisibilityMigrationStatus contains the current status of a visibility migration.
+type VisibilityMigrationStatus struct {
+	// Store is the name of the secondary visibility store the visibility is migrated to.
+	Store string `json:"store"`
+	// Phase is the current phase of the migration.
+	Phase VisibilityMigrationPhase `json:"phase"`
+	// Phases lists the phases the migration went through, in order.
+	// +optional
+	Phases []VisibilityMigrationPhaseStatus `json:"phases,omitempty"`
 }
 
 // TemporalClusterStatus defines the observed state of Cluster.
@@ -1123,6 +2296,7 @@ func (s *TemporalClusterStatus) AddServiceStatus(status *ServiceStatus) {
 		if serviceStatus.Name == status.Name {
 			s.Services[i].Version = status.Version
 			s.Services[i].Ready = status.Ready
+			s.Services[i].Canary = status.Canary
 			found = true
 		}
 	}
@@ -1176,11 +2350,259 @@ func (c *TemporalCluster) MTLSWithCertManagerEnabled() bool {
 		c.Spec.MTLS.Provider == CertManagerMTLSProvider
 }
 
+// MTLSWithOperatorEnabled returns true if mTLS is enabled for internode or frontend using certificates issued by the operator.
+func (c *TemporalCluster) MTLSWithOperatorEnabled() bool {
+	return c.Spec.MTLS != nil &&
+		(c.Spec.MTLS.InternodeEnabled() || c.Spec.MTLS.FrontendEnabled()) &&
+		c.Spec.MTLS.Provider == OperatorMTLSProvider
+}
+
+// MTLSWithSpireEnabled returns true if mTLS is enabled for internode or frontend using SPIFFE identities issued by SPIRE.
+func (c *TemporalCluster) MTLSWithSpireEnabled() bool {
+	return c.Spec.MTLS != nil &&
+		(c.Spec.MTLS.InternodeEnabled() || c.Spec.MTLS.FrontendEnabled()) &&
+		c.Spec.MTLS.Provider == SpireMTLSProvider &&
+		c.Spec.MTLS.Spire != nil
+}
+
+// LinkerdAuthorizationPoliciesEnabled returns true if linkerd policy resources should be created for the cluster.
+func (c *TemporalCluster) LinkerdAuthorizationPoliciesEnabled() bool {
+	return c.Spec.MTLS != nil &&
+		c.Spec.MTLS.Provider == LinkerdMTLSProvider &&
+		c.Spec.MTLS.Linkerd != nil &&
+		c.Spec.MTLS.Linkerd.AuthorizationPolicies
+}
+
+// MTLSWithIstioEnabled returns true if the cluster's mTLS is handled by istio.
+func (c *TemporalCluster) MTLSWithIstioEnabled() bool {
+	return c.Spec.MTLS != nil && c.Spec.MTLS.Provider == IstioMTLSProvider
+}
+
+// IstioAmbientEnabled returns true if the cluster runs in istio ambient mode.
+func (c *TemporalCluster) IstioAmbientEnabled() bool {
+	return c.MTLSWithIstioEnabled() &&
+		c.Spec.MTLS.Istio != nil &&
+		c.Spec.MTLS.Istio.Ambient != nil &&
+		c.Spec.MTLS.Istio.Ambient.Enabled
+}
+
+// IstioWaypointEnabled returns true if a waypoint proxy should be deployed for the cluster.
+func (c *TemporalCluster) IstioWaypointEnabled() bool {
+	return c.IstioAmbientEnabled() && c.Spec.MTLS.Istio.Ambient.Waypoint
+}
+
+// IstioAuthorizationPoliciesEnabled returns true if istio authorization policies should be created for the cluster.
+func (c *TemporalCluster) IstioAuthorizationPoliciesEnabled() bool {
+	return c.MTLSWithIstioEnabled() &&
+		c.Spec.MTLS.Istio != nil &&
+		c.Spec.MTLS.Istio.AuthorizationPolicies
+}
+
+// MTLSWithCertificatesEnabled returns true if mTLS is enabled for internode or frontend using certificates
+// mounted from secrets, either managed by cert-manager or by the operator.
+func (c *TemporalCluster) MTLSWithCertificatesEnabled() bool {
+	return c.MTLSWithCertManagerEnabled() || c.MTLSWithOperatorEnabled()
+}
+
+// FrontendDNSNames returns the DNS names of the frontend mTLS certificate.
+// It includes the user-supplied extra DNS names and the hostnames the frontend is exposed with.
+func (c *TemporalCluster) FrontendDNSNames() []string {
+	names := []string{}
+	if c.Spec.MTLS != nil && c.Spec.MTLS.Frontend != nil {
+		names = append(names, c.Spec.MTLS.Frontend.ServerName(c))
+		names = append(names, c.Spec.MTLS.Frontend.ExtraDNSNames...)
+	}
+	if c.Spec.Services != nil && c.Spec.Services.Frontend != nil {
+		names = append(names, c.Spec.Services.Frontend.Expose.Hostnames()...)
+	}
+
+	result := []string{}
+	for _, name := range names {
+		if !slices.Contains(result, name) {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// CodecServerEnabled returns true if the operator deploys a codec server for the cluster.
+func (c *TemporalCluster) CodecServerEnabled() bool {
+	return c.Spec.CodecServer != nil && c.Spec.CodecServer.Enabled
+}
+
+// CodecServerEndpoint returns the URL the UI uses to reach the codec server.
+func (c *TemporalCluster) CodecServerEndpoint() string {
+	if !c.CodecServerEnabled() {
+		return ""
+	}
+
+	if c.Spec.CodecServer.Endpoint != "" {
+		return c.Spec.CodecServer.Endpoint
+	}
+
+	if origins := c.Spec.CodecServer.Ingress.origins(); len(origins) > 0 {
+		return origins[0]
+	}
+
+	return fmt.Sprintf("http://%s.%s.svc:%d", c.ChildResourceName("codec-server"), c.Namespace, *c.Spec.CodecServer.Port)
+}
+
+// UIOrigins returns the origins the UI is served from, computed from the UI ingress hosts and gateway hostnames.
+func (c *TemporalCluster) UIOrigins() []string {
+	if c.Spec.UI == nil || !c.Spec.UI.Enabled {
+		return nil
+	}
+
+	origins := c.Spec.UI.Ingress.origins()
+	if c.Spec.UI.Gateway != nil {
+		for _, hostname := range c.Spec.UI.Gateway.Hostnames {
+			origins = append(origins, fmt.Sprintf("https://%s", hostname))
+		}
+	}
+
+	return origins
+}
+
+// origins returns the origins served by the ingress.
+// Hosts covered by a TLS configuration are served using https.
+func (s *TemporalUIIngressSpec) origins() []string {
+	if s == nil {
+		return nil
+	}
+
+	origins := []string{}
+	for _, host := range s.Hosts {
+		// Hosts may contain a path, only keep the host.
+		host, _, _ = strings.Cut(host, "/")
+
+		scheme := "http"
+		for _, tls := range s.TLS {
+			if slices.Contains(tls.Hosts, host) {
+				scheme = "https"
+			}
+		}
+
+		origin := fmt.Sprintf("%s://%s", scheme, host)
+		if !slices.Contains(origins, origin) {
+			origins = append(origins, origin)
+		}
+	}
+
+	return origins
+}
+
+// ServiceImage returns the temporal server image of the provided service, including its tag.
+// Once a canary is promoted, its image is used.
+func (c *TemporalCluster) ServiceImage(spec *ServiceSpec) string {
+	if spec != nil && spec.Canary.IsPromoted() {
+		spec = spec.WithCanary()
+	}
+	image := c.Spec.Image
+	if spec != nil && spec.Image != "" {
+		image = spec.Image
+	}
+	return fmt.Sprintf("%s:%s", image, c.ServiceVersion(spec))
+}
+
+// ServiceVersion returns the temporal version of the provided service.
+// Once a canary is promoted, its version is used.
+func (c *TemporalCluster) ServiceVersion(spec *ServiceSpec) *version.Version {
+	if spec != nil && spec.Canary.IsPromoted() {
+		spec = spec.WithCanary()
+	}
+	if spec != nil && spec.Version != nil {
+		return spec.Version
+	}
+	return c.Spec.Version
+}
+
 // ChildResourceName returns child resource name using the cluster's name.
 func (c *TemporalCluster) ChildResourceName(resource string) string {
 	return fmt.Sprintf("%s-%s", c.Name, resource)
 }
 
+// PersistencePlanOnlyAnnotation enables the persistence plan only mode when set to "true".
+const PersistencePlanOnlyAnnotation = "operator.temporal.io/persistence-plan-only"
+
+// IsPersistencePlanOnly returns true if schema updates should only be planned,
+// using either spec.persistence.planOnly or the PersistencePlanOnlyAnnotation annotation.
+func (c *TemporalCluster) IsPersistencePlanOnly() bool {
+	return c.Spec.Persistence.PlanOnly || c.Annotations[PersistencePlanOnlyAnnotation] == "true"
+}
+
+const (
+	// SecondaryVisibilityWritingModeDynamicConfigKey is the dynamic config key setting how visibility records
+	// are written to the secondary visibility store.
+	SecondaryVisibilityWritingModeDynamicConfigKey = "system.secondaryVisibilityWritingMode"
+	// EnableReadFromSecondaryVisibilityDynamicConfigKey is the dynamic config key making temporal read
+	// visibility records from the secondary visibility store.
+	EnableReadFromSecondaryVisibilityDynamicConfigKey = "system.enableReadFromSecondaryVisibility"
+)
+
+// VisibilityMigrationPhase returns the current phase of the visibility migration.
+// It returns an empty phase if no migration to the secondary visibility store was started.
+func (c *TemporalCluster) VisibilityMigrationPhase() VisibilityMigrationPhase {
+	if c.Spec.Persistence.VisibilityMigration == nil || c.Spec.Persistence.SecondaryVisibilityStore == nil {
+		return ""
+	}
+
+	if c.Status.Persistence == nil || c.Status.Persistence.VisibilityMigration == nil {
+		return ""
+	}
+
+	if c.Status.Persistence.VisibilityMigration.Store != c.Spec.Persistence.SecondaryVisibilityStore.Name {
+		return ""
+	}
+
+	return c.Status.Persistence.VisibilityMigration.Phase
+}
+
+// GetDynamicConfig returns the cluster dynamic config.
+// While a visibility migration is running, the secondary visibility keys are set according to the migration phase,
+// overriding the values provided in spec.dynamicConfig.
+func (c *TemporalCluster) GetDynamicConfig() *DynamicConfigSpec {
+	if c.Spec.Persistence.VisibilityMigration == nil {
+		return c.Spec.DynamicConfig
+	}
+
+	dynamicConfig := &DynamicConfigSpec{
+		PollInterval: &metav1.Duration{Duration: time.Minute * 10},
+	}
+	if c.Spec.DynamicConfig != nil {
+		dynamicConfig = c.Spec.DynamicConfig.DeepCopy()
+	}
+	if dynamicConfig.Values == nil {
+		dynamicConfig.Values = map[string][]ConstrainedValue{}
+	}
+
+	writingMode, readFromSecondary := "off", false
+	switch c.VisibilityMigrationPhase() {
+	case DualWriteVisibilityMigrationPhase:
+		writingMode = "dual"
+	case ReadSecondaryVisibilityMigrationPhase:
+		writingMode, readFromSecondary = "dual", true
+	case PromotedVisibilityMigrationPhase:
+		// The secondary store is now the only visibility store.
+		delete(dynamicConfig.Values, SecondaryVisibilityWritingModeDynamicConfigKey)
+		delete(dynamicConfig.Values, EnableReadFromSecondaryVisibilityDynamicConfigKey)
+		return dynamicConfig
+	}
+
+	dynamicConfig.Values[SecondaryVisibilityWritingModeDynamicConfigKey] = []ConstrainedValue{
+		{Value: &apiextensionsv1.JSON{Raw: []byte(strconv.Quote(writingMode))}},
+	}
+	dynamicConfig.Values[EnableReadFromSecondaryVisibilityDynamicConfigKey] = []ConstrainedValue{
+		{Value: &apiextensionsv1.JSON{Raw: []byte(strconv.FormatBool(readFromSecondary))}},
+	}
+
+	return dynamicConfig
+}
+
+// CanaryResourceName returns the name of the canary resources of the provided service.
+func (c *TemporalCluster) CanaryResourceName(service string) string {
+	return c.ChildResourceName(fmt.Sprintf("%s-canary", service))
+}
+
 func (c *TemporalCluster) GetPublicClientAddress() string {
 	// If mTLS frontend is enabled, always use the public frontend address
 	if c.Spec.MTLS != nil && c.Spec.MTLS.Frontend != nil && c.Spec.MTLS.Frontend.Enabled {
@@ -1,25 +1,654 @@
 package v1beta1
 
 import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/alexandrevilain/temporal-operator/pkg/version"
+	corev1 "k8s.io/api/core/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+var trustDomainRegexp = regexp.MustCompile(`^[a-z0-9._-]+$`)
+
 func (m *MTLSSpec) Validate() (admission.Warnings, field.ErrorList) {
 	var warns admission.Warnings
 	var errs field.ErrorList
 
-	if m == nil || m.Provider != CertManagerMTLSProvider {
+	if m == nil {
 		return nil, nil
 	}
 
-	if m.RenewBefore != nil {
-		if m.RenewBefore.Duration < 5*time.Minute {
-			errs = append(errs, field.Invalid(field.NewPath("spec.mTLS.renewBefore"), m.RenewBefore, "must be at least 5 minutes"))
+	switch m.Provider {
+	case CertManagerMTLSProvider, OperatorMTLSProvider:
+		if m.RenewBefore != nil {
+			if m.RenewBefore.Duration < 5*time.Minute {
+				errs = append(errs, field.Invalid(field.NewPath("spec.mTLS.renewBefore"), m.RenewBefore, "must be at least 5 minutes"))
+			}
 		}
+	case SpireMTLSProvider:
+		errs = append(errs, m.Spire.validate(field.NewPath("spec", "mTLS", "spire"))...)
+	case LinkerdMTLSProvider:
+		errs = append(errs, m.Linkerd.validate(field.NewPath("spec", "mTLS", "linkerd"))...)
+	case IstioMTLSProvider:
+		errs = append(errs, m.Istio.validate(field.NewPath("spec", "mTLS", "istio"))...)
 	}
 
 	return warns, errs
 }
+
+func (s *SpireMTLSSpec) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if s == nil {
+		return append(errs, field.Required(path, "required when mTLS provider is spire"))
+	}
+
+	if !trustDomainRegexp.MatchString(s.TrustDomain) {
+		errs = append(errs, field.Invalid(path.Child("trustDomain"), s.TrustDomain, "must be a valid SPIFFE trust domain"))
+	}
+
+	return errs
+}
+
+func (l *LinkerdMTLSSpec) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if l == nil {
+		return nil
+	}
+
+	for i, identity := range l.FrontendIdentities {
+		// Linkerd only supports "*" alone or as the first label of the identity.
+		invalidWildcard := identity != "*" && strings.Contains(strings.TrimPrefix(identity, "*."), "*")
+		if identity == "" || invalidWildcard {
+			errs = append(errs, field.Invalid(path.Child("frontendIdentities").Index(i), identity, "must be \"*\" or an identity with an optional \"*.\" prefix"))
+		}
+	}
+
+	return errs
+}
+
+func (i *IstioMTLSSpec) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if i == nil {
+		return nil
+	}
+
+	for j, principal := range i.FrontendPrincipals {
+		// Istio only supports "*" as a prefix or as a suffix of the principal.
+		wildcards := strings.Count(principal, "*")
+		validWildcard := wildcards == 0 || (wildcards == 1 && (strings.HasPrefix(principal, "*") || strings.HasSuffix(principal, "*")))
+		if principal == "" || !validWildcard {
+			errs = append(errs, field.Invalid(path.Child("frontendPrincipals").Index(j), principal, "must be \"*\" or a principal with an optional \"*\" prefix or suffix"))
+		}
+	}
+
+	if i.Ambient != nil && !i.Ambient.Enabled && (i.Ambient.Waypoint || i.Ambient.LabelNamespace) {
+		errs = append(errs, field.Invalid(path.Child("ambient", "enabled"), i.Ambient.Enabled, "must be enabled to use a waypoint or to label the namespace"))
+	}
+
+	return errs
+}
+
+// uiFeatureMinVersions holds the minimal UI version supporting each UI setting
+// not available in every UI release.
+var uiFeatureMinVersions = []struct {
+	path    []string
+	version *version.Version
+	isSet   func(s *TemporalUISpec) bool
+}{
+	{
+		path:    []string{"auth", "useIDTokenAsBearer"},
+		version: version.MustNewVersionFromString("2.15.0"),
+		isSet:   func(s *TemporalUISpec) bool { return s.Auth != nil && s.Auth.UseIDTokenAsBearer },
+	},
+	{
+		path:    []string{"auth", "maxSessionDuration"},
+		version: version.MustNewVersionFromString("2.26.0"),
+		isSet:   func(s *TemporalUISpec) bool { return s.Auth != nil && s.Auth.MaxSessionDuration != nil },
+	},
+	{
+		path:    []string{"codec", "includeCredentials"},
+		version: version.MustNewVersionFromString("2.16.0"),
+		isSet:   func(s *TemporalUISpec) bool { return s.Codec != nil && s.Codec.IncludeCredentials },
+	},
+	{
+		path:    []string{"features", "workflowTerminateDisabled"},
+		version: version.MustNewVersionFromString("2.13.0"),
+		isSet:   func(s *TemporalUISpec) bool { return s.Features != nil && s.Features.WorkflowTerminateDisabled },
+	},
+	{
+		path:    []string{"features", "workflowCancelDisabled"},
+		version: version.MustNewVersionFromString("2.13.0"),
+		isSet:   func(s *TemporalUISpec) bool { return s.Features != nil && s.Features.WorkflowCancelDisabled },
+	},
+	{
+		path:    []string{"features", "workflowSignalDisabled"},
+		version: version.MustNewVersionFromString("2.13.0"),
+		isSet:   func(s *TemporalUISpec) bool { return s.Features != nil && s.Features.WorkflowSignalDisabled },
+	},
+	{
+		path:    []string{"features", "workflowResetDisabled"},
+		version: version.MustNewVersionFromString("2.13.0"),
+		isSet:   func(s *TemporalUISpec) bool { return s.Features != nil && s.Features.WorkflowResetDisabled },
+	},
+	{
+		path:    []string{"features", "batchActionsDisabled"},
+		version: version.MustNewVersionFromString("2.13.0"),
+		isSet:   func(s *TemporalUISpec) bool { return s.Features != nil && s.Features.BatchActionsDisabled },
+	},
+	{
+		path:    []string{"features", "startWorkflowDisabled"},
+		version: version.MustNewVersionFromString("2.25.0"),
+		isSet:   func(s *TemporalUISpec) bool { return s.Features != nil && s.Features.StartWorkflowDisabled },
+	},
+}
+
+// Validate validates the service pods disruption budget and scheduling fields.
+func (s *ServiceSpec) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if s == nil {
+		return errs
+	}
+
+	if pdb := s.PodDisruptionBudget; pdb != nil && pdb.MinAvailable != nil && pdb.MaxUnavailable != nil {
+		errs = append(errs, field.Forbidden(path.Child("podDisruptionBudget"), "minAvailable and maxUnavailable are mutually exclusive"))
+	}
+
+	for i, constraint := range s.TopologySpreadConstraints {
+		errs = append(errs, validateTopologySpreadConstraint(constraint, path.Child("topologySpreadConstraints").Index(i))...)
+	}
+
+	if s.Affinity != nil {
+		errs = append(errs, validateAffinity(s.Affinity, path.Child("affinity"))...)
+	}
+
+	errs = append(errs, metav1validation.ValidateLabels(s.NodeSelector, path.Child("nodeSelector"))...)
+
+	for i, toleration := range s.Tolerations {
+		errs = append(errs, validateToleration(toleration, path.Child("tolerations").Index(i))...)
+	}
+
+	if s.PriorityClassName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(s.PriorityClassName) {
+			errs = append(errs, field.Invalid(path.Child("priorityClassName"), s.PriorityClassName, msg))
+		}
+	}
+
+	if s.Shutdown != nil {
+		errs = append(errs, s.Shutdown.validate(path.Child("shutdown"))...)
+	}
+
+	if s.Canary != nil {
+		errs = append(errs, s.Canary.validate(s, path.Child("canary"))...)
+	}
+
+	return errs
+}
+
+// Validate validates the datastore backup, password rotation and token authentication fields.
+func (s *DatastoreSpec) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if s == nil {
+		return errs
+	}
+
+	if s.Backup != nil {
+		errs = append(errs, s.Backup.validate(s, path.Child("backup"))...)
+	}
+
+	if s.IsDualCredentialPasswordRotation() && s.PasswordSecretRef == nil {
+		errs = append(errs, field.Required(path.Child("passwordSecretRef"), "a password secret is required for dual credential password rotation"))
+	}
+
+	if s.IsManaged() {
+		errs = append(errs, s.Managed.validate(path.Child("managed"))...)
+
+		// The connection details are derived from the managed database cluster.
+		derived := []struct {
+			name string
+			set  bool
+		}{
+			{"sql", s.SQL != nil},
+			{"cassandra", s.Cassandra != nil},
+			{"elasticsearch", s.Elasticsearch != nil},
+			{"passwordSecretRef", s.PasswordSecretRef != nil},
+			{"tls", s.TLS != nil},
+		}
+		for _, f := range derived {
+			if f.set {
+				errs = append(errs, field.Forbidden(path.Child(f.name), "can't be set for managed datastores"))
+			}
+		}
+	}
+
+	if s.IsSQLTokenAuth() {
+		errs = append(errs, s.SQL.Auth.validate(path.Child("sql", "auth"))...)
+
+		if s.PasswordSecretRef != nil {
+			errs = append(errs, field.Forbidden(path.Child("passwordSecretRef"), "can't be set when the datastore authenticates with tokens"))
+		}
+
+		if s.TLS == nil || !s.TLS.Enabled {
+			errs = append(errs, field.Required(path.Child("tls"), "TLS must be enabled to send authentication tokens to the datastore"))
+		}
+
+		// The backup presets authenticate with the datastore password.
+		if s.Backup != nil && s.Backup.Preset != nil {
+			errs = append(errs, field.Forbidden(path.Child("backup", "preset"), "backup presets don't support token authentication"))
+		}
+	}
+
+	if s.Elasticsearch != nil && s.Elasticsearch.Auth != nil {
+		errs = append(errs, s.Elasticsearch.Auth.validate(path.Child("elasticsearch", "auth"))...)
+
+		if s.Elasticsearch.Username != "" {
+			errs = append(errs, field.Forbidden(path.Child("elasticsearch", "username"), "can't be set along with auth, use auth.basic.username instead"))
+		}
+
+		if (s.IsElasticsearchAPIKeyAuth() || s.IsElasticsearchAWSRequestSigning()) && s.PasswordSecretRef != nil {
+			errs = append(errs, field.Forbidden(path.Child("passwordSecretRef"), "can't be set when the datastore doesn't authenticate with a password"))
+		}
+	}
+
+	return errs
+}
+
+func (a *ElasticsearchAuthSpec) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	modes := 0
+	for _, set := range []bool{a.Basic != nil, a.APIKey != nil, a.AWSRequestSigning != nil} {
+		if set {
+			modes++
+		}
+	}
+	if modes != 1 {
+		errs = append(errs, field.Invalid(path, modes, "exactly one of basic, apiKey or awsRequestSigning must be set"))
+	}
+
+	if a.Basic != nil && a.Basic.Username == "" {
+		errs = append(errs, field.Required(path.Child("basic", "username"), "a username is required for basic authentication"))
+	}
+
+	if a.APIKey != nil && a.APIKey.SecretRef.Name == "" {
+		errs = append(errs, field.Required(path.Child("apiKey", "secretRef", "name"), "the secret holding the API key is required"))
+	}
+
+	if a.AWSRequestSigning != nil && a.AWSRequestSigning.Region == "" {
+		errs = append(errs, field.Required(path.Child("awsRequestSigning", "region"), "the AWS region is required to sign requests"))
+	}
+
+	return errs
+}
+
+func (m *ManagedDatastoreSpec) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	providers := 0
+	for _, set := range []bool{m.CloudNativePG != nil, m.PerconaXtraDB != nil, m.K8ssandra != nil} {
+		if set {
+			providers++
+		}
+	}
+	if providers != 1 {
+		errs = append(errs, field.Invalid(path, providers, "exactly one of cloudNativePG, perconaXtraDB or k8ssandra must be set"))
+	}
+
+	return errs
+}
+
+func (a *SQLAuthSpec) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	modes := 0
+	for _, set := range []bool{a.AWSIAM != nil, a.AzureAD != nil, a.GCPIAM != nil} {
+		if set {
+			modes++
+		}
+	}
+	if modes != 1 {
+		errs = append(errs, field.Invalid(path, modes, "exactly one of awsIAM, azureAD or gcpIAM must be set"))
+	}
+
+	return errs
+}
+
+func (b *DatastoreBackupSpec) validate(datastore *DatastoreSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	methods := 0
+	for _, set := range []bool{b.Job != nil, b.Preset != nil, b.VolumeSnapshot != nil} {
+		if set {
+			methods++
+		}
+	}
+	if methods != 1 {
+		return append(errs, field.Invalid(path, methods, "exactly one of job, preset or volumeSnapshot must be set"))
+	}
+
+	if b.Job != nil && b.Job.Template.Spec == nil {
+		errs = append(errs, field.Required(path.Child("job", "template", "spec"), "required to run the backup job"))
+	}
+
+	if b.VolumeSnapshot != nil && b.VolumeSnapshot.PersistentVolumeClaimName == "" {
+		errs = append(errs, field.Required(path.Child("volumeSnapshot", "persistentVolumeClaimName"), "required to take a volume snapshot"))
+	}
+
+	if b.Preset != nil {
+		errs = append(errs, b.Preset.validate(datastore, path.Child("preset"))...)
+	}
+
+	return errs
+}
+
+func (p *DatastoreBackupPresetSpec) validate(datastore *DatastoreSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	switch {
+	case datastore.IsSQL():
+		if (p.PersistentVolumeClaim == nil) == (p.S3 == nil) {
+			errs = append(errs, field.Invalid(path, datastore.GetType(), "exactly one of persistentVolumeClaim or s3 must be set for SQL datastores"))
+		}
+	case datastore.GetType() == CassandraDatastore:
+		// nodetool snapshots are stored on the cassandra nodes.
+		if p.PersistentVolumeClaim != nil || p.S3 != nil {
+			errs = append(errs, field.Forbidden(path, "persistentVolumeClaim and s3 are not supported for cassandra, snapshots are stored on the cassandra nodes"))
+		}
+	default:
+		errs = append(errs, field.Forbidden(path, fmt.Sprintf("backup preset is not supported for %s datastores", datastore.GetType())))
+	}
+
+	if p.PersistentVolumeClaim != nil && p.PersistentVolumeClaim.ClaimName == "" {
+		errs = append(errs, field.Required(path.Child("persistentVolumeClaim", "claimName"), "required to store the backup"))
+	}
+
+	if p.S3 != nil && !strings.HasPrefix(p.S3.URL, "s3://") {
+		errs = append(errs, field.Invalid(path.Child("s3", "url"), p.S3.URL, "must be an s3:// URL"))
+	}
+
+	return errs
+}
+
+func (c *ServiceCanarySpec) validate(service *ServiceSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	// Canary pods are taken from the service replicas, at least one stable pod has to remain.
+	if service.Autoscaling == nil && service.Replicas != nil && c.GetReplicas() >= *service.Replicas {
+		errs = append(errs, field.Invalid(path.Child("replicas"), c.GetReplicas(), "must be lower than the service replicas"))
+	}
+
+	if c.Duration != nil && c.Duration.Duration < 0 {
+		errs = append(errs, field.Invalid(path.Child("duration"), c.Duration.Duration.String(), "must be greater than or equal to 0"))
+	}
+
+	return errs
+}
+
+func (s *ServiceShutdownSpec) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	var preStopDelay time.Duration
+	if s.PreStopDelay != nil {
+		preStopDelay = s.PreStopDelay.Duration
+		if preStopDelay < 0 {
+			errs = append(errs, field.Invalid(path.Child("preStopDelay"), s.PreStopDelay.Duration.String(), "must be greater than or equal to 0"))
+		}
+	}
+
+	if s.TerminationGracePeriod != nil && s.TerminationGracePeriod.Duration <= preStopDelay {
+		errs = append(errs, field.Invalid(path.Child("terminationGracePeriod"), s.TerminationGracePeriod.Duration.String(), "must be greater than the preStop delay"))
+	}
+
+	return errs
+}
+
+func validateTopologySpreadConstraint(constraint corev1.TopologySpreadConstraint, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if constraint.MaxSkew <= 0 {
+		errs = append(errs, field.Invalid(path.Child("maxSkew"), constraint.MaxSkew, "must be greater than zero"))
+	}
+
+	if constraint.TopologyKey == "" {
+		errs = append(errs, field.Required(path.Child("topologyKey"), "can not be empty"))
+	} else {
+		for _, msg := range validation.IsQualifiedName(constraint.TopologyKey) {
+			errs = append(errs, field.Invalid(path.Child("topologyKey"), constraint.TopologyKey, msg))
+		}
+	}
+
+	switch constraint.WhenUnsatisfiable {
+	case corev1.DoNotSchedule, corev1.ScheduleAnyway:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("whenUnsatisfiable"), constraint.WhenUnsatisfiable, []string{string(corev1.DoNotSchedule), string(corev1.ScheduleAnyway)}))
+	}
+
+	if constraint.MinDomains != nil && *constraint.MinDomains <= 0 {
+		errs = append(errs, field.Invalid(path.Child("minDomains"), *constraint.MinDomains, "must be greater than zero"))
+	}
+
+	if constraint.MinDomains != nil && constraint.WhenUnsatisfiable != corev1.DoNotSchedule {
+		errs = append(errs, field.Invalid(path.Child("minDomains"), *constraint.MinDomains, "can only be set when whenUnsatisfiable is DoNotSchedule"))
+	}
+
+	errs = append(errs, metav1validation.ValidateLabelSelector(constraint.LabelSelector, metav1validation.LabelSelectorValidationOptions{}, path.Child("labelSelector"))...)
+
+	return errs
+}
+
+func validateAffinity(affinity *corev1.Affinity, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if nodeAffinity := affinity.NodeAffinity; nodeAffinity != nil {
+		required := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		if required != nil && len(required.NodeSelectorTerms) == 0 {
+			errs = append(errs, field.Required(path.Child("nodeAffinity", "requiredDuringSchedulingIgnoredDuringExecution", "nodeSelectorTerms"), "must have at least one node selector term"))
+		}
+
+		for i, term := range nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			if term.Weight < 1 || term.Weight > 100 {
+				errs = append(errs, field.Invalid(path.Child("nodeAffinity", "preferredDuringSchedulingIgnoredDuringExecution").Index(i).Child("weight"), term.Weight, "must be in the range 1-100"))
+			}
+		}
+	}
+
+	if affinity.PodAffinity != nil {
+		errs = append(errs, validatePodAffinityTerms(
+			affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			path.Child("podAffinity"),
+		)...)
+	}
+
+	if affinity.PodAntiAffinity != nil {
+		errs = append(errs, validatePodAffinityTerms(
+			affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			path.Child("podAntiAffinity"),
+		)...)
+	}
+
+	return errs
+}
+
+func validatePodAffinityTerms(required []corev1.PodAffinityTerm, preferred []corev1.WeightedPodAffinityTerm, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	validateTerm := func(term corev1.PodAffinityTerm, termPath *field.Path) {
+		if term.TopologyKey == "" {
+			errs = append(errs, field.Required(termPath.Child("topologyKey"), "can not be empty"))
+		}
+		errs = append(errs, metav1validation.ValidateLabelSelector(term.LabelSelector, metav1validation.LabelSelectorValidationOptions{}, termPath.Child("labelSelector"))...)
+	}
+
+	for i, term := range required {
+		validateTerm(term, path.Child("requiredDuringSchedulingIgnoredDuringExecution").Index(i))
+	}
+
+	for i, term := range preferred {
+		termPath := path.Child("preferredDuringSchedulingIgnoredDuringExecution").Index(i)
+		if term.Weight < 1 || term.Weight > 100 {
+			errs = append(errs, field.Invalid(termPath.Child("weight"), term.Weight, "must be in the range 1-100"))
+		}
+		validateTerm(term.PodAffinityTerm, termPath.Child("podAffinityTerm"))
+	}
+
+	return errs
+}
+
+func validateToleration(toleration corev1.Toleration, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if toleration.Key != "" {
+		for _, msg := range validation.IsQualifiedName(toleration.Key) {
+			errs = append(errs, field.Invalid(path.Child("key"), toleration.Key, msg))
+		}
+	}
+
+	switch toleration.Operator {
+	case corev1.TolerationOpEqual, "":
+		if toleration.Key == "" {
+			errs = append(errs, field.Invalid(path.Child("operator"), toleration.Operator, "operator must be Exists when key is empty"))
+		}
+		for _, msg := range validation.IsValidLabelValue(toleration.Value) {
+			errs = append(errs, field.Invalid(path.Child("value"), toleration.Value, msg))
+		}
+	case corev1.TolerationOpExists:
+		if toleration.Value != "" {
+			errs = append(errs, field.Invalid(path.Child("value"), toleration.Value, "value must be empty when operator is Exists"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(path.Child("operator"), toleration.Operator, []string{string(corev1.TolerationOpEqual), string(corev1.TolerationOpExists)}))
+	}
+
+	switch toleration.Effect {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("effect"), toleration.Effect, []string{string(corev1.TaintEffectNoSchedule), string(corev1.TaintEffectPreferNoSchedule), string(corev1.TaintEffectNoExecute)}))
+	}
+
+	if toleration.TolerationSeconds != nil && toleration.Effect != corev1.TaintEffectNoExecute {
+		errs = append(errs, field.Invalid(path.Child("tolerationSeconds"), *toleration.TolerationSeconds, "can only be set when effect is NoExecute"))
+	}
+
+	return errs
+}
+
+func (c *CodecServerSpec) Validate() (admission.Warnings, field.ErrorList) {
+	var warns admission.Warnings
+	var errs field.ErrorList
+
+	if c == nil || !c.Enabled {
+		return nil, nil
+	}
+
+	path := field.NewPath("spec", "codecServer")
+
+	if c.Image == "" {
+		errs = append(errs, field.Required(path.Child("image"), "required when the codec server is enabled"))
+	}
+
+	if c.Endpoint != "" {
+		if err := validateURL(c.Endpoint); err != nil {
+			errs = append(errs, field.Invalid(path.Child("endpoint"), c.Endpoint, err.Error()))
+		}
+	} else if c.Ingress == nil {
+		warns = append(warns, "Codec server has no ingress nor endpoint: the UI will use the codec server service URL which is not reachable from users browsers")
+	}
+
+	return warns, errs
+}
+
+func (s *TemporalUISpec) Validate() (admission.Warnings, field.ErrorList) {
+	var warns admission.Warnings
+	var errs field.ErrorList
+
+	if s == nil || !s.Enabled {
+		return nil, nil
+	}
+
+	path := field.NewPath("spec", "ui")
+
+	errs = append(errs, s.Auth.validate(path.Child("auth"))...)
+
+	if s.Codec != nil && s.Codec.Endpoint != "" {
+		if err := validateURL(s.Codec.Endpoint); err != nil {
+			errs = append(errs, field.Invalid(path.Child("codec", "endpoint"), s.Codec.Endpoint, err.Error()))
+		}
+	}
+
+	uiVersion, err := version.NewVersionFromString(s.Version)
+	if err != nil {
+		warns = append(warns, fmt.Sprintf("Can't parse UI version %q, UI settings are not checked against it", s.Version))
+		return warns, errs
+	}
+
+	for _, feature := range uiFeatureMinVersions {
+		if feature.isSet(s) && uiVersion.LessThan(feature.version) {
+			errs = append(errs, field.Forbidden(path.Child(feature.path[0], feature.path[1:]...), fmt.Sprintf("requires UI version >= %s", feature.version.String())))
+		}
+	}
+
+	return warns, errs
+}
+
+func (a *TemporalUIAuthSpec) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if a == nil {
+		return nil
+	}
+
+	if err := validateURL(a.ProviderURL); err != nil {
+		errs = append(errs, field.Invalid(path.Child("providerURL"), a.ProviderURL, err.Error()))
+	}
+
+	if a.IssuerURL != "" {
+		if err := validateURL(a.IssuerURL); err != nil {
+			errs = append(errs, field.Invalid(path.Child("issuerURL"), a.IssuerURL, err.Error()))
+		}
+	}
+
+	if err := validateURL(a.CallbackURL); err != nil {
+		errs = append(errs, field.Invalid(path.Child("callbackURL"), a.CallbackURL, err.Error()))
+	}
+
+	if a.ClientID == "" {
+		errs = append(errs, field.Required(path.Child("clientID"), "required when auth is enabled"))
+	}
+
+	if a.ClientSecretRef == nil || a.ClientSecretRef.Name == "" || a.ClientSecretRef.Key == "" {
+		errs = append(errs, field.Required(path.Child("clientSecretRef"), "a secret name and key are required when auth is enabled"))
+	}
+
+	return errs
+}
+
+// validateURL ensures the provided string is an absolute http(s) URL.
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("must be a valid URL: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("must be an absolute http or https URL")
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("must contain a host")
+	}
+
+	return nil
+}
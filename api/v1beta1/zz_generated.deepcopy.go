@@ -25,11 +25,14 @@ import (
 	"github.com/alexandrevilain/temporal-operator/pkg/version"
 	"github.com/gocql/gocql"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"k8s.io/api/autoscaling/v2"
 	"k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	apisv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -118,6 +121,124 @@ func (in *AuthorizationSpecJWTKeyProvider) DeepCopy() *AuthorizationSpecJWTKeyPr
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoscalingPrometheusSpec) DeepCopyInto(out *AutoscalingPrometheusSpec) {
+	*out = *in
+	if in.AuthenticationRef != nil {
+		in, out := &in.AuthenticationRef, &out.AuthenticationRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalingPrometheusSpec.
+func (in *AutoscalingPrometheusSpec) DeepCopy() *AutoscalingPrometheusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoscalingPrometheusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoscalingSpec) DeepCopyInto(out *AutoscalingSpec) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]v2.MetricSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Prometheus != nil {
+		in, out := &in.Prometheus, &out.Prometheus
+		*out = make([]AutoscalingPrometheusSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Behavior != nil {
+		in, out := &in.Behavior, &out.Behavior
+		*out = new(v2.HorizontalPodAutoscalerBehavior)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalingSpec.
+func (in *AutoscalingSpec) DeepCopy() *AutoscalingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoscalingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupPersistentVolumeClaimDestination) DeepCopyInto(out *BackupPersistentVolumeClaimDestination) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupPersistentVolumeClaimDestination.
+func (in *BackupPersistentVolumeClaimDestination) DeepCopy() *BackupPersistentVolumeClaimDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupPersistentVolumeClaimDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupS3Destination) DeepCopyInto(out *BackupS3Destination) {
+	*out = *in
+	if in.Endpoint != nil {
+		in, out := &in.Endpoint, &out.Endpoint
+		*out = new(string)
+		**out = **in
+	}
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = new(S3Credentials)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupS3Destination.
+func (in *BackupS3Destination) DeepCopy() *BackupS3Destination {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupS3Destination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
+	*out = *in
+	if in.ReadySince != nil {
+		in, out := &in.ReadySince, &out.ReadySince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStatus.
+func (in *CanaryStatus) DeepCopy() *CanaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CassandraConsistencySpec) DeepCopyInto(out *CassandraConsistencySpec) {
 	*out = *in
@@ -243,6 +364,69 @@ func (in *ClusterArchivalSpec) DeepCopy() *ClusterArchivalSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CodecServerSpec) DeepCopyInto(out *CodecServerSpec) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.KeysSecretRef != nil {
+		in, out := &in.KeysSecretRef, &out.KeysSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.CORSOrigins != nil {
+		in, out := &in.CORSOrigins, &out.CORSOrigins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(TemporalUIIngressSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(ServiceSpecOverride)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(ObjectMetaOverride)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CodecServerSpec.
+func (in *CodecServerSpec) DeepCopy() *CodecServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CodecServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConstrainedValue) DeepCopyInto(out *ConstrainedValue) {
 	*out = *in
@@ -280,179 +464,450 @@ func (in *Constraints) DeepCopy() *Constraints {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DatastoreSpec) DeepCopyInto(out *DatastoreSpec) {
+func (in *DatastoreBackupJobSpec) DeepCopyInto(out *DatastoreBackupJobSpec) {
 	*out = *in
-	if in.SQL != nil {
-		in, out := &in.SQL, &out.SQL
-		*out = new(SQLSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Elasticsearch != nil {
-		in, out := &in.Elasticsearch, &out.Elasticsearch
-		*out = new(ElasticsearchSpec)
-		**out = **in
-	}
-	if in.Cassandra != nil {
-		in, out := &in.Cassandra, &out.Cassandra
-		*out = new(CassandraSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.PasswordSecretRef != nil {
-		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
-		*out = new(SecretKeyReference)
-		**out = **in
-	}
-	if in.TLS != nil {
-		in, out := &in.TLS, &out.TLS
-		*out = new(DatastoreTLSSpec)
-		(*in).DeepCopyInto(*out)
-	}
+	in.Template.DeepCopyInto(&out.Template)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatastoreSpec.
-func (in *DatastoreSpec) DeepCopy() *DatastoreSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatastoreBackupJobSpec.
+func (in *DatastoreBackupJobSpec) DeepCopy() *DatastoreBackupJobSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DatastoreSpec)
+	out := new(DatastoreBackupJobSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DatastoreStatus) DeepCopyInto(out *DatastoreStatus) {
+func (in *DatastoreBackupPresetSpec) DeepCopyInto(out *DatastoreBackupPresetSpec) {
 	*out = *in
-	if in.SchemaVersion != nil {
-		in, out := &in.SchemaVersion, &out.SchemaVersion
-		*out = new(version.Version)
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(BackupPersistentVolumeClaimDestination)
+		**out = **in
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(BackupS3Destination)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.JMXPort != nil {
+		in, out := &in.JMXPort, &out.JMXPort
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatastoreStatus.
-func (in *DatastoreStatus) DeepCopy() *DatastoreStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatastoreBackupPresetSpec.
+func (in *DatastoreBackupPresetSpec) DeepCopy() *DatastoreBackupPresetSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DatastoreStatus)
+	out := new(DatastoreBackupPresetSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DatastoreTLSSpec) DeepCopyInto(out *DatastoreTLSSpec) {
+func (in *DatastoreBackupSpec) DeepCopyInto(out *DatastoreBackupSpec) {
 	*out = *in
-	if in.CertFileRef != nil {
-		in, out := &in.CertFileRef, &out.CertFileRef
-		*out = new(SecretKeyReference)
-		**out = **in
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(DatastoreBackupJobSpec)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.KeyFileRef != nil {
-		in, out := &in.KeyFileRef, &out.KeyFileRef
-		*out = new(SecretKeyReference)
-		**out = **in
+	if in.Preset != nil {
+		in, out := &in.Preset, &out.Preset
+		*out = new(DatastoreBackupPresetSpec)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.CaFileRef != nil {
-		in, out := &in.CaFileRef, &out.CaFileRef
-		*out = new(SecretKeyReference)
-		**out = **in
+	if in.VolumeSnapshot != nil {
+		in, out := &in.VolumeSnapshot, &out.VolumeSnapshot
+		*out = new(DatastoreBackupVolumeSnapshotSpec)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatastoreTLSSpec.
-func (in *DatastoreTLSSpec) DeepCopy() *DatastoreTLSSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatastoreBackupSpec.
+func (in *DatastoreBackupSpec) DeepCopy() *DatastoreBackupSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DatastoreTLSSpec)
+	out := new(DatastoreBackupSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeploymentOverride) DeepCopyInto(out *DeploymentOverride) {
+func (in *DatastoreBackupStatus) DeepCopyInto(out *DatastoreBackupStatus) {
 	*out = *in
-	if in.ObjectMetaOverride != nil {
-		in, out := &in.ObjectMetaOverride, &out.ObjectMetaOverride
-		*out = new(ObjectMetaOverride)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Spec != nil {
-		in, out := &in.Spec, &out.Spec
-		*out = new(DeploymentOverrideSpec)
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(version.Version)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.JSONPatch != nil {
-		in, out := &in.JSONPatch, &out.JSONPatch
-		*out = new(apiextensionsv1.JSON)
-		(*in).DeepCopyInto(*out)
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentOverride.
-func (in *DeploymentOverride) DeepCopy() *DeploymentOverride {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatastoreBackupStatus.
+func (in *DatastoreBackupStatus) DeepCopy() *DatastoreBackupStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DeploymentOverride)
+	out := new(DatastoreBackupStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeploymentOverrideSpec) DeepCopyInto(out *DeploymentOverrideSpec) {
+func (in *DatastoreBackupVolumeSnapshotSpec) DeepCopyInto(out *DatastoreBackupVolumeSnapshotSpec) {
 	*out = *in
-	if in.Template != nil {
-		in, out := &in.Template, &out.Template
-		*out = new(PodTemplateSpecOverride)
-		(*in).DeepCopyInto(*out)
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentOverrideSpec.
-func (in *DeploymentOverrideSpec) DeepCopy() *DeploymentOverrideSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatastoreBackupVolumeSnapshotSpec.
+func (in *DatastoreBackupVolumeSnapshotSpec) DeepCopy() *DatastoreBackupVolumeSnapshotSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DeploymentOverrideSpec)
+	out := new(DatastoreBackupVolumeSnapshotSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynamicConfigSpec) DeepCopyInto(out *DynamicConfigSpec) {
+func (in *DatastorePasswordRotationSpec) DeepCopyInto(out *DatastorePasswordRotationSpec) {
 	*out = *in
-	if in.PollInterval != nil {
-		in, out := &in.PollInterval, &out.PollInterval
-		*out = new(metav1.Duration)
-		**out = **in
-	}
-	if in.Values != nil {
-		in, out := &in.Values, &out.Values
-		*out = make(map[string][]ConstrainedValue, len(*in))
-		for key, val := range *in {
-			var outVal []ConstrainedValue
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				inVal := (*in)[key]
-				in, out := &inVal, &outVal
-				*out = make([]ConstrainedValue, len(*in))
-				for i := range *in {
-					(*in)[i].DeepCopyInto(&(*out)[i])
-				}
-			}
-			(*out)[key] = outVal
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicConfigSpec.
-func (in *DynamicConfigSpec) DeepCopy() *DynamicConfigSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatastorePasswordRotationSpec.
+func (in *DatastorePasswordRotationSpec) DeepCopy() *DatastorePasswordRotationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicConfigSpec)
+	out := new(DatastorePasswordRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatastorePreflightStatus) DeepCopyInto(out *DatastorePreflightStatus) {
+	*out = *in
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make([]PreflightCheck, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatastorePreflightStatus.
+func (in *DatastorePreflightStatus) DeepCopy() *DatastorePreflightStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DatastorePreflightStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatastoreSpec) DeepCopyInto(out *DatastoreSpec) {
+	*out = *in
+	if in.SQL != nil {
+		in, out := &in.SQL, &out.SQL
+		*out = new(SQLSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Elasticsearch != nil {
+		in, out := &in.Elasticsearch, &out.Elasticsearch
+		*out = new(ElasticsearchSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cassandra != nil {
+		in, out := &in.Cassandra, &out.Cassandra
+		*out = new(CassandraSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PasswordSecretRef != nil {
+		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(DatastoreTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Managed != nil {
+		in, out := &in.Managed, &out.Managed
+		*out = new(ManagedDatastoreSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(DatastoreBackupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PasswordRotation != nil {
+		in, out := &in.PasswordRotation, &out.PasswordRotation
+		*out = new(DatastorePasswordRotationSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatastoreSpec.
+func (in *DatastoreSpec) DeepCopy() *DatastoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DatastoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatastoreStatus) DeepCopyInto(out *DatastoreStatus) {
+	*out = *in
+	if in.SchemaVersion != nil {
+		in, out := &in.SchemaVersion, &out.SchemaVersion
+		*out = new(version.Version)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastSchemaOperation != nil {
+		in, out := &in.LastSchemaOperation, &out.LastSchemaOperation
+		*out = new(SchemaOperationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PlannedMigrations != nil {
+		in, out := &in.PlannedMigrations, &out.PlannedMigrations
+		*out = new(SchemaMigrationPlan)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastBackup != nil {
+		in, out := &in.LastBackup, &out.LastBackup
+		*out = new(DatastoreBackupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Preflight != nil {
+		in, out := &in.Preflight, &out.Preflight
+		*out = new(DatastorePreflightStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Managed != nil {
+		in, out := &in.Managed, &out.Managed
+		*out = new(ManagedDatastoreStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatastoreStatus.
+func (in *DatastoreStatus) DeepCopy() *DatastoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DatastoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatastoreTLSSpec) DeepCopyInto(out *DatastoreTLSSpec) {
+	*out = *in
+	if in.CertFileRef != nil {
+		in, out := &in.CertFileRef, &out.CertFileRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.KeyFileRef != nil {
+		in, out := &in.KeyFileRef, &out.KeyFileRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.CaFileRef != nil {
+		in, out := &in.CaFileRef, &out.CaFileRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatastoreTLSSpec.
+func (in *DatastoreTLSSpec) DeepCopy() *DatastoreTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DatastoreTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentOverride) DeepCopyInto(out *DeploymentOverride) {
+	*out = *in
+	if in.ObjectMetaOverride != nil {
+		in, out := &in.ObjectMetaOverride, &out.ObjectMetaOverride
+		*out = new(ObjectMetaOverride)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Spec != nil {
+		in, out := &in.Spec, &out.Spec
+		*out = new(DeploymentOverrideSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JSONPatch != nil {
+		in, out := &in.JSONPatch, &out.JSONPatch
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentOverride.
+func (in *DeploymentOverride) DeepCopy() *DeploymentOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentOverrideSpec) DeepCopyInto(out *DeploymentOverrideSpec) {
+	*out = *in
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(PodTemplateSpecOverride)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentOverrideSpec.
+func (in *DeploymentOverrideSpec) DeepCopy() *DeploymentOverrideSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentOverrideSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicConfigSpec) DeepCopyInto(out *DynamicConfigSpec) {
+	*out = *in
+	if in.PollInterval != nil {
+		in, out := &in.PollInterval, &out.PollInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make(map[string][]ConstrainedValue, len(*in))
+		for key, val := range *in {
+			var outVal []ConstrainedValue
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]ConstrainedValue, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicConfigSpec.
+func (in *DynamicConfigSpec) DeepCopy() *DynamicConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchAPIKeyAuthSpec) DeepCopyInto(out *ElasticsearchAPIKeyAuthSpec) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchAPIKeyAuthSpec.
+func (in *ElasticsearchAPIKeyAuthSpec) DeepCopy() *ElasticsearchAPIKeyAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchAPIKeyAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchAWSRequestSigningSpec) DeepCopyInto(out *ElasticsearchAWSRequestSigningSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchAWSRequestSigningSpec.
+func (in *ElasticsearchAWSRequestSigningSpec) DeepCopy() *ElasticsearchAWSRequestSigningSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchAWSRequestSigningSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchAuthSpec) DeepCopyInto(out *ElasticsearchAuthSpec) {
+	*out = *in
+	if in.Basic != nil {
+		in, out := &in.Basic, &out.Basic
+		*out = new(ElasticsearchBasicAuthSpec)
+		**out = **in
+	}
+	if in.APIKey != nil {
+		in, out := &in.APIKey, &out.APIKey
+		*out = new(ElasticsearchAPIKeyAuthSpec)
+		**out = **in
+	}
+	if in.AWSRequestSigning != nil {
+		in, out := &in.AWSRequestSigning, &out.AWSRequestSigning
+		*out = new(ElasticsearchAWSRequestSigningSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchAuthSpec.
+func (in *ElasticsearchAuthSpec) DeepCopy() *ElasticsearchAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchBasicAuthSpec) DeepCopyInto(out *ElasticsearchBasicAuthSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchBasicAuthSpec.
+func (in *ElasticsearchBasicAuthSpec) DeepCopy() *ElasticsearchBasicAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchBasicAuthSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -462,44 +917,170 @@ func (in *ElasticsearchIndices) DeepCopyInto(out *ElasticsearchIndices) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchIndices.
-func (in *ElasticsearchIndices) DeepCopy() *ElasticsearchIndices {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchIndices.
+func (in *ElasticsearchIndices) DeepCopy() *ElasticsearchIndices {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchIndices)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchSpec) DeepCopyInto(out *ElasticsearchSpec) {
+	*out = *in
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(ElasticsearchAuthSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Indices = in.Indices
+	out.CloseIdleConnectionsInterval = in.CloseIdleConnectionsInterval
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchSpec.
+func (in *ElasticsearchSpec) DeepCopy() *ElasticsearchSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilestoreArchiver) DeepCopyInto(out *FilestoreArchiver) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilestoreArchiver.
+func (in *FilestoreArchiver) DeepCopy() *FilestoreArchiver {
+	if in == nil {
+		return nil
+	}
+	out := new(FilestoreArchiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FrontendExposeSpec) DeepCopyInto(out *FrontendExposeSpec) {
+	*out = *in
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(FrontendExternalServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(FrontendIngressSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GRPCRoute != nil {
+		in, out := &in.GRPCRoute, &out.GRPCRoute
+		*out = new(FrontendRouteSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLSRoute != nil {
+		in, out := &in.TLSRoute, &out.TLSRoute
+		*out = new(FrontendRouteSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPRoute != nil {
+		in, out := &in.HTTPRoute, &out.HTTPRoute
+		*out = new(GatewayHTTPRouteSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FrontendExposeSpec.
+func (in *FrontendExposeSpec) DeepCopy() *FrontendExposeSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchIndices)
+	out := new(FrontendExposeSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchSpec) DeepCopyInto(out *ElasticsearchSpec) {
+func (in *FrontendExternalServiceSpec) DeepCopyInto(out *FrontendExternalServiceSpec) {
 	*out = *in
-	out.Indices = in.Indices
-	out.CloseIdleConnectionsInterval = in.CloseIdleConnectionsInterval
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerClass != nil {
+		in, out := &in.LoadBalancerClass, &out.LoadBalancerClass
+		*out = new(string)
+		**out = **in
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodePort != nil {
+		in, out := &in.NodePort, &out.NodePort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchSpec.
-func (in *ElasticsearchSpec) DeepCopy() *ElasticsearchSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FrontendExternalServiceSpec.
+func (in *FrontendExternalServiceSpec) DeepCopy() *FrontendExternalServiceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchSpec)
+	out := new(FrontendExternalServiceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FilestoreArchiver) DeepCopyInto(out *FilestoreArchiver) {
+func (in *FrontendIngressSpec) DeepCopyInto(out *FrontendIngressSpec) {
 	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = make([]networkingv1.IngressTLS, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilestoreArchiver.
-func (in *FilestoreArchiver) DeepCopy() *FilestoreArchiver {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FrontendIngressSpec.
+func (in *FrontendIngressSpec) DeepCopy() *FrontendIngressSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(FilestoreArchiver)
+	out := new(FrontendIngressSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -524,6 +1105,61 @@ func (in *FrontendMTLSSpec) DeepCopy() *FrontendMTLSSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FrontendRouteSpec) DeepCopyInto(out *FrontendRouteSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ParentRefs != nil {
+		in, out := &in.ParentRefs, &out.ParentRefs
+		*out = make([]apisv1.ParentReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]apisv1.Hostname, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FrontendRouteSpec.
+func (in *FrontendRouteSpec) DeepCopy() *FrontendRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FrontendRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FrontendServiceSpec) DeepCopyInto(out *FrontendServiceSpec) {
+	*out = *in
+	in.ServiceSpec.DeepCopyInto(&out.ServiceSpec)
+	if in.Expose != nil {
+		in, out := &in.Expose, &out.Expose
+		*out = new(FrontendExposeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FrontendServiceSpec.
+func (in *FrontendServiceSpec) DeepCopy() *FrontendServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FrontendServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GCSArchiver) DeepCopyInto(out *GCSArchiver) {
 	*out = *in
@@ -544,6 +1180,47 @@ func (in *GCSArchiver) DeepCopy() *GCSArchiver {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayHTTPRouteSpec) DeepCopyInto(out *GatewayHTTPRouteSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ParentRefs != nil {
+		in, out := &in.ParentRefs, &out.ParentRefs
+		*out = make([]apisv1.ParentReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]apisv1.Hostname, len(*in))
+		copy(*out, *in)
+	}
+	if in.PathMatches != nil {
+		in, out := &in.PathMatches, &out.PathMatches
+		*out = make([]apisv1.HTTPPathMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayHTTPRouteSpec.
+func (in *GatewayHTTPRouteSpec) DeepCopy() *GatewayHTTPRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayHTTPRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InternalFrontendServiceSpec) DeepCopyInto(out *InternalFrontendServiceSpec) {
 	*out = *in
@@ -575,6 +1252,66 @@ func (in *InternodeMTLSSpec) DeepCopy() *InternodeMTLSSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioAmbientSpec) DeepCopyInto(out *IstioAmbientSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioAmbientSpec.
+func (in *IstioAmbientSpec) DeepCopy() *IstioAmbientSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioAmbientSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioMTLSSpec) DeepCopyInto(out *IstioMTLSSpec) {
+	*out = *in
+	if in.FrontendPrincipals != nil {
+		in, out := &in.FrontendPrincipals, &out.FrontendPrincipals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ambient != nil {
+		in, out := &in.Ambient, &out.Ambient
+		*out = new(IstioAmbientSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioMTLSSpec.
+func (in *IstioMTLSSpec) DeepCopy() *IstioMTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioMTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LinkerdMTLSSpec) DeepCopyInto(out *LinkerdMTLSSpec) {
+	*out = *in
+	if in.FrontendIdentities != nil {
+		in, out := &in.FrontendIdentities, &out.FrontendIdentities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LinkerdMTLSSpec.
+func (in *LinkerdMTLSSpec) DeepCopy() *LinkerdMTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LinkerdMTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogSpec) DeepCopyInto(out *LogSpec) {
 	*out = *in
@@ -608,29 +1345,134 @@ func (in *MTLSSpec) DeepCopyInto(out *MTLSSpec) {
 		*out = new(FrontendMTLSSpec)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.CertificatesDuration != nil {
-		in, out := &in.CertificatesDuration, &out.CertificatesDuration
-		*out = new(CertificatesDurationSpec)
+	if in.CertificatesDuration != nil {
+		in, out := &in.CertificatesDuration, &out.CertificatesDuration
+		*out = new(CertificatesDurationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Spire != nil {
+		in, out := &in.Spire, &out.Spire
+		*out = new(SpireMTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Linkerd != nil {
+		in, out := &in.Linkerd, &out.Linkerd
+		*out = new(LinkerdMTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Istio != nil {
+		in, out := &in.Istio, &out.Istio
+		*out = new(IstioMTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MTLSSpec.
+func (in *MTLSSpec) DeepCopy() *MTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedClusterCreateSpec) DeepCopyInto(out *ManagedClusterCreateSpec) {
+	*out = *in
+	if in.Spec != nil {
+		in, out := &in.Spec, &out.Spec
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedClusterCreateSpec.
+func (in *ManagedClusterCreateSpec) DeepCopy() *ManagedClusterCreateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedClusterCreateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedClusterReference) DeepCopyInto(out *ManagedClusterReference) {
+	*out = *in
+	if in.Create != nil {
+		in, out := &in.Create, &out.Create
+		*out = new(ManagedClusterCreateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedClusterReference.
+func (in *ManagedClusterReference) DeepCopy() *ManagedClusterReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedClusterReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedDatastoreSpec) DeepCopyInto(out *ManagedDatastoreSpec) {
+	*out = *in
+	if in.CloudNativePG != nil {
+		in, out := &in.CloudNativePG, &out.CloudNativePG
+		*out = new(ManagedClusterReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PerconaXtraDB != nil {
+		in, out := &in.PerconaXtraDB, &out.PerconaXtraDB
+		*out = new(ManagedClusterReference)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.RefreshInterval != nil {
-		in, out := &in.RefreshInterval, &out.RefreshInterval
-		*out = new(metav1.Duration)
-		**out = **in
+	if in.K8ssandra != nil {
+		in, out := &in.K8ssandra, &out.K8ssandra
+		*out = new(ManagedClusterReference)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.RenewBefore != nil {
-		in, out := &in.RenewBefore, &out.RenewBefore
-		*out = new(metav1.Duration)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedDatastoreSpec.
+func (in *ManagedDatastoreSpec) DeepCopy() *ManagedDatastoreSpec {
+	if in == nil {
+		return nil
 	}
+	out := new(ManagedDatastoreSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MTLSSpec.
-func (in *MTLSSpec) DeepCopy() *MTLSSpec {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedDatastoreStatus) DeepCopyInto(out *ManagedDatastoreStatus) {
+	*out = *in
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedDatastoreStatus.
+func (in *ManagedDatastoreStatus) DeepCopy() *ManagedDatastoreStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(MTLSSpec)
+	out := new(ManagedDatastoreStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -736,6 +1578,36 @@ func (in *ObjectReference) DeepCopy() *ObjectReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetSpec) DeepCopyInto(out *PodDisruptionBudgetSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetSpec.
+func (in *PodDisruptionBudgetSpec) DeepCopy() *PodDisruptionBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodTemplateSpecOverride) DeepCopyInto(out *PodTemplateSpecOverride) {
 	*out = *in
@@ -761,6 +1633,21 @@ func (in *PodTemplateSpecOverride) DeepCopy() *PodTemplateSpecOverride {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreflightCheck) DeepCopyInto(out *PreflightCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreflightCheck.
+func (in *PreflightCheck) DeepCopy() *PreflightCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(PreflightCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PrometheusScrapeConfig) DeepCopyInto(out *PrometheusScrapeConfig) {
 	*out = *in
@@ -930,6 +1817,81 @@ func (in *S3Credentials) DeepCopy() *S3Credentials {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SQLAWSIAMAuthSpec) DeepCopyInto(out *SQLAWSIAMAuthSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLAWSIAMAuthSpec.
+func (in *SQLAWSIAMAuthSpec) DeepCopy() *SQLAWSIAMAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SQLAWSIAMAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SQLAuthSpec) DeepCopyInto(out *SQLAuthSpec) {
+	*out = *in
+	if in.AWSIAM != nil {
+		in, out := &in.AWSIAM, &out.AWSIAM
+		*out = new(SQLAWSIAMAuthSpec)
+		**out = **in
+	}
+	if in.AzureAD != nil {
+		in, out := &in.AzureAD, &out.AzureAD
+		*out = new(SQLAzureADAuthSpec)
+		**out = **in
+	}
+	if in.GCPIAM != nil {
+		in, out := &in.GCPIAM, &out.GCPIAM
+		*out = new(SQLGCPIAMAuthSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLAuthSpec.
+func (in *SQLAuthSpec) DeepCopy() *SQLAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SQLAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SQLAzureADAuthSpec) DeepCopyInto(out *SQLAzureADAuthSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLAzureADAuthSpec.
+func (in *SQLAzureADAuthSpec) DeepCopy() *SQLAzureADAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SQLAzureADAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SQLGCPIAMAuthSpec) DeepCopyInto(out *SQLGCPIAMAuthSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLGCPIAMAuthSpec.
+func (in *SQLGCPIAMAuthSpec) DeepCopy() *SQLGCPIAMAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SQLGCPIAMAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SQLSpec) DeepCopyInto(out *SQLSpec) {
 	*out = *in
@@ -946,6 +1908,11 @@ func (in *SQLSpec) DeepCopyInto(out *SQLSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(SQLAuthSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLSpec.
@@ -1189,129 +2156,303 @@ func (in *ScheduleSpec) DeepCopyInto(out *ScheduleSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.ExcludeCalendars != nil {
-		in, out := &in.ExcludeCalendars, &out.ExcludeCalendars
-		*out = make([]ScheduleCalendarSpec, len(*in))
+	if in.ExcludeCalendars != nil {
+		in, out := &in.ExcludeCalendars, &out.ExcludeCalendars
+		*out = make([]ScheduleCalendarSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartAt != nil {
+		in, out := &in.StartAt, &out.StartAt
+		*out = (*in).DeepCopy()
+	}
+	if in.EndAt != nil {
+		in, out := &in.EndAt, &out.EndAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Jitter != nil {
+		in, out := &in.Jitter, &out.Jitter
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleSpec.
+func (in *ScheduleSpec) DeepCopy() *ScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleState) DeepCopyInto(out *ScheduleState) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleState.
+func (in *ScheduleState) DeepCopy() *ScheduleState {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleWorkflowAction) DeepCopyInto(out *ScheduleWorkflowAction) {
+	*out = *in
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WorkflowExecutionTimeout != nil {
+		in, out := &in.WorkflowExecutionTimeout, &out.WorkflowExecutionTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.WorkflowRunTimeout != nil {
+		in, out := &in.WorkflowRunTimeout, &out.WorkflowRunTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.WorkflowTaskTimeout != nil {
+		in, out := &in.WorkflowTaskTimeout, &out.WorkflowTaskTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Memo != nil {
+		in, out := &in.Memo, &out.Memo
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SearchAttributes != nil {
+		in, out := &in.SearchAttributes, &out.SearchAttributes
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleWorkflowAction.
+func (in *ScheduleWorkflowAction) DeepCopy() *ScheduleWorkflowAction {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleWorkflowAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleYearRange) DeepCopyInto(out *ScheduleYearRange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleYearRange.
+func (in *ScheduleYearRange) DeepCopy() *ScheduleYearRange {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleYearRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaMigration) DeepCopyInto(out *SchemaMigration) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaMigration.
+func (in *SchemaMigration) DeepCopy() *SchemaMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaMigrationPlan) DeepCopyInto(out *SchemaMigrationPlan) {
+	*out = *in
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(version.Version)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Migrations != nil {
+		in, out := &in.Migrations, &out.Migrations
+		*out = make([]SchemaMigration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaMigrationPlan.
+func (in *SchemaMigrationPlan) DeepCopy() *SchemaMigrationPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaMigrationPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaOperationStatus) DeepCopyInto(out *SchemaOperationStatus) {
+	*out = *in
+	if in.AppliedVersions != nil {
+		in, out := &in.AppliedVersions, &out.AppliedVersions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PlannedMigrations != nil {
+		in, out := &in.PlannedMigrations, &out.PlannedMigrations
+		*out = make([]SchemaMigration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.StartAt != nil {
-		in, out := &in.StartAt, &out.StartAt
-		*out = (*in).DeepCopy()
-	}
-	if in.EndAt != nil {
-		in, out := &in.EndAt, &out.EndAt
-		*out = (*in).DeepCopy()
-	}
-	if in.Jitter != nil {
-		in, out := &in.Jitter, &out.Jitter
-		*out = new(metav1.Duration)
-		**out = **in
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make([]PreflightCheck, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleSpec.
-func (in *ScheduleSpec) DeepCopy() *ScheduleSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaOperationStatus.
+func (in *SchemaOperationStatus) DeepCopy() *SchemaOperationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ScheduleSpec)
+	out := new(SchemaOperationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ScheduleState) DeepCopyInto(out *ScheduleState) {
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleState.
-func (in *ScheduleState) DeepCopy() *ScheduleState {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
 	if in == nil {
 		return nil
 	}
-	out := new(ScheduleState)
+	out := new(SecretKeyReference)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ScheduleWorkflowAction) DeepCopyInto(out *ScheduleWorkflowAction) {
+func (in *ServiceCanarySpec) DeepCopyInto(out *ServiceCanarySpec) {
 	*out = *in
-	if in.Inputs != nil {
-		in, out := &in.Inputs, &out.Inputs
-		*out = new(apiextensionsv1.JSON)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.WorkflowExecutionTimeout != nil {
-		in, out := &in.WorkflowExecutionTimeout, &out.WorkflowExecutionTimeout
-		*out = new(metav1.Duration)
-		**out = **in
-	}
-	if in.WorkflowRunTimeout != nil {
-		in, out := &in.WorkflowRunTimeout, &out.WorkflowRunTimeout
-		*out = new(metav1.Duration)
-		**out = **in
-	}
-	if in.WorkflowTaskTimeout != nil {
-		in, out := &in.WorkflowTaskTimeout, &out.WorkflowTaskTimeout
-		*out = new(metav1.Duration)
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
 		**out = **in
 	}
-	if in.RetryPolicy != nil {
-		in, out := &in.RetryPolicy, &out.RetryPolicy
-		*out = new(RetryPolicy)
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(version.Version)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Memo != nil {
-		in, out := &in.Memo, &out.Memo
-		*out = new(apiextensionsv1.JSON)
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(PodTemplateSpecOverride)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.SearchAttributes != nil {
-		in, out := &in.SearchAttributes, &out.SearchAttributes
-		*out = new(apiextensionsv1.JSON)
-		(*in).DeepCopyInto(*out)
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleWorkflowAction.
-func (in *ScheduleWorkflowAction) DeepCopy() *ScheduleWorkflowAction {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceCanarySpec.
+func (in *ServiceCanarySpec) DeepCopy() *ServiceCanarySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ScheduleWorkflowAction)
+	out := new(ServiceCanarySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ScheduleYearRange) DeepCopyInto(out *ScheduleYearRange) {
+func (in *ServiceProbesSpec) DeepCopyInto(out *ServiceProbesSpec) {
 	*out = *in
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleYearRange.
-func (in *ScheduleYearRange) DeepCopy() *ScheduleYearRange {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceProbesSpec.
+func (in *ServiceProbesSpec) DeepCopy() *ServiceProbesSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ScheduleYearRange)
+	out := new(ServiceProbesSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
+func (in *ServiceShutdownSpec) DeepCopyInto(out *ServiceShutdownSpec) {
 	*out = *in
+	if in.PreStopDelay != nil {
+		in, out := &in.PreStopDelay, &out.PreStopDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.TerminationGracePeriod != nil {
+		in, out := &in.TerminationGracePeriod, &out.TerminationGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
-func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceShutdownSpec.
+func (in *ServiceShutdownSpec) DeepCopy() *ServiceShutdownSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SecretKeyReference)
+	out := new(ServiceShutdownSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1352,6 +2493,62 @@ func (in *ServiceSpec) DeepCopyInto(out *ServiceSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(AutoscalingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(ServiceProbesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Shutdown != nil {
+		in, out := &in.Shutdown, &out.Shutdown
+		*out = new(ServiceShutdownSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(version.Version)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(ServiceCanarySpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceSpec.
@@ -1387,6 +2584,11 @@ func (in *ServiceSpecOverride) DeepCopy() *ServiceSpecOverride {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceStatus) DeepCopyInto(out *ServiceStatus) {
 	*out = *in
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceStatus.
@@ -1404,7 +2606,7 @@ func (in *ServicesSpec) DeepCopyInto(out *ServicesSpec) {
 	*out = *in
 	if in.Frontend != nil {
 		in, out := &in.Frontend, &out.Frontend
-		*out = new(ServiceSpec)
+		*out = new(FrontendServiceSpec)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.InternalFrontend != nil {
@@ -1444,6 +2646,41 @@ func (in *ServicesSpec) DeepCopy() *ServicesSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpireMTLSSpec) DeepCopyInto(out *SpireMTLSSpec) {
+	*out = *in
+	if in.WorkloadAPI != nil {
+		in, out := &in.WorkloadAPI, &out.WorkloadAPI
+		*out = new(SpireWorkloadAPISpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpireMTLSSpec.
+func (in *SpireMTLSSpec) DeepCopy() *SpireMTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SpireMTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpireWorkloadAPISpec) DeepCopyInto(out *SpireWorkloadAPISpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpireWorkloadAPISpec.
+func (in *SpireWorkloadAPISpec) DeepCopy() *SpireWorkloadAPISpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SpireWorkloadAPISpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TemporalAdminToolsSpec) DeepCopyInto(out *TemporalAdminToolsSpec) {
 	*out = *in
@@ -1661,6 +2898,11 @@ func (in *TemporalClusterSpec) DeepCopyInto(out *TemporalClusterSpec) {
 		*out = new(TemporalUISpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CodecServer != nil {
+		in, out := &in.CodecServer, &out.CodecServer
+		*out = new(CodecServerSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AdminTools != nil {
 		in, out := &in.AdminTools, &out.AdminTools
 		*out = new(TemporalAdminToolsSpec)
@@ -1709,7 +2951,9 @@ func (in *TemporalClusterStatus) DeepCopyInto(out *TemporalClusterStatus) {
 	if in.Services != nil {
 		in, out := &in.Services, &out.Services
 		*out = make([]ServiceStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Persistence != nil {
 		in, out := &in.Persistence, &out.Persistence
@@ -1902,6 +3146,11 @@ func (in *TemporalPersistenceSpec) DeepCopyInto(out *TemporalPersistenceSpec) {
 		*out = new(DatastoreSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.VisibilityMigration != nil {
+		in, out := &in.VisibilityMigration, &out.VisibilityMigration
+		*out = new(VisibilityMigrationSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemporalPersistenceSpec.
@@ -1937,6 +3186,11 @@ func (in *TemporalPersistenceStatus) DeepCopyInto(out *TemporalPersistenceStatus
 		*out = new(DatastoreStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.VisibilityMigration != nil {
+		in, out := &in.VisibilityMigration, &out.VisibilityMigration
+		*out = new(VisibilityMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemporalPersistenceStatus.
@@ -2057,6 +3311,71 @@ func (in *TemporalScheduleStatus) DeepCopy() *TemporalScheduleStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemporalUIAuthSpec) DeepCopyInto(out *TemporalUIAuthSpec) {
+	*out = *in
+	if in.ClientSecretRef != nil {
+		in, out := &in.ClientSecretRef, &out.ClientSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxSessionDuration != nil {
+		in, out := &in.MaxSessionDuration, &out.MaxSessionDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemporalUIAuthSpec.
+func (in *TemporalUIAuthSpec) DeepCopy() *TemporalUIAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TemporalUIAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemporalUICodecSpec) DeepCopyInto(out *TemporalUICodecSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemporalUICodecSpec.
+func (in *TemporalUICodecSpec) DeepCopy() *TemporalUICodecSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TemporalUICodecSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemporalUIFeaturesSpec) DeepCopyInto(out *TemporalUIFeaturesSpec) {
+	*out = *in
+	if in.CORSOrigins != nil {
+		in, out := &in.CORSOrigins, &out.CORSOrigins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemporalUIFeaturesSpec.
+func (in *TemporalUIFeaturesSpec) DeepCopy() *TemporalUIFeaturesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TemporalUIFeaturesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TemporalUIIngressSpec) DeepCopyInto(out *TemporalUIIngressSpec) {
 	*out = *in
@@ -2115,11 +3434,31 @@ func (in *TemporalUISpec) DeepCopyInto(out *TemporalUISpec) {
 		*out = new(TemporalUIIngressSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(GatewayHTTPRouteSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Service != nil {
 		in, out := &in.Service, &out.Service
 		*out = new(ObjectMetaOverride)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(TemporalUIAuthSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Codec != nil {
+		in, out := &in.Codec, &out.Codec
+		*out = new(TemporalUICodecSpec)
+		**out = **in
+	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = new(TemporalUIFeaturesSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemporalUISpec.
@@ -2131,3 +3470,61 @@ func (in *TemporalUISpec) DeepCopy() *TemporalUISpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VisibilityMigrationPhaseStatus) DeepCopyInto(out *VisibilityMigrationPhaseStatus) {
+	*out = *in
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VisibilityMigrationPhaseStatus.
+func (in *VisibilityMigrationPhaseStatus) DeepCopy() *VisibilityMigrationPhaseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VisibilityMigrationPhaseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VisibilityMigrationSpec) DeepCopyInto(out *VisibilityMigrationSpec) {
+	*out = *in
+	out.SoakPeriod = in.SoakPeriod
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VisibilityMigrationSpec.
+func (in *VisibilityMigrationSpec) DeepCopy() *VisibilityMigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VisibilityMigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VisibilityMigrationStatus) DeepCopyInto(out *VisibilityMigrationStatus) {
+	*out = *in
+	if in.Phases != nil {
+		in, out := &in.Phases, &out.Phases
+		*out = make([]VisibilityMigrationPhaseStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VisibilityMigrationStatus.
+func (in *VisibilityMigrationStatus) DeepCopy() *VisibilityMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VisibilityMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,147 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command datastore-auth-proxy forwards the temporal services connections to a datastore which
+// requires an authentication temporal doesn't support:
+//   - for SQL datastores, each connection is authenticated with a short-lived cloud provider token.
+//   - for elasticsearch datastores, each request is authenticated with an API key.
+//
+// Usage:
+//
+//	datastore-auth-proxy [flags] <store>
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/esauth"
+	"github.com/alexandrevilain/temporal-operator/pkg/schemarunner"
+	"github.com/alexandrevilain/temporal-operator/pkg/sqlauth"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/log/tag"
+)
+
+func main() {
+	var (
+		configPath    string
+		listenAddress string
+	)
+
+	flag.StringVar(&configPath, "config", "/etc/schema-runner/config.json", "The path of the schema runner configuration file holding the datastores.")
+	flag.StringVar(&listenAddress, "listen", "127.0.0.1:15432", "The address the proxy listens on.")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: datastore-auth-proxy [flags] <store>")
+		os.Exit(2)
+	}
+
+	os.Exit(run(configPath, listenAddress, schemarunner.Store(flag.Arg(0))))
+}
+
+func run(configPath, listenAddress string, store schemarunner.Store) int {
+	logger := log.NewCLILogger()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	config, err := schemarunner.LoadConfig(configPath)
+	if err != nil {
+		logger.Error("Unable to load config", tag.Error(err))
+		return 1
+	}
+
+	storeConfig, err := config.Store(store)
+	if err != nil {
+		logger.Error("Unable to find store", tag.Error(err))
+		return 1
+	}
+
+	listener, err := net.Listen("tcp", listenAddress)
+	if err != nil {
+		logger.Error("Unable to listen", tag.Error(err))
+		return 1
+	}
+
+	if storeConfig.Datastore.GetType() == v1beta1.ElasticsearchDatastore {
+		err = serveElasticsearch(ctx, storeConfig.Datastore, listener, logger)
+	} else {
+		err = serveSQL(ctx, storeConfig.Datastore, listener, logger)
+	}
+	if err != nil {
+		logger.Error("Proxy stopped", tag.Error(err))
+		return 1
+	}
+
+	return 0
+}
+
+func serveSQL(ctx context.Context, datastore *v1beta1.DatastoreSpec, listener net.Listener, logger log.Logger) error {
+	tokens, err := sqlauth.NewTokenSource(ctx, datastore)
+	if err != nil {
+		return fmt.Errorf("can't create token source: %w", err)
+	}
+
+	proxy, err := sqlauth.NewProxy(datastore, tokens, logger)
+	if err != nil {
+		return fmt.Errorf("can't create proxy: %w", err)
+	}
+
+	logger.Info("Proxy started", tag.NewStringTag("store", datastore.Name), tag.NewStringTag("address", listener.Addr().String()))
+
+	return proxy.Serve(ctx, listener)
+}
+
+func serveElasticsearch(ctx context.Context, datastore *v1beta1.DatastoreSpec, listener net.Listener, logger log.Logger) error {
+	authenticator, err := esauth.NewAuthenticator(datastore)
+	if err != nil {
+		return fmt.Errorf("can't create authenticator: %w", err)
+	}
+
+	proxy, err := esauth.NewProxy(datastore, authenticator)
+	if err != nil {
+		return fmt.Errorf("can't create proxy: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           proxy,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	logger.Info("Proxy started", tag.NewStringTag("store", datastore.Name), tag.NewStringTag("address", listener.Addr().String()))
+
+	err = server.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,94 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command schema-runner creates, sets up and updates the schema of a temporal cluster datastore.
+//
+// Usage:
+//
+//	schema-runner [flags] <create|setup|update> <store>
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/schemarunner"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/log/tag"
+)
+
+func main() {
+	var (
+		configPath             string
+		terminationMessagePath string
+	)
+
+	flag.StringVar(&configPath, "config", "/etc/schema-runner/config.json", "The path of the runner configuration file.")
+	flag.StringVar(&terminationMessagePath, "termination-message-path", "/dev/termination-log", "The path the result is written to, leave empty to only write it to stdout.")
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: schema-runner [flags] <create|setup|update> <store>")
+		os.Exit(2)
+	}
+
+	operation := v1beta1.SchemaOperation(flag.Arg(0))
+	store := schemarunner.Store(flag.Arg(1))
+
+	os.Exit(run(configPath, terminationMessagePath, operation, store))
+}
+
+func run(configPath, terminationMessagePath string, operation v1beta1.SchemaOperation, store schemarunner.Store) int {
+	logger := log.NewCLILogger()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	config, err := schemarunner.LoadConfig(configPath)
+	if err != nil {
+		logger.Error("Unable to load config", tag.Error(err))
+		return 1
+	}
+
+	runner := schemarunner.New(config, logger)
+	defer func() {
+		err := runner.ShutdownProxy(context.Background())
+		if err != nil {
+			logger.Warn("Unable to shutdown proxy", tag.Error(err))
+		}
+	}()
+
+	result := runner.Run(ctx, operation, store)
+
+	err = schemarunner.WriteResult(os.Stdout, terminationMessagePath, result)
+	if err != nil {
+		logger.Error("Unable to write result", tag.Error(err))
+		return 1
+	}
+
+	if result.Error != "" {
+		logger.Error("Schema operation failed", tag.NewStringTag("operation", string(operation)), tag.NewStringTag("store", string(store)), tag.NewStringTag("error", result.Error))
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,238 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alexandrevilain/controller-tools/pkg/hash"
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/meta"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/persistence"
+	"go.temporal.io/server/common/primitives"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// datastoreSecretsField indexes clusters by the name of the secrets referenced by their datastores.
+const datastoreSecretsField = ".spec.persistence.secretRefs"
+
+// datastoreSecretsRolloutOrder is the order in which services are rolled out when datastore secrets change.
+// Each service is only rolled out once the previous one is ready.
+var datastoreSecretsRolloutOrder = []primitives.ServiceName{
+	primitives.HistoryService,
+	primitives.MatchingService,
+	primitives.InternalFrontendService,
+	primitives.FrontendService,
+	primitives.WorkerService,
+}
+
+// secretVersions returns the resource version of the referenced secrets, keyed by secret name.
+// It returns an error if a referenced secret or key is missing.
+func (r *TemporalClusterReconciler) secretVersions(ctx context.Context, cluster *v1beta1.TemporalCluster, refs []*v1beta1.SecretKeyReference) (map[string]string, error) {
+	versions := map[string]string{}
+	for _, ref := range refs {
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: cluster.GetNamespace(), Name: ref.Name}, secret)
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("secret %s not found", ref.Name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't get secret %s: %w", ref.Name, err)
+		}
+
+		if _, ok := secret.Data[ref.Key]; !ok {
+			return nil, fmt.Errorf("key %s not found in secret %s", ref.Key, ref.Name)
+		}
+
+		versions[ref.Name] = secret.ResourceVersion
+	}
+	return versions, nil
+}
+
+// datastoreSecretsHash returns a hash of the version of the secrets referenced by the cluster datastores.
+// It returns an empty hash if the datastores don't reference any secret.
+func (r *TemporalClusterReconciler) datastoreSecretsHash(ctx context.Context, cluster *v1beta1.TemporalCluster) (string, error) {
+	refs := []*v1beta1.SecretKeyReference{}
+	for _, datastore := range cluster.Spec.Persistence.GetDatastores() {
+		refs = append(refs, persistence.DatastoreSecretRefs(datastore)...)
+	}
+
+	if len(refs) == 0 {
+		return "", nil
+	}
+
+	versions, err := r.secretVersions(ctx, cluster, refs)
+	if err != nil {
+		return "", err
+	}
+
+	return hash.Sha256(versions)
+}
+
+// serviceDatastoreSecretsHashes returns the datastore secrets hash each service should be rolled out with.
+// When datastore secrets change, services are given the new hash one after another, following
+// datastoreSecretsRolloutOrder: a service keeps its current hash until the previous one is rolled out.
+func (r *TemporalClusterReconciler) serviceDatastoreSecretsHashes(ctx context.Context, cluster *v1beta1.TemporalCluster) (map[string]string, error) {
+	logger := log.FromContext(ctx)
+
+	desired, err := r.datastoreSecretsHash(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("can't compute datastore secrets hash: %w", err)
+	}
+
+	hashes := map[string]string{}
+	previousRolledOut := true
+	for _, service := range datastoreSecretsRolloutOrder {
+		serviceName := string(service)
+
+		specs, err := cluster.Spec.Services.GetServiceSpec(service)
+		if err != nil {
+			return nil, err
+		}
+
+		workload, template, err := r.serviceWorkload(ctx, cluster, serviceName, specs.GetWorkloadKind())
+		if err != nil {
+			return nil, err
+		}
+
+		// Services which are not deployed yet are created with the current secrets.
+		if workload == nil {
+			hashes[serviceName] = desired
+			continue
+		}
+
+		current := template.Annotations[meta.DatastoreSecretsHashKey]
+		if current != desired && !previousRolledOut {
+			logger.Info("Waiting for previous service to roll out datastore secrets", "service", serviceName)
+			hashes[serviceName] = current
+			continue
+		}
+
+		hashes[serviceName] = desired
+
+		if current != desired {
+			previousRolledOut = false
+			continue
+		}
+
+		status, err := resource.GetStatus(workload)
+		if err != nil {
+			return nil, fmt.Errorf("can't compute %s workload status: %w", serviceName, err)
+		}
+		previousRolledOut = previousRolledOut && status.Ready
+	}
+
+	return hashes, nil
+}
+
+// serviceWorkload returns the workload of the provided kind running the service and its pod template.
+// It returns a nil workload if it doesn't exist.
+func (r *TemporalClusterReconciler) serviceWorkload(ctx context.Context, cluster *v1beta1.TemporalCluster, serviceName string, kind v1beta1.WorkloadKind) (client.Object, *corev1.PodTemplateSpec, error) {
+	key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.ChildResourceName(serviceName)}
+
+	var (
+		workload client.Object
+		template *corev1.PodTemplateSpec
+	)
+	switch kind {
+	case v1beta1.StatefulSetWorkloadKind:
+		statefulSet := &appsv1.StatefulSet{}
+		workload, template = statefulSet, &statefulSet.Spec.Template
+	default:
+		deployment := &appsv1.Deployment{}
+		workload, template = deployment, &deployment.Spec.Template
+	}
+
+	err := r.Get(ctx, key, workload)
+	if apierrors.IsNotFound(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't get %s workload: %w", serviceName, err)
+	}
+
+	// Objects returned by the client have no type meta, set it for the status computation.
+	workload.GetObjectKind().SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind(string(kind)))
+
+	return workload, template, nil
+}
+
+// addDatastoreSecretsToIndex indexes clusters by the secrets referenced by their datastores.
+func addDatastoreSecretsToIndex(rawObj client.Object) []string {
+	cluster, ok := rawObj.(*v1beta1.TemporalCluster)
+	if !ok {
+		return nil
+	}
+
+	names := []string{}
+	for _, datastore := range cluster.Spec.Persistence.GetDatastores() {
+		for _, ref := range persistence.DatastoreSecretRefs(datastore) {
+			names = append(names, ref.Name)
+		}
+	}
+
+	// Managed datastores secrets are only known once resolved, they are reported in the status.
+	if cluster.Status.Persistence != nil {
+		for _, status := range []*v1beta1.DatastoreStatus{
+			cluster.Status.Persistence.DefaultStore,
+			cluster.Status.Persistence.VisibilityStore,
+			cluster.Status.Persistence.SecondaryVisibilityStore,
+			cluster.Status.Persistence.AdvancedVisibilityStore,
+		} {
+			if status != nil && status.Managed != nil {
+				names = append(names, status.Managed.Secrets...)
+			}
+		}
+	}
+
+	return names
+}
+
+// secretToClustersMapfunc returns a reconcile request for each cluster referencing the secret in its datastores.
+func (r *TemporalClusterReconciler) secretToClustersMapfunc(ctx context.Context, object client.Object) []reconcile.Request {
+	logger := log.FromContext(ctx)
+
+	clusters := &v1beta1.TemporalClusterList{}
+	err := r.List(ctx, clusters,
+		client.InNamespace(object.GetNamespace()),
+		client.MatchingFields{datastoreSecretsField: object.GetName()},
+	)
+	if err != nil {
+		logger.Error(err, "Can't list clusters referencing datastore secret", "secret", object.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(clusters.Items))
+	for _, cluster := range clusters.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      cluster.GetName(),
+				Namespace: cluster.GetNamespace(),
+			},
+		})
+	}
+	return requests
+}
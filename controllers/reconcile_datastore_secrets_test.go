@@ -0,0 +1,144 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/persistence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSecretVersions(t *testing.T) {
+	tests := map[string]struct {
+		datastore      *v1beta1.DatastoreSpec
+		expectedSecret string
+		expectedErr    string
+	}{
+		"password without secret key defined": {
+			datastore: &v1beta1.DatastoreSpec{
+				Name: "default",
+				PasswordSecretRef: &v1beta1.SecretKeyReference{
+					Name: "postgres",
+				},
+			},
+			expectedSecret: "postgres",
+		},
+		"password with secret key defined": {
+			datastore: &v1beta1.DatastoreSpec{
+				Name: "default",
+				PasswordSecretRef: &v1beta1.SecretKeyReference{
+					Name: "postgres",
+					Key:  "custom",
+				},
+			},
+			expectedSecret: "postgres",
+		},
+		"elasticsearch api key without secret key defined": {
+			datastore: &v1beta1.DatastoreSpec{
+				Name: "visibility",
+				Elasticsearch: &v1beta1.ElasticsearchSpec{
+					Auth: &v1beta1.ElasticsearchAuthSpec{
+						APIKey: &v1beta1.ElasticsearchAPIKeyAuthSpec{
+							SecretRef: v1beta1.SecretKeyReference{Name: "elasticsearch"},
+						},
+					},
+				},
+			},
+			expectedSecret: "elasticsearch",
+		},
+		"missing secret key": {
+			datastore: &v1beta1.DatastoreSpec{
+				Name: "default",
+				PasswordSecretRef: &v1beta1.SecretKeyReference{
+					Name: "postgres",
+					Key:  "missing",
+				},
+			},
+			expectedErr: "key missing not found in secret postgres",
+		},
+		"missing secret": {
+			datastore: &v1beta1.DatastoreSpec{
+				Name: "default",
+				PasswordSecretRef: &v1beta1.SecretKeyReference{
+					Name: "unknown",
+				},
+			},
+			expectedErr: "secret unknown not found",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+			}
+
+			postgres := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "postgres",
+					Namespace: "demo",
+				},
+				Data: map[string][]byte{
+					"password": []byte("secret"),
+					"custom":   []byte("secret"),
+				},
+			}
+			elasticsearch := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "elasticsearch",
+					Namespace: "demo",
+				},
+				Data: map[string][]byte{
+					"apiKey": []byte("secret"),
+				},
+			}
+
+			scheme := runtime.NewScheme()
+			utilruntime.Must(corev1.AddToScheme(scheme))
+
+			r := &TemporalClusterReconciler{
+				Base: Base{
+					Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(postgres, elasticsearch).Build(),
+					Scheme: scheme,
+				},
+			}
+
+			versions, err := r.secretVersions(context.Background(), cluster, persistence.DatastoreSecretRefs(test.datastore))
+			if test.expectedErr != "" {
+				require.Error(tt, err)
+				assert.Equal(tt, test.expectedErr, err.Error())
+				return
+			}
+
+			require.NoError(tt, err)
+			assert.Contains(tt, versions, test.expectedSecret)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/istio"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileIstioAmbientNamespace enrolls the cluster's namespace in the istio ambient mesh if requested.
+// The namespace may be shared with other workloads, so labels are only added, never removed.
+func (r *TemporalClusterReconciler) reconcileIstioAmbientNamespace(ctx context.Context, cluster *v1beta1.TemporalCluster) error {
+	labels := istio.GetNamespaceLabels(cluster)
+	if len(labels) == 0 {
+		return nil
+	}
+
+	namespace := &corev1.Namespace{}
+	err := r.Get(ctx, types.NamespacedName{Name: cluster.Namespace}, namespace)
+	if err != nil {
+		return fmt.Errorf("can't get namespace %s: %w", cluster.Namespace, err)
+	}
+
+	upToDate := true
+	for key, value := range labels {
+		if namespace.Labels[key] != value {
+			upToDate = false
+		}
+	}
+	if upToDate {
+		return nil
+	}
+
+	patch := client.MergeFrom(namespace.DeepCopy())
+	namespace.Labels = metadata.Merge(namespace.Labels, labels)
+
+	err = r.Patch(ctx, namespace, patch)
+	if err != nil {
+		return fmt.Errorf("can't label namespace %s: %w", cluster.Namespace, err)
+	}
+
+	return nil
+}
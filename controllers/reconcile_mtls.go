@@ -0,0 +1,469 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alexandrevilain/controller-tools/pkg/hash"
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/meta"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/certmanager"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/operator"
+	certmanagerapiutil "github.com/cert-manager/cert-manager/pkg/api/util"
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	certmanagermeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const caRotationRequeueInterval = 10 * time.Second
+
+// reconcileOperatorCertificates issues and renews the cluster certificates when the operator is used as mTLS provider.
+// It returns the duration until the next certificate renewal.
+func (r *TemporalClusterReconciler) reconcileOperatorCertificates(ctx context.Context, cluster *v1beta1.TemporalCluster) (time.Duration, error) {
+	if !cluster.MTLSWithOperatorEnabled() {
+		return 0, nil
+	}
+
+	var nextRenewal time.Time
+	for _, certificate := range operator.Certificates(cluster) {
+		if !certificate.Enabled {
+			continue
+		}
+
+		renewal, err := r.reconcileOperatorCertificate(ctx, cluster, certificate, false)
+		if err != nil {
+			return 0, err
+		}
+
+		if nextRenewal.IsZero() || renewal.Before(nextRenewal) {
+			nextRenewal = renewal
+		}
+	}
+
+	if nextRenewal.IsZero() {
+		return 0, nil
+	}
+
+	return max(time.Until(nextRenewal), time.Second), nil
+}
+
+// reconcileOperatorCertificate issues the provided certificate if needed, or if reissue is true.
+// It returns the time at which the certificate should be renewed.
+func (b *Base) reconcileOperatorCertificate(ctx context.Context, cluster *v1beta1.TemporalCluster, certificate *operator.Certificate, reissue bool) (time.Time, error) {
+	var issuer map[string][]byte
+	if certificate.IssuerSecretName != "" {
+		issuerSecret, err := b.getSecret(ctx, cluster, certificate.IssuerSecretName)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("can't get certificate %s issuer secret: %w", certificate.Name, err)
+		}
+		if len(issuerSecret.Data[certmanager.TLSCert]) == 0 {
+			return time.Time{}, fmt.Errorf("certificate %s issuer is not issued yet", certificate.Name)
+		}
+		issuer = issuerSecret.Data
+	}
+
+	object, err := b.Reconciler.ReconcileBuilder(ctx, cluster, operator.NewCertificateSecretBuilder(cluster, b.Scheme, certificate, issuer, reissue))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("can't reconcile certificate %s secret: %w", certificate.Name, err)
+	}
+
+	secret, ok := object.(*corev1.Secret)
+	if !ok {
+		return time.Time{}, errors.New("can't cast certificate object to *corev1.Secret")
+	}
+
+	certificates, err := certmanager.DecodeCertificates(secret.Data[certmanager.TLSCert])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("can't decode certificate %s: %w", certificate.Name, err)
+	}
+	if len(certificates) == 0 {
+		return time.Time{}, fmt.Errorf("no certificate found in %s secret", certificate.SecretName)
+	}
+
+	return operator.RenewalTime(certificates[0], cluster.Spec.MTLS.RenewBefore), nil
+}
+
+// reconcileCARotation keeps the CA bundle trusted by the cluster components up-to-date and
+// drives the rotation of the certificate authorities, issued by cert-manager or the operator, without downtime:
+//   - when a new root CA is issued, it is added to the CA bundle next to the previous one;
+//   - once all pods trust the new bundle, certificates issued by a previous CA are reissued;
+//   - once all certificates are reissued, the previous root CA is removed from the bundle.
+//
+// It returns the hash of the CA bundle the cluster pods should be annotated with.
+func (r *TemporalClusterReconciler) reconcileCARotation(ctx context.Context, cluster *v1beta1.TemporalCluster) (string, time.Duration, error) {
+	if !cluster.MTLSWithOperatorEnabled() && !(cluster.MTLSWithCertManagerEnabled() && r.AvailableAPIs.CertManager) {
+		return "", 0, nil
+	}
+
+	bundleSecret, err := r.getSecret(ctx, cluster, cluster.ChildResourceName(certmanager.CABundle))
+	if err != nil {
+		return "", 0, fmt.Errorf("can't get CA bundle secret: %w", err)
+	}
+
+	trusted, err := certmanager.DecodeCertificates(bundleSecret.Data[certmanager.TLSCA])
+	if err != nil {
+		return "", 0, fmt.Errorf("can't decode CA bundle: %w", err)
+	}
+
+	rootCASecret, err := r.getSecret(ctx, cluster, cluster.ChildResourceName(certmanager.RootCACertificate))
+	if err != nil {
+		return "", 0, fmt.Errorf("can't get root CA secret: %w", err)
+	}
+
+	rootCAs, err := certmanager.DecodeCertificates(rootCASecret.Data[certmanager.TLSCert])
+	if err != nil {
+		return "", 0, fmt.Errorf("can't decode root CA: %w", err)
+	}
+
+	// The root CA is not issued yet, keep the current bundle as is.
+	if len(rootCAs) == 0 {
+		if len(trusted) == 0 {
+			return "", 0, nil
+		}
+		bundleHash, err := caBundleHash(trusted)
+		return bundleHash, 0, err
+	}
+
+	rootCA := rootCAs[0]
+	desired := trusted
+	reason := v1beta1.CAUpToDateReason
+	message := ""
+
+	switch {
+	case len(trusted) == 0:
+		desired = []*x509.Certificate{rootCA}
+	case !certmanager.ContainsCertificate(trusted, rootCA):
+		desired = append(append([]*x509.Certificate{}, trusted...), rootCA)
+		reason = v1beta1.CABundlePublishedReason
+		message = "Waiting for all pods to trust the new root CA"
+	default:
+		bundleHash, err := caBundleHash(trusted)
+		if err != nil {
+			return "", 0, err
+		}
+
+		rolledOut, err := r.isCABundleRolledOut(ctx, cluster, bundleHash)
+		if err != nil {
+			return "", 0, err
+		}
+
+		staleCertificates, err := r.certificatesIssuedByPreviousCA(ctx, cluster)
+		if err != nil {
+			return "", 0, err
+		}
+
+		switch {
+		case len(staleCertificates) > 0 && len(trusted) > 1 && !rolledOut:
+			reason = v1beta1.CABundlePublishedReason
+			message = "Waiting for all pods to trust the new root CA"
+		case len(staleCertificates) > 0:
+			err := r.reissueCertificates(ctx, staleCertificates)
+			if err != nil {
+				return "", 0, err
+			}
+			reason = v1beta1.CertificatesReissuingReason
+			message = fmt.Sprintf("Waiting for %d certificate(s) issued by a previous CA to be reissued", len(staleCertificates))
+		case len(trusted) > 1:
+			desired = []*x509.Certificate{rootCA}
+			reason = v1beta1.PreviousCADroppedReason
+			message = "Waiting for all pods to drop the previous root CA"
+		case !rolledOut:
+			if condition, ok := v1beta1.GetTemporalClusterCARotationCondition(cluster); ok && condition.Reason == v1beta1.PreviousCADroppedReason {
+				reason = v1beta1.PreviousCADroppedReason
+				message = condition.Message
+			}
+		}
+	}
+
+	_, err = r.Reconciler.ReconcileBuilder(ctx, cluster, certmanager.NewMTLSCABundleSecretBuilder(cluster, r.Scheme, certmanager.EncodeCertificates(desired)))
+	if err != nil {
+		return "", 0, fmt.Errorf("can't reconcile CA bundle secret: %w", err)
+	}
+
+	bundleHash, err := caBundleHash(desired)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if reason == v1beta1.CAUpToDateReason {
+		v1beta1.SetTemporalClusterCARotation(cluster, metav1.ConditionFalse, reason, message)
+		return bundleHash, 0, nil
+	}
+
+	v1beta1.SetTemporalClusterCARotation(cluster, metav1.ConditionTrue, reason, message)
+
+	return bundleHash, caRotationRequeueInterval, nil
+}
+
+func caBundleHash(certificates []*x509.Certificate) (string, error) {
+	bundleHash, err := hash.Sha256(certmanager.EncodeCertificates(certificates))
+	if err != nil {
+		return "", fmt.Errorf("can't compute CA bundle hash: %w", err)
+	}
+	return bundleHash, nil
+}
+
+// getSecret returns the requested secret from the cluster namespace.
+// An empty secret is returned if it does not exist.
+func (b *Base) getSecret(ctx context.Context, cluster *v1beta1.TemporalCluster, name string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	err := b.Get(ctx, types.NamespacedName{Namespace: cluster.GetNamespace(), Name: name}, secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// isCABundleRolledOut returns true if all the cluster's deployments and statefulsets are rolled out with the provided CA bundle hash.
+func (r *TemporalClusterReconciler) isCABundleRolledOut(ctx context.Context, cluster *v1beta1.TemporalCluster, bundleHash string) (bool, error) {
+	deployments := &appsv1.DeploymentList{}
+	err := r.List(ctx, deployments, client.InNamespace(cluster.GetNamespace()), client.MatchingFields{ownerKey: cluster.GetName()})
+	if err != nil {
+		return false, fmt.Errorf("can't list cluster deployments: %w", err)
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	err = r.List(ctx, statefulSets, client.InNamespace(cluster.GetNamespace()), client.MatchingFields{ownerKey: cluster.GetName()})
+	if err != nil {
+		return false, fmt.Errorf("can't list cluster statefulsets: %w", err)
+	}
+
+	workloads := []client.Object{}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		if deployment.Spec.Template.Annotations[meta.CABundleHashKey] != bundleHash {
+			return false, nil
+		}
+		deployment.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+		workloads = append(workloads, deployment)
+	}
+
+	for i := range statefulSets.Items {
+		statefulSet := &statefulSets.Items[i]
+		if statefulSet.Spec.Template.Annotations[meta.CABundleHashKey] != bundleHash {
+			return false, nil
+		}
+		statefulSet.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("StatefulSet"))
+		workloads = append(workloads, statefulSet)
+	}
+
+	for _, workload := range workloads {
+		status, err := resource.GetStatus(workload)
+		if err != nil {
+			return false, err
+		}
+
+		if !status.Ready {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// staleCertificate is a certificate issued by a previous CA.
+type staleCertificate struct {
+	name string
+	isCA bool
+	// reissue requests the certificate to be issued again by its issuer's current CA.
+	reissue func(ctx context.Context) error
+}
+
+// certificatesIssuedByPreviousCA returns the cluster's certificates which are not signed by their issuer's current CA.
+// CA certificates are returned first: leaf certificates are only returned once their issuing CA is up-to-date.
+func (r *TemporalClusterReconciler) certificatesIssuedByPreviousCA(ctx context.Context, cluster *v1beta1.TemporalCluster) ([]*staleCertificate, error) {
+	var (
+		certificates []*staleCertificate
+		err          error
+	)
+	if cluster.MTLSWithOperatorEnabled() {
+		certificates, err = r.operatorCertificatesIssuedByPreviousCA(ctx, cluster)
+	} else {
+		certificates, err = r.certManagerCertificatesIssuedByPreviousCA(ctx, cluster)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cas := []*staleCertificate{}
+	leaves := []*staleCertificate{}
+
+	for _, certificate := range certificates {
+		if certificate.isCA {
+			cas = append(cas, certificate)
+		} else {
+			leaves = append(leaves, certificate)
+		}
+	}
+
+	if len(cas) > 0 {
+		return cas, nil
+	}
+
+	return leaves, nil
+}
+
+func (r *TemporalClusterReconciler) operatorCertificatesIssuedByPreviousCA(ctx context.Context, cluster *v1beta1.TemporalCluster) ([]*staleCertificate, error) {
+	result := []*staleCertificate{}
+
+	for _, certificate := range operator.Certificates(cluster) {
+		if !certificate.Enabled || certificate.IssuerSecretName == "" {
+			continue
+		}
+
+		stale, err := r.isSecretIssuedByPreviousCA(ctx, cluster, certificate.SecretName, certificate.IssuerSecretName)
+		if err != nil {
+			return nil, err
+		}
+
+		if !stale {
+			continue
+		}
+
+		result = append(result, &staleCertificate{
+			name: certificate.Name,
+			isCA: certificate.IsCA,
+			reissue: func(ctx context.Context) error {
+				_, err := r.reconcileOperatorCertificate(ctx, cluster, certificate, true)
+				return err
+			},
+		})
+	}
+
+	return result, nil
+}
+
+func (r *TemporalClusterReconciler) certManagerCertificatesIssuedByPreviousCA(ctx context.Context, cluster *v1beta1.TemporalCluster) ([]*staleCertificate, error) {
+	certificates := &certmanagerv1.CertificateList{}
+	err := r.List(ctx, certificates, client.InNamespace(cluster.GetNamespace()), client.MatchingFields{ownerKey: cluster.GetName()})
+	if err != nil {
+		return nil, fmt.Errorf("can't list cluster certificates: %w", err)
+	}
+
+	result := []*staleCertificate{}
+
+	for i := range certificates.Items {
+		certificate := &certificates.Items[i]
+
+		stale, err := r.isIssuedByPreviousCA(ctx, cluster, certificate)
+		if err != nil {
+			return nil, err
+		}
+
+		if !stale {
+			continue
+		}
+
+		result = append(result, &staleCertificate{
+			name: certificate.GetName(),
+			isCA: certificate.Spec.IsCA,
+			reissue: func(ctx context.Context) error {
+				return r.reissueCertManagerCertificate(ctx, certificate)
+			},
+		})
+	}
+
+	return result, nil
+}
+
+func (r *TemporalClusterReconciler) isIssuedByPreviousCA(ctx context.Context, cluster *v1beta1.TemporalCluster, certificate *certmanagerv1.Certificate) (bool, error) {
+	if certificate.Spec.IssuerRef.Kind != certmanagerv1.IssuerKind {
+		return false, nil
+	}
+
+	issuer := &certmanagerv1.Issuer{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: cluster.GetNamespace(), Name: certificate.Spec.IssuerRef.Name}, issuer)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("can't get issuer: %w", err)
+	}
+
+	// Only certificates issued by a CA issuer are concerned by the rotation, the root CA is self-signed.
+	if issuer.Spec.CA == nil {
+		return false, nil
+	}
+
+	return r.isSecretIssuedByPreviousCA(ctx, cluster, certificate.Spec.SecretName, issuer.Spec.CA.SecretName)
+}
+
+// isSecretIssuedByPreviousCA returns true if the certificate stored in the provided secret is not signed
+// by the CA stored in the provided CA secret.
+func (r *TemporalClusterReconciler) isSecretIssuedByPreviousCA(ctx context.Context, cluster *v1beta1.TemporalCluster, secretName, caSecretName string) (bool, error) {
+	certificateSecret, err := r.getSecret(ctx, cluster, secretName)
+	if err != nil {
+		return false, fmt.Errorf("can't get certificate secret: %w", err)
+	}
+
+	caSecret, err := r.getSecret(ctx, cluster, caSecretName)
+	if err != nil {
+		return false, fmt.Errorf("can't get issuer CA secret: %w", err)
+	}
+
+	// Certificates not issued yet are handled by their provider.
+	if len(certificateSecret.Data[certmanager.TLSCert]) == 0 || len(caSecret.Data[certmanager.TLSCert]) == 0 {
+		return false, nil
+	}
+
+	issued, err := certmanager.IsIssuedBy(certificateSecret.Data[certmanager.TLSCert], caSecret.Data[certmanager.TLSCert])
+	if err != nil {
+		return false, fmt.Errorf("can't check certificate %s issuer: %w", secretName, err)
+	}
+
+	return !issued, nil
+}
+
+// reissueCertificates requests the provided certificates to be issued again.
+func (r *TemporalClusterReconciler) reissueCertificates(ctx context.Context, certificates []*staleCertificate) error {
+	for _, certificate := range certificates {
+		err := certificate.reissue(ctx)
+		if err != nil {
+			return fmt.Errorf("can't reissue certificate %s: %w", certificate.name, err)
+		}
+	}
+
+	return nil
+}
+
+// reissueCertManagerCertificate asks cert-manager to reissue the provided certificate
+// by setting its Issuing condition, the same way cmctl renew does.
+func (r *TemporalClusterReconciler) reissueCertManagerCertificate(ctx context.Context, certificate *certmanagerv1.Certificate) error {
+	issuing := certmanagerv1.CertificateCondition{
+		Type:   certmanagerv1.CertificateConditionIssuing,
+		Status: certmanagermeta.ConditionTrue,
+	}
+	if certmanagerapiutil.CertificateHasCondition(certificate, issuing) {
+		return nil
+	}
+
+	certmanagerapiutil.SetCertificateCondition(certificate, certificate.GetGeneration(), certmanagerv1.CertificateConditionIssuing, certmanagermeta.ConditionTrue, "CARotation", "Certificate issued by a previous CA is being reissued")
+
+	return r.Status().Update(ctx, certificate)
+}
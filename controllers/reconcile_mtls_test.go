@@ -0,0 +1,221 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexandrevilain/controller-tools/pkg/reconciler"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/meta"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/certmanager"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/operator"
+	"github.com/alexandrevilain/temporal-operator/pkg/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileCARotation(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := &v1beta1.TemporalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "demo",
+		},
+		Spec: v1beta1.TemporalClusterSpec{
+			Version: version.MustNewVersionFromString("1.25.0"),
+			MTLS: &v1beta1.MTLSSpec{
+				Provider:  v1beta1.OperatorMTLSProvider,
+				Internode: &v1beta1.InternodeMTLSSpec{Enabled: true},
+			},
+		},
+	}
+	cluster.Default()
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(appsv1.AddToScheme(scheme))
+	utilruntime.Must(v1beta1.AddToScheme(scheme))
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-history",
+			Namespace:       "demo",
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cluster, v1beta1.GroupVersion.WithKind("TemporalCluster"))},
+		},
+	}
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(deployment).
+		WithIndex(&appsv1.Deployment{}, ownerKey, addResourceToIndex).
+		WithIndex(&appsv1.StatefulSet{}, ownerKey, addResourceToIndex).
+		Build()
+
+	r := &TemporalClusterReconciler{
+		Base: Base{
+			Client: c,
+			Scheme: scheme,
+			Reconciler: &reconciler.Reconciler{
+				Client:   c,
+				Scheme:   scheme,
+				Recorder: record.NewFakeRecorder(100),
+			},
+		},
+	}
+
+	certificates := map[string]*operator.Certificate{}
+	for _, certificate := range operator.Certificates(cluster) {
+		certificates[certificate.Name] = certificate
+	}
+
+	secretData := func(name string) []byte {
+		secret := &corev1.Secret{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: "demo", Name: cluster.ChildResourceName(name)}, secret)
+		require.NoError(t, err)
+		return secret.Data[certmanager.TLSCert]
+	}
+
+	issuedBy := func(name, issuer string) bool {
+		issued, err := certmanager.IsIssuedBy(secretData(name), secretData(issuer))
+		require.NoError(t, err)
+		return issued
+	}
+
+	bundle := func() []byte {
+		secret := &corev1.Secret{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: "demo", Name: cluster.ChildResourceName(certmanager.CABundle)}, secret)
+		require.NoError(t, err)
+		return secret.Data[certmanager.TLSCA]
+	}
+
+	bundleContains := func(rootCA []byte) bool {
+		trusted, err := certmanager.DecodeCertificates(bundle())
+		require.NoError(t, err)
+		certificates, err := certmanager.DecodeCertificates(rootCA)
+		require.NoError(t, err)
+		return certmanager.ContainsCertificate(trusted, certificates[0])
+	}
+
+	// rollOut marks the cluster deployment as rolled out with the provided CA bundle hash.
+	rollOut := func(bundleHash string) {
+		object := &appsv1.Deployment{}
+		err := c.Get(ctx, client.ObjectKeyFromObject(deployment), object)
+		require.NoError(t, err)
+
+		object.Spec.Template.Annotations = map[string]string{meta.CABundleHashKey: bundleHash}
+		require.NoError(t, c.Update(ctx, object))
+
+		object.Status = appsv1.DeploymentStatus{
+			ObservedGeneration: object.Generation,
+			Replicas:           1,
+			UpdatedReplicas:    1,
+			ReadyReplicas:      1,
+			AvailableReplicas:  1,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+			},
+		}
+		require.NoError(t, c.Status().Update(ctx, object))
+	}
+
+	assertCondition := func(status metav1.ConditionStatus, reason string) {
+		condition, ok := v1beta1.GetTemporalClusterCARotationCondition(cluster)
+		require.True(t, ok)
+		assert.Equal(t, status, condition.Status)
+		assert.Equal(t, reason, condition.Reason)
+	}
+
+	_, err := r.reconcileOperatorCertificates(ctx, cluster)
+	require.NoError(t, err)
+
+	// The first bundle only trusts the root CA.
+	bundleHash, requeueAfter, err := r.reconcileCARotation(ctx, cluster)
+	require.NoError(t, err)
+	assert.Zero(t, requeueAfter)
+	assertCondition(metav1.ConditionFalse, v1beta1.CAUpToDateReason)
+	assert.Equal(t, secretData(certmanager.RootCACertificate), bundle())
+	rollOut(bundleHash)
+
+	previousRootCA := secretData(certmanager.RootCACertificate)
+
+	// A new root CA is issued: it's published next to the previous one.
+	_, err = r.reconcileOperatorCertificate(ctx, cluster, certificates[certmanager.RootCACertificate], true)
+	require.NoError(t, err)
+	require.NotEqual(t, previousRootCA, secretData(certmanager.RootCACertificate))
+
+	bundleHash, requeueAfter, err = r.reconcileCARotation(ctx, cluster)
+	require.NoError(t, err)
+	assert.Equal(t, caRotationRequeueInterval, requeueAfter)
+	assertCondition(metav1.ConditionTrue, v1beta1.CABundlePublishedReason)
+	assert.True(t, bundleContains(previousRootCA))
+	assert.True(t, bundleContains(secretData(certmanager.RootCACertificate)))
+
+	// Certificates are not reissued while pods don't trust the new bundle.
+	_, _, err = r.reconcileCARotation(ctx, cluster)
+	require.NoError(t, err)
+	assertCondition(metav1.ConditionTrue, v1beta1.CABundlePublishedReason)
+	assert.False(t, issuedBy(certmanager.InternodeIntermediateCACertificate, certmanager.RootCACertificate))
+
+	rollOut(bundleHash)
+
+	// Once rolled out, the intermediate CA is reissued first, then the leaf certificate.
+	_, _, err = r.reconcileCARotation(ctx, cluster)
+	require.NoError(t, err)
+	assertCondition(metav1.ConditionTrue, v1beta1.CertificatesReissuingReason)
+	assert.True(t, issuedBy(certmanager.InternodeIntermediateCACertificate, certmanager.RootCACertificate))
+	assert.False(t, issuedBy(certmanager.InternodeCertificate, certmanager.InternodeIntermediateCACertificate))
+	assert.True(t, bundleContains(previousRootCA))
+
+	_, _, err = r.reconcileCARotation(ctx, cluster)
+	require.NoError(t, err)
+	assertCondition(metav1.ConditionTrue, v1beta1.CertificatesReissuingReason)
+	assert.True(t, issuedBy(certmanager.InternodeCertificate, certmanager.InternodeIntermediateCACertificate))
+	assert.True(t, bundleContains(previousRootCA))
+
+	// All certificates are reissued: the previous root CA is dropped.
+	bundleHash, requeueAfter, err = r.reconcileCARotation(ctx, cluster)
+	require.NoError(t, err)
+	assert.Equal(t, caRotationRequeueInterval, requeueAfter)
+	assertCondition(metav1.ConditionTrue, v1beta1.PreviousCADroppedReason)
+	assert.False(t, bundleContains(previousRootCA))
+	assert.Equal(t, secretData(certmanager.RootCACertificate), bundle())
+
+	// The rotation is reported until pods are rolled out without the previous root CA.
+	_, _, err = r.reconcileCARotation(ctx, cluster)
+	require.NoError(t, err)
+	assertCondition(metav1.ConditionTrue, v1beta1.PreviousCADroppedReason)
+
+	rollOut(bundleHash)
+
+	_, requeueAfter, err = r.reconcileCARotation(ctx, cluster)
+	require.NoError(t, err)
+	assert.Zero(t, requeueAfter)
+	assertCondition(metav1.ConditionFalse, v1beta1.CAUpToDateReason)
+}
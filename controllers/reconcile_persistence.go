@@ -20,7 +20,6 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"path"
 	"strings"
 	"time"
 
@@ -29,10 +28,18 @@ import (
 	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/base"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/persistence"
+	"github.com/alexandrevilain/temporal-operator/pkg/schemarunner"
 	"github.com/alexandrevilain/temporal-operator/pkg/version"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// persistencePlanOnlyRequeueAfter is the delay before checking again if the plan only mode has been disabled.
+const persistencePlanOnlyRequeueAfter = time.Minute
+
 func sanitizeVersionToName(version *version.Version) string {
 	return strings.ReplaceAll(version.String(), ".", "-")
 }
@@ -103,33 +110,166 @@ func (r *TemporalClusterReconciler) reconcilePersistenceStatus(cluster *v1beta1.
 	}
 }
 
-func getDatabaseScriptCommand(script string) []string {
-	return []string{path.Join("/etc/scripts", script)}
+// schemaJobCommand returns the schema runner arguments running the operation on the store.
+func schemaJobCommand(operation v1beta1.SchemaOperation, store schemarunner.Store) []string {
+	return []string{string(operation), string(store)}
+}
+
+// clusterStores returns the stores of the cluster, in order.
+func clusterStores(cluster *v1beta1.TemporalCluster) []schemarunner.Store {
+	stores := []schemarunner.Store{schemarunner.DefaultStore, schemarunner.VisibilityStore}
+	if cluster.Spec.Persistence.SecondaryVisibilityStore != nil {
+		stores = append(stores, schemarunner.SecondaryVisibilityStore)
+	}
+	if cluster.Spec.Persistence.AdvancedVisibilityStore != nil {
+		stores = append(stores, schemarunner.AdvancedVisibilityStore)
+	}
+	return stores
+}
+
+// datastoreStatus returns the status of the provided store.
+func datastoreStatus(cluster *v1beta1.TemporalCluster, store schemarunner.Store) *v1beta1.DatastoreStatus {
+	switch store {
+	case schemarunner.DefaultStore:
+		return cluster.Status.Persistence.DefaultStore
+	case schemarunner.VisibilityStore:
+		return cluster.Status.Persistence.VisibilityStore
+	case schemarunner.SecondaryVisibilityStore:
+		return cluster.Status.Persistence.SecondaryVisibilityStore
+	case schemarunner.AdvancedVisibilityStore:
+		return cluster.Status.Persistence.AdvancedVisibilityStore
+	}
+	return nil
+}
+
+// datastoreSpec returns the spec of the provided store.
+func datastoreSpec(cluster *v1beta1.TemporalCluster, store schemarunner.Store) *v1beta1.DatastoreSpec {
+	switch store {
+	case schemarunner.DefaultStore:
+		return cluster.Spec.Persistence.DefaultStore
+	case schemarunner.VisibilityStore:
+		return cluster.Spec.Persistence.VisibilityStore
+	case schemarunner.SecondaryVisibilityStore:
+		return cluster.Spec.Persistence.SecondaryVisibilityStore
+	case schemarunner.AdvancedVisibilityStore:
+		return cluster.Spec.Persistence.AdvancedVisibilityStore
+	}
+	return nil
+}
+
+// recordSchemaOperation records the result reported by the job schema runner in the store status.
+// The result is informative: failing to retrieve it doesn't fail the reconciliation.
+func (r *TemporalClusterReconciler) recordSchemaOperation(ctx context.Context, cluster *v1beta1.TemporalCluster, job *reconciler.Job) {
+	logger := log.FromContext(ctx)
+
+	status := datastoreStatus(cluster, schemarunner.Store(job.Command[1]))
+	if status == nil {
+		return
+	}
+
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+
+	pods := &corev1.PodList{}
+	err := reader.List(ctx, pods,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{batchv1.JobNameLabel: cluster.ChildResourceName(job.Name)},
+	)
+	if err != nil {
+		logger.Error(err, "Can't list schema job pods", "name", job.Name)
+		return
+	}
+
+	result, err := schemarunner.ResultFromPods(pods.Items, persistence.SchemaRunnerContainerName)
+	if err != nil {
+		logger.Error(err, "Can't get schema job result", "name", job.Name)
+		return
+	}
+
+	if result != nil {
+		status.LastSchemaOperation = result
+	}
+}
+
+// planSchemaJobs replaces the pending schema update jobs of already setup stores by plan jobs,
+// reporting the migrations in the store status without applying them.
+// It returns the oldest schema version of the stores with a pending update, nil if no update is pending.
+func planSchemaJobs(cluster *v1beta1.TemporalCluster, jobs []*reconciler.Job) *version.Version {
+	var current *version.Version
+	for i, job := range jobs {
+		if job.Command[0] != string(v1beta1.UpdateSchemaOperation) {
+			continue
+		}
+
+		store := schemarunner.Store(job.Command[1])
+		status := datastoreStatus(cluster, store)
+		// Newly created schemas are always brought to the cluster version.
+		if status == nil || status.SchemaVersion == nil || job.Skip(cluster) {
+			continue
+		}
+
+		if current == nil || status.SchemaVersion.LessThan(current) {
+			current = status.SchemaVersion.DeepCopy()
+		}
+		jobs[i] = &reconciler.Job{
+			Name:    strings.Replace(job.Name, "update-", "plan-", 1),
+			Command: schemaJobCommand(v1beta1.PlanSchemaOperation, store),
+			Skip: func(owner runtime.Object) bool {
+				c := owner.(*v1beta1.TemporalCluster)
+				plan := datastoreStatus(c, store).PlannedMigrations
+				return plan != nil && plan.Version != nil && plan.Version.Equal(c.Spec.Version.Version)
+			},
+			ReportSuccess: func(owner runtime.Object) error {
+				c := owner.(*v1beta1.TemporalCluster)
+				status := datastoreStatus(c, store)
+				plan := &v1beta1.SchemaMigrationPlan{
+					Version: c.Spec.Version.DeepCopy(),
+				}
+				if status.LastSchemaOperation != nil && status.LastSchemaOperation.Operation == v1beta1.PlanSchemaOperation {
+					plan.SchemaVersion = status.LastSchemaOperation.SchemaVersion
+					plan.Migrations = status.LastSchemaOperation.PlannedMigrations
+				}
+				status.PlannedMigrations = plan
+				return nil
+			},
+		}
+	}
+
+	return current
 }
 
 // reconcilePersistence tries to reconcile the cluster persistence.
-func (r *TemporalClusterReconciler) reconcilePersistence(ctx context.Context, cluster *v1beta1.TemporalCluster) (time.Duration, error) {
+// In plan only mode, it returns the version the services have to keep running until the planned schema migrations are applied.
+func (r *TemporalClusterReconciler) reconcilePersistence(ctx context.Context, cluster *v1beta1.TemporalCluster) (*version.Version, time.Duration, error) {
 	// First of all, ensure status fields are set.
 	r.reconcilePersistenceStatus(cluster)
 
-	// Ensure the configmap containing scripts is up-to-date
-	_, err := r.Reconciler.ReconcileBuilder(ctx, cluster, persistence.NewSchemaScriptsConfigmapBuilder(cluster, r.Scheme))
+	// Ensure the configmap containing the schema runner configuration is up-to-date
+	_, err := r.Reconciler.ReconcileBuilder(ctx, cluster, persistence.NewSchemaRunnerConfigmapBuilder(cluster, r.Scheme))
 	if err != nil {
-		return 0, fmt.Errorf("can't reconcile schema script configmap: %w", err)
+		return nil, 0, fmt.Errorf("can't reconcile schema runner configmap: %w", err)
 	}
 
 	// Ensure the serviceaccount used by jobs is up-to-date
 	serviceAccountBuilder := base.NewServiceAccountBuilder(persistence.ServiceNameSuffix, cluster, r.Scheme)
 	_, err = r.Reconciler.ReconcileBuilders(ctx, cluster, []resource.Builder{serviceAccountBuilder})
 	if err != nil {
-		return 0, fmt.Errorf("can't reconcile schema serviceaccount: %w", err)
+		return nil, 0, fmt.Errorf("can't reconcile schema serviceaccount: %w", err)
+	}
+
+	// Check the datastores are reachable before running any schema operation or rolling out services.
+	requeueAfter, err := r.reconcilePersistencePreflight(ctx, cluster)
+	if err != nil || requeueAfter > 0 {
+		return nil, requeueAfter, err
 	}
 
 	// Then for each stores actions, check if the corresponding job is created and has successfully ran.
 	jobs := []*reconciler.Job{
 		{
 			Name:    "create-default-database",
-			Command: getDatabaseScriptCommand(persistence.CreateDefaultDatabaseScript),
+			Command: schemaJobCommand(v1beta1.CreateSchemaOperation, schemarunner.DefaultStore),
 			Skip: func(owner runtime.Object) bool {
 				cluster := owner.(*v1beta1.TemporalCluster)
 				return cluster.Spec.Persistence.DefaultStore.SkipCreate ||
@@ -143,7 +283,7 @@ func (r *TemporalClusterReconciler) reconcilePersistence(ctx context.Context, cl
 		},
 		{
 			Name:    "create-visibility-database",
-			Command: getDatabaseScriptCommand(persistence.CreateVisibilityDatabaseScript),
+			Command: schemaJobCommand(v1beta1.CreateSchemaOperation, schemarunner.VisibilityStore),
 			Skip: func(owner runtime.Object) bool {
 				cluster := owner.(*v1beta1.TemporalCluster)
 				return cluster.Spec.Persistence.VisibilityStore.SkipCreate ||
@@ -157,7 +297,7 @@ func (r *TemporalClusterReconciler) reconcilePersistence(ctx context.Context, cl
 		},
 		{
 			Name:    "setup-default-schema",
-			Command: getDatabaseScriptCommand(persistence.SetupDefaultSchemaScript),
+			Command: schemaJobCommand(v1beta1.SetupSchemaOperation, schemarunner.DefaultStore),
 			Skip: func(owner runtime.Object) bool {
 				return owner.(*v1beta1.TemporalCluster).Status.Persistence.DefaultStore.Setup
 			},
@@ -169,7 +309,7 @@ func (r *TemporalClusterReconciler) reconcilePersistence(ctx context.Context, cl
 		},
 		{
 			Name:    "setup-visibility-schema",
-			Command: getDatabaseScriptCommand(persistence.SetupVisibilitySchemaScript),
+			Command: schemaJobCommand(v1beta1.SetupSchemaOperation, schemarunner.VisibilityStore),
 			Skip: func(owner runtime.Object) bool {
 				return owner.(*v1beta1.TemporalCluster).Status.Persistence.VisibilityStore.Setup
 			},
@@ -181,7 +321,7 @@ func (r *TemporalClusterReconciler) reconcilePersistence(ctx context.Context, cl
 		},
 		{
 			Name:    fmt.Sprintf("update-default-schema-v-%s", sanitizeVersionToName(cluster.Spec.Version)),
-			Command: getDatabaseScriptCommand(persistence.UpdateDefaultSchemaScript),
+			Command: schemaJobCommand(v1beta1.UpdateSchemaOperation, schemarunner.DefaultStore),
 			Skip: func(owner runtime.Object) bool {
 				c := owner.(*v1beta1.TemporalCluster)
 				if c.Status.Persistence.DefaultStore.SchemaVersion == nil {
@@ -198,7 +338,7 @@ func (r *TemporalClusterReconciler) reconcilePersistence(ctx context.Context, cl
 		},
 		{
 			Name:    fmt.Sprintf("update-visibility-schema-v-%s-%s", sanitizeVersionToName(cluster.Spec.Version), datastoreTypeShortName(cluster.Spec.Persistence.VisibilityStore.GetType())),
-			Command: getDatabaseScriptCommand(persistence.UpdateVisibilitySchemaScript),
+			Command: schemaJobCommand(v1beta1.UpdateSchemaOperation, schemarunner.VisibilityStore),
 			Skip: func(owner runtime.Object) bool {
 				c := owner.(*v1beta1.TemporalCluster)
 				if c.Status.Persistence.VisibilityStore.SchemaVersion == nil {
@@ -222,7 +362,7 @@ func (r *TemporalClusterReconciler) reconcilePersistence(ctx context.Context, cl
 		jobs = append(jobs,
 			&reconciler.Job{
 				Name:    "create-secondary-visibility-database",
-				Command: getDatabaseScriptCommand(persistence.CreateSecondaryVisibilityDatabaseScript),
+				Command: schemaJobCommand(v1beta1.CreateSchemaOperation, schemarunner.SecondaryVisibilityStore),
 				Skip: func(owner runtime.Object) bool {
 					return owner.(*v1beta1.TemporalCluster).Status.Persistence.SecondaryVisibilityStore.Created
 				},
@@ -234,7 +374,7 @@ func (r *TemporalClusterReconciler) reconcilePersistence(ctx context.Context, cl
 			},
 			&reconciler.Job{
 				Name:    "setup-secondary-visibility-schema",
-				Command: getDatabaseScriptCommand(persistence.SetupSecondaryVisibilitySchemaScript),
+				Command: schemaJobCommand(v1beta1.SetupSchemaOperation, schemarunner.SecondaryVisibilityStore),
 				Skip: func(owner runtime.Object) bool {
 					return owner.(*v1beta1.TemporalCluster).Status.Persistence.SecondaryVisibilityStore.Setup
 				},
@@ -246,7 +386,7 @@ func (r *TemporalClusterReconciler) reconcilePersistence(ctx context.Context, cl
 			},
 			&reconciler.Job{
 				Name:    fmt.Sprintf("update-2nd-visibility-schema-v-%s-%s", sanitizeVersionToName(cluster.Spec.Version), datastoreTypeShortName(cluster.Spec.Persistence.SecondaryVisibilityStore.GetType())),
-				Command: getDatabaseScriptCommand(persistence.UpdateSecondaryVisibilitySchemaScript),
+				Command: schemaJobCommand(v1beta1.UpdateSchemaOperation, schemarunner.SecondaryVisibilityStore),
 				Skip: func(owner runtime.Object) bool {
 					c := owner.(*v1beta1.TemporalCluster)
 					if c.Status.Persistence.SecondaryVisibilityStore.SchemaVersion == nil {
@@ -269,7 +409,7 @@ func (r *TemporalClusterReconciler) reconcilePersistence(ctx context.Context, cl
 		jobs = append(jobs,
 			&reconciler.Job{
 				Name:    "create-advanced-visibility-database",
-				Command: getDatabaseScriptCommand(persistence.CreateAdvancedVisibilityDatabaseScript),
+				Command: schemaJobCommand(v1beta1.CreateSchemaOperation, schemarunner.AdvancedVisibilityStore),
 				Skip: func(owner runtime.Object) bool {
 					return owner.(*v1beta1.TemporalCluster).Status.Persistence.AdvancedVisibilityStore.Created
 				},
@@ -281,7 +421,7 @@ func (r *TemporalClusterReconciler) reconcilePersistence(ctx context.Context, cl
 			},
 			&reconciler.Job{
 				Name:    "setup-advanced-visibility-schema",
-				Command: getDatabaseScriptCommand(persistence.SetupAdvancedVisibilitySchemaScript),
+				Command: schemaJobCommand(v1beta1.SetupSchemaOperation, schemarunner.AdvancedVisibilityStore),
 				Skip: func(owner runtime.Object) bool {
 					return owner.(*v1beta1.TemporalCluster).Status.Persistence.AdvancedVisibilityStore.Setup
 				},
@@ -293,7 +433,7 @@ func (r *TemporalClusterReconciler) reconcilePersistence(ctx context.Context, cl
 			},
 			&reconciler.Job{
 				Name:    fmt.Sprintf("update-advanced-visibility-schema-v-%s", sanitizeVersionToName(cluster.Spec.Version)),
-				Command: getDatabaseScriptCommand(persistence.UpdateAdvancedVisibilitySchemaScript),
+				Command: schemaJobCommand(v1beta1.UpdateSchemaOperation, schemarunner.AdvancedVisibilityStore),
 				Skip: func(owner runtime.Object) bool {
 					c := owner.(*v1beta1.TemporalCluster)
 					if c.Status.Persistence.AdvancedVisibilityStore.SchemaVersion == nil {
@@ -310,10 +450,53 @@ func (r *TemporalClusterReconciler) reconcilePersistence(ctx context.Context, cl
 			})
 	}
 
+	var heldVersion *version.Version
+	if cluster.IsPersistencePlanOnly() {
+		heldVersion = planSchemaJobs(cluster, jobs)
+	}
+
+	// Record the schema runner result of each job once it succeeded.
+	for _, job := range jobs {
+		reportSuccess := job.ReportSuccess
+		job.ReportSuccess = func(owner runtime.Object) error {
+			c := owner.(*v1beta1.TemporalCluster)
+			r.recordSchemaOperation(ctx, c, job)
+			if job.Command[0] == string(v1beta1.UpdateSchemaOperation) {
+				if status := datastoreStatus(c, schemarunner.Store(job.Command[1])); status != nil {
+					status.PlannedMigrations = nil
+				}
+			}
+			return reportSuccess(owner)
+		}
+	}
+
+	// Schema updates are only run once the stores are backed up.
+	requeueAfter, err = r.reconcilePersistenceBackups(ctx, cluster, jobs)
+	if err != nil || requeueAfter > 0 {
+		return nil, requeueAfter, err
+	}
+
 	factory := func(owner runtime.Object, scheme *runtime.Scheme, name string, command []string) resource.Builder {
 		cluster := owner.(*v1beta1.TemporalCluster)
-		return persistence.NewSchemaJobBuilder(cluster, scheme, name, command)
+		return persistence.NewSchemaJobBuilder(cluster, scheme, name, command, r.SchemaRunnerImage)
+	}
+
+	requeueAfter, err = r.Jobs.Reconcile(ctx, cluster, factory, jobs)
+	if err != nil {
+		return nil, requeueAfter, err
+	}
+
+	if requeueAfter == 0 {
+		return heldVersion, 0, nil
+	}
+
+	// A job is still running, record its result so failures are reported while the job retries.
+	for _, job := range jobs {
+		if !job.Skip(cluster) {
+			r.recordSchemaOperation(ctx, cluster, job)
+			break
+		}
 	}
 
-	return r.Jobs.Reconcile(ctx, cluster, factory, jobs)
+	return nil, requeueAfter, nil
 }
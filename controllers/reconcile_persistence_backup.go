@@ -0,0 +1,133 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alexandrevilain/controller-tools/pkg/reconciler"
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/persistence"
+	"github.com/alexandrevilain/temporal-operator/pkg/schemarunner"
+	snapshotv1 "github.com/alexandrevilain/temporal-operator/pkg/snapshotter/apis/volumesnapshot/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// volumeSnapshotRequeueAfter is the delay before checking again if a volume snapshot is ready.
+const volumeSnapshotRequeueAfter = 10 * time.Second
+
+// backupName returns the name of the backup taken before running the provided schema update job.
+func backupName(job *reconciler.Job) string {
+	name := strings.Replace(job.Name, "update-", "backup-", 1)
+	return strings.Replace(name, "-schema-", "-", 1)
+}
+
+// reportBackup records the backup location in the store status.
+func reportBackup(cluster *v1beta1.TemporalCluster, store schemarunner.Store, name string) {
+	status := datastoreStatus(cluster, store)
+	if status == nil {
+		return
+	}
+
+	status.LastBackup = &v1beta1.DatastoreBackupStatus{
+		Version:     cluster.Spec.Version.DeepCopy(),
+		Location:    persistence.BackupLocation(cluster, datastoreSpec(cluster, store), name),
+		CompletedAt: &metav1.Time{Time: time.Now()},
+	}
+}
+
+// reconcilePersistenceBackups takes the configured backups of the stores about to be updated by the provided jobs.
+// It returns a requeue delay while backups are running, so schema updates only run once backups succeeded.
+func (r *TemporalClusterReconciler) reconcilePersistenceBackups(ctx context.Context, cluster *v1beta1.TemporalCluster, jobs []*reconciler.Job) (time.Duration, error) {
+	logger := log.FromContext(ctx)
+
+	backupJobs := []*reconciler.Job{}
+	for _, job := range jobs {
+		if job.Command[0] != string(v1beta1.UpdateSchemaOperation) {
+			continue
+		}
+
+		store := schemarunner.Store(job.Command[1])
+		spec := datastoreSpec(cluster, store)
+		status := datastoreStatus(cluster, store)
+		if spec == nil || spec.Backup == nil || status == nil {
+			continue
+		}
+
+		// Newly created schemas have nothing to backup.
+		if status.SchemaVersion == nil || job.Skip(cluster) {
+			continue
+		}
+
+		if status.LastBackup != nil && status.LastBackup.Version != nil && status.LastBackup.Version.Equal(cluster.Spec.Version.Version) {
+			continue
+		}
+
+		name := backupName(job)
+
+		if spec.Backup.VolumeSnapshot != nil {
+			if !r.AvailableAPIs.VolumeSnapshot {
+				return 0, errors.New("can't backup datastore using a volume snapshot as volume snapshots are not available in the cluster")
+			}
+
+			object, err := r.Reconciler.ReconcileBuilder(ctx, cluster, persistence.NewVolumeSnapshotBuilder(cluster, r.Scheme, name, spec))
+			if err != nil {
+				return 0, fmt.Errorf("can't reconcile volume snapshot: %w", err)
+			}
+
+			snapshot := object.(*snapshotv1.VolumeSnapshot)
+			if snapshot.Status != nil && snapshot.Status.Error != nil && snapshot.Status.Error.Message != nil {
+				return 0, fmt.Errorf("volume snapshot %s failed: %s", snapshot.Name, *snapshot.Status.Error.Message)
+			}
+
+			if snapshot.Status == nil || snapshot.Status.ReadyToUse == nil || !*snapshot.Status.ReadyToUse {
+				logger.Info("Waiting for volume snapshot to be ready", "name", snapshot.Name)
+				return volumeSnapshotRequeueAfter, nil
+			}
+
+			reportBackup(cluster, store, name)
+			continue
+		}
+
+		backupJobs = append(backupJobs, &reconciler.Job{
+			Name:    name,
+			Command: []string{string(store)},
+			Skip: func(_ runtime.Object) bool {
+				return false
+			},
+			ReportSuccess: func(owner runtime.Object) error {
+				reportBackup(owner.(*v1beta1.TemporalCluster), store, name)
+				return nil
+			},
+		})
+	}
+
+	factory := func(owner runtime.Object, scheme *runtime.Scheme, name string, command []string) resource.Builder {
+		cluster := owner.(*v1beta1.TemporalCluster)
+		return persistence.NewBackupJobBuilder(cluster, scheme, name, datastoreSpec(cluster, schemarunner.Store(command[0])))
+	}
+
+	return r.Jobs.Reconcile(ctx, cluster, factory, backupJobs)
+}
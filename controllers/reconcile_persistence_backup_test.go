@@ -0,0 +1,232 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexandrevilain/controller-tools/pkg/reconciler"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/discovery"
+	"github.com/alexandrevilain/temporal-operator/pkg/schemarunner"
+	snapshotv1 "github.com/alexandrevilain/temporal-operator/pkg/snapshotter/apis/volumesnapshot/v1"
+	"github.com/alexandrevilain/temporal-operator/pkg/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcilePersistenceBackups(t *testing.T) {
+	jobBackup := &v1beta1.DatastoreBackupSpec{
+		Job: &v1beta1.DatastoreBackupJobSpec{Location: "s3://backups/temporal"},
+	}
+	snapshotBackup := &v1beta1.DatastoreBackupSpec{
+		VolumeSnapshot: &v1beta1.DatastoreBackupVolumeSnapshotSpec{PersistentVolumeClaimName: "postgres"},
+	}
+
+	objectMeta := metav1.ObjectMeta{
+		Name:      "test-backup-default-v-1-25-0",
+		Namespace: "demo",
+	}
+
+	tests := map[string]struct {
+		backup                 *v1beta1.DatastoreBackupSpec
+		status                 *v1beta1.DatastoreStatus
+		volumeSnapshotDisabled bool
+		objects                []client.Object
+		expectedRequeueAfter   bool
+		expectedErr            string
+		expectedBackupObject   client.Object
+		expectedLocation       string
+	}{
+		"no backup configured": {
+			status: &v1beta1.DatastoreStatus{SchemaVersion: version.MustNewVersionFromString("1.24.2")},
+		},
+		"new schema is not backed up": {
+			backup: jobBackup,
+			status: &v1beta1.DatastoreStatus{},
+		},
+		"pending backup job blocks the update": {
+			backup:               jobBackup,
+			status:               &v1beta1.DatastoreStatus{SchemaVersion: version.MustNewVersionFromString("1.24.2")},
+			expectedRequeueAfter: true,
+			expectedBackupObject: &batchv1.Job{},
+		},
+		"completed backup job is recorded": {
+			backup: jobBackup,
+			status: &v1beta1.DatastoreStatus{SchemaVersion: version.MustNewVersionFromString("1.24.2")},
+			objects: []client.Object{
+				&batchv1.Job{ObjectMeta: objectMeta, Status: batchv1.JobStatus{Succeeded: 1}},
+			},
+			expectedLocation: "s3://backups/temporal",
+		},
+		"backup already taken for the version is skipped": {
+			backup: jobBackup,
+			status: &v1beta1.DatastoreStatus{
+				SchemaVersion: version.MustNewVersionFromString("1.24.2"),
+				LastBackup: &v1beta1.DatastoreBackupStatus{
+					Version:  version.MustNewVersionFromString("1.25.0"),
+					Location: "s3://backups/previous",
+				},
+			},
+			expectedLocation: "s3://backups/previous",
+		},
+		"pending volume snapshot blocks the update": {
+			backup:               snapshotBackup,
+			status:               &v1beta1.DatastoreStatus{SchemaVersion: version.MustNewVersionFromString("1.24.2")},
+			expectedRequeueAfter: true,
+			expectedBackupObject: &snapshotv1.VolumeSnapshot{},
+		},
+		"volume snapshot not ready blocks the update": {
+			backup: snapshotBackup,
+			status: &v1beta1.DatastoreStatus{SchemaVersion: version.MustNewVersionFromString("1.24.2")},
+			objects: []client.Object{
+				&snapshotv1.VolumeSnapshot{ObjectMeta: objectMeta, Status: &snapshotv1.VolumeSnapshotStatus{ReadyToUse: ptr.To(false)}},
+			},
+			expectedRequeueAfter: true,
+		},
+		"ready volume snapshot is recorded": {
+			backup: snapshotBackup,
+			status: &v1beta1.DatastoreStatus{SchemaVersion: version.MustNewVersionFromString("1.24.2")},
+			objects: []client.Object{
+				&snapshotv1.VolumeSnapshot{ObjectMeta: objectMeta, Status: &snapshotv1.VolumeSnapshotStatus{ReadyToUse: ptr.To(true)}},
+			},
+			expectedLocation: "volumesnapshot/test-backup-default-v-1-25-0",
+		},
+		"failed volume snapshot returns an error": {
+			backup: snapshotBackup,
+			status: &v1beta1.DatastoreStatus{SchemaVersion: version.MustNewVersionFromString("1.24.2")},
+			objects: []client.Object{
+				&snapshotv1.VolumeSnapshot{
+					ObjectMeta: objectMeta,
+					Status: &snapshotv1.VolumeSnapshotStatus{
+						ReadyToUse: ptr.To(false),
+						Error:      &snapshotv1.VolumeSnapshotError{Message: ptr.To("no space left")},
+					},
+				},
+			},
+			expectedErr: "volume snapshot test-backup-default-v-1-25-0 failed: no space left",
+		},
+		"volume snapshots not available returns an error": {
+			backup:                 snapshotBackup,
+			status:                 &v1beta1.DatastoreStatus{SchemaVersion: version.MustNewVersionFromString("1.24.2")},
+			volumeSnapshotDisabled: true,
+			expectedErr:            "can't backup datastore using a volume snapshot as volume snapshots are not available in the cluster",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			ctx := context.Background()
+
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.25.0"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							Name:   "default",
+							SQL:    &v1beta1.SQLSpec{PluginName: "postgres12", ConnectAddr: "postgres:5432", User: "temporal", DatabaseName: "temporal"},
+							Backup: test.backup,
+						},
+					},
+				},
+				Status: v1beta1.TemporalClusterStatus{
+					Persistence: &v1beta1.TemporalPersistenceStatus{
+						DefaultStore: test.status,
+					},
+				},
+			}
+
+			scheme := runtime.NewScheme()
+			utilruntime.Must(corev1.AddToScheme(scheme))
+			utilruntime.Must(batchv1.AddToScheme(scheme))
+			utilruntime.Must(snapshotv1.AddToScheme(scheme))
+			utilruntime.Must(v1beta1.AddToScheme(scheme))
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(test.objects...).Build()
+
+			r := &TemporalClusterReconciler{
+				Base: Base{
+					Client: c,
+					Scheme: scheme,
+					Jobs: &reconciler.JosbReconciler{
+						Client:   c,
+						Scheme:   scheme,
+						Recorder: record.NewFakeRecorder(10),
+					},
+					Reconciler: &reconciler.Reconciler{
+						Client:   c,
+						Scheme:   scheme,
+						Recorder: record.NewFakeRecorder(10),
+					},
+				},
+				AvailableAPIs: &discovery.AvailableAPIs{VolumeSnapshot: !test.volumeSnapshotDisabled},
+			}
+
+			jobs := []*reconciler.Job{updateJob("update-default-schema-v-1-25-0", schemarunner.DefaultStore)}
+
+			requeueAfter, err := r.reconcilePersistenceBackups(ctx, cluster, jobs)
+			if test.expectedErr != "" {
+				require.EqualError(tt, err, test.expectedErr)
+				return
+			}
+			require.NoError(tt, err)
+
+			if test.expectedRequeueAfter {
+				assert.Positive(tt, requeueAfter)
+			} else {
+				assert.Zero(tt, requeueAfter)
+			}
+
+			if test.expectedBackupObject != nil {
+				err := c.Get(ctx, types.NamespacedName{Namespace: objectMeta.Namespace, Name: objectMeta.Name}, test.expectedBackupObject)
+				require.NoError(tt, err)
+			}
+
+			lastBackup := cluster.Status.Persistence.DefaultStore.LastBackup
+			if test.expectedLocation == "" {
+				assert.Nil(tt, lastBackup)
+				return
+			}
+
+			require.NotNil(tt, lastBackup)
+			assert.Equal(tt, test.expectedLocation, lastBackup.Location)
+			assert.True(tt, lastBackup.Version.Equal(cluster.Spec.Version.Version))
+
+			// A second backup is not taken for the same version.
+			requeueAfter, err = r.reconcilePersistenceBackups(ctx, cluster, jobs)
+			require.NoError(tt, err)
+			assert.Zero(tt, requeueAfter)
+			assert.Same(tt, lastBackup, cluster.Status.Persistence.DefaultStore.LastBackup)
+		})
+	}
+}
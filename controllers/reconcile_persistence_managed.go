@@ -0,0 +1,151 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/persistence"
+	"github.com/alexandrevilain/temporal-operator/pkg/schemarunner"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// managedDatastoresRequeueAfter is the delay before checking again if the managed database clusters are ready.
+const managedDatastoresRequeueAfter = 10 * time.Second
+
+// resolveManagedDatastores sets the connection details of the managed datastores in the cluster spec.
+// It's called before creating the patch helper: the connection details derived from the database clusters
+// are only set in memory and never written back to the cluster spec.
+// It returns the managed datastores status, keyed by store, and a requeue delay if a database cluster isn't ready.
+func (r *TemporalClusterReconciler) resolveManagedDatastores(ctx context.Context, cluster *v1beta1.TemporalCluster) (map[schemarunner.Store]*v1beta1.ManagedDatastoreStatus, time.Duration, error) {
+	logger := log.FromContext(ctx)
+
+	statuses := map[schemarunner.Store]*v1beta1.ManagedDatastoreStatus{}
+	var requeueAfter time.Duration
+
+	for _, store := range clusterStores(cluster) {
+		datastore := datastoreSpec(cluster, store)
+		if !datastore.IsManaged() {
+			continue
+		}
+
+		ref := persistence.ManagedClusterReference(datastore.Managed)
+		obj, err := persistence.NewManagedClusterObject(cluster, datastore.Managed)
+		if err != nil {
+			return statuses, 0, fmt.Errorf("can't resolve %s datastore: %w", store, err)
+		}
+
+		kind := persistence.ManagedClusterKind(obj)
+		status := &v1beta1.ManagedDatastoreStatus{Kind: kind, Name: ref.Name}
+		statuses[store] = status
+
+		err = r.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if apierrors.IsNotFound(err) {
+			if ref.Create == nil {
+				return statuses, 0, fmt.Errorf("%s %s referenced by %s datastore not found", kind, ref.Name, store)
+			}
+
+			err := r.createManagedCluster(ctx, cluster, datastore.Managed)
+			if err != nil {
+				return statuses, 0, err
+			}
+
+			logger.Info("Created managed database cluster", "store", store, "kind", kind, "name", ref.Name)
+			requeueAfter = managedDatastoresRequeueAfter
+			continue
+		}
+		if err != nil {
+			return statuses, 0, fmt.Errorf("can't get %s %s: %w", kind, ref.Name, err)
+		}
+
+		if !persistence.IsManagedClusterReady(obj) {
+			logger.Info("Waiting for managed database cluster to be ready", "store", store, "kind", kind, "name", ref.Name)
+			requeueAfter = managedDatastoresRequeueAfter
+			continue
+		}
+
+		status.Ready = true
+
+		connection, err := persistence.GetManagedConnection(obj)
+		if err != nil {
+			return statuses, 0, fmt.Errorf("can't get %s datastore connection details: %w", store, err)
+		}
+
+		user := ""
+		if connection.UserKey != "" {
+			user, err = r.managedClusterUser(ctx, cluster, connection)
+			if err != nil {
+				return statuses, 0, fmt.Errorf("can't get %s datastore user: %w", store, err)
+			}
+		}
+
+		connection.Apply(datastore, user)
+
+		status.Address = connection.Address()
+		status.Secrets = connection.Secrets()
+	}
+
+	return statuses, requeueAfter, nil
+}
+
+// createManagedCluster creates the database cluster of the managed datastore.
+// The database cluster may already have been created for another datastore.
+func (r *TemporalClusterReconciler) createManagedCluster(ctx context.Context, cluster *v1beta1.TemporalCluster, managed *v1beta1.ManagedDatastoreSpec) error {
+	obj, err := persistence.BuildManagedCluster(cluster, managed)
+	if err != nil {
+		return fmt.Errorf("can't build managed database cluster: %w", err)
+	}
+
+	err = r.Create(ctx, obj)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("can't create %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	return nil
+}
+
+// managedClusterUser reads the user from the database cluster password secret.
+func (r *TemporalClusterReconciler) managedClusterUser(ctx context.Context, cluster *v1beta1.TemporalCluster, connection *persistence.ManagedConnection) (string, error) {
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: cluster.GetNamespace(), Name: connection.PasswordSecretRef.Name}, secret)
+	if err != nil {
+		return "", fmt.Errorf("can't get secret %s: %w", connection.PasswordSecretRef.Name, err)
+	}
+
+	user, ok := secret.Data[connection.UserKey]
+	if !ok || len(user) == 0 {
+		return "", fmt.Errorf("key %s not found in secret %s", connection.UserKey, connection.PasswordSecretRef.Name)
+	}
+
+	return string(user), nil
+}
+
+// reconcileManagedDatastoresStatus reports the managed datastores database clusters in the cluster status.
+func (r *TemporalClusterReconciler) reconcileManagedDatastoresStatus(cluster *v1beta1.TemporalCluster, statuses map[schemarunner.Store]*v1beta1.ManagedDatastoreStatus) {
+	r.reconcilePersistenceStatus(cluster)
+
+	for _, store := range clusterStores(cluster) {
+		datastoreStatus(cluster, store).Managed = statuses[store]
+	}
+}
@@ -0,0 +1,230 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alexandrevilain/controller-tools/pkg/hash"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/persistence"
+	"github.com/alexandrevilain/temporal-operator/pkg/schemarunner"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// preflightPendingRequeueAfter is the delay before checking again the outcome of running preflight checks.
+	preflightPendingRequeueAfter = 10 * time.Second
+	// preflightFailedRequeueAfter is the delay before checking again the outcome of failing preflight checks.
+	preflightFailedRequeueAfter = 30 * time.Second
+)
+
+// preflightJobName returns the name of the job checking the store against the datastore hash.
+func preflightJobName(store schemarunner.Store, datastoreHash string) string {
+	name := string(store)
+	switch store {
+	case schemarunner.SecondaryVisibilityStore:
+		name = "2nd-visibility"
+	case schemarunner.AdvancedVisibilityStore:
+		name = "advanced-visibility"
+	case schemarunner.DefaultStore, schemarunner.VisibilityStore:
+	}
+	return fmt.Sprintf("preflight-%s-%s", name, datastoreHash[:8])
+}
+
+// preflightSecretRefs returns the secret references whose changes trigger the preflight checks again.
+// Password changes are only checked before being rolled out when the datastore accepts two credentials during rotations,
+// otherwise they are rolled out right away so services pick up the new password.
+func preflightSecretRefs(spec *v1beta1.DatastoreSpec) []*v1beta1.SecretKeyReference {
+	if spec.IsDualCredentialPasswordRotation() {
+		return persistence.DatastoreSecretRefs(spec)
+	}
+	return persistence.DatastoreTLSSecretRefs(spec)
+}
+
+// datastoreHash returns a hash of the datastore spec and of the version of the secrets it references,
+// so checks run again when the connection settings or the credentials change.
+// It returns an error if a referenced secret or key is missing.
+func (r *TemporalClusterReconciler) datastoreHash(ctx context.Context, cluster *v1beta1.TemporalCluster, spec *v1beta1.DatastoreSpec) (string, error) {
+	// Missing secrets are always reported, even if their changes don't trigger the checks again.
+	versions, err := r.secretVersions(ctx, cluster, persistence.DatastoreSecretRefs(spec))
+	if err != nil {
+		return "", err
+	}
+
+	secretVersions := map[string]string{}
+	for _, ref := range preflightSecretRefs(spec) {
+		secretVersions[ref.Name] = versions[ref.Name]
+	}
+
+	return hash.Sha256(struct {
+		Spec    *v1beta1.DatastoreSpec
+		Secrets map[string]string
+	}{
+		Spec:    spec,
+		Secrets: secretVersions,
+	})
+}
+
+// reconcilePersistencePreflight runs the connectivity preflight checks of each store whose
+// connection settings changed since they last passed, and reports them in the PersistenceReachable condition.
+// It returns a requeue delay until all checks passed, blocking the rollout of the cluster services.
+func (r *TemporalClusterReconciler) reconcilePersistencePreflight(ctx context.Context, cluster *v1beta1.TemporalCluster) (time.Duration, error) {
+	failures := []string{}
+	pending := []string{}
+
+	for _, store := range clusterStores(cluster) {
+		spec := datastoreSpec(cluster, store)
+		status := datastoreStatus(cluster, store)
+		if spec == nil || status == nil {
+			continue
+		}
+
+		datastoreHash, err := r.datastoreHash(ctx, cluster, spec)
+		if err != nil {
+			status.Preflight = &v1beta1.DatastorePreflightStatus{
+				Error: err.Error(),
+			}
+			failures = append(failures, fmt.Sprintf("%s: %s", store, err))
+			continue
+		}
+
+		if status.Preflight != nil && status.Preflight.Hash == datastoreHash && status.Preflight.Passed {
+			continue
+		}
+
+		result, err := r.reconcilePreflightJob(ctx, cluster, store, datastoreHash)
+		if err != nil {
+			return 0, err
+		}
+
+		if result == nil {
+			pending = append(pending, string(store))
+			continue
+		}
+
+		status.Preflight = result
+		if !result.Passed {
+			failures = append(failures, fmt.Sprintf("%s: %s", store, result.Error))
+		}
+	}
+
+	if len(failures) > 0 {
+		v1beta1.SetTemporalClusterPersistenceReachable(cluster, metav1.ConditionFalse, v1beta1.PersistenceUnreachableReason, strings.Join(failures, "; "))
+		return preflightFailedRequeueAfter, nil
+	}
+
+	if len(pending) > 0 {
+		v1beta1.SetTemporalClusterPersistenceReachable(cluster, metav1.ConditionUnknown, v1beta1.PersistenceCheckingReason,
+			fmt.Sprintf("Running preflight checks for stores: %s", strings.Join(pending, ", ")))
+		return preflightPendingRequeueAfter, nil
+	}
+
+	v1beta1.SetTemporalClusterPersistenceReachable(cluster, metav1.ConditionTrue, v1beta1.PersistenceReachableReason, "All datastores passed the preflight checks")
+	return 0, nil
+}
+
+// reconcilePreflightJob ensures the preflight job of the store exists and returns its outcome.
+// It returns nil while the checks are running. Failed jobs are deleted so the checks run again.
+func (r *TemporalClusterReconciler) reconcilePreflightJob(ctx context.Context, cluster *v1beta1.TemporalCluster, store schemarunner.Store, datastoreHash string) (*v1beta1.DatastorePreflightStatus, error) {
+	logger := log.FromContext(ctx)
+
+	name := preflightJobName(store, datastoreHash)
+	builder := persistence.NewSchemaJobBuilder(cluster, r.Scheme, name, schemaJobCommand(v1beta1.CheckSchemaOperation, store), r.SchemaRunnerImage)
+	expected := builder.Build()
+
+	job := &batchv1.Job{}
+	err := r.Get(ctx, client.ObjectKeyFromObject(expected), job)
+	if apierrors.IsNotFound(err) {
+		logger.Info("Running datastore preflight checks", "store", store, "name", name)
+
+		_, err = controllerutil.CreateOrUpdate(ctx, r.Client, expected, func() error {
+			return builder.Update(expected)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("can't create preflight job: %w", err)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't get preflight job: %w", err)
+	}
+
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+
+	pods := &corev1.PodList{}
+	err = reader.List(ctx, pods,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{batchv1.JobNameLabel: job.Name},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't list preflight job pods: %w", err)
+	}
+
+	result, err := schemarunner.ResultFromPods(pods.Items, persistence.SchemaRunnerContainerName)
+	if err != nil {
+		return nil, fmt.Errorf("can't get preflight job result: %w", err)
+	}
+
+	if jobFailed(job) {
+		logger.Info("Datastore preflight checks failed, deleting job to run them again", "store", store, "name", name)
+
+		err = r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("can't delete preflight job: %w", err)
+		}
+
+		if result == nil {
+			result = &v1beta1.SchemaOperationStatus{Error: "preflight job failed without reporting a result"}
+		}
+	}
+
+	// A running job reporting an error is retrying failed checks.
+	if result == nil || (job.Status.Succeeded != 1 && result.Error == "") {
+		return nil, nil
+	}
+
+	return &v1beta1.DatastorePreflightStatus{
+		Hash:   datastoreHash,
+		Passed: job.Status.Succeeded == 1 && result.Error == "",
+		Checks: result.Checks,
+		Error:  result.Error,
+	}, nil
+}
+
+// jobFailed returns true if the job reached its backoff limit.
+func jobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/controller-tools/pkg/reconciler"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/schemarunner"
+	"github.com/alexandrevilain/temporal-operator/pkg/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// updateJob returns a schema update job skipped once the store schema version reaches the cluster version.
+func updateJob(name string, store schemarunner.Store) *reconciler.Job {
+	return &reconciler.Job{
+		Name:    name,
+		Command: schemaJobCommand(v1beta1.UpdateSchemaOperation, store),
+		Skip: func(owner runtime.Object) bool {
+			c := owner.(*v1beta1.TemporalCluster)
+			status := datastoreStatus(c, store)
+			return status.SchemaVersion != nil && status.SchemaVersion.GreaterOrEqual(c.Spec.Version)
+		},
+		ReportSuccess: func(_ runtime.Object) error {
+			return nil
+		},
+	}
+}
+
+func TestPlanSchemaJobs(t *testing.T) {
+	tests := map[string]struct {
+		defaultStore    *v1beta1.DatastoreStatus
+		visibilityStore *v1beta1.DatastoreStatus
+		expectedJobs    []string
+		expectedVersion *version.Version
+	}{
+		"stores up to date": {
+			defaultStore:    &v1beta1.DatastoreStatus{SchemaVersion: version.MustNewVersionFromString("1.25.0")},
+			visibilityStore: &v1beta1.DatastoreStatus{SchemaVersion: version.MustNewVersionFromString("1.25.0")},
+			expectedJobs:    []string{"setup-default-schema", "update-default-schema-v-1-25-0", "update-visibility-schema-v-1-25-0"},
+		},
+		"new stores are updated": {
+			defaultStore:    &v1beta1.DatastoreStatus{},
+			visibilityStore: &v1beta1.DatastoreStatus{},
+			expectedJobs:    []string{"setup-default-schema", "update-default-schema-v-1-25-0", "update-visibility-schema-v-1-25-0"},
+		},
+		"stores behind are planned": {
+			defaultStore:    &v1beta1.DatastoreStatus{SchemaVersion: version.MustNewVersionFromString("1.24.2")},
+			visibilityStore: &v1beta1.DatastoreStatus{SchemaVersion: version.MustNewVersionFromString("1.23.1")},
+			expectedJobs:    []string{"setup-default-schema", "plan-default-schema-v-1-25-0", "plan-visibility-schema-v-1-25-0"},
+			expectedVersion: version.MustNewVersionFromString("1.23.1"),
+		},
+		"only the store behind is planned": {
+			defaultStore:    &v1beta1.DatastoreStatus{SchemaVersion: version.MustNewVersionFromString("1.25.0")},
+			visibilityStore: &v1beta1.DatastoreStatus{SchemaVersion: version.MustNewVersionFromString("1.24.2")},
+			expectedJobs:    []string{"setup-default-schema", "update-default-schema-v-1-25-0", "plan-visibility-schema-v-1-25-0"},
+			expectedVersion: version.MustNewVersionFromString("1.24.2"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.25.0"),
+				},
+				Status: v1beta1.TemporalClusterStatus{
+					Persistence: &v1beta1.TemporalPersistenceStatus{
+						DefaultStore:    test.defaultStore,
+						VisibilityStore: test.visibilityStore,
+					},
+				},
+			}
+
+			jobs := []*reconciler.Job{
+				{
+					Name:    "setup-default-schema",
+					Command: schemaJobCommand(v1beta1.SetupSchemaOperation, schemarunner.DefaultStore),
+				},
+				updateJob("update-default-schema-v-1-25-0", schemarunner.DefaultStore),
+				updateJob("update-visibility-schema-v-1-25-0", schemarunner.VisibilityStore),
+			}
+
+			current := planSchemaJobs(cluster, jobs)
+			assert.Equal(tt, test.expectedVersion, current)
+
+			names := []string{}
+			for _, job := range jobs {
+				names = append(names, job.Name)
+			}
+			assert.Equal(tt, test.expectedJobs, names)
+		})
+	}
+}
+
+func TestPlanSchemaJobReportsPlan(t *testing.T) {
+	cluster := &v1beta1.TemporalCluster{
+		Spec: v1beta1.TemporalClusterSpec{
+			Version: version.MustNewVersionFromString("1.25.0"),
+		},
+		Status: v1beta1.TemporalClusterStatus{
+			Persistence: &v1beta1.TemporalPersistenceStatus{
+				DefaultStore: &v1beta1.DatastoreStatus{
+					SchemaVersion: version.MustNewVersionFromString("1.24.2"),
+				},
+				VisibilityStore: &v1beta1.DatastoreStatus{},
+			},
+		},
+	}
+
+	jobs := []*reconciler.Job{updateJob("update-default-schema-v-1-25-0", schemarunner.DefaultStore)}
+	current := planSchemaJobs(cluster, jobs)
+	require.NotNil(t, current)
+
+	plan := jobs[0]
+	assert.Equal(t, []string{string(v1beta1.PlanSchemaOperation), string(schemarunner.DefaultStore)}, plan.Command)
+	assert.False(t, plan.Skip(cluster), "the plan job must run until a plan is reported")
+
+	// The plan reports the migrations recorded from the schema runner result.
+	cluster.Status.Persistence.DefaultStore.LastSchemaOperation = &v1beta1.SchemaOperationStatus{
+		Operation:     v1beta1.PlanSchemaOperation,
+		SchemaVersion: "1.13",
+		PlannedMigrations: []v1beta1.SchemaMigration{
+			{Version: "1.14", Directory: "v1.14"},
+		},
+	}
+	require.NoError(t, plan.ReportSuccess(cluster))
+
+	planned := cluster.Status.Persistence.DefaultStore.PlannedMigrations
+	require.NotNil(t, planned)
+	assert.Equal(t, "1.25.0", planned.Version.String())
+	assert.Equal(t, "1.13", planned.SchemaVersion)
+	assert.Equal(t, []v1beta1.SchemaMigration{{Version: "1.14", Directory: "v1.14"}}, planned.Migrations)
+	assert.True(t, plan.Skip(cluster), "the plan job must not run again for the same cluster version")
+
+	// Planning the migrations doesn't change the schema version.
+	assert.Equal(t, "1.24.2", cluster.Status.Persistence.DefaultStore.SchemaVersion.String())
+
+	// A new cluster version is planned again.
+	cluster.Spec.Version = version.MustNewVersionFromString("1.26.0")
+	assert.False(t, plan.Skip(cluster))
+}
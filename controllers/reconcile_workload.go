@@ -0,0 +1,105 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// workloadMigrationInProgress returns true if the service workload kind changed
+// and the workload of the desired kind isn't ready yet.
+// While the migration is in progress, the previous workload is kept running.
+func (r *TemporalClusterReconciler) workloadMigrationInProgress(ctx context.Context, cluster *v1beta1.TemporalCluster, serviceName string, kind v1beta1.WorkloadKind) (bool, error) {
+	var desired, previous client.Object
+	switch kind {
+	case v1beta1.StatefulSetWorkloadKind:
+		desired, previous = &appsv1.StatefulSet{}, &appsv1.Deployment{}
+	default:
+		desired, previous = &appsv1.Deployment{}, &appsv1.StatefulSet{}
+	}
+
+	key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.ChildResourceName(serviceName)}
+
+	err := r.Get(ctx, key, previous)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("can't get %s previous workload: %w", serviceName, err)
+	}
+
+	err = r.Get(ctx, key, desired)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("can't get %s workload: %w", serviceName, err)
+	}
+
+	// Objects returned by the client have no type meta, set it for the status computation.
+	desired.GetObjectKind().SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind(string(kind)))
+
+	status, err := resource.GetStatus(desired)
+	if err != nil {
+		return false, fmt.Errorf("can't compute %s workload status: %w", serviceName, err)
+	}
+
+	return !status.Ready, nil
+}
+
+// stableWorkloadSelectsCanary returns true if the service stable workload was created before pods had a track label.
+// Its selector is immutable and would also match the canary pods, so no canary can be deployed next to it.
+func (r *TemporalClusterReconciler) stableWorkloadSelectsCanary(ctx context.Context, cluster *v1beta1.TemporalCluster, serviceName string, kind v1beta1.WorkloadKind) (bool, error) {
+	key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.ChildResourceName(serviceName)}
+
+	var selector *metav1.LabelSelector
+	switch kind {
+	case v1beta1.StatefulSetWorkloadKind:
+		statefulSet := &appsv1.StatefulSet{}
+		err := r.Get(ctx, key, statefulSet)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, fmt.Errorf("can't get %s workload: %w", serviceName, err)
+		}
+		selector = statefulSet.Spec.Selector
+	default:
+		deployment := &appsv1.Deployment{}
+		err := r.Get(ctx, key, deployment)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, fmt.Errorf("can't get %s workload: %w", serviceName, err)
+		}
+		selector = deployment.Spec.Selector
+	}
+
+	return !metadata.SelectsTrack(selector), nil
+}
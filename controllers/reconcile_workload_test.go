@@ -0,0 +1,118 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStableWorkloadSelectsCanary(t *testing.T) {
+	legacySelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			"app.kubernetes.io/name":      "test",
+			"app.kubernetes.io/component": "history",
+		},
+	}
+	trackSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			"app.kubernetes.io/name":      "test",
+			"app.kubernetes.io/component": "history",
+			"app.kubernetes.io/track":     "stable",
+		},
+	}
+
+	objectMeta := metav1.ObjectMeta{
+		Name:      "test-history",
+		Namespace: "demo",
+	}
+
+	tests := map[string]struct {
+		kind     v1beta1.WorkloadKind
+		objects  []client.Object
+		expected bool
+	}{
+		"no workload": {
+			kind:     v1beta1.DeploymentWorkloadKind,
+			expected: false,
+		},
+		"deployment with track selector": {
+			kind: v1beta1.DeploymentWorkloadKind,
+			objects: []client.Object{
+				&appsv1.Deployment{ObjectMeta: objectMeta, Spec: appsv1.DeploymentSpec{Selector: trackSelector}},
+			},
+			expected: false,
+		},
+		"deployment created before the track label": {
+			kind: v1beta1.DeploymentWorkloadKind,
+			objects: []client.Object{
+				&appsv1.Deployment{ObjectMeta: objectMeta, Spec: appsv1.DeploymentSpec{Selector: legacySelector}},
+			},
+			expected: true,
+		},
+		"statefulset created before the track label": {
+			kind: v1beta1.StatefulSetWorkloadKind,
+			objects: []client.Object{
+				&appsv1.StatefulSet{ObjectMeta: objectMeta, Spec: appsv1.StatefulSetSpec{Selector: legacySelector}},
+			},
+			expected: true,
+		},
+		"previous workload kind is ignored": {
+			kind: v1beta1.StatefulSetWorkloadKind,
+			objects: []client.Object{
+				&appsv1.Deployment{ObjectMeta: objectMeta, Spec: appsv1.DeploymentSpec{Selector: legacySelector}},
+			},
+			expected: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+			}
+
+			scheme := runtime.NewScheme()
+			utilruntime.Must(appsv1.AddToScheme(scheme))
+
+			r := &TemporalClusterReconciler{
+				Base: Base{
+					Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(test.objects...).Build(),
+					Scheme: scheme,
+				},
+			}
+
+			blocked, err := r.stableWorkloadSelectsCanary(context.Background(), cluster, "history", test.kind)
+			require.NoError(tt, err)
+			assert.Equal(tt, test.expected, blocked)
+		})
+	}
+}
@@ -30,30 +30,42 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
 	"github.com/alexandrevilain/temporal-operator/internal/discovery"
+	kedav1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/keda/apis/keda/v1alpha1"
+	linkerdpolicyv1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/linkerd/apis/policy/v1alpha1"
+	linkerdpolicyv1beta1 "github.com/alexandrevilain/temporal-operator/pkg/linkerd/apis/policy/v1beta1"
+	snapshotv1 "github.com/alexandrevilain/temporal-operator/pkg/snapshotter/apis/volumesnapshot/v1"
 	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	istionetworkingv1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
 	istiosecurityv1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	"github.com/alexandrevilain/controller-tools/pkg/hash"
 	"github.com/alexandrevilain/controller-tools/pkg/patch"
 	"github.com/alexandrevilain/controller-tools/pkg/resource"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/admintools"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/base"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/codecserver"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/config"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/certmanager"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/istio"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/linkerd"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/spire"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/prometheus"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/ui"
 	"github.com/alexandrevilain/temporal-operator/pkg/status"
@@ -68,6 +80,10 @@ type TemporalClusterReconciler struct {
 	Base
 
 	AvailableAPIs *discovery.AvailableAPIs
+	// APIReader reads objects the manager doesn't cache, like schema job pods.
+	APIReader client.Reader
+	// SchemaRunnerImage is the image running schema jobs.
+	SchemaRunnerImage string
 }
 
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;delete
@@ -75,13 +91,25 @@ type TemporalClusterReconciler struct {
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups="",resources=events,verbs=get;create;patch
 //+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update
-//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups="keda.sh",resources=scaledobjects,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups="networking.k8s.io",resources=ingresses,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups="cert-manager.io",resources=certificates;issuers,verbs=get;list;watch;create;update;delete
-//+kubebuilder:rbac:groups="security.istio.io",resources=peerauthentications,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups="cert-manager.io",resources=certificates/status,verbs=update
+//+kubebuilder:rbac:groups="security.istio.io",resources=peerauthentications;authorizationpolicies,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups="networking.istio.io",resources=destinationrules,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups="gateway.networking.k8s.io",resources=gateways;grpcroutes;httproutes;tlsroutes,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;patch
+//+kubebuilder:rbac:groups="policy.linkerd.io",resources=servers;authorizationpolicies;meshtlsauthentications,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups="monitoring.coreos.com",resources=servicemonitors,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups="postgresql.cnpg.io",resources=clusters,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups="pxc.percona.com",resources=perconaxtradbclusters,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups="k8ssandra.io",resources=k8ssandraclusters,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups="snapshot.storage.k8s.io",resources=volumesnapshots,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups=temporal.io,resources=temporalclusters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=temporal.io,resources=temporalclusters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=temporal.io,resources=temporalclusters/finalizers,verbs=update
@@ -108,6 +136,10 @@ func (r *TemporalClusterReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return reconcile.Result{}, nil
 	}
 
+	// Resolve managed datastores before creating the patch helper: the connection details derived from
+	// their database clusters must not be written back to the cluster spec.
+	managedStatuses, managedRequeueAfter, managedErr := r.resolveManagedDatastores(ctx, cluster)
+
 	patchHelper, err := patch.NewHelper(cluster, r.Client)
 	if err != nil {
 		return reconcile.Result{}, err
@@ -127,59 +159,116 @@ func (r *TemporalClusterReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		v1beta1.SetTemporalClusterReady(cluster, metav1.ConditionUnknown, v1beta1.ProgressingReason, "")
 	}
 
-	if requeueAfter, err := r.reconcilePersistence(ctx, cluster); err != nil || requeueAfter > 0 {
-		if err != nil {
-			logger.Error(err, "Can't reconcile persistence")
-			if requeueAfter == 0 {
-				requeueAfter = 2 * time.Second
-			}
-			return r.handleErrorWithRequeue(cluster, v1beta1.PersistenceReconciliationFailedReason, err, requeueAfter)
-		}
-		if requeueAfter > 0 {
-			return reconcile.Result{RequeueAfter: requeueAfter}, nil
+	r.reconcileManagedDatastoresStatus(cluster, managedStatuses)
+	if managedErr != nil {
+		logger.Error(managedErr, "Can't resolve managed datastores")
+		return r.handleErrorWithRequeue(cluster, v1beta1.PersistenceReconciliationFailedReason, managedErr, 2*time.Second)
+	}
+	if managedRequeueAfter > 0 {
+		v1beta1.SetTemporalClusterReady(cluster, metav1.ConditionFalse, v1beta1.ManagedDatastoresNotReadyReason, "Waiting for the managed datastores database clusters to be ready")
+		return reconcile.Result{RequeueAfter: managedRequeueAfter}, nil
+	}
+
+	heldVersion, persistenceRequeueAfter, err := r.reconcilePersistence(ctx, cluster)
+	if err != nil {
+		logger.Error(err, "Can't reconcile persistence")
+		if persistenceRequeueAfter == 0 {
+			persistenceRequeueAfter = 2 * time.Second
 		}
+		return r.handleErrorWithRequeue(cluster, v1beta1.PersistenceReconciliationFailedReason, err, persistenceRequeueAfter)
+	}
+	if persistenceRequeueAfter > 0 {
+		return reconcile.Result{RequeueAfter: persistenceRequeueAfter}, nil
+	}
+
+	// The visibility migration phase drives the rendered temporal configuration, move it forward before reconciling resources.
+	migrationRequeueAfter := status.ReconcileVisibilityMigration(cluster, time.Now())
+
+	renewAfter, err := r.reconcileOperatorCertificates(ctx, cluster)
+	if err != nil {
+		logger.Error(err, "Can't reconcile mTLS certificates")
+		return r.handleErrorWithRequeue(cluster, v1beta1.MTLSReconciliationFailedReason, err, 2*time.Second)
+	}
+
+	caBundleHash, requeueAfter, err := r.reconcileCARotation(ctx, cluster)
+	if err != nil {
+		logger.Error(err, "Can't reconcile mTLS CA rotation")
+		return r.handleErrorWithRequeue(cluster, v1beta1.MTLSReconciliationFailedReason, err, 2*time.Second)
 	}
 
-	if err := r.reconcileResources(ctx, cluster); err != nil {
+	if requeueAfter == 0 || (renewAfter > 0 && renewAfter < requeueAfter) {
+		requeueAfter = renewAfter
+	}
+
+	if err := r.reconcileIstioAmbientNamespace(ctx, cluster); err != nil {
+		logger.Error(err, "Can't reconcile istio ambient namespace")
+		return r.handleErrorWithRequeue(cluster, v1beta1.ResourcesReconciliationFailedReason, err, 2*time.Second)
+	}
+
+	// Hold back the upgrade of the services until the planned schema migrations are approved.
+	desiredVersion := cluster.Spec.Version
+	if heldVersion != nil {
+		cluster.Spec.Version = heldVersion
+	}
+	canaryRequeueAfter, err := r.reconcileResources(ctx, cluster, caBundleHash)
+	cluster.Spec.Version = desiredVersion
+	if err != nil {
 		logger.Error(err, "Can't reconcile resources")
 		return r.handleErrorWithRequeue(cluster, v1beta1.ResourcesReconciliationFailedReason, err, 2*time.Second)
 	}
 
-	return r.handleSuccess(cluster)
+	if requeueAfter == 0 || (canaryRequeueAfter > 0 && canaryRequeueAfter < requeueAfter) {
+		requeueAfter = canaryRequeueAfter
+	}
+
+	if requeueAfter == 0 || (migrationRequeueAfter > 0 && migrationRequeueAfter < requeueAfter) {
+		requeueAfter = migrationRequeueAfter
+	}
+
+	if heldVersion != nil {
+		if requeueAfter == 0 || persistencePlanOnlyRequeueAfter < requeueAfter {
+			requeueAfter = persistencePlanOnlyRequeueAfter
+		}
+		v1beta1.SetTemporalClusterReconcileSuccess(cluster, metav1.ConditionTrue, v1beta1.PersistencePlanOnlyReason,
+			fmt.Sprintf("Schema migrations are planned, services keep running %s until the persistence plan only mode is disabled", heldVersion))
+		return reconcile.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	return r.handleSuccessWithRequeue(cluster, requeueAfter)
 }
 
-func (r *TemporalClusterReconciler) reconcileResources(ctx context.Context, temporalCluster *v1beta1.TemporalCluster) error {
+func (r *TemporalClusterReconciler) reconcileResources(ctx context.Context, temporalCluster *v1beta1.TemporalCluster, caBundleHash string) (time.Duration, error) {
 	// reconcile configmap first, then compute its hash.
 	configMapObject, err := r.Reconciler.ReconcileBuilder(ctx,
 		temporalCluster,
 		config.NewConfigmapBuilder(temporalCluster, r.Scheme))
 	if err != nil {
-		return fmt.Errorf("can't reconcile configmap: %w", err)
+		return 0, fmt.Errorf("can't reconcile configmap: %w", err)
 	}
 
 	configMap, ok := configMapObject.(*corev1.ConfigMap)
 	if !ok {
-		return errors.New("can't cast configmap object to *corev1.ConfigMap")
+		return 0, errors.New("can't cast configmap object to *corev1.ConfigMap")
 	}
 
 	configHash, err := hash.Sha256(configMap.Data)
 	if err != nil {
-		return fmt.Errorf("can't compute configmap hash: %w", err)
+		return 0, fmt.Errorf("can't compute configmap hash: %w", err)
 	}
 
-	builders, err := r.resourceBuilders(temporalCluster, configHash)
+	builders, err := r.resourceBuilders(ctx, temporalCluster, configHash, caBundleHash)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	objects, err := r.Reconciler.ReconcileBuilders(ctx, temporalCluster, builders)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	statuses, err := status.ReconciledObjectsToServiceStatuses(temporalCluster, objects)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	for _, status := range statuses {
@@ -196,12 +285,18 @@ func (r *TemporalClusterReconciler) reconcileResources(ctx context.Context, temp
 		v1beta1.SetTemporalClusterReady(temporalCluster, metav1.ConditionFalse, v1beta1.ServicesNotReadyReason, "")
 	}
 
-	return nil
+	// Canaries status may change without any change of the cluster resources.
+	return status.CanaryRequeueAfter(temporalCluster, objects, time.Now()), nil
 }
 
-func (r *TemporalClusterReconciler) resourceBuilders(temporalCluster *v1beta1.TemporalCluster, configHash string) ([]resource.Builder, error) {
+func (r *TemporalClusterReconciler) resourceBuilders(ctx context.Context, temporalCluster *v1beta1.TemporalCluster, configHash, caBundleHash string) ([]resource.Builder, error) {
 	builders := []resource.Builder{
 		base.NewFrontendServiceBuilder(temporalCluster, r.Scheme),
+		base.NewFrontendExternalServiceBuilder(temporalCluster, r.Scheme),
+		base.NewFrontendIngressBuilder(temporalCluster, r.Scheme),
+		base.NewFrontendGRPCRouteBuilder(temporalCluster, r.Scheme),
+		base.NewFrontendTLSRouteBuilder(temporalCluster, r.Scheme),
+		base.NewFrontendHTTPRouteBuilder(temporalCluster, r.Scheme),
 	}
 
 	services := []primitives.ServiceName{
@@ -212,6 +307,11 @@ func (r *TemporalClusterReconciler) resourceBuilders(temporalCluster *v1beta1.Te
 		primitives.InternalFrontendService,
 	}
 
+	datastoreSecretsHashes, err := r.serviceDatastoreSecretsHashes(ctx, temporalCluster)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, service := range services {
 		specs, err := temporalCluster.Spec.Services.GetServiceSpec(service)
 		if err != nil {
@@ -221,12 +321,45 @@ func (r *TemporalClusterReconciler) resourceBuilders(temporalCluster *v1beta1.Te
 		serviceName := string(service)
 
 		builders = append(builders, base.NewServiceAccountBuilder(serviceName, temporalCluster, r.Scheme))
-		builders = append(builders, base.NewDeploymentBuilder(serviceName, temporalCluster, r.Scheme, specs, configHash))
+		migrating, err := r.workloadMigrationInProgress(ctx, temporalCluster, serviceName, specs.GetWorkloadKind())
+		if err != nil {
+			return nil, err
+		}
+
+		canarySpecs := specs
+		if specs.Canary != nil {
+			canaryBlocked, err := r.stableWorkloadSelectsCanary(ctx, temporalCluster, serviceName, specs.GetWorkloadKind())
+			if err != nil {
+				return nil, err
+			}
+			// The stable workload would select the canary pods: don't run them and keep all the replicas stable.
+			// The canary status reports the canary as blocked, a promotion is still rolled out to the stable pods.
+			if canaryBlocked {
+				canarySpecs = specs.DeepCopy()
+				canarySpecs.Canary = nil
+				if specs.Canary.IsObserved() {
+					specs = canarySpecs
+				}
+			}
+		}
+
+		builders = append(builders, base.NewDeploymentBuilder(serviceName, temporalCluster, r.Scheme, specs, configHash, caBundleHash, datastoreSecretsHashes[serviceName], r.SchemaRunnerImage, migrating))
+		builders = append(builders, base.NewStatefulSetBuilder(serviceName, temporalCluster, r.Scheme, specs, configHash, caBundleHash, datastoreSecretsHashes[serviceName], r.SchemaRunnerImage, migrating))
+		builders = append(builders, base.NewCanaryDeploymentBuilder(serviceName, temporalCluster, r.Scheme, canarySpecs, configHash, caBundleHash, datastoreSecretsHashes[serviceName], r.SchemaRunnerImage))
 		builders = append(builders, base.NewHeadlessServiceBuilder(serviceName, temporalCluster, r.Scheme, specs))
+		builders = append(builders, base.NewHorizontalPodAutoscalerBuilder(serviceName, temporalCluster, r.Scheme, specs, r.AvailableAPIs.KEDA))
+		builders = append(builders, base.NewScaledObjectBuilder(serviceName, temporalCluster, r.Scheme, specs, r.AvailableAPIs.KEDA))
+		builders = append(builders, base.NewPodDisruptionBudgetBuilder(serviceName, temporalCluster, r.Scheme, specs))
 
 		builders = append(builders, istio.NewPeerAuthenticationBuilder(serviceName, temporalCluster, r.Scheme, specs))
 		builders = append(builders, istio.NewDestinationRuleBuilder(serviceName, temporalCluster, r.Scheme, specs))
+		builders = append(builders, istio.NewAuthorizationPolicyBuilder(serviceName, temporalCluster, r.Scheme, specs))
 		builders = append(builders, prometheus.NewServiceMonitorBuilder(serviceName, temporalCluster, r.Scheme, specs))
+
+		for _, port := range linkerd.ServerPorts() {
+			builders = append(builders, linkerd.NewServerBuilder(serviceName, port, temporalCluster, r.Scheme))
+			builders = append(builders, linkerd.NewAuthorizationPolicyBuilder(serviceName, port, temporalCluster, r.Scheme))
+		}
 	}
 
 	builders = append(builders,
@@ -242,23 +375,29 @@ func (r *TemporalClusterReconciler) resourceBuilders(temporalCluster *v1beta1.Te
 		certmanager.NewMTLSFrontendIntermediateCAIssuerBuilder(temporalCluster, r.Scheme),
 		certmanager.NewMTLSFrontendCertificateBuilder(temporalCluster, r.Scheme),
 		certmanager.NewWorkerFrontendClientCertificateBuilder(temporalCluster, r.Scheme),
+		spire.NewHelperConfigmapBuilder(temporalCluster, r.Scheme),
+		istio.NewWaypointBuilder(temporalCluster, r.Scheme),
+		istio.NewWaypointAuthorizationPolicyBuilder(temporalCluster, r.Scheme),
+		linkerd.NewInternodeMeshTLSAuthenticationBuilder(temporalCluster, r.Scheme),
+		linkerd.NewFrontendMeshTLSAuthenticationBuilder(temporalCluster, r.Scheme),
 		// UI:
-		ui.NewDeploymentBuilder(temporalCluster, r.Scheme, configHash),
+		ui.NewDeploymentBuilder(temporalCluster, r.Scheme, configHash, caBundleHash),
 		ui.NewServiceBuilder(temporalCluster, r.Scheme),
 		ui.NewIngressBuilder(temporalCluster, r.Scheme),
+		ui.NewHTTPRouteBuilder(temporalCluster, r.Scheme),
 		ui.NewFrontendClientCertificateBuilder(temporalCluster, r.Scheme),
+		// Codec server:
+		codecserver.NewDeploymentBuilder(temporalCluster, r.Scheme),
+		codecserver.NewServiceBuilder(temporalCluster, r.Scheme),
+		codecserver.NewIngressBuilder(temporalCluster, r.Scheme),
 		// Admin tools:
-		admintools.NewDeploymentBuilder(temporalCluster, r.Scheme, configHash),
+		admintools.NewDeploymentBuilder(temporalCluster, r.Scheme, configHash, caBundleHash),
 		admintools.NewFrontendClientCertificateBuilder(temporalCluster, r.Scheme),
 	)
 
 	return builders, nil
 }
 
-func (r *TemporalClusterReconciler) handleSuccess(cluster *v1beta1.TemporalCluster) (ctrl.Result, error) {
-	return r.handleSuccessWithRequeue(cluster, 0)
-}
-
 func (r *TemporalClusterReconciler) handleSuccessWithRequeue(cluster *v1beta1.TemporalCluster, requeueAfter time.Duration) (ctrl.Result, error) {
 	v1beta1.SetTemporalClusterReconcileSuccess(cluster, metav1.ConditionTrue, v1beta1.ReconcileSuccessReason, "")
 	return reconcile.Result{RequeueAfter: requeueAfter}, nil
@@ -275,12 +414,17 @@ func (r *TemporalClusterReconciler) handleErrorWithRequeue(cluster *v1beta1.Temp
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *TemporalClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	for _, resource := range []client.Object{&appsv1.Deployment{}, &corev1.ConfigMap{}, &corev1.Service{}, &corev1.ServiceAccount{}, &networkingv1.Ingress{}, &batchv1.Job{}} {
+	for _, resource := range []client.Object{&appsv1.Deployment{}, &appsv1.StatefulSet{}, &corev1.ConfigMap{}, &corev1.Service{}, &corev1.ServiceAccount{}, &networkingv1.Ingress{}, &batchv1.Job{}, &autoscalingv2.HorizontalPodAutoscaler{}, &policyv1.PodDisruptionBudget{}} {
 		if err := mgr.GetFieldIndexer().IndexField(context.Background(), resource, ownerKey, addResourceToIndex); err != nil {
 			return err
 		}
 	}
 
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1beta1.TemporalCluster{}, datastoreSecretsField, addDatastoreSecretsToIndex)
+	if err != nil {
+		return err
+	}
+
 	controller := ctrl.NewControllerManagedBy(mgr).
 		For(&v1beta1.TemporalCluster{}, builder.WithPredicates(predicate.Or(
 			predicate.GenerationChangedPredicate{},
@@ -288,11 +432,16 @@ func (r *TemporalClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			predicate.AnnotationChangedPredicate{},
 		))).
 		Owns(&appsv1.Deployment{}).
+		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&networkingv1.Ingress{}).
-		Owns(&batchv1.Job{})
+		Owns(&batchv1.Job{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		// Roll out the cluster services when a secret referenced by its datastores changes.
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.secretToClustersMapfunc))
 
 	if r.AvailableAPIs.CertManager {
 		controller = controller.
@@ -309,15 +458,71 @@ func (r *TemporalClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if r.AvailableAPIs.Istio {
 		controller = controller.
 			Owns(&istiosecurityv1beta1.PeerAuthentication{}).
+			Owns(&istiosecurityv1beta1.AuthorizationPolicy{}).
 			Owns(&istionetworkingv1beta1.DestinationRule{})
 
-		for _, resource := range []client.Object{&istiosecurityv1beta1.PeerAuthentication{}, &istionetworkingv1beta1.DestinationRule{}} {
+		for _, resource := range []client.Object{&istiosecurityv1beta1.PeerAuthentication{}, &istiosecurityv1beta1.AuthorizationPolicy{}, &istionetworkingv1beta1.DestinationRule{}} {
 			if err := mgr.GetFieldIndexer().IndexField(context.Background(), resource, ownerKey, addIstioResourceToIndex); err != nil {
 				return err
 			}
 		}
 	}
 
+	if r.AvailableAPIs.GatewayAPI {
+		controller = controller.Owns(&gatewayv1.Gateway{})
+
+		for _, resource := range []client.Object{&gatewayv1.Gateway{}} {
+			if err := mgr.GetFieldIndexer().IndexField(context.Background(), resource, ownerKey, addGatewayAPIResourceToIndex); err != nil {
+				return err
+			}
+		}
+	}
+
+	if r.AvailableAPIs.GatewayAPIHTTPRoute {
+		controller = controller.Owns(&gatewayv1.HTTPRoute{})
+
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), &gatewayv1.HTTPRoute{}, ownerKey, addGatewayAPIResourceToIndex); err != nil {
+			return err
+		}
+	}
+
+	if r.AvailableAPIs.GatewayAPIGRPCRoute {
+		controller = controller.Owns(&gatewayv1.GRPCRoute{})
+
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), &gatewayv1.GRPCRoute{}, ownerKey, addGatewayAPIResourceToIndex); err != nil {
+			return err
+		}
+	}
+
+	if r.AvailableAPIs.GatewayAPITLSRoute {
+		controller = controller.Owns(&gatewayv1alpha2.TLSRoute{})
+
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), &gatewayv1alpha2.TLSRoute{}, ownerKey, addGatewayAPIResourceToIndex); err != nil {
+			return err
+		}
+	}
+
+	if r.AvailableAPIs.KEDA {
+		controller = controller.Owns(&kedav1alpha1.ScaledObject{})
+
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), &kedav1alpha1.ScaledObject{}, ownerKey, addKEDAResourceToIndex); err != nil {
+			return err
+		}
+	}
+
+	if r.AvailableAPIs.Linkerd {
+		controller = controller.
+			Owns(&linkerdpolicyv1beta1.Server{}).
+			Owns(&linkerdpolicyv1alpha1.AuthorizationPolicy{}).
+			Owns(&linkerdpolicyv1alpha1.MeshTLSAuthentication{})
+
+		for _, resource := range []client.Object{&linkerdpolicyv1beta1.Server{}, &linkerdpolicyv1alpha1.AuthorizationPolicy{}, &linkerdpolicyv1alpha1.MeshTLSAuthentication{}} {
+			if err := mgr.GetFieldIndexer().IndexField(context.Background(), resource, ownerKey, addLinkerdResourceToIndex); err != nil {
+				return err
+			}
+		}
+	}
+
 	if r.AvailableAPIs.PrometheusOperator {
 		controller = controller.Owns(&monitoringv1.ServiceMonitor{})
 
@@ -328,17 +533,28 @@ func (r *TemporalClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		}
 	}
 
+	if r.AvailableAPIs.VolumeSnapshot {
+		controller = controller.Owns(&snapshotv1.VolumeSnapshot{})
+
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), &snapshotv1.VolumeSnapshot{}, ownerKey, addVolumeSnapshotResourceToIndex); err != nil {
+			return err
+		}
+	}
+
 	return controller.Complete(r)
 }
 
 func addResourceToIndex(rawObj client.Object) []string {
 	switch resourceObject := rawObj.(type) {
 	case *appsv1.Deployment,
+		*appsv1.StatefulSet,
 		*corev1.ConfigMap,
 		*corev1.Service,
 		*corev1.ServiceAccount,
 		*networkingv1.Ingress,
-		*batchv1.Job:
+		*batchv1.Job,
+		*autoscalingv2.HorizontalPodAutoscaler,
+		*policyv1.PodDisruptionBudget:
 		owner := metav1.GetControllerOf(resourceObject)
 		return validateAndGetOwner(owner)
 	default:
@@ -349,6 +565,7 @@ func addResourceToIndex(rawObj client.Object) []string {
 func addIstioResourceToIndex(rawObj client.Object) []string {
 	switch resourceObject := rawObj.(type) {
 	case *istiosecurityv1beta1.PeerAuthentication,
+		*istiosecurityv1beta1.AuthorizationPolicy,
 		*istionetworkingv1beta1.DestinationRule:
 		owner := metav1.GetControllerOf(resourceObject)
 		return validateAndGetOwner(owner)
@@ -357,6 +574,51 @@ func addIstioResourceToIndex(rawObj client.Object) []string {
 	}
 }
 
+func addGatewayAPIResourceToIndex(rawObj client.Object) []string {
+	switch resourceObject := rawObj.(type) {
+	case *gatewayv1.Gateway,
+		*gatewayv1.HTTPRoute,
+		*gatewayv1.GRPCRoute,
+		*gatewayv1alpha2.TLSRoute:
+		owner := metav1.GetControllerOf(resourceObject)
+		return validateAndGetOwner(owner)
+	default:
+		return nil
+	}
+}
+
+func addKEDAResourceToIndex(rawObj client.Object) []string {
+	switch resourceObject := rawObj.(type) {
+	case *kedav1alpha1.ScaledObject:
+		owner := metav1.GetControllerOf(resourceObject)
+		return validateAndGetOwner(owner)
+	default:
+		return nil
+	}
+}
+
+func addVolumeSnapshotResourceToIndex(rawObj client.Object) []string {
+	switch resourceObject := rawObj.(type) {
+	case *snapshotv1.VolumeSnapshot:
+		owner := metav1.GetControllerOf(resourceObject)
+		return validateAndGetOwner(owner)
+	default:
+		return nil
+	}
+}
+
+func addLinkerdResourceToIndex(rawObj client.Object) []string {
+	switch resourceObject := rawObj.(type) {
+	case *linkerdpolicyv1beta1.Server,
+		*linkerdpolicyv1alpha1.AuthorizationPolicy,
+		*linkerdpolicyv1alpha1.MeshTLSAuthentication:
+		owner := metav1.GetControllerOf(resourceObject)
+		return validateAndGetOwner(owner)
+	default:
+		return nil
+	}
+}
+
 func addCertManagerResourceToIndex(rawObj client.Object) []string {
 	switch resourceObject := rawObj.(type) {
 	case *certmanagerv1.Issuer,
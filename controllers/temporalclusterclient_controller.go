@@ -39,6 +39,7 @@ import (
 
 	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/certmanager"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/operator"
 	"github.com/alexandrevilain/temporal-operator/pkg/kubernetes"
 	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 )
@@ -106,8 +107,8 @@ func (r *TemporalClusterClientReconciler) Reconcile(ctx context.Context, req ctr
 		return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
 	}
 
-	if !(cluster.MTLSWithCertManagerEnabled() && cluster.Spec.MTLS.FrontendEnabled()) {
-		return reconcile.Result{Requeue: false}, errors.New("mTLS for frontend not enabled using cert-manager for the cluster, can't create a client")
+	if !(cluster.MTLSWithCertificatesEnabled() && cluster.Spec.MTLS.FrontendEnabled()) {
+		return reconcile.Result{Requeue: false}, errors.New("mTLS for frontend not enabled using cert-manager or operator for the cluster, can't create a client")
 	}
 
 	clusterClient.Status.ServerName = cluster.Spec.MTLS.Frontend.ServerName(cluster)
@@ -117,26 +118,44 @@ func (r *TemporalClusterClientReconciler) Reconcile(ctx context.Context, req ctr
 		}
 	}
 
-	builder := certmanager.NewGenericFrontendClientCertificateBuilder(cluster, r.Scheme, clusterClient.GetName())
-	certificateObject := builder.Build()
+	var (
+		secretName string
+		result     reconcile.Result
+	)
+	if cluster.MTLSWithOperatorEnabled() {
+		certificate := operator.ClientCertificate(cluster, clusterClient.GetName())
 
-	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, certificateObject, func() error {
-		return builder.Update(certificateObject)
-	})
-	if err != nil {
-		return reconcile.Result{}, err
-	}
+		renewal, err := r.reconcileOperatorCertificate(ctx, cluster, certificate, false)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
 
-	certificate := certificateObject.(*certmanagerv1.Certificate)
+		secretName = certificate.SecretName
+		result.RequeueAfter = max(time.Until(renewal), time.Second)
+	} else {
+		builder := certmanager.NewGenericFrontendClientCertificateBuilder(cluster, r.Scheme, clusterClient.GetName())
+		certificateObject := builder.Build()
 
-	condition := certmanagerapiutil.GetCertificateCondition(certificate, certmanagerv1.CertificateConditionReady)
-	if condition == nil || condition.Status != certmanagermeta.ConditionTrue {
-		logger.Info("Waiting for certificate to become ready, requeuing")
-		return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+		_, err = controllerutil.CreateOrUpdate(ctx, r.Client, certificateObject, func() error {
+			return builder.Update(certificateObject)
+		})
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		certificate := certificateObject.(*certmanagerv1.Certificate)
+
+		condition := certmanagerapiutil.GetCertificateCondition(certificate, certmanagerv1.CertificateConditionReady)
+		if condition == nil || condition.Status != certmanagermeta.ConditionTrue {
+			logger.Info("Waiting for certificate to become ready, requeuing")
+			return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+
+		secretName = certificate.Spec.SecretName
 	}
 
 	if clusterClient.GetNamespace() != cluster.GetNamespace() {
-		originalSecret := client.ObjectKey{Namespace: certificate.GetNamespace(), Name: certificate.Spec.SecretName}
+		originalSecret := client.ObjectKey{Namespace: cluster.GetNamespace(), Name: secretName}
 		err = kubernetes.NewSecretCopier(r.Client, r.Scheme).Copy(ctx, clusterClient, originalSecret, clusterClient.GetNamespace())
 		if err != nil {
 			return reconcile.Result{}, err
@@ -144,10 +163,10 @@ func (r *TemporalClusterClientReconciler) Reconcile(ctx context.Context, req ctr
 	}
 
 	clusterClient.Status.SecretRef = &corev1.LocalObjectReference{
-		Name: certificate.Spec.SecretName,
+		Name: secretName,
 	}
 
-	return reconcile.Result{}, nil
+	return result, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -193,7 +212,14 @@ func (r *TemporalClusterClientReconciler) SetupWithManager(mgr ctrl.Manager) err
 				))
 	}
 
-	controller.Owns(&corev1.Secret{})
+	// Certificates issued by the operator are stored in secrets owned by the cluster.
+	controller = controller.
+		Owns(&corev1.Secret{}).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(
+				EnqueueRequestForClusterClientReferencingOwnerCluster(r.Client),
+			))
 
 	return controller.Complete(r)
 }
@@ -21,18 +21,37 @@ import (
 	"fmt"
 
 	"github.com/alexandrevilain/controller-tools/pkg/discovery"
+	cnpgv1 "github.com/alexandrevilain/temporal-operator/pkg/cnpg/apis/postgresql/v1"
+	k8ssandrav1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/k8ssandra/apis/k8ssandra/v1alpha1"
+	kedav1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/keda/apis/keda/v1alpha1"
+	linkerdpolicyv1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/linkerd/apis/policy/v1alpha1"
+	linkerdpolicyv1beta1 "github.com/alexandrevilain/temporal-operator/pkg/linkerd/apis/policy/v1beta1"
+	pxcv1 "github.com/alexandrevilain/temporal-operator/pkg/percona/apis/pxc/v1"
+	snapshotv1 "github.com/alexandrevilain/temporal-operator/pkg/snapshotter/apis/volumesnapshot/v1"
 	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/go-logr/logr"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	istionetworkingv1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
 	istiosecurityv1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 )
 
 // AvailableAPIs holds available apis in the cluster.
 type AvailableAPIs struct {
-	Istio              bool
-	CertManager        bool
-	PrometheusOperator bool
+	Istio               bool
+	CertManager         bool
+	PrometheusOperator  bool
+	Linkerd             bool
+	GatewayAPI          bool
+	GatewayAPIHTTPRoute bool
+	GatewayAPIGRPCRoute bool
+	GatewayAPITLSRoute  bool
+	KEDA                bool
+	VolumeSnapshot      bool
+	CloudNativePG       bool
+	PerconaXtraDB       bool
+	K8ssandra           bool
 }
 
 // FindAvailableAPIs searches for available well-known APIs in the cluster.
@@ -55,9 +74,69 @@ func FindAvailableAPIs(logger logr.Logger, mgr discovery.Manager) (*AvailableAPI
 		return nil, fmt.Errorf("can't determine if prometheus-operator is available: %w", err)
 	}
 
+	resources.Linkerd, err = mgr.AreObjectsSupported(&linkerdpolicyv1beta1.Server{}, &linkerdpolicyv1alpha1.AuthorizationPolicy{}, &linkerdpolicyv1alpha1.MeshTLSAuthentication{})
+	if err != nil {
+		return nil, fmt.Errorf("can't determine if linkerd is available: %w", err)
+	}
+
+	resources.GatewayAPI, err = mgr.AreObjectsSupported(&gatewayv1.Gateway{})
+	if err != nil {
+		return nil, fmt.Errorf("can't determine if gateway-api is available: %w", err)
+	}
+
+	resources.GatewayAPIHTTPRoute, err = mgr.AreObjectsSupported(&gatewayv1.HTTPRoute{})
+	if err != nil {
+		return nil, fmt.Errorf("can't determine if gateway-api HTTPRoute is available: %w", err)
+	}
+
+	resources.GatewayAPIGRPCRoute, err = mgr.AreObjectsSupported(&gatewayv1.GRPCRoute{})
+	if err != nil {
+		return nil, fmt.Errorf("can't determine if gateway-api GRPCRoute is available: %w", err)
+	}
+
+	resources.GatewayAPITLSRoute, err = mgr.AreObjectsSupported(&gatewayv1alpha2.TLSRoute{})
+	if err != nil {
+		return nil, fmt.Errorf("can't determine if gateway-api TLSRoute is available: %w", err)
+	}
+
+	resources.KEDA, err = mgr.AreObjectsSupported(&kedav1alpha1.ScaledObject{})
+	if err != nil {
+		return nil, fmt.Errorf("can't determine if keda is available: %w", err)
+	}
+
+	resources.VolumeSnapshot, err = mgr.AreObjectsSupported(&snapshotv1.VolumeSnapshot{})
+	if err != nil {
+		return nil, fmt.Errorf("can't determine if volume snapshots are available: %w", err)
+	}
+
+	resources.CloudNativePG, err = mgr.AreObjectsSupported(&cnpgv1.Cluster{})
+	if err != nil {
+		return nil, fmt.Errorf("can't determine if cloudnative-pg is available: %w", err)
+	}
+
+	resources.PerconaXtraDB, err = mgr.AreObjectsSupported(&pxcv1.PerconaXtraDBCluster{})
+	if err != nil {
+		return nil, fmt.Errorf("can't determine if percona-xtradb-cluster-operator is available: %w", err)
+	}
+
+	resources.K8ssandra, err = mgr.AreObjectsSupported(&k8ssandrav1alpha1.K8ssandraCluster{})
+	if err != nil {
+		return nil, fmt.Errorf("can't determine if k8ssandra-operator is available: %w", err)
+	}
+
 	logResourceAvailability(logger, "cert-manager", resources.CertManager)
 	logResourceAvailability(logger, "istio", resources.Istio)
 	logResourceAvailability(logger, "prometheus-operator", resources.PrometheusOperator)
+	logResourceAvailability(logger, "linkerd", resources.Linkerd)
+	logResourceAvailability(logger, "gateway-api", resources.GatewayAPI)
+	logResourceAvailability(logger, "gateway-api HTTPRoute", resources.GatewayAPIHTTPRoute)
+	logResourceAvailability(logger, "gateway-api GRPCRoute", resources.GatewayAPIGRPCRoute)
+	logResourceAvailability(logger, "gateway-api TLSRoute", resources.GatewayAPITLSRoute)
+	logResourceAvailability(logger, "keda", resources.KEDA)
+	logResourceAvailability(logger, "volume snapshots", resources.VolumeSnapshot)
+	logResourceAvailability(logger, "cloudnative-pg", resources.CloudNativePG)
+	logResourceAvailability(logger, "percona-xtradb-cluster-operator", resources.PerconaXtraDB)
+	logResourceAvailability(logger, "k8ssandra-operator", resources.K8ssandra)
 
 	return resources, nil
 }
@@ -19,6 +19,7 @@ package metadata
 
 import (
 	"github.com/alexandrevilain/temporal-operator/pkg/version"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -63,3 +64,26 @@ func HeadlessLabels() map[string]string {
 		"app.kubernetes.io/headless": "true",
 	}
 }
+
+// trackLabel is the label holding the track of the service pods.
+const trackLabel = "app.kubernetes.io/track"
+
+const (
+	// StableTrack is the track of the service pods running the cluster configuration.
+	StableTrack = "stable"
+	// CanaryTrack is the track of the service pods running the canary configuration.
+	CanaryTrack = "canary"
+)
+
+// TrackLabels returns labels to express whether a workload runs the stable or the canary pods of a service.
+func TrackLabels(track string) map[string]string {
+	return map[string]string{
+		trackLabel: track,
+	}
+}
+
+// SelectsTrack returns true if the provided workload selector only matches the pods of a track.
+// Workloads created before pods had a track label match the pods of all the tracks.
+func SelectsTrack(selector *metav1.LabelSelector) bool {
+	return selector != nil && selector.MatchLabels[trackLabel] != ""
+}
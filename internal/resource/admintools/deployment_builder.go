@@ -42,16 +42,18 @@ const (
 )
 
 type DeploymentBuilder struct {
-	instance   *v1beta1.TemporalCluster
-	scheme     *runtime.Scheme
-	configHash string
+	instance     *v1beta1.TemporalCluster
+	scheme       *runtime.Scheme
+	configHash   string
+	caBundleHash string
 }
 
-func NewDeploymentBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, configHash string) *DeploymentBuilder {
+func NewDeploymentBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, configHash, caBundleHash string) *DeploymentBuilder {
 	return &DeploymentBuilder{
-		instance:   instance,
-		scheme:     scheme,
-		configHash: configHash,
+		instance:     instance,
+		scheme:       scheme,
+		configHash:   configHash,
+		caBundleHash: caBundleHash,
 	}
 }
 
@@ -96,7 +98,7 @@ func (b *DeploymentBuilder) Update(object client.Object) error {
 	volumes := []corev1.Volume{}
 	volumeMounts := []corev1.VolumeMount{}
 
-	if b.instance.MTLSWithCertManagerEnabled() && b.instance.Spec.MTLS.FrontendEnabled() {
+	if b.instance.MTLSWithCertificatesEnabled() && b.instance.Spec.MTLS.FrontendEnabled() {
 		volumeMounts = append(volumeMounts,
 			corev1.VolumeMount{
 				Name:      certmanager.AdmintoolsFrontendClientCertificate,
@@ -105,13 +107,8 @@ func (b *DeploymentBuilder) Update(object client.Object) error {
 		)
 		volumes = append(volumes,
 			corev1.Volume{
-				Name: certmanager.AdmintoolsFrontendClientCertificate,
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName:  b.instance.ChildResourceName(certmanager.AdmintoolsFrontendClientCertificate),
-						DefaultMode: ptr.To[int32](corev1.SecretVolumeSourceDefaultMode),
-					},
-				},
+				Name:         certmanager.AdmintoolsFrontendClientCertificate,
+				VolumeSource: certmanager.GetClientCertificateVolumeSource(b.instance, certmanager.AdmintoolsFrontendClientCertificate),
 			},
 		)
 
@@ -128,7 +125,7 @@ func (b *DeploymentBuilder) Update(object client.Object) error {
 	}
 
 	deployment.Spec.Template = corev1.PodTemplateSpec{
-		ObjectMeta: meta.BuildPodObjectMeta(b.instance, "admintools", b.configHash),
+		ObjectMeta: meta.BuildPodObjectMeta(b.instance, "admintools", b.instance.Spec.Version, b.configHash, b.caBundleHash, ""),
 		Spec: corev1.PodSpec{
 			ImagePullSecrets: b.instance.Spec.ImagePullSecrets,
 			Containers: []corev1.Container{
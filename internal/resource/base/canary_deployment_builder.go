@@ -0,0 +1,188 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base
+
+import (
+	"fmt"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	"github.com/alexandrevilain/temporal-operator/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ resource.Builder = (*CanaryDeploymentBuilder)(nil)
+
+// CanaryDeploymentBuilder builds the Deployment running the canary pods of a service.
+// Canary pods carry the service labels, so they join the same membership ring and endpoints as the stable pods.
+// Their track label keeps them out of the stable workload, autoscaler and PodDisruptionBudget selectors,
+// except for stable workloads created before pods had a track label: the controller doesn't run canaries next to them.
+type CanaryDeploymentBuilder struct {
+	serviceName string
+	instance    *v1beta1.TemporalCluster
+	scheme      *runtime.Scheme
+	service     *v1beta1.ServiceSpec
+	pod         *DeploymentBuilder
+}
+
+func NewCanaryDeploymentBuilder(serviceName string, instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, service *v1beta1.ServiceSpec, configHash, caBundleHash, datastoreSecretsHash, authProxyImage string) *CanaryDeploymentBuilder {
+	return &CanaryDeploymentBuilder{
+		serviceName: serviceName,
+		instance:    instance,
+		scheme:      scheme,
+		service:     service,
+		pod:         NewDeploymentBuilder(serviceName, instance, scheme, service.WithCanary(), configHash, caBundleHash, datastoreSecretsHash, authProxyImage, false),
+	}
+}
+
+func (b *CanaryDeploymentBuilder) labels() map[string]string {
+	return metadata.Merge(
+		metadata.GetLabels(b.instance, b.serviceName, b.instance.ServiceVersion(b.pod.service), b.instance.Labels),
+		metadata.TrackLabels(metadata.CanaryTrack),
+	)
+}
+
+func (b *CanaryDeploymentBuilder) Build() client.Object {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.CanaryResourceName(b.serviceName),
+			Namespace:   b.instance.Namespace,
+			Labels:      b.labels(),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *CanaryDeploymentBuilder) Enabled() bool {
+	if !isBuilderEnabled(b.instance, b.serviceName) {
+		return false
+	}
+
+	switch b.service.Canary.GetAction() {
+	case v1beta1.ObserveCanaryAction:
+		return b.service.Canary != nil
+	case v1beta1.PromoteCanaryAction:
+		// The canary pods are kept until the promoted configuration is rolled out to the stable pods.
+		return !canaryPromoted(b.instance, b.serviceName)
+	default:
+		return false
+	}
+}
+
+func (b *CanaryDeploymentBuilder) Update(object client.Object) error {
+	deployment := object.(*appsv1.Deployment)
+	deployment.Labels = metadata.Merge(
+		object.GetLabels(),
+		b.labels(),
+	)
+	deployment.Annotations = metadata.Merge(
+		object.GetAnnotations(),
+		metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+	)
+
+	deployment.Spec.Replicas = ptr.To(b.service.Canary.GetReplicas())
+	deployment.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: metadata.Merge(
+			metadata.LabelsSelector(b.instance, b.serviceName),
+			metadata.TrackLabels(metadata.CanaryTrack),
+		),
+	}
+
+	template, err := b.pod.podTemplateSpec()
+	if err != nil {
+		return err
+	}
+	template.Labels = metadata.Merge(template.Labels, metadata.TrackLabels(metadata.CanaryTrack))
+	deployment.Spec.Template = template
+
+	if b.instance.Spec.Services.Overrides != nil && b.instance.Spec.Services.Overrides.Deployment != nil {
+		err := kubernetes.ApplyDeploymentOverrides(deployment, b.instance.Spec.Services.Overrides.Deployment)
+		if err != nil {
+			return fmt.Errorf("can't apply deployment overrides: %w", err)
+		}
+	}
+
+	if b.service.Overrides != nil && b.service.Overrides.Deployment != nil {
+		err := kubernetes.ApplyDeploymentOverrides(deployment, b.service.Overrides.Deployment)
+		if err != nil {
+			return fmt.Errorf("failed applying deployment overrides: %w", err)
+		}
+	}
+
+	if b.service.Canary.Template != nil {
+		err := kubernetes.ApplyPodTemplateSpecOverrides(&deployment.Spec.Template, b.service.Canary.Template)
+		if err != nil {
+			return fmt.Errorf("failed applying canary template overrides: %w", err)
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, deployment, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
+
+// canaryPromoted returns true if the promoted canary configuration is rolled out to the service stable pods.
+func canaryPromoted(instance *v1beta1.TemporalCluster, serviceName string) bool {
+	for _, status := range instance.Status.Services {
+		if status.Name == serviceName {
+			return status.Canary != nil && status.Canary.Phase == v1beta1.PromotedCanaryPhase
+		}
+	}
+	return false
+}
+
+// stableReplicas returns the number of stable pods of the service.
+// While a canary is observed, its pods are taken from the service replicas.
+func stableReplicas(service *v1beta1.ServiceSpec) *int32 {
+	if service.Replicas == nil || !service.Canary.IsObserved() {
+		return service.Replicas
+	}
+	return ptr.To(max(*service.Replicas-service.Canary.GetReplicas(), 1))
+}
+
+// stableMinReplicas returns the lower limit for the number of stable pods of an autoscaled service.
+// While a canary is observed, its pods are taken from the autoscaling minimum replicas.
+func stableMinReplicas(service *v1beta1.ServiceSpec) *int32 {
+	minReplicas := autoscalingMinReplicas(service.Autoscaling)
+	if !service.Canary.IsObserved() {
+		return minReplicas
+	}
+	return ptr.To(max(*minReplicas-service.Canary.GetReplicas(), 1))
+}
+
+// stableSelector returns the selector of the service stable workload.
+// Workloads created before pods had a track label keep their selector, as it's immutable.
+func stableSelector(instance *v1beta1.TemporalCluster, serviceName string, current *metav1.LabelSelector) *metav1.LabelSelector {
+	if current != nil {
+		return current
+	}
+	return &metav1.LabelSelector{
+		MatchLabels: metadata.Merge(
+			metadata.LabelsSelector(instance, serviceName),
+			metadata.TrackLabels(metadata.StableTrack),
+		),
+	}
+}
@@ -0,0 +1,271 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/base"
+	"github.com/alexandrevilain/temporal-operator/pkg/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+func TestCanaryDeploymentBuilder(t *testing.T) {
+	tests := map[string]struct {
+		canary                     *v1beta1.ServiceCanarySpec
+		canaryPhase                v1beta1.CanaryPhase
+		expectedCanaryEnabled      bool
+		expectedStableReplicas     int32
+		expectedStableImage        string
+		expectedStableNodeSelector map[string]string
+	}{
+		"no canary": {
+			expectedStableReplicas: 3,
+			expectedStableImage:    "temporalio/server:1.24.0",
+		},
+		"observed canary": {
+			canary: &v1beta1.ServiceCanarySpec{
+				Version: version.MustNewVersionFromString("1.25.0"),
+			},
+			expectedCanaryEnabled:  true,
+			expectedStableReplicas: 2,
+			expectedStableImage:    "temporalio/server:1.24.0",
+		},
+		"promoting canary": {
+			canary: &v1beta1.ServiceCanarySpec{
+				Version: version.MustNewVersionFromString("1.25.0"),
+				Action:  v1beta1.PromoteCanaryAction,
+			},
+			canaryPhase:                v1beta1.PromotingCanaryPhase,
+			expectedCanaryEnabled:      true,
+			expectedStableReplicas:     3,
+			expectedStableImage:        "temporalio/server:1.25.0",
+			expectedStableNodeSelector: map[string]string{"pool": "canary"},
+		},
+		"promoted canary": {
+			canary: &v1beta1.ServiceCanarySpec{
+				Version: version.MustNewVersionFromString("1.25.0"),
+				Action:  v1beta1.PromoteCanaryAction,
+			},
+			canaryPhase:                v1beta1.PromotedCanaryPhase,
+			expectedStableReplicas:     3,
+			expectedStableImage:        "temporalio/server:1.25.0",
+			expectedStableNodeSelector: map[string]string{"pool": "canary"},
+		},
+		"aborted canary": {
+			canary: &v1beta1.ServiceCanarySpec{
+				Version: version.MustNewVersionFromString("1.25.0"),
+				Action:  v1beta1.AbortCanaryAction,
+			},
+			expectedStableReplicas: 3,
+			expectedStableImage:    "temporalio/server:1.24.0",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.24.0"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{PluginName: "postgres12"},
+						},
+						VisibilityStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{PluginName: "postgres12"},
+						},
+					},
+				},
+			}
+			cluster.Default()
+
+			if test.canaryPhase != "" {
+				cluster.Status.Services = []v1beta1.ServiceStatus{
+					{
+						Name:   "history",
+						Canary: &v1beta1.CanaryStatus{Phase: test.canaryPhase},
+					},
+				}
+			}
+
+			scheme := runtime.NewScheme()
+			require.NoError(tt, v1beta1.AddToScheme(scheme))
+
+			service := cluster.Spec.Services.History
+			service.Replicas = ptr.To[int32](3)
+			service.Canary = test.canary
+			if service.Canary != nil {
+				service.Canary.Template = &v1beta1.PodTemplateSpecOverride{
+					Spec: &apiextensionsv1.JSON{Raw: []byte(`{"nodeSelector":{"pool":"canary"}}`)},
+				}
+			}
+
+			stableBuilder := base.NewDeploymentBuilder("history", cluster, scheme, service, "hash", "", "", "", false)
+			stableObject := stableBuilder.Build()
+			require.NoError(tt, stableBuilder.Update(stableObject))
+			stable := stableObject.(*appsv1.Deployment)
+
+			assert.Equal(tt, test.expectedStableReplicas, *stable.Spec.Replicas)
+			assert.Equal(tt, test.expectedStableImage, stable.Spec.Template.Spec.Containers[0].Image)
+			assert.Equal(tt, test.expectedStableNodeSelector, stable.Spec.Template.Spec.NodeSelector)
+
+			canaryBuilder := base.NewCanaryDeploymentBuilder("history", cluster, scheme, service, "hash", "", "", "")
+			assert.Equal(tt, test.expectedCanaryEnabled, canaryBuilder.Enabled())
+			if !test.expectedCanaryEnabled {
+				return
+			}
+
+			canaryObject := canaryBuilder.Build()
+			require.NoError(tt, canaryBuilder.Update(canaryObject))
+			canary := canaryObject.(*appsv1.Deployment)
+
+			assert.Equal(tt, "test-history-canary", canary.Name)
+			assert.Equal(tt, int32(1), *canary.Spec.Replicas)
+			assert.Equal(tt, "temporalio/server:1.25.0", canary.Spec.Template.Spec.Containers[0].Image)
+			assert.Equal(tt, map[string]string{"pool": "canary"}, canary.Spec.Template.Spec.NodeSelector)
+			assert.Equal(tt, "canary", canary.Spec.Selector.MatchLabels["app.kubernetes.io/track"])
+			assert.Equal(tt, "canary", canary.Spec.Template.Labels["app.kubernetes.io/track"])
+			assert.Equal(tt, "1.25.0", canary.Spec.Template.Labels["app.kubernetes.io/version"])
+
+			// Canary pods carry the service selector labels, so they share the same membership ring.
+			for k, v := range metadata.LabelsSelector(cluster, "history") {
+				assert.Equal(tt, v, canary.Spec.Template.Labels[k])
+			}
+
+			// Canary pods are not selected by the stable workload.
+			stableSelector, err := metav1.LabelSelectorAsSelector(stable.Spec.Selector)
+			require.NoError(tt, err)
+			assert.True(tt, stableSelector.Matches(labels.Set(stable.Spec.Template.Labels)))
+			assert.False(tt, stableSelector.Matches(labels.Set(canary.Spec.Template.Labels)))
+		})
+	}
+}
+
+func TestCanaryDeploymentBuilderStableSelector(t *testing.T) {
+	cluster := &v1beta1.TemporalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "demo",
+		},
+		Spec: v1beta1.TemporalClusterSpec{
+			Persistence: v1beta1.TemporalPersistenceSpec{
+				DefaultStore: &v1beta1.DatastoreSpec{
+					SQL: &v1beta1.SQLSpec{PluginName: "postgres12"},
+				},
+				VisibilityStore: &v1beta1.DatastoreSpec{
+					SQL: &v1beta1.SQLSpec{PluginName: "postgres12"},
+				},
+			},
+		},
+	}
+	cluster.Default()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	// Deployments created before pods had a track label keep their selector, as it's immutable.
+	existing := &metav1.LabelSelector{
+		MatchLabels: metadata.LabelsSelector(cluster, "history"),
+	}
+
+	builder := base.NewDeploymentBuilder("history", cluster, scheme, cluster.Spec.Services.History, "hash", "", "", "", false)
+	object := builder.Build()
+	object.(*appsv1.Deployment).Spec.Selector = existing.DeepCopy()
+	require.NoError(t, builder.Update(object))
+
+	deployment := object.(*appsv1.Deployment)
+	assert.Equal(t, existing, deployment.Spec.Selector)
+	assert.Equal(t, "stable", deployment.Spec.Template.Labels["app.kubernetes.io/track"])
+}
+
+func TestCanaryAutoscalingMinReplicas(t *testing.T) {
+	tests := map[string]struct {
+		minReplicas         *int32
+		canary              *v1beta1.ServiceCanarySpec
+		expectedMinReplicas int32
+	}{
+		"no canary": {
+			minReplicas:         ptr.To[int32](4),
+			expectedMinReplicas: 4,
+		},
+		"observed canary is taken from min replicas": {
+			minReplicas: ptr.To[int32](4),
+			canary: &v1beta1.ServiceCanarySpec{
+				Replicas: ptr.To[int32](2),
+			},
+			expectedMinReplicas: 2,
+		},
+		"at least one stable pod remains": {
+			canary:              &v1beta1.ServiceCanarySpec{},
+			expectedMinReplicas: 1,
+		},
+		"promoting canary": {
+			minReplicas: ptr.To[int32](4),
+			canary: &v1beta1.ServiceCanarySpec{
+				Action: v1beta1.PromoteCanaryAction,
+			},
+			expectedMinReplicas: 4,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+			}
+			cluster.Default()
+
+			scheme := runtime.NewScheme()
+			require.NoError(tt, v1beta1.AddToScheme(scheme))
+
+			service := cluster.Spec.Services.History
+			service.Autoscaling = &v1beta1.AutoscalingSpec{
+				MinReplicas: test.minReplicas,
+				MaxReplicas: 10,
+			}
+			service.Canary = test.canary
+
+			builder := base.NewHorizontalPodAutoscalerBuilder("history", cluster, scheme, service, false)
+			require.True(tt, builder.Enabled())
+
+			object := builder.Build()
+			require.NoError(tt, builder.Update(object))
+
+			hpa := object.(*autoscalingv2.HorizontalPodAutoscaler)
+			assert.Equal(tt, test.expectedMinReplicas, *hpa.Spec.MinReplicas)
+			assert.Equal(tt, int32(10), hpa.Spec.MaxReplicas)
+		})
+	}
+}
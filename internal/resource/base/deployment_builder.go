@@ -26,6 +26,7 @@ import (
 	"github.com/alexandrevilain/temporal-operator/internal/metadata"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/meta"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/certmanager"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/spire"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/persistence"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/prometheus"
 	"github.com/alexandrevilain/temporal-operator/pkg/kubernetes"
@@ -34,7 +35,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -43,20 +43,31 @@ import (
 var _ resource.Builder = (*DeploymentBuilder)(nil)
 
 type DeploymentBuilder struct {
-	serviceName string
-	instance    *v1beta1.TemporalCluster
-	scheme      *runtime.Scheme
-	service     *v1beta1.ServiceSpec
-	configHash  string
+	serviceName  string
+	instance     *v1beta1.TemporalCluster
+	scheme       *runtime.Scheme
+	service      *v1beta1.ServiceSpec
+	configHash   string
+	caBundleHash string
+	// datastoreSecretsHash is the hash of the datastore secrets the pods should be rolled out with.
+	datastoreSecretsHash string
+	// authProxyImage is the image of the auth proxy sidecars of datastores authenticating with tokens.
+	authProxyImage string
+	// migrating is true when the service workload kind changed and the new workload isn't ready yet.
+	migrating bool
 }
 
-func NewDeploymentBuilder(serviceName string, instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, service *v1beta1.ServiceSpec, configHash string) *DeploymentBuilder {
+func NewDeploymentBuilder(serviceName string, instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, service *v1beta1.ServiceSpec, configHash, caBundleHash, datastoreSecretsHash, authProxyImage string, migrating bool) *DeploymentBuilder {
 	return &DeploymentBuilder{
-		serviceName: serviceName,
-		instance:    instance,
-		scheme:      scheme,
-		service:     service,
-		configHash:  configHash,
+		serviceName:          serviceName,
+		instance:             instance,
+		scheme:               scheme,
+		service:              service,
+		configHash:           configHash,
+		caBundleHash:         caBundleHash,
+		datastoreSecretsHash: datastoreSecretsHash,
+		authProxyImage:       authProxyImage,
+		migrating:            migrating,
 	}
 }
 
@@ -65,44 +76,85 @@ func (b *DeploymentBuilder) Build() client.Object {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        b.instance.ChildResourceName(b.serviceName),
 			Namespace:   b.instance.Namespace,
-			Labels:      metadata.GetLabels(b.instance, b.serviceName, b.instance.Spec.Version, b.instance.Labels),
+			Labels:      metadata.GetLabels(b.instance, b.serviceName, b.instance.ServiceVersion(b.service), b.instance.Labels),
 			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
 		},
 	}
 }
 
 func (b *DeploymentBuilder) Enabled() bool {
-	return isBuilderEnabled(b.instance, b.serviceName)
+	// The deployment is kept while the service is migrated to a StatefulSet.
+	return isBuilderEnabled(b.instance, b.serviceName) &&
+		(b.service.GetWorkloadKind() == v1beta1.DeploymentWorkloadKind || b.migrating)
 }
 
 func (b *DeploymentBuilder) Update(object client.Object) error {
 	deployment := object.(*appsv1.Deployment)
 	deployment.Labels = metadata.Merge(
 		object.GetLabels(),
-		metadata.GetLabels(b.instance, b.serviceName, b.instance.Spec.Version, b.instance.Labels),
+		metadata.GetLabels(b.instance, b.serviceName, b.instance.ServiceVersion(b.service), b.instance.Labels),
 	)
 	deployment.Annotations = metadata.Merge(
 		object.GetAnnotations(),
 		metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
 	)
 
-	// worker has no grpc endpoint so omit liveness probe
-	var livenessProbe *corev1.Probe
-	if b.serviceName != string(primitives.WorkerService) {
-		livenessProbe = &corev1.Probe{
-			InitialDelaySeconds: 150,
-			TimeoutSeconds:      1,
-			PeriodSeconds:       10,
-			SuccessThreshold:    1,
-			FailureThreshold:    3,
-			ProbeHandler: corev1.ProbeHandler{
-				TCPSocket: &corev1.TCPSocketAction{
-					Port: intstr.FromString("rpc"),
-				},
-			},
+	// When the service is autoscaled, replicas are managed by the autoscaler.
+	// Only set them when creating the deployment.
+	if b.service.Autoscaling == nil {
+		deployment.Spec.Replicas = stableReplicas(b.service)
+	} else if deployment.Spec.Replicas == nil {
+		deployment.Spec.Replicas = stableMinReplicas(b.service)
+	}
+
+	deployment.Spec.Selector = stableSelector(b.instance, b.serviceName, deployment.Spec.Selector)
+
+	template, err := b.podTemplateSpec()
+	if err != nil {
+		return err
+	}
+	template.Labels = metadata.Merge(template.Labels, metadata.TrackLabels(metadata.StableTrack))
+	deployment.Spec.Template = template
+
+	if b.instance.Spec.Services.Overrides != nil && b.instance.Spec.Services.Overrides.Deployment != nil {
+		err := kubernetes.ApplyDeploymentOverrides(deployment, b.instance.Spec.Services.Overrides.Deployment)
+		if err != nil {
+			return fmt.Errorf("can't apply deployment overrides: %w", err)
 		}
 	}
 
+	if b.service.Overrides != nil && b.service.Overrides.Deployment != nil {
+		err := kubernetes.ApplyDeploymentOverrides(deployment, b.service.Overrides.Deployment)
+		if err != nil {
+			return fmt.Errorf("failed applying deployment overrides: %w", err)
+		}
+	}
+
+	// Once promoted, the canary template is applied to the stable pods.
+	if b.service.Canary.IsPromoted() && b.service.Canary.Template != nil {
+		err := kubernetes.ApplyPodTemplateSpecOverrides(&deployment.Spec.Template, b.service.Canary.Template)
+		if err != nil {
+			return fmt.Errorf("failed applying canary template overrides: %w", err)
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, deployment, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
+
+// podTemplateSpec returns the pod template of the service.
+// It's shared by the service Deployment and StatefulSet.
+func (b *DeploymentBuilder) podTemplateSpec() (corev1.PodTemplateSpec, error) {
+	livenessProbe, readinessProbe, startupProbe := b.probes()
+
+	terminationGracePeriodSeconds, err := b.terminationGracePeriodSeconds()
+	if err != nil {
+		return corev1.PodTemplateSpec{}, fmt.Errorf("failed computing termination grace period: %w", err)
+	}
+
 	envVars := []corev1.EnvVar{
 		{
 			Name: "POD_IP",
@@ -149,7 +201,7 @@ func (b *DeploymentBuilder) Update(object client.Object) error {
 
 	volumes = append(volumes, persistence.GetDatastoresVolumes(datastores)...)
 
-	if b.instance.Spec.DynamicConfig != nil {
+	if b.instance.GetDynamicConfig() != nil {
 		volumes = append(volumes, corev1.Volume{
 			Name: "dynamicconfig",
 			VolumeSource: corev1.VolumeSource{
@@ -217,11 +269,20 @@ func (b *DeploymentBuilder) Update(object client.Object) error {
 		}
 	}
 
-	if b.instance.MTLSWithCertManagerEnabled() {
+	if b.instance.MTLSWithCertificatesEnabled() {
+		// The CA bundle is mounted in place of the intermediate CAs secrets: it holds the trusted root CAs,
+		// allowing the previous and the new root CA to be trusted at the same time during a CA rotation.
+		if b.instance.Spec.MTLS.InternodeEnabled() || b.instance.Spec.MTLS.FrontendEnabled() {
+			volumes = append(volumes, corev1.Volume{
+				Name:         certmanager.CABundle,
+				VolumeSource: certmanager.GetCABundleVolumeSource(b.instance),
+			})
+		}
+
 		if b.instance.Spec.MTLS.InternodeEnabled() {
 			volumeMounts = append(volumeMounts,
 				corev1.VolumeMount{
-					Name:      certmanager.InternodeIntermediateCACertificate,
+					Name:      certmanager.CABundle,
 					MountPath: b.instance.Spec.MTLS.Internode.GetIntermediateCACertificateMountPath(),
 				},
 				corev1.VolumeMount{
@@ -231,15 +292,6 @@ func (b *DeploymentBuilder) Update(object client.Object) error {
 			)
 
 			volumes = append(volumes,
-				corev1.Volume{
-					Name: certmanager.InternodeIntermediateCACertificate,
-					VolumeSource: corev1.VolumeSource{
-						Secret: &corev1.SecretVolumeSource{
-							SecretName:  b.instance.ChildResourceName(certmanager.InternodeIntermediateCACertificate),
-							DefaultMode: ptr.To[int32](corev1.SecretVolumeSourceDefaultMode),
-						},
-					},
-				},
 				corev1.Volume{
 					Name: certmanager.InternodeCertificate,
 					VolumeSource: corev1.VolumeSource{
@@ -254,7 +306,7 @@ func (b *DeploymentBuilder) Update(object client.Object) error {
 		if b.instance.Spec.MTLS.FrontendEnabled() {
 			volumeMounts = append(volumeMounts,
 				corev1.VolumeMount{
-					Name:      certmanager.FrontendIntermediateCACertificate,
+					Name:      certmanager.CABundle,
 					MountPath: b.instance.Spec.MTLS.Frontend.GetIntermediateCACertificateMountPath(),
 				},
 				corev1.VolumeMount{
@@ -264,15 +316,6 @@ func (b *DeploymentBuilder) Update(object client.Object) error {
 			)
 
 			volumes = append(volumes,
-				corev1.Volume{
-					Name: certmanager.FrontendIntermediateCACertificate,
-					VolumeSource: corev1.VolumeSource{
-						Secret: &corev1.SecretVolumeSource{
-							SecretName:  b.instance.ChildResourceName(certmanager.FrontendIntermediateCACertificate),
-							DefaultMode: ptr.To[int32](corev1.SecretVolumeSourceDefaultMode),
-						},
-					},
-				},
 				corev1.Volume{
 					Name: certmanager.FrontendCertificate,
 					VolumeSource: corev1.VolumeSource{
@@ -303,6 +346,19 @@ func (b *DeploymentBuilder) Update(object client.Object) error {
 		}
 	}
 
+	// Auth proxies come first so they're listening before the other init containers and the service start.
+	initContainers := append(persistence.GetAuthProxyContainers(b.instance, b.authProxyImage), b.service.InitContainers...)
+	volumes = append(volumes, persistence.GetAuthProxyVolumes(b.instance)...)
+	sidecarContainers := []corev1.Container{}
+
+	if b.instance.MTLSWithSpireEnabled() {
+		volumes = append(volumes, spire.GetVolumes(b.instance)...)
+		volumeMounts = append(volumeMounts, spire.GetCertificatesVolumeMount())
+		// The SVID has to be written before the service starts, then kept up-to-date by the sidecar.
+		initContainers = append([]corev1.Container{spire.GetHelperInitContainer(b.instance)}, initContainers...)
+		sidecarContainers = append(sidecarContainers, spire.GetHelperSidecarContainer(b.instance))
+	}
+
 	containerPorts := []corev1.ContainerPort{
 		{
 			Name:          "rpc",
@@ -334,22 +390,19 @@ func (b *DeploymentBuilder) Update(object client.Object) error {
 		})
 	}
 
-	deployment.Spec.Replicas = b.service.Replicas
-
-	deployment.Spec.Selector = &metav1.LabelSelector{
-		MatchLabels: metadata.LabelsSelector(b.instance, b.serviceName),
-	}
+	objectMeta := meta.BuildPodObjectMeta(b.instance, b.serviceName, b.instance.ServiceVersion(b.service), b.configHash, b.caBundleHash, b.datastoreSecretsHash)
+	objectMeta.Labels = metadata.Merge(persistence.GetDatastoresPodLabels(datastores), objectMeta.Labels)
 
-	deployment.Spec.Template = corev1.PodTemplateSpec{
-		ObjectMeta: meta.BuildPodObjectMeta(b.instance, b.serviceName, b.configHash),
+	return corev1.PodTemplateSpec{
+		ObjectMeta: objectMeta,
 		Spec: corev1.PodSpec{
 			ServiceAccountName:       b.instance.ChildResourceName(b.serviceName),
 			DeprecatedServiceAccount: b.instance.ChildResourceName(b.serviceName),
 			ImagePullSecrets:         b.instance.Spec.ImagePullSecrets,
-			Containers: []corev1.Container{
+			Containers: append([]corev1.Container{
 				{
 					Name:                     "service", // name "service" is here to simplify overrides
-					Image:                    fmt.Sprintf("%s:%s", b.instance.Spec.Image, b.instance.Spec.Version),
+					Image:                    b.instance.ServiceImage(b.service),
 					ImagePullPolicy:          corev1.PullIfNotPresent,
 					Resources:                b.service.Resources,
 					TerminationMessagePath:   corev1.TerminationMessagePathDefault,
@@ -360,17 +413,25 @@ func (b *DeploymentBuilder) Update(object client.Object) error {
 							Drop: []corev1.Capability{"ALL"},
 						},
 					},
-					Ports:         containerPorts,
-					LivenessProbe: livenessProbe,
-					Env:           envVars,
-					VolumeMounts:  volumeMounts,
+					Ports:          containerPorts,
+					LivenessProbe:  livenessProbe,
+					ReadinessProbe: readinessProbe,
+					StartupProbe:   startupProbe,
+					Lifecycle:      b.lifecycle(),
+					Env:            envVars,
+					VolumeMounts:   volumeMounts,
 				},
-			},
-			InitContainers:                b.service.InitContainers,
+			}, sidecarContainers...),
+			InitContainers:                initContainers,
 			RestartPolicy:                 corev1.RestartPolicyAlways,
-			TerminationGracePeriodSeconds: ptr.To[int64](30),
+			TerminationGracePeriodSeconds: ptr.To(terminationGracePeriodSeconds),
 			DNSPolicy:                     corev1.DNSClusterFirst,
 			SchedulerName:                 corev1.DefaultSchedulerName,
+			TopologySpreadConstraints:     b.topologySpreadConstraints(),
+			Affinity:                      b.service.Affinity,
+			NodeSelector:                  b.service.NodeSelector,
+			Tolerations:                   b.service.Tolerations,
+			PriorityClassName:             b.service.PriorityClassName,
 			SecurityContext: &corev1.PodSecurityContext{
 				RunAsUser:    ptr.To[int64](1000),
 				RunAsGroup:   ptr.To[int64](1000),
@@ -379,25 +440,29 @@ func (b *DeploymentBuilder) Update(object client.Object) error {
 			},
 			Volumes: volumes,
 		},
-	}
+	}, nil
+}
 
-	if b.instance.Spec.Services.Overrides != nil && b.instance.Spec.Services.Overrides.Deployment != nil {
-		err := kubernetes.ApplyDeploymentOverrides(deployment, b.instance.Spec.Services.Overrides.Deployment)
-		if err != nil {
-			return fmt.Errorf("can't apply deployment overrides: %w", err)
-		}
+// topologySpreadConstraints returns the service pods topology spread constraints.
+// Constraints without label selector select the service pods.
+// No constraint is added by default, so that existing pods aren't rolled out on upgrade.
+func (b *DeploymentBuilder) topologySpreadConstraints() []corev1.TopologySpreadConstraint {
+	if len(b.service.TopologySpreadConstraints) == 0 {
+		return nil
 	}
 
-	if b.service.Overrides != nil && b.service.Overrides.Deployment != nil {
-		err := kubernetes.ApplyDeploymentOverrides(deployment, b.service.Overrides.Deployment)
-		if err != nil {
-			return fmt.Errorf("failed applying deployment overrides: %w", err)
-		}
+	selector := &metav1.LabelSelector{
+		MatchLabels: metadata.LabelsSelector(b.instance, b.serviceName),
 	}
 
-	if err := controllerutil.SetControllerReference(b.instance, deployment, b.scheme); err != nil {
-		return fmt.Errorf("failed setting controller reference: %w", err)
+	constraints := make([]corev1.TopologySpreadConstraint, 0, len(b.service.TopologySpreadConstraints))
+	for _, constraint := range b.service.TopologySpreadConstraints {
+		constraint := *constraint.DeepCopy()
+		if constraint.LabelSelector == nil {
+			constraint.LabelSelector = selector.DeepCopy()
+		}
+		constraints = append(constraints, constraint)
 	}
 
-	return nil
+	return constraints
 }
@@ -0,0 +1,414 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/base"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/meta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/server/common/primitives"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestDeploymentBuilderShutdown(t *testing.T) {
+	tests := map[string]struct {
+		serviceName                    string
+		dynamicConfig                  *v1beta1.DynamicConfigSpec
+		shutdown                       *v1beta1.ServiceShutdownSpec
+		mTLS                           *v1beta1.MTLSSpec
+		expectedGRPCReadiness          bool
+		expectedPreStop                []string
+		expectedTerminationGracePeriod int64
+	}{
+		"history defaults": {
+			serviceName:                    "history",
+			expectedGRPCReadiness:          true,
+			expectedPreStop:                []string{"sleep", "5"},
+			expectedTerminationGracePeriod: 35,
+		},
+		"frontend with drain settings": {
+			serviceName: "frontend",
+			dynamicConfig: &v1beta1.DynamicConfigSpec{
+				Values: map[string][]v1beta1.ConstrainedValue{
+					"frontend.shutdownDrainDuration": {
+						{Value: &apiextensionsv1.JSON{Raw: []byte(`"30s"`)}},
+					},
+					"frontend.shutdownFailHealthCheckDuration": {
+						{Value: &apiextensionsv1.JSON{Raw: []byte(`10`)}},
+					},
+				},
+			},
+			expectedGRPCReadiness:          true,
+			expectedPreStop:                []string{"sleep", "5"},
+			expectedTerminationGracePeriod: 75,
+		},
+		"matching with overridden shutdown": {
+			serviceName: "matching",
+			shutdown: &v1beta1.ServiceShutdownSpec{
+				PreStopDelay:           &metav1.Duration{},
+				TerminationGracePeriod: &metav1.Duration{Duration: 2 * time.Minute},
+			},
+			expectedGRPCReadiness:          true,
+			expectedTerminationGracePeriod: 120,
+		},
+		"internode mTLS uses tcp probes": {
+			serviceName: "history",
+			mTLS: &v1beta1.MTLSSpec{
+				Provider:  v1beta1.CertManagerMTLSProvider,
+				Internode: &v1beta1.InternodeMTLSSpec{Enabled: true},
+			},
+			expectedPreStop:                []string{"sleep", "5"},
+			expectedTerminationGracePeriod: 35,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					DynamicConfig: test.dynamicConfig,
+					MTLS:          test.mTLS,
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{PluginName: "postgres12"},
+						},
+						VisibilityStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{PluginName: "postgres12"},
+						},
+					},
+				},
+			}
+			cluster.Default()
+
+			scheme := runtime.NewScheme()
+			require.NoError(tt, v1beta1.AddToScheme(scheme))
+
+			service, err := cluster.Spec.Services.GetServiceSpec(primitives.ServiceName(test.serviceName))
+			require.NoError(tt, err)
+			service.Shutdown = test.shutdown
+
+			builder := base.NewDeploymentBuilder(test.serviceName, cluster, scheme, service, "", "", "", "", false)
+			object := builder.Build()
+			require.NoError(tt, builder.Update(object))
+
+			pod := object.(*appsv1.Deployment).Spec.Template.Spec
+			container := pod.Containers[0]
+
+			require.NotNil(tt, container.ReadinessProbe)
+			require.NotNil(tt, container.StartupProbe)
+			assert.Equal(tt, test.expectedGRPCReadiness, container.ReadinessProbe.GRPC != nil)
+			assert.Equal(tt, test.expectedGRPCReadiness, container.ReadinessProbe.TCPSocket == nil)
+
+			if test.expectedPreStop == nil {
+				assert.Nil(tt, container.Lifecycle)
+			} else {
+				require.NotNil(tt, container.Lifecycle)
+				assert.Equal(tt, test.expectedPreStop, container.Lifecycle.PreStop.Exec.Command)
+			}
+
+			require.NotNil(tt, pod.TerminationGracePeriodSeconds)
+			assert.Equal(tt, test.expectedTerminationGracePeriod, *pod.TerminationGracePeriodSeconds)
+		})
+	}
+}
+
+func TestDeploymentBuilderDatastoreSecretsHash(t *testing.T) {
+	tests := map[string]struct {
+		datastoreSecretsHash string
+		expectedAnnotation   bool
+	}{
+		"hash is set on pods": {
+			datastoreSecretsHash: "abc",
+			expectedAnnotation:   true,
+		},
+		"empty hash is omitted": {
+			datastoreSecretsHash: "",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{PluginName: "postgres12"},
+						},
+						VisibilityStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{PluginName: "postgres12"},
+						},
+					},
+				},
+			}
+			cluster.Default()
+
+			scheme := runtime.NewScheme()
+			require.NoError(tt, v1beta1.AddToScheme(scheme))
+
+			service, err := cluster.Spec.Services.GetServiceSpec(primitives.HistoryService)
+			require.NoError(tt, err)
+
+			builder := base.NewDeploymentBuilder("history", cluster, scheme, service, "hash", "", test.datastoreSecretsHash, "", false)
+			object := builder.Build()
+			require.NoError(tt, builder.Update(object))
+
+			annotations := object.(*appsv1.Deployment).Spec.Template.Annotations
+			value, ok := annotations[meta.DatastoreSecretsHashKey]
+			assert.Equal(tt, test.expectedAnnotation, ok)
+			assert.Equal(tt, test.datastoreSecretsHash, value)
+		})
+	}
+}
+
+func TestDeploymentBuilderTopologySpreadConstraints(t *testing.T) {
+	tests := map[string]struct {
+		constraints         []corev1.TopologySpreadConstraint
+		expectedConstraints []corev1.TopologySpreadConstraint
+	}{
+		"no default constraint": {
+			expectedConstraints: nil,
+		},
+		"service selector is set when missing": {
+			constraints: []corev1.TopologySpreadConstraint{
+				{
+					MaxSkew:           1,
+					TopologyKey:       corev1.LabelTopologyZone,
+					WhenUnsatisfiable: corev1.DoNotSchedule,
+				},
+			},
+			expectedConstraints: []corev1.TopologySpreadConstraint{
+				{
+					MaxSkew:           1,
+					TopologyKey:       corev1.LabelTopologyZone,
+					WhenUnsatisfiable: corev1.DoNotSchedule,
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"app.kubernetes.io/name":      "test",
+							"app.kubernetes.io/component": "history",
+							"app.kubernetes.io/part-of":   "temporal",
+						},
+					},
+				},
+			},
+		},
+		"user selector is kept": {
+			constraints: []corev1.TopologySpreadConstraint{
+				{
+					MaxSkew:           2,
+					TopologyKey:       corev1.LabelHostname,
+					WhenUnsatisfiable: corev1.ScheduleAnyway,
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": "custom"},
+					},
+				},
+			},
+			expectedConstraints: []corev1.TopologySpreadConstraint{
+				{
+					MaxSkew:           2,
+					TopologyKey:       corev1.LabelHostname,
+					WhenUnsatisfiable: corev1.ScheduleAnyway,
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": "custom"},
+					},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{PluginName: "postgres12"},
+						},
+						VisibilityStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{PluginName: "postgres12"},
+						},
+					},
+				},
+			}
+			cluster.Default()
+
+			scheme := runtime.NewScheme()
+			require.NoError(tt, v1beta1.AddToScheme(scheme))
+
+			service, err := cluster.Spec.Services.GetServiceSpec(primitives.HistoryService)
+			require.NoError(tt, err)
+			service.TopologySpreadConstraints = test.constraints
+
+			builder := base.NewDeploymentBuilder("history", cluster, scheme, service, "hash", "", "", "", false)
+			object := builder.Build()
+			require.NoError(tt, builder.Update(object))
+
+			assert.Equal(tt, test.expectedConstraints, object.(*appsv1.Deployment).Spec.Template.Spec.TopologySpreadConstraints)
+		})
+	}
+}
+
+func TestDeploymentBuilderAuthProxy(t *testing.T) {
+	tests := map[string]struct {
+		auth                   *v1beta1.SQLAuthSpec
+		expectedInitContainers []string
+		expectedLabels         map[string]string
+	}{
+		"no auth proxy without token authentication": {
+			expectedInitContainers: []string{},
+		},
+		"aws iam adds the auth proxy": {
+			auth: &v1beta1.SQLAuthSpec{
+				AWSIAM: &v1beta1.SQLAWSIAMAuthSpec{Region: "eu-west-1", Role: "arn:aws:iam::123456789012:role/temporal"},
+			},
+			expectedInitContainers: []string{"default-auth-proxy"},
+		},
+		"azure ad opts pods in workload identity": {
+			auth: &v1beta1.SQLAuthSpec{
+				AzureAD: &v1beta1.SQLAzureADAuthSpec{ClientID: "client"},
+			},
+			expectedInitContainers: []string{"default-auth-proxy"},
+			expectedLabels:         map[string]string{"azure.workload.identity/use": "true"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							Name: "default",
+							SQL: &v1beta1.SQLSpec{
+								PluginName:  "postgres12",
+								ConnectAddr: "db.example.com:5432",
+								Auth:        test.auth,
+							},
+							TLS: &v1beta1.DatastoreTLSSpec{Enabled: true},
+						},
+						VisibilityStore: &v1beta1.DatastoreSpec{
+							Name: "visibility",
+							SQL:  &v1beta1.SQLSpec{PluginName: "postgres12"},
+						},
+					},
+				},
+			}
+			cluster.Default()
+
+			scheme := runtime.NewScheme()
+			require.NoError(tt, v1beta1.AddToScheme(scheme))
+
+			service, err := cluster.Spec.Services.GetServiceSpec(primitives.HistoryService)
+			require.NoError(tt, err)
+
+			builder := base.NewDeploymentBuilder("history", cluster, scheme, service, "hash", "", "", "operator:test", false)
+			object := builder.Build()
+			require.NoError(tt, builder.Update(object))
+
+			template := object.(*appsv1.Deployment).Spec.Template
+
+			names := []string{}
+			for _, container := range template.Spec.InitContainers {
+				names = append(names, container.Name)
+				assert.Equal(tt, "operator:test", container.Image)
+				assert.Equal(tt, corev1.ContainerRestartPolicyAlways, *container.RestartPolicy)
+				assert.Contains(tt, container.Command, "127.0.0.1:15432")
+				assert.Equal(tt, []string{"default"}, container.Args)
+			}
+			assert.Equal(tt, test.expectedInitContainers, names)
+
+			for key, value := range test.expectedLabels {
+				assert.Equal(tt, value, template.Labels[key])
+			}
+		})
+	}
+}
+
+func TestDeploymentBuilderElasticsearchAuthProxy(t *testing.T) {
+	cluster := &v1beta1.TemporalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "demo",
+		},
+		Spec: v1beta1.TemporalClusterSpec{
+			Persistence: v1beta1.TemporalPersistenceSpec{
+				DefaultStore: &v1beta1.DatastoreSpec{
+					Name: "default",
+					SQL:  &v1beta1.SQLSpec{PluginName: "postgres12"},
+				},
+				VisibilityStore: &v1beta1.DatastoreSpec{
+					Name: "visibility",
+					Elasticsearch: &v1beta1.ElasticsearchSpec{
+						URL: "https://es.example.com:9243",
+						Auth: &v1beta1.ElasticsearchAuthSpec{
+							APIKey: &v1beta1.ElasticsearchAPIKeyAuthSpec{
+								SecretRef: v1beta1.SecretKeyReference{Name: "es-api-key"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	cluster.Default()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	service, err := cluster.Spec.Services.GetServiceSpec(primitives.HistoryService)
+	require.NoError(t, err)
+
+	builder := base.NewDeploymentBuilder("history", cluster, scheme, service, "hash", "", "", "operator:test", false)
+	object := builder.Build()
+	require.NoError(t, builder.Update(object))
+
+	template := object.(*appsv1.Deployment).Spec.Template
+	require.Len(t, template.Spec.InitContainers, 1)
+
+	container := template.Spec.InitContainers[0]
+	assert.Equal(t, "visibility-auth-proxy", container.Name)
+	assert.Contains(t, container.Command, "127.0.0.1:15433")
+	assert.Equal(t, []string{"visibility"}, container.Args)
+	require.Len(t, container.Env, 1)
+	assert.Equal(t, "TEMPORAL_VISIBILITY_DATASTORE_API_KEY", container.Env[0].Name)
+	assert.Equal(t, "es-api-key", container.Env[0].ValueFrom.SecretKeyRef.Name)
+}
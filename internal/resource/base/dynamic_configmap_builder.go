@@ -60,14 +60,14 @@ func (b *DynamicConfigmapBuilder) Build() client.Object {
 }
 
 func (b *DynamicConfigmapBuilder) Enabled() bool {
-	return b.instance.Spec.DynamicConfig != nil
+	return b.instance.GetDynamicConfig() != nil
 }
 
 func (b *DynamicConfigmapBuilder) Update(object client.Object) error {
 	configMap := object.(*corev1.ConfigMap)
 
 	currentValues := config.YamlDynamicConfig{}
-	expectedValues, err := config.DynamicConfigToYamlDynamicConfig(b.instance.Spec.DynamicConfig)
+	expectedValues, err := config.DynamicConfigToYamlDynamicConfig(b.instance.GetDynamicConfig())
 	if err != nil {
 		return fmt.Errorf("failed computing expected dynamic config: %w", err)
 	}
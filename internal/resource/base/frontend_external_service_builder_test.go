@@ -0,0 +1,151 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/base"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+func TestFrontendExternalServiceBuilder(t *testing.T) {
+	tests := map[string]struct {
+		expose              *v1beta1.FrontendExposeSpec
+		httpPort            *int32
+		existingPorts       []corev1.ServicePort
+		expectedEnabled     bool
+		expectedType        corev1.ServiceType
+		expectedPorts       []corev1.ServicePort
+		expectedAnnotations map[string]string
+	}{
+		"disabled without expose": {
+			expectedEnabled: false,
+		},
+		"disabled without service": {
+			expose: &v1beta1.FrontendExposeSpec{
+				Ingress: &v1beta1.FrontendIngressSpec{},
+			},
+			expectedEnabled: false,
+		},
+		"defaults to load balancer": {
+			expose: &v1beta1.FrontendExposeSpec{
+				Service: &v1beta1.FrontendExternalServiceSpec{},
+			},
+			expectedEnabled: true,
+			expectedType:    corev1.ServiceTypeLoadBalancer,
+			expectedPorts: []corev1.ServicePort{
+				{Name: "grpc-rpc", Protocol: corev1.ProtocolTCP, Port: 7233, TargetPort: intstr.FromString("rpc")},
+				{Name: "http", Protocol: corev1.ProtocolTCP, Port: 7243, TargetPort: intstr.FromString("http")},
+			},
+		},
+		"node port with annotations": {
+			expose: &v1beta1.FrontendExposeSpec{
+				Service: &v1beta1.FrontendExternalServiceSpec{
+					Type:        corev1.ServiceTypeNodePort,
+					NodePort:    ptr.To[int32](30233),
+					Annotations: map[string]string{"external-dns.alpha.kubernetes.io/hostname": "temporal.example.com"},
+				},
+			},
+			expectedEnabled: true,
+			expectedType:    corev1.ServiceTypeNodePort,
+			expectedPorts: []corev1.ServicePort{
+				{Name: "grpc-rpc", Protocol: corev1.ProtocolTCP, Port: 7233, TargetPort: intstr.FromString("rpc"), NodePort: 30233},
+				{Name: "http", Protocol: corev1.ProtocolTCP, Port: 7243, TargetPort: intstr.FromString("http")},
+			},
+			expectedAnnotations: map[string]string{"external-dns.alpha.kubernetes.io/hostname": "temporal.example.com"},
+		},
+		"keeps allocated node ports": {
+			expose: &v1beta1.FrontendExposeSpec{
+				Service: &v1beta1.FrontendExternalServiceSpec{
+					Type: corev1.ServiceTypeNodePort,
+				},
+			},
+			existingPorts: []corev1.ServicePort{
+				{Name: "grpc-rpc", NodePort: 31000},
+				{Name: "http", NodePort: 31001},
+			},
+			expectedEnabled: true,
+			expectedType:    corev1.ServiceTypeNodePort,
+			expectedPorts: []corev1.ServicePort{
+				{Name: "grpc-rpc", Protocol: corev1.ProtocolTCP, Port: 7233, TargetPort: intstr.FromString("rpc"), NodePort: 31000},
+				{Name: "http", Protocol: corev1.ProtocolTCP, Port: 7243, TargetPort: intstr.FromString("http"), NodePort: 31001},
+			},
+		},
+		"no http port when disabled": {
+			expose: &v1beta1.FrontendExposeSpec{
+				Service: &v1beta1.FrontendExternalServiceSpec{},
+			},
+			httpPort:        ptr.To[int32](0),
+			expectedEnabled: true,
+			expectedType:    corev1.ServiceTypeLoadBalancer,
+			expectedPorts: []corev1.ServicePort{
+				{Name: "grpc-rpc", Protocol: corev1.ProtocolTCP, Port: 7233, TargetPort: intstr.FromString("rpc")},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Services: &v1beta1.ServicesSpec{
+						Frontend: &v1beta1.FrontendServiceSpec{
+							ServiceSpec: v1beta1.ServiceSpec{HTTPPort: test.httpPort},
+							Expose:      test.expose,
+						},
+					},
+				},
+			}
+			cluster.Default()
+
+			scheme := runtime.NewScheme()
+			require.NoError(tt, v1beta1.AddToScheme(scheme))
+
+			builder := base.NewFrontendExternalServiceBuilder(cluster, scheme)
+			require.Equal(tt, test.expectedEnabled, builder.Enabled())
+			if !test.expectedEnabled {
+				return
+			}
+
+			object := builder.Build()
+			object.(*corev1.Service).Spec.Ports = test.existingPorts
+			require.NoError(tt, builder.Update(object))
+
+			service := object.(*corev1.Service)
+			assert.Equal(tt, "test-frontend-external", service.Name)
+			assert.Equal(tt, test.expectedType, service.Spec.Type)
+			assert.Equal(tt, test.expectedPorts, service.Spec.Ports)
+			assert.Equal(tt, "frontend", service.Spec.Selector["app.kubernetes.io/component"])
+			for key, value := range test.expectedAnnotations {
+				assert.Equal(tt, value, service.Annotations[key])
+			}
+		})
+	}
+}
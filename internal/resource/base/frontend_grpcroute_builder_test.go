@@ -0,0 +1,115 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/base"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestFrontendGRPCRouteBuilder(t *testing.T) {
+	tests := map[string]struct {
+		route           *v1beta1.FrontendRouteSpec
+		port            *int32
+		expectedEnabled bool
+		expectedPort    gatewayv1.PortNumber
+	}{
+		"disabled without route": {
+			expectedEnabled: false,
+		},
+		"routes to the frontend port": {
+			route: &v1beta1.FrontendRouteSpec{
+				ParentRefs: []gatewayv1.ParentReference{
+					{Name: "public", Namespace: ptr.To[gatewayv1.Namespace]("gateways")},
+				},
+				Hostnames: []gatewayv1.Hostname{"temporal.example.com"},
+			},
+			expectedEnabled: true,
+			expectedPort:    7233,
+		},
+		"custom frontend port and several parents": {
+			route: &v1beta1.FrontendRouteSpec{
+				ParentRefs: []gatewayv1.ParentReference{
+					{Name: "public"},
+					{Name: "internal", SectionName: ptr.To[gatewayv1.SectionName]("grpc")},
+				},
+				Hostnames:   []gatewayv1.Hostname{"temporal.example.com", "temporal.example.org"},
+				Annotations: map[string]string{"example.com/owner": "platform"},
+			},
+			port:            ptr.To[int32](8233),
+			expectedEnabled: true,
+			expectedPort:    8233,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Services: &v1beta1.ServicesSpec{
+						Frontend: &v1beta1.FrontendServiceSpec{
+							ServiceSpec: v1beta1.ServiceSpec{Port: test.port},
+						},
+					},
+				},
+			}
+			if test.route != nil {
+				cluster.Spec.Services.Frontend.Expose = &v1beta1.FrontendExposeSpec{GRPCRoute: test.route}
+			}
+			cluster.Default()
+
+			scheme := runtime.NewScheme()
+			require.NoError(tt, v1beta1.AddToScheme(scheme))
+
+			builder := base.NewFrontendGRPCRouteBuilder(cluster, scheme)
+			require.Equal(tt, test.expectedEnabled, builder.Enabled())
+			if !test.expectedEnabled {
+				return
+			}
+
+			object := builder.Build()
+			require.NoError(tt, builder.Update(object))
+
+			route := object.(*gatewayv1.GRPCRoute)
+			assert.Equal(tt, "test-frontend", route.Name)
+			assert.Equal(tt, test.route.ParentRefs, route.Spec.ParentRefs)
+			assert.Equal(tt, test.route.Hostnames, route.Spec.Hostnames)
+			for key, value := range test.route.Annotations {
+				assert.Equal(tt, value, route.Annotations[key])
+			}
+
+			require.Len(tt, route.Spec.Rules, 1)
+			require.Len(tt, route.Spec.Rules[0].BackendRefs, 1)
+			backend := route.Spec.Rules[0].BackendRefs[0].BackendRef
+			assert.Equal(tt, gatewayv1.ObjectName("test-frontend"), backend.Name)
+			assert.Equal(tt, test.expectedPort, *backend.Port)
+		})
+	}
+}
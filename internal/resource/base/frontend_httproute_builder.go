@@ -0,0 +1,80 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base
+
+import (
+	"fmt"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/meta"
+	"github.com/alexandrevilain/temporal-operator/pkg/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ resource.Builder = (*FrontendHTTPRouteBuilder)(nil)
+
+// FrontendHTTPRouteBuilder builds the Gateway API HTTPRoute to the frontend HTTP API port.
+type FrontendHTTPRouteBuilder struct {
+	instance *v1beta1.TemporalCluster
+	scheme   *runtime.Scheme
+}
+
+func NewFrontendHTTPRouteBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme) *FrontendHTTPRouteBuilder {
+	return &FrontendHTTPRouteBuilder{
+		instance: instance,
+		scheme:   scheme,
+	}
+}
+
+func (b *FrontendHTTPRouteBuilder) Build() client.Object {
+	return &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.ChildResourceName(meta.FrontendService),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, meta.FrontendService, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *FrontendHTTPRouteBuilder) Enabled() bool {
+	frontend := b.instance.Spec.Services.Frontend
+	return frontend.Expose != nil &&
+		frontend.Expose.HTTPRoute != nil &&
+		frontend.HTTPPort != nil && *frontend.HTTPPort > 0
+}
+
+func (b *FrontendHTTPRouteBuilder) Update(object client.Object) error {
+	route := object.(*gatewayv1.HTTPRoute)
+	frontend := b.instance.Spec.Services.Frontend
+
+	route.Annotations = metadata.Merge(object.GetAnnotations(), frontend.Expose.HTTPRoute.Annotations)
+	route.Spec = kubernetes.HTTPRouteSpec(frontend.Expose.HTTPRoute, b.instance.ChildResourceName(meta.FrontendService), *frontend.HTTPPort)
+
+	if err := controllerutil.SetControllerReference(b.instance, route, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base
+
+import (
+	"fmt"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/meta"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ resource.Builder = (*FrontendIngressBuilder)(nil)
+
+// FrontendIngressBuilder builds the Ingress routing gRPC traffic to the frontend.
+type FrontendIngressBuilder struct {
+	instance *v1beta1.TemporalCluster
+	scheme   *runtime.Scheme
+}
+
+func NewFrontendIngressBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme) *FrontendIngressBuilder {
+	return &FrontendIngressBuilder{
+		instance: instance,
+		scheme:   scheme,
+	}
+}
+
+func (b *FrontendIngressBuilder) Build() client.Object {
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.ChildResourceName(meta.FrontendService),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, meta.FrontendService, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *FrontendIngressBuilder) Enabled() bool {
+	expose := b.instance.Spec.Services.Frontend.Expose
+	return expose != nil && expose.Ingress != nil
+}
+
+// grpcAnnotations returns the ingress-nginx annotations required to proxy gRPC traffic to the frontend.
+func (b *FrontendIngressBuilder) grpcAnnotations() map[string]string {
+	if b.instance.Spec.MTLS != nil && b.instance.Spec.MTLS.FrontendEnabled() {
+		// The frontend authenticates clients using their certificate, so TLS can't be terminated by the ingress.
+		return map[string]string{
+			"nginx.ingress.kubernetes.io/backend-protocol": "GRPCS",
+			"nginx.ingress.kubernetes.io/ssl-passthrough":  "true",
+		}
+	}
+	return map[string]string{
+		"nginx.ingress.kubernetes.io/backend-protocol": "GRPC",
+	}
+}
+
+func (b *FrontendIngressBuilder) Update(object client.Object) error {
+	ingress := object.(*networkingv1.Ingress)
+	spec := b.instance.Spec.Services.Frontend.Expose.Ingress
+
+	ingress.Labels = object.GetLabels()
+	ingress.Annotations = metadata.Merge(object.GetAnnotations(), b.grpcAnnotations(), spec.Annotations)
+
+	rules := make([]networkingv1.IngressRule, 0, len(spec.Hosts))
+	for _, host := range spec.Hosts {
+		pathType := networkingv1.PathTypePrefix
+		rules = append(rules, networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: b.instance.ChildResourceName(meta.FrontendService),
+									Port: networkingv1.ServiceBackendPort{
+										Number: *b.instance.Spec.Services.Frontend.Port,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+
+	ingress.Spec = networkingv1.IngressSpec{
+		IngressClassName: spec.IngressClassName,
+		Rules:            rules,
+		TLS:              spec.TLS,
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, ingress, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/base"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+func TestFrontendIngressBuilder(t *testing.T) {
+	tests := map[string]struct {
+		ingress             *v1beta1.FrontendIngressSpec
+		mTLS                *v1beta1.MTLSSpec
+		expectedEnabled     bool
+		expectedAnnotations map[string]string
+		expectedHosts       []string
+	}{
+		"disabled without ingress": {
+			expectedEnabled: false,
+		},
+		"grpc backend without frontend mTLS": {
+			ingress: &v1beta1.FrontendIngressSpec{
+				IngressClassName: ptr.To("nginx"),
+				Hosts:            []string{"temporal.example.com", "temporal.example.org"},
+			},
+			expectedEnabled: true,
+			expectedAnnotations: map[string]string{
+				"nginx.ingress.kubernetes.io/backend-protocol": "GRPC",
+			},
+			expectedHosts: []string{"temporal.example.com", "temporal.example.org"},
+		},
+		"ssl passthrough with frontend mTLS": {
+			ingress: &v1beta1.FrontendIngressSpec{
+				Hosts: []string{"temporal.example.com"},
+			},
+			mTLS: &v1beta1.MTLSSpec{
+				Provider: v1beta1.CertManagerMTLSProvider,
+				Frontend: &v1beta1.FrontendMTLSSpec{Enabled: true},
+			},
+			expectedEnabled: true,
+			expectedAnnotations: map[string]string{
+				"nginx.ingress.kubernetes.io/backend-protocol": "GRPCS",
+				"nginx.ingress.kubernetes.io/ssl-passthrough":  "true",
+			},
+			expectedHosts: []string{"temporal.example.com"},
+		},
+		"user annotations override defaults": {
+			ingress: &v1beta1.FrontendIngressSpec{
+				Hosts: []string{"temporal.example.com"},
+				Annotations: map[string]string{
+					"nginx.ingress.kubernetes.io/backend-protocol": "GRPCS",
+					"cert-manager.io/cluster-issuer":               "letsencrypt",
+				},
+			},
+			expectedEnabled: true,
+			expectedAnnotations: map[string]string{
+				"nginx.ingress.kubernetes.io/backend-protocol": "GRPCS",
+				"cert-manager.io/cluster-issuer":               "letsencrypt",
+			},
+			expectedHosts: []string{"temporal.example.com"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					MTLS: test.mTLS,
+				},
+			}
+			if test.ingress != nil {
+				cluster.Spec.Services = &v1beta1.ServicesSpec{
+					Frontend: &v1beta1.FrontendServiceSpec{
+						Expose: &v1beta1.FrontendExposeSpec{Ingress: test.ingress},
+					},
+				}
+			}
+			cluster.Default()
+
+			scheme := runtime.NewScheme()
+			require.NoError(tt, v1beta1.AddToScheme(scheme))
+
+			builder := base.NewFrontendIngressBuilder(cluster, scheme)
+			require.Equal(tt, test.expectedEnabled, builder.Enabled())
+			if !test.expectedEnabled {
+				return
+			}
+
+			object := builder.Build()
+			require.NoError(tt, builder.Update(object))
+
+			ingress := object.(*networkingv1.Ingress)
+			assert.Equal(tt, "test-frontend", ingress.Name)
+			assert.Equal(tt, test.ingress.IngressClassName, ingress.Spec.IngressClassName)
+			for key, value := range test.expectedAnnotations {
+				assert.Equal(tt, value, ingress.Annotations[key])
+			}
+			if _, ok := test.expectedAnnotations["nginx.ingress.kubernetes.io/ssl-passthrough"]; !ok {
+				assert.NotContains(tt, ingress.Annotations, "nginx.ingress.kubernetes.io/ssl-passthrough")
+			}
+
+			hosts := []string{}
+			for _, rule := range ingress.Spec.Rules {
+				hosts = append(hosts, rule.Host)
+				require.Len(tt, rule.HTTP.Paths, 1)
+				path := rule.HTTP.Paths[0]
+				assert.Equal(tt, "/", path.Path)
+				assert.Equal(tt, networkingv1.PathTypePrefix, *path.PathType)
+				assert.Equal(tt, "test-frontend", path.Backend.Service.Name)
+				assert.Equal(tt, int32(7233), path.Backend.Service.Port.Number)
+			}
+			assert.Equal(tt, test.expectedHosts, hosts)
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
 	"github.com/alexandrevilain/temporal-operator/internal/metadata"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/meta"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/istio"
 	"go.temporal.io/server/common/primitives"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -67,7 +68,11 @@ func (b *FrontendServiceBuilder) Update(object client.Object) error {
 	service.Labels = metadata.Merge(
 		object.GetLabels(),
 		metadata.GetLabels(b.instance, meta.FrontendService, b.instance.Spec.Version, b.instance.Labels),
+		istio.GetFrontendServiceLabels(b.instance),
 	)
+	if !b.instance.IstioWaypointEnabled() {
+		delete(service.Labels, istio.UseWaypointLabel)
+	}
 	service.Annotations = metadata.Merge(
 		object.GetAnnotations(),
 		metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
@@ -0,0 +1,90 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base
+
+import (
+	"fmt"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+var _ resource.Builder = (*FrontendTLSRouteBuilder)(nil)
+
+// FrontendTLSRouteBuilder builds the Gateway API TLSRoute passing TLS connections through to the frontend.
+type FrontendTLSRouteBuilder struct {
+	instance *v1beta1.TemporalCluster
+	scheme   *runtime.Scheme
+}
+
+func NewFrontendTLSRouteBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme) *FrontendTLSRouteBuilder {
+	return &FrontendTLSRouteBuilder{
+		instance: instance,
+		scheme:   scheme,
+	}
+}
+
+func (b *FrontendTLSRouteBuilder) Build() client.Object {
+	return &gatewayv1alpha2.TLSRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.ChildResourceName(meta.FrontendService),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, meta.FrontendService, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *FrontendTLSRouteBuilder) Enabled() bool {
+	expose := b.instance.Spec.Services.Frontend.Expose
+	return expose != nil && expose.TLSRoute != nil
+}
+
+func (b *FrontendTLSRouteBuilder) Update(object client.Object) error {
+	route := object.(*gatewayv1alpha2.TLSRoute)
+	spec := b.instance.Spec.Services.Frontend.Expose.TLSRoute
+
+	route.Annotations = metadata.Merge(object.GetAnnotations(), spec.Annotations)
+	route.Spec = gatewayv1alpha2.TLSRouteSpec{
+		CommonRouteSpec: gatewayv1.CommonRouteSpec{
+			ParentRefs: spec.ParentRefs,
+		},
+		Hostnames: spec.Hostnames,
+		Rules: []gatewayv1alpha2.TLSRouteRule{
+			{
+				BackendRefs: []gatewayv1.BackendRef{
+					frontendBackendRef(b.instance),
+				},
+			},
+		},
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, route, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
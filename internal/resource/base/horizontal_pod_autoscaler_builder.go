@@ -0,0 +1,127 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base
+
+import (
+	"fmt"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const defaultAutoscalingCPUUtilization = 80
+
+// autoscalingMetrics returns the metrics the service is scaled on.
+// It defaults to the CPU utilization if no metric is provided.
+func autoscalingMetrics(spec *v1beta1.AutoscalingSpec) []autoscalingv2.MetricSpec {
+	if len(spec.Metrics) > 0 || len(spec.Prometheus) > 0 {
+		return spec.Metrics
+	}
+
+	return []autoscalingv2.MetricSpec{
+		{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: ptr.To[int32](defaultAutoscalingCPUUtilization),
+				},
+			},
+		},
+	}
+}
+
+// autoscalingMinReplicas returns the lower limit for the number of replicas.
+func autoscalingMinReplicas(spec *v1beta1.AutoscalingSpec) *int32 {
+	if spec.MinReplicas != nil {
+		return spec.MinReplicas
+	}
+	return ptr.To[int32](1)
+}
+
+var _ resource.Builder = (*HorizontalPodAutoscalerBuilder)(nil)
+
+// HorizontalPodAutoscalerBuilder builds the HorizontalPodAutoscaler of a service deployment.
+// It's only used when KEDA isn't available in the cluster.
+type HorizontalPodAutoscalerBuilder struct {
+	serviceName   string
+	instance      *v1beta1.TemporalCluster
+	scheme        *runtime.Scheme
+	service       *v1beta1.ServiceSpec
+	kedaAvailable bool
+}
+
+func NewHorizontalPodAutoscalerBuilder(serviceName string, instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, service *v1beta1.ServiceSpec, kedaAvailable bool) *HorizontalPodAutoscalerBuilder {
+	return &HorizontalPodAutoscalerBuilder{
+		serviceName:   serviceName,
+		instance:      instance,
+		scheme:        scheme,
+		service:       service,
+		kedaAvailable: kedaAvailable,
+	}
+}
+
+func (b *HorizontalPodAutoscalerBuilder) Build() client.Object {
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.ChildResourceName(b.serviceName),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, b.serviceName, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *HorizontalPodAutoscalerBuilder) Enabled() bool {
+	return isBuilderEnabled(b.instance, b.serviceName) &&
+		b.service != nil &&
+		b.service.Autoscaling != nil &&
+		!b.kedaAvailable
+}
+
+func (b *HorizontalPodAutoscalerBuilder) Update(object client.Object) error {
+	hpa := object.(*autoscalingv2.HorizontalPodAutoscaler)
+	spec := b.service.Autoscaling
+
+	hpa.Spec = autoscalingv2.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       string(b.service.GetWorkloadKind()),
+			Name:       b.instance.ChildResourceName(b.serviceName),
+		},
+		MinReplicas: stableMinReplicas(b.service),
+		MaxReplicas: spec.MaxReplicas,
+		Metrics:     autoscalingMetrics(spec),
+		Behavior:    spec.Behavior,
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, hpa, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base
+
+import (
+	"fmt"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ resource.Builder = (*PodDisruptionBudgetBuilder)(nil)
+
+// PodDisruptionBudgetBuilder builds the PodDisruptionBudget of a service.
+type PodDisruptionBudgetBuilder struct {
+	serviceName string
+	instance    *v1beta1.TemporalCluster
+	scheme      *runtime.Scheme
+	service     *v1beta1.ServiceSpec
+}
+
+func NewPodDisruptionBudgetBuilder(serviceName string, instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, service *v1beta1.ServiceSpec) *PodDisruptionBudgetBuilder {
+	return &PodDisruptionBudgetBuilder{
+		serviceName: serviceName,
+		instance:    instance,
+		scheme:      scheme,
+		service:     service,
+	}
+}
+
+func (b *PodDisruptionBudgetBuilder) Build() client.Object {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.ChildResourceName(b.serviceName),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, b.serviceName, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *PodDisruptionBudgetBuilder) Enabled() bool {
+	return isBuilderEnabled(b.instance, b.serviceName) &&
+		b.service != nil &&
+		b.service.PodDisruptionBudget.IsEnabled()
+}
+
+func (b *PodDisruptionBudgetBuilder) Update(object client.Object) error {
+	pdb := object.(*policyv1.PodDisruptionBudget)
+
+	// Canary pods are not part of the budget.
+	pdb.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: metadata.Merge(
+			metadata.LabelsSelector(b.instance, b.serviceName),
+			metadata.TrackLabels(metadata.StableTrack),
+		),
+	}
+
+	spec := b.service.PodDisruptionBudget
+	switch {
+	case spec != nil && spec.MinAvailable != nil:
+		pdb.Spec.MinAvailable = spec.MinAvailable
+		pdb.Spec.MaxUnavailable = nil
+	case spec != nil && spec.MaxUnavailable != nil:
+		pdb.Spec.MinAvailable = nil
+		pdb.Spec.MaxUnavailable = spec.MaxUnavailable
+	default:
+		pdb.Spec.MinAvailable = nil
+		pdb.Spec.MaxUnavailable = ptr.To(intstr.FromInt32(1))
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, pdb, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
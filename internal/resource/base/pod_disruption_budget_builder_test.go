@@ -0,0 +1,98 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/base"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+func TestPodDisruptionBudgetBuilder(t *testing.T) {
+	tests := map[string]struct {
+		pdb                    *v1beta1.PodDisruptionBudgetSpec
+		expectedEnabled        bool
+		expectedMinAvailable   *intstr.IntOrString
+		expectedMaxUnavailable *intstr.IntOrString
+	}{
+		"defaults to max unavailable 1": {
+			expectedEnabled:        true,
+			expectedMaxUnavailable: ptr.To(intstr.FromInt32(1)),
+		},
+		"min available": {
+			pdb: &v1beta1.PodDisruptionBudgetSpec{
+				MinAvailable: ptr.To(intstr.FromString("50%")),
+			},
+			expectedEnabled:      true,
+			expectedMinAvailable: ptr.To(intstr.FromString("50%")),
+		},
+		"max unavailable": {
+			pdb: &v1beta1.PodDisruptionBudgetSpec{
+				MaxUnavailable: ptr.To(intstr.FromInt32(2)),
+			},
+			expectedEnabled:        true,
+			expectedMaxUnavailable: ptr.To(intstr.FromInt32(2)),
+		},
+		"disabled": {
+			pdb: &v1beta1.PodDisruptionBudgetSpec{
+				Enabled: ptr.To(false),
+			},
+			expectedEnabled: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+			}
+			cluster.Default()
+
+			scheme := runtime.NewScheme()
+			require.NoError(tt, v1beta1.AddToScheme(scheme))
+
+			service := &v1beta1.ServiceSpec{PodDisruptionBudget: test.pdb}
+
+			builder := base.NewPodDisruptionBudgetBuilder("history", cluster, scheme, service)
+			require.Equal(tt, test.expectedEnabled, builder.Enabled())
+			if !test.expectedEnabled {
+				return
+			}
+
+			object := builder.Build()
+			require.NoError(tt, builder.Update(object))
+
+			pdb := object.(*policyv1.PodDisruptionBudget)
+			assert.Equal(tt, "test-history", pdb.Name)
+			assert.Equal(tt, test.expectedMinAvailable, pdb.Spec.MinAvailable)
+			assert.Equal(tt, test.expectedMaxUnavailable, pdb.Spec.MaxUnavailable)
+			assert.Equal(tt, "stable", pdb.Spec.Selector.MatchLabels["app.kubernetes.io/track"])
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	kedav1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/keda/apis/keda/v1alpha1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// PrometheusAutoscalingQuery returns the PromQL query the service is scaled on.
+// Predefined metrics select the cluster's pods using the pod label added by the Kubernetes service discovery.
+func PrometheusAutoscalingQuery(instance *v1beta1.TemporalCluster, serviceName string, spec v1beta1.AutoscalingPrometheusSpec) string {
+	if spec.Query != "" {
+		return spec.Query
+	}
+
+	podSelector := fmt.Sprintf(`pod=~"%s-.*"`, instance.ChildResourceName(serviceName))
+
+	switch spec.Metric {
+	case v1beta1.TaskQueueBacklogMetric:
+		return fmt.Sprintf("sum(approximate_backlog_count{%s})", podSelector)
+	case v1beta1.RequestRateMetric:
+		return fmt.Sprintf("sum(rate(service_requests{%s}[1m]))", podSelector)
+	default:
+		return ""
+	}
+}
+
+// resourceMetricTriggers converts the provided Resource metrics to KEDA cpu and memory triggers.
+func resourceMetricTriggers(metrics []autoscalingv2.MetricSpec) []kedav1alpha1.ScaleTriggers {
+	triggers := []kedav1alpha1.ScaleTriggers{}
+	for _, metric := range metrics {
+		if metric.Type != autoscalingv2.ResourceMetricSourceType || metric.Resource == nil {
+			continue
+		}
+
+		var value string
+		switch metric.Resource.Target.Type {
+		case autoscalingv2.UtilizationMetricType:
+			if metric.Resource.Target.AverageUtilization == nil {
+				continue
+			}
+			value = strconv.Itoa(int(*metric.Resource.Target.AverageUtilization))
+		case autoscalingv2.AverageValueMetricType:
+			if metric.Resource.Target.AverageValue == nil {
+				continue
+			}
+			value = metric.Resource.Target.AverageValue.String()
+		default:
+			continue
+		}
+
+		triggers = append(triggers, kedav1alpha1.ScaleTriggers{
+			Type:       metric.Resource.Name.String(),
+			MetricType: metric.Resource.Target.Type,
+			Metadata: map[string]string{
+				"value": value,
+			},
+		})
+	}
+	return triggers
+}
+
+var _ resource.Builder = (*ScaledObjectBuilder)(nil)
+
+// ScaledObjectBuilder builds the KEDA ScaledObject of a service deployment.
+type ScaledObjectBuilder struct {
+	serviceName   string
+	instance      *v1beta1.TemporalCluster
+	scheme        *runtime.Scheme
+	service       *v1beta1.ServiceSpec
+	kedaAvailable bool
+}
+
+func NewScaledObjectBuilder(serviceName string, instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, service *v1beta1.ServiceSpec, kedaAvailable bool) *ScaledObjectBuilder {
+	return &ScaledObjectBuilder{
+		serviceName:   serviceName,
+		instance:      instance,
+		scheme:        scheme,
+		service:       service,
+		kedaAvailable: kedaAvailable,
+	}
+}
+
+func (b *ScaledObjectBuilder) Build() client.Object {
+	return &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.ChildResourceName(b.serviceName),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, b.serviceName, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *ScaledObjectBuilder) Enabled() bool {
+	return isBuilderEnabled(b.instance, b.serviceName) &&
+		b.service != nil &&
+		b.service.Autoscaling != nil &&
+		b.kedaAvailable
+}
+
+func (b *ScaledObjectBuilder) Update(object client.Object) error {
+	scaledObject := object.(*kedav1alpha1.ScaledObject)
+	spec := b.service.Autoscaling
+
+	triggers := resourceMetricTriggers(autoscalingMetrics(spec))
+	for _, prometheus := range spec.Prometheus {
+		trigger := kedav1alpha1.ScaleTriggers{
+			Type: "prometheus",
+			Metadata: map[string]string{
+				"serverAddress": prometheus.ServerAddress,
+				"query":         PrometheusAutoscalingQuery(b.instance, b.serviceName, prometheus),
+				"threshold":     prometheus.Threshold,
+			},
+		}
+		if prometheus.AuthenticationRef != nil {
+			trigger.AuthenticationRef = &kedav1alpha1.AuthenticationRef{
+				Name: prometheus.AuthenticationRef.Name,
+			}
+		}
+		triggers = append(triggers, trigger)
+	}
+
+	scaledObject.Spec = kedav1alpha1.ScaledObjectSpec{
+		ScaleTargetRef: &kedav1alpha1.ScaleTarget{
+			APIVersion: "apps/v1",
+			Kind:       string(b.service.GetWorkloadKind()),
+			Name:       b.instance.ChildResourceName(b.serviceName),
+		},
+		MinReplicaCount: stableMinReplicas(b.service),
+		MaxReplicaCount: &spec.MaxReplicas,
+		Triggers:        triggers,
+	}
+
+	if spec.Behavior != nil {
+		scaledObject.Spec.Advanced = &kedav1alpha1.AdvancedConfig{
+			HorizontalPodAutoscalerConfig: &kedav1alpha1.HorizontalPodAutoscalerConfig{
+				Behavior: spec.Behavior,
+			},
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, scaledObject, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/base"
+	kedav1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/keda/apis/keda/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+func TestScaledObjectBuilder(t *testing.T) {
+	tests := map[string]struct {
+		serviceName      string
+		autoscaling      *v1beta1.AutoscalingSpec
+		expectedTriggers []kedav1alpha1.ScaleTriggers
+	}{
+		"defaults to cpu utilization": {
+			serviceName: "history",
+			autoscaling: &v1beta1.AutoscalingSpec{MaxReplicas: 3},
+			expectedTriggers: []kedav1alpha1.ScaleTriggers{
+				{
+					Type:       "cpu",
+					MetricType: autoscalingv2.UtilizationMetricType,
+					Metadata:   map[string]string{"value": "80"},
+				},
+			},
+		},
+		"matching task queue backlog and memory": {
+			serviceName: "matching",
+			autoscaling: &v1beta1.AutoscalingSpec{
+				MaxReplicas: 3,
+				Metrics: []autoscalingv2.MetricSpec{
+					{
+						Type: autoscalingv2.ResourceMetricSourceType,
+						Resource: &autoscalingv2.ResourceMetricSource{
+							Name: corev1.ResourceMemory,
+							Target: autoscalingv2.MetricTarget{
+								Type:               autoscalingv2.UtilizationMetricType,
+								AverageUtilization: ptr.To[int32](70),
+							},
+						},
+					},
+				},
+				Prometheus: []v1beta1.AutoscalingPrometheusSpec{
+					{
+						ServerAddress: "http://prometheus:9090",
+						Metric:        v1beta1.TaskQueueBacklogMetric,
+						Threshold:     "100",
+					},
+				},
+			},
+			expectedTriggers: []kedav1alpha1.ScaleTriggers{
+				{
+					Type:       "memory",
+					MetricType: autoscalingv2.UtilizationMetricType,
+					Metadata:   map[string]string{"value": "70"},
+				},
+				{
+					Type: "prometheus",
+					Metadata: map[string]string{
+						"serverAddress": "http://prometheus:9090",
+						"query":         `sum(approximate_backlog_count{pod=~"test-matching-.*"})`,
+						"threshold":     "100",
+					},
+				},
+			},
+		},
+		"frontend request rate only": {
+			serviceName: "frontend",
+			autoscaling: &v1beta1.AutoscalingSpec{
+				MaxReplicas: 3,
+				Prometheus: []v1beta1.AutoscalingPrometheusSpec{
+					{
+						ServerAddress: "http://prometheus:9090",
+						Metric:        v1beta1.RequestRateMetric,
+						Threshold:     "50",
+					},
+				},
+			},
+			expectedTriggers: []kedav1alpha1.ScaleTriggers{
+				{
+					Type: "prometheus",
+					Metadata: map[string]string{
+						"serverAddress": "http://prometheus:9090",
+						"query":         `sum(rate(service_requests{pod=~"test-frontend-.*"}[1m]))`,
+						"threshold":     "50",
+					},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+			}
+			cluster.Default()
+
+			scheme := runtime.NewScheme()
+			require.NoError(tt, v1beta1.AddToScheme(scheme))
+
+			service := &v1beta1.ServiceSpec{Autoscaling: test.autoscaling}
+
+			require.False(tt, base.NewHorizontalPodAutoscalerBuilder(test.serviceName, cluster, scheme, service, true).Enabled())
+
+			builder := base.NewScaledObjectBuilder(test.serviceName, cluster, scheme, service, true)
+			require.True(tt, builder.Enabled())
+
+			object := builder.Build()
+			require.NoError(tt, builder.Update(object))
+
+			scaledObject := object.(*kedav1alpha1.ScaledObject)
+			assert.Equal(tt, "test-"+test.serviceName, scaledObject.Spec.ScaleTargetRef.Name)
+			assert.Equal(tt, ptr.To[int32](1), scaledObject.Spec.MinReplicaCount)
+			assert.Equal(tt, ptr.To[int32](3), scaledObject.Spec.MaxReplicaCount)
+			assert.Equal(tt, test.expectedTriggers, scaledObject.Spec.Triggers)
+		})
+	}
+}
@@ -33,6 +33,8 @@ import (
 const (
 	gcpServiceAccountAnnotation = "iam.gke.io/gcp-service-account"
 	awsRoleArnAnnotation        = "eks.amazonaws.com/role-arn"
+	azureClientIDAnnotation     = "azure.workload.identity/client-id"
+	azureTenantIDAnnotation     = "azure.workload.identity/tenant-id"
 )
 
 var _ resource.Builder = (*ServiceAccountBuilder)(nil)
@@ -92,6 +94,30 @@ func (b *ServiceAccountBuilder) getIAMAnnotations() map[string]string {
 		annotations[gcpServiceAccountAnnotation] = *b.instance.Spec.Persistence.AdvancedVisibilityStore.SQL.GCPServiceAccount
 	}
 
+	for _, store := range b.instance.Spec.Persistence.GetDatastores() {
+		if store.IsElasticsearchAWSRequestSigning() && store.Elasticsearch.Auth.AWSRequestSigning.Role != "" {
+			annotations[awsRoleArnAnnotation] = store.Elasticsearch.Auth.AWSRequestSigning.Role
+		}
+
+		if !store.IsSQLTokenAuth() {
+			continue
+		}
+
+		auth := store.SQL.Auth
+		if auth.AWSIAM != nil {
+			annotations[awsRoleArnAnnotation] = auth.AWSIAM.Role
+		}
+		if auth.AzureAD != nil {
+			annotations[azureClientIDAnnotation] = auth.AzureAD.ClientID
+			if auth.AzureAD.TenantID != "" {
+				annotations[azureTenantIDAnnotation] = auth.AzureAD.TenantID
+			}
+		}
+		if auth.GCPIAM != nil {
+			annotations[gcpServiceAccountAnnotation] = auth.GCPIAM.ServiceAccount
+		}
+	}
+
 	return annotations
 }
 
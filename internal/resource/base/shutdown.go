@@ -0,0 +1,195 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/pkg/temporal/config"
+	"go.temporal.io/server/common/dynamicconfig"
+	"go.temporal.io/server/common/primitives"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// defaultPreStopDelay is the default time the preStop hook waits before the service container is stopped.
+	defaultPreStopDelay = 5 * time.Second
+	// defaultShutdownGracePeriod is the time left to the service to stop once drained.
+	defaultShutdownGracePeriod = 30 * time.Second
+)
+
+// healthCheckServiceNames maps temporal services to the gRPC service name they report their health for.
+var healthCheckServiceNames = map[primitives.ServiceName]string{
+	primitives.FrontendService:         "temporal.api.workflowservice.v1.WorkflowService",
+	primitives.InternalFrontendService: "temporal.api.workflowservice.v1.WorkflowService",
+	primitives.HistoryService:          "temporal.api.workflowservice.v1.HistoryService",
+	primitives.MatchingService:         "temporal.api.workflowservice.v1.MatchingService",
+}
+
+// shutdownDrainKeys maps temporal services to the dynamic config keys delaying their shutdown.
+var shutdownDrainKeys = map[primitives.ServiceName][]string{
+	primitives.FrontendService: {
+		dynamicconfig.FrontendShutdownFailHealthCheckDuration.Key().String(),
+		dynamicconfig.FrontendShutdownDrainDuration.Key().String(),
+	},
+	primitives.InternalFrontendService: {
+		dynamicconfig.FrontendShutdownFailHealthCheckDuration.Key().String(),
+		dynamicconfig.FrontendShutdownDrainDuration.Key().String(),
+	},
+	primitives.HistoryService: {
+		dynamicconfig.HistoryShutdownDrainDuration.Key().String(),
+	},
+	primitives.MatchingService: {
+		dynamicconfig.MatchingShutdownDrainDuration.Key().String(),
+	},
+}
+
+// rpcServesTLS returns true if the service rpc port is served over TLS by temporal itself.
+// Kubelet gRPC probes don't support TLS, a TCP probe is used instead.
+func (b *DeploymentBuilder) rpcServesTLS() bool {
+	if !b.instance.MTLSWithCertificatesEnabled() && !b.instance.MTLSWithSpireEnabled() {
+		return false
+	}
+
+	if b.serviceName == string(primitives.FrontendService) {
+		return b.instance.Spec.MTLS.FrontendEnabled()
+	}
+
+	return b.instance.Spec.MTLS.InternodeEnabled()
+}
+
+// healthCheckHandler returns the handler checking the service is serving.
+func (b *DeploymentBuilder) healthCheckHandler() corev1.ProbeHandler {
+	if b.rpcServesTLS() {
+		return corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: intstr.FromString("rpc"),
+			},
+		}
+	}
+
+	return corev1.ProbeHandler{
+		GRPC: &corev1.GRPCAction{
+			Port:    *b.service.Port,
+			Service: ptr.To(healthCheckServiceNames[primitives.ServiceName(b.serviceName)]),
+		},
+	}
+}
+
+// probes returns the liveness, readiness and startup probes of the service container.
+// Probes set in the service spec replace the computed ones.
+func (b *DeploymentBuilder) probes() (liveness, readiness, startup *corev1.Probe) {
+	// worker has no grpc endpoint so omit probes
+	if _, ok := healthCheckServiceNames[primitives.ServiceName(b.serviceName)]; ok {
+		liveness = &corev1.Probe{
+			TimeoutSeconds:   1,
+			PeriodSeconds:    10,
+			SuccessThreshold: 1,
+			FailureThreshold: 3,
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{
+					Port: intstr.FromString("rpc"),
+				},
+			},
+		}
+		readiness = &corev1.Probe{
+			TimeoutSeconds:   1,
+			PeriodSeconds:    5,
+			SuccessThreshold: 1,
+			FailureThreshold: 3,
+			ProbeHandler:     b.healthCheckHandler(),
+		}
+		// Give the service up to 150s to start, as the liveness probe initial delay previously did.
+		startup = &corev1.Probe{
+			TimeoutSeconds:   1,
+			PeriodSeconds:    5,
+			SuccessThreshold: 1,
+			FailureThreshold: 30,
+			ProbeHandler:     b.healthCheckHandler(),
+		}
+	}
+
+	if b.service.Probes != nil {
+		if b.service.Probes.Liveness != nil {
+			liveness = b.service.Probes.Liveness
+		}
+		if b.service.Probes.Readiness != nil {
+			readiness = b.service.Probes.Readiness
+		}
+		if b.service.Probes.Startup != nil {
+			startup = b.service.Probes.Startup
+		}
+	}
+
+	return liveness, readiness, startup
+}
+
+// preStopDelay returns the time the preStop hook waits before the service container is stopped.
+func (b *DeploymentBuilder) preStopDelay() time.Duration {
+	if b.service.Shutdown != nil && b.service.Shutdown.PreStopDelay != nil {
+		return b.service.Shutdown.PreStopDelay.Duration
+	}
+	return defaultPreStopDelay
+}
+
+// lifecycle returns the service container lifecycle.
+// The preStop hook lets the pod be removed from the service endpoints before temporal starts draining.
+func (b *DeploymentBuilder) lifecycle() *corev1.Lifecycle {
+	delay := b.preStopDelay()
+	if delay <= 0 {
+		return nil
+	}
+
+	return &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"sleep", strconv.FormatInt(durationSeconds(delay), 10)},
+			},
+		},
+	}
+}
+
+// terminationGracePeriodSeconds returns the pods termination grace period.
+// It's the sum of the preStop delay, the temporal shutdown drain settings and a grace period for the service to stop.
+func (b *DeploymentBuilder) terminationGracePeriodSeconds() (int64, error) {
+	if b.service.Shutdown != nil && b.service.Shutdown.TerminationGracePeriod != nil {
+		return durationSeconds(b.service.Shutdown.TerminationGracePeriod.Duration), nil
+	}
+
+	total := b.preStopDelay() + defaultShutdownGracePeriod
+	for _, key := range shutdownDrainKeys[primitives.ServiceName(b.serviceName)] {
+		d, ok, err := config.DynamicConfigDuration(b.instance.GetDynamicConfig(), key)
+		if err != nil {
+			return 0, err
+		}
+		if ok && d > 0 {
+			total += d
+		}
+	}
+
+	return durationSeconds(total), nil
+}
+
+// durationSeconds returns the duration in seconds, rounded up.
+func durationSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
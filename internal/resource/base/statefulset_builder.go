@@ -0,0 +1,137 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base
+
+import (
+	"fmt"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	"github.com/alexandrevilain/temporal-operator/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ resource.Builder = (*StatefulSetBuilder)(nil)
+
+// StatefulSetBuilder builds the StatefulSet of a service using the StatefulSet workload kind.
+// Its pods are the same as the ones of the service Deployment.
+type StatefulSetBuilder struct {
+	serviceName string
+	instance    *v1beta1.TemporalCluster
+	scheme      *runtime.Scheme
+	service     *v1beta1.ServiceSpec
+	// migrating is true when the service workload kind changed and the new workload isn't ready yet.
+	migrating bool
+	pod       *DeploymentBuilder
+}
+
+func NewStatefulSetBuilder(serviceName string, instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, service *v1beta1.ServiceSpec, configHash, caBundleHash, datastoreSecretsHash, authProxyImage string, migrating bool) *StatefulSetBuilder {
+	return &StatefulSetBuilder{
+		serviceName: serviceName,
+		instance:    instance,
+		scheme:      scheme,
+		service:     service,
+		migrating:   migrating,
+		pod:         NewDeploymentBuilder(serviceName, instance, scheme, service, configHash, caBundleHash, datastoreSecretsHash, authProxyImage, migrating),
+	}
+}
+
+func (b *StatefulSetBuilder) Build() client.Object {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.ChildResourceName(b.serviceName),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, b.serviceName, b.instance.ServiceVersion(b.service), b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *StatefulSetBuilder) Enabled() bool {
+	// The statefulset is kept while the service is migrated back to a Deployment.
+	return isBuilderEnabled(b.instance, b.serviceName) &&
+		(b.service.GetWorkloadKind() == v1beta1.StatefulSetWorkloadKind || b.migrating)
+}
+
+func (b *StatefulSetBuilder) Update(object client.Object) error {
+	statefulSet := object.(*appsv1.StatefulSet)
+	statefulSet.Labels = metadata.Merge(
+		object.GetLabels(),
+		metadata.GetLabels(b.instance, b.serviceName, b.instance.ServiceVersion(b.service), b.instance.Labels),
+	)
+	statefulSet.Annotations = metadata.Merge(
+		object.GetAnnotations(),
+		metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+	)
+
+	// When the service is autoscaled, replicas are managed by the autoscaler.
+	// Only set them when creating the statefulset.
+	if b.service.Autoscaling == nil {
+		statefulSet.Spec.Replicas = stableReplicas(b.service)
+	} else if statefulSet.Spec.Replicas == nil {
+		statefulSet.Spec.Replicas = stableMinReplicas(b.service)
+	}
+
+	statefulSet.Spec.Selector = stableSelector(b.instance, b.serviceName, statefulSet.Spec.Selector)
+	statefulSet.Spec.ServiceName = b.instance.ChildResourceName(fmt.Sprintf("%s-headless", b.serviceName))
+	// Pods don't depend on each other to start, only rollouts are ordered.
+	statefulSet.Spec.PodManagementPolicy = appsv1.ParallelPodManagement
+	statefulSet.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{
+		Type: appsv1.RollingUpdateStatefulSetStrategyType,
+	}
+
+	template, err := b.pod.podTemplateSpec()
+	if err != nil {
+		return err
+	}
+	template.Labels = metadata.Merge(template.Labels, metadata.TrackLabels(metadata.StableTrack))
+	statefulSet.Spec.Template = template
+
+	if b.instance.Spec.Services.Overrides != nil && b.instance.Spec.Services.Overrides.Deployment != nil {
+		err := kubernetes.ApplyStatefulSetOverrides(statefulSet, b.instance.Spec.Services.Overrides.Deployment)
+		if err != nil {
+			return fmt.Errorf("can't apply deployment overrides: %w", err)
+		}
+	}
+
+	if b.service.Overrides != nil && b.service.Overrides.Deployment != nil {
+		err := kubernetes.ApplyStatefulSetOverrides(statefulSet, b.service.Overrides.Deployment)
+		if err != nil {
+			return fmt.Errorf("failed applying deployment overrides: %w", err)
+		}
+	}
+
+	// Once promoted, the canary template is applied to the stable pods.
+	if b.service.Canary.IsPromoted() && b.service.Canary.Template != nil {
+		err := kubernetes.ApplyPodTemplateSpecOverrides(&statefulSet.Spec.Template, b.service.Canary.Template)
+		if err != nil {
+			return fmt.Errorf("failed applying canary template overrides: %w", err)
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, statefulSet, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package base_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/base"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestStatefulSetBuilder(t *testing.T) {
+	tests := map[string]struct {
+		workloadKind               v1beta1.WorkloadKind
+		migrating                  bool
+		expectedDeploymentEnabled  bool
+		expectedStatefulSetEnabled bool
+	}{
+		"deployment": {
+			workloadKind:              v1beta1.DeploymentWorkloadKind,
+			expectedDeploymentEnabled: true,
+		},
+		"statefulset": {
+			workloadKind:               v1beta1.StatefulSetWorkloadKind,
+			expectedStatefulSetEnabled: true,
+		},
+		"migrating to statefulset": {
+			workloadKind:               v1beta1.StatefulSetWorkloadKind,
+			migrating:                  true,
+			expectedDeploymentEnabled:  true,
+			expectedStatefulSetEnabled: true,
+		},
+		"migrating back to deployment": {
+			workloadKind:               v1beta1.DeploymentWorkloadKind,
+			migrating:                  true,
+			expectedDeploymentEnabled:  true,
+			expectedStatefulSetEnabled: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{PluginName: "postgres12"},
+						},
+						VisibilityStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{PluginName: "postgres12"},
+						},
+					},
+				},
+			}
+			cluster.Default()
+
+			scheme := runtime.NewScheme()
+			require.NoError(tt, v1beta1.AddToScheme(scheme))
+
+			service := cluster.Spec.Services.History
+			service.WorkloadKind = test.workloadKind
+
+			deploymentBuilder := base.NewDeploymentBuilder("history", cluster, scheme, service, "hash", "", "", "", test.migrating)
+			statefulSetBuilder := base.NewStatefulSetBuilder("history", cluster, scheme, service, "hash", "", "", "", test.migrating)
+			assert.Equal(tt, test.expectedDeploymentEnabled, deploymentBuilder.Enabled())
+			assert.Equal(tt, test.expectedStatefulSetEnabled, statefulSetBuilder.Enabled())
+
+			deploymentObject := deploymentBuilder.Build()
+			require.NoError(tt, deploymentBuilder.Update(deploymentObject))
+			deployment := deploymentObject.(*appsv1.Deployment)
+
+			statefulSetObject := statefulSetBuilder.Build()
+			require.NoError(tt, statefulSetBuilder.Update(statefulSetObject))
+			statefulSet := statefulSetObject.(*appsv1.StatefulSet)
+
+			assert.Equal(tt, "test-history", statefulSet.Name)
+			assert.Equal(tt, "test-history-headless", statefulSet.Spec.ServiceName)
+			assert.Equal(tt, deployment.Spec.Selector, statefulSet.Spec.Selector)
+			assert.Equal(tt, deployment.Spec.Template, statefulSet.Spec.Template)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codecserver
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	"github.com/alexandrevilain/temporal-operator/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// ServiceName is the name of the codec server component.
+	ServiceName = "codec-server"
+
+	keysVolumeName = "keys"
+	// CORSOriginsEnvVar is the environment variable holding the comma-separated list
+	// of origins the codec server should allow.
+	CORSOriginsEnvVar = "CODEC_SERVER_CORS_ORIGINS"
+	// PortEnvVar is the environment variable holding the port the codec server should listen on.
+	PortEnvVar = "CODEC_SERVER_PORT"
+)
+
+var _ resource.Builder = (*DeploymentBuilder)(nil)
+
+type DeploymentBuilder struct {
+	instance *v1beta1.TemporalCluster
+	scheme   *runtime.Scheme
+}
+
+func NewDeploymentBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme) *DeploymentBuilder {
+	return &DeploymentBuilder{
+		instance: instance,
+		scheme:   scheme,
+	}
+}
+
+func (b *DeploymentBuilder) Build() client.Object {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.ChildResourceName(ServiceName),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, ServiceName, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *DeploymentBuilder) Enabled() bool {
+	return b.instance.CodecServerEnabled()
+}
+
+// CORSOrigins returns the origins allowed to call the codec server.
+func CORSOrigins(instance *v1beta1.TemporalCluster) []string {
+	origins := instance.UIOrigins()
+	for _, origin := range instance.Spec.CodecServer.CORSOrigins {
+		if !slices.Contains(origins, origin) {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+func (b *DeploymentBuilder) Update(object client.Object) error {
+	deployment := object.(*appsv1.Deployment)
+	spec := b.instance.Spec.CodecServer
+
+	deployment.Labels = metadata.Merge(
+		object.GetLabels(),
+		metadata.GetLabels(b.instance, ServiceName, b.instance.Spec.Version, b.instance.Labels),
+	)
+	deployment.Annotations = metadata.Merge(
+		object.GetAnnotations(),
+		metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+	)
+
+	volumes := []corev1.Volume{}
+	volumeMounts := []corev1.VolumeMount{}
+
+	env := []corev1.EnvVar{
+		{
+			Name:  PortEnvVar,
+			Value: fmt.Sprintf("%d", *spec.Port),
+		},
+		{
+			Name:  CORSOriginsEnvVar,
+			Value: strings.Join(CORSOrigins(b.instance), ","),
+		},
+	}
+	env = append(env, spec.Env...)
+
+	if spec.KeysSecretRef != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: keysVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  spec.KeysSecretRef.Name,
+					DefaultMode: ptr.To[int32](corev1.SecretVolumeSourceDefaultMode),
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      keysVolumeName,
+			MountPath: spec.KeysMountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	deployment.Spec.Replicas = spec.Replicas
+
+	deployment.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: metadata.LabelsSelector(b.instance, ServiceName),
+	}
+	deployment.Spec.Template = corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      metadata.GetLabels(b.instance, ServiceName, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+		Spec: corev1.PodSpec{
+			ImagePullSecrets: b.instance.Spec.ImagePullSecrets,
+			Containers: []corev1.Container{
+				{
+					Name:                     ServiceName,
+					Image:                    spec.Image,
+					Args:                     spec.Args,
+					ImagePullPolicy:          corev1.PullIfNotPresent,
+					Resources:                spec.Resources,
+					TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+					TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+					Ports: []corev1.ContainerPort{
+						{
+							Name:          "http",
+							ContainerPort: *spec.Port,
+							Protocol:      corev1.ProtocolTCP,
+						},
+					},
+					Env:          env,
+					VolumeMounts: volumeMounts,
+				},
+			},
+			Volumes:                       volumes,
+			RestartPolicy:                 corev1.RestartPolicyAlways,
+			TerminationGracePeriodSeconds: ptr.To[int64](30),
+			DNSPolicy:                     corev1.DNSClusterFirst,
+			SchedulerName:                 corev1.DefaultSchedulerName,
+		},
+	}
+
+	if spec.Overrides != nil && spec.Overrides.Deployment != nil {
+		err := kubernetes.ApplyDeploymentOverrides(deployment, spec.Overrides.Deployment)
+		if err != nil {
+			return fmt.Errorf("can't apply deployment overrides: %w", err)
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, deployment, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codecserver_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/codecserver"
+	"github.com/alexandrevilain/temporal-operator/pkg/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestCORSOrigins(t *testing.T) {
+	tests := map[string]struct {
+		ui       *v1beta1.TemporalUISpec
+		origins  []string
+		expected []string
+	}{
+		"ui disabled": {
+			ui:       &v1beta1.TemporalUISpec{Enabled: false},
+			origins:  []string{"https://extra.example.com"},
+			expected: []string{"https://extra.example.com"},
+		},
+		"ui ingress hosts": {
+			ui: &v1beta1.TemporalUISpec{
+				Enabled: true,
+				Ingress: &v1beta1.TemporalUIIngressSpec{
+					Hosts: []string{"temporal.example.com/ui", "temporal.internal"},
+					TLS: []networkingv1.IngressTLS{
+						{Hosts: []string{"temporal.example.com"}},
+					},
+				},
+			},
+			expected: []string{"https://temporal.example.com", "http://temporal.internal"},
+		},
+		"ui gateway hostnames and extra origins": {
+			ui: &v1beta1.TemporalUISpec{
+				Enabled: true,
+				Gateway: &v1beta1.GatewayHTTPRouteSpec{
+					Hostnames: []gatewayv1.Hostname{"temporal.example.com"},
+				},
+			},
+			origins:  []string{"https://temporal.example.com", "https://extra.example.com"},
+			expected: []string{"https://temporal.example.com", "https://extra.example.com"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			instance := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "default",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					UI: test.ui,
+					CodecServer: &v1beta1.CodecServerSpec{
+						Enabled:     true,
+						CORSOrigins: test.origins,
+					},
+				},
+			}
+
+			assert.Equal(tt, test.expected, codecserver.CORSOrigins(instance))
+		})
+	}
+}
+
+func TestDeploymentBuilderPodMetadata(t *testing.T) {
+	instance := &v1beta1.TemporalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Namespace:   "default",
+			Annotations: map[string]string{"team": "payments"},
+		},
+		Spec: v1beta1.TemporalClusterSpec{
+			Version: version.MustNewVersionFromString("1.25.0"),
+			Metrics: &v1beta1.MetricsSpec{
+				Enabled: true,
+				Prometheus: &v1beta1.PrometheusSpec{
+					ListenPort: ptr.To[int32](9090),
+					ScrapeConfig: &v1beta1.PrometheusScrapeConfig{
+						Annotations: true,
+					},
+				},
+			},
+			CodecServer: &v1beta1.CodecServerSpec{
+				Enabled: true,
+				Image:   "codec-server:latest",
+				Port:    ptr.To[int32](8081),
+			},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	builder := codecserver.NewDeploymentBuilder(instance, scheme)
+	object := builder.Build()
+	require.NoError(t, builder.Update(object))
+
+	template := object.(*appsv1.Deployment).Spec.Template
+	assert.Equal(t, map[string]string{"team": "payments"}, template.Annotations)
+	assert.Equal(t, "codec-server", template.Labels["app.kubernetes.io/component"])
+	assert.Equal(t, "1.25.0", template.Labels["app.kubernetes.io/version"])
+}
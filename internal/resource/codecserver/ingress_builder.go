@@ -0,0 +1,124 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codecserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ resource.Builder = (*IngressBuilder)(nil)
+
+type IngressBuilder struct {
+	instance *v1beta1.TemporalCluster
+	scheme   *runtime.Scheme
+}
+
+func NewIngressBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme) *IngressBuilder {
+	return &IngressBuilder{
+		instance: instance,
+		scheme:   scheme,
+	}
+}
+
+func (b *IngressBuilder) Build() client.Object {
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.ChildResourceName(ServiceName),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, ServiceName, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *IngressBuilder) Enabled() bool {
+	return b.instance.CodecServerEnabled() && b.instance.Spec.CodecServer.Ingress != nil
+}
+
+// corsAnnotations returns the ingress-nginx annotations allowing the UI to call the codec server.
+func (b *IngressBuilder) corsAnnotations() map[string]string {
+	origins := CORSOrigins(b.instance)
+	if len(origins) == 0 {
+		return nil
+	}
+
+	return map[string]string{
+		"nginx.ingress.kubernetes.io/enable-cors":            "true",
+		"nginx.ingress.kubernetes.io/cors-allow-origin":      strings.Join(origins, ","),
+		"nginx.ingress.kubernetes.io/cors-allow-methods":     "POST, GET, OPTIONS",
+		"nginx.ingress.kubernetes.io/cors-allow-headers":     "Authorization, Content-Type, X-Namespace",
+		"nginx.ingress.kubernetes.io/cors-allow-credentials": "true",
+	}
+}
+
+func (b *IngressBuilder) Update(object client.Object) error {
+	ingress := object.(*networkingv1.Ingress)
+	spec := b.instance.Spec.CodecServer
+
+	ingress.Labels = object.GetLabels()
+	// User-provided annotations take precedence over the CORS annotations.
+	ingress.Annotations = metadata.Merge(object.GetAnnotations(), b.corsAnnotations(), spec.Ingress.Annotations)
+
+	rules := make([]networkingv1.IngressRule, 0, len(spec.Ingress.Hosts))
+	for _, host := range spec.Ingress.Hosts {
+		host, _, _ = strings.Cut(host, "/")
+		pathType := networkingv1.PathTypePrefix
+		rules = append(rules, networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: b.instance.ChildResourceName(ServiceName),
+									Port: networkingv1.ServiceBackendPort{
+										Name: "http",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+
+	ingress.Spec = networkingv1.IngressSpec{
+		IngressClassName: spec.Ingress.IngressClassName,
+		Rules:            rules,
+		TLS:              spec.Ingress.TLS,
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, ingress, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+	return nil
+}
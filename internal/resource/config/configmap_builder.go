@@ -20,6 +20,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"path"
 	"strconv"
 	"time"
@@ -29,6 +30,8 @@ import (
 	"github.com/alexandrevilain/temporal-operator/internal/metadata"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/meta"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/certmanager"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/spire"
+	resourcepersistence "github.com/alexandrevilain/temporal-operator/internal/resource/persistence"
 	archivalutil "github.com/alexandrevilain/temporal-operator/pkg/temporal/archival"
 	"github.com/alexandrevilain/temporal-operator/pkg/temporal/authorization"
 	"github.com/alexandrevilain/temporal-operator/pkg/temporal/config"
@@ -87,6 +90,13 @@ func (b *ConfigmapBuilder) buildDatastoreConfig(store *v1beta1.DatastoreSpec) (*
 		v1beta1.MySQL8Datastore:
 		cfg.SQL = persistence.NewSQLConfigFromDatastoreSpec(store)
 		cfg.SQL.Password = fmt.Sprintf("{{ .Env.%s }}", store.GetPasswordEnvVarName())
+		// Datastores authenticating with tokens are reached through the auth proxy sidecar,
+		// which handles TLS and authentication.
+		if proxy, ok := resourcepersistence.GetAuthProxy(b.instance, store); ok {
+			cfg.SQL.ConnectAddr = proxy.Address()
+			cfg.SQL.Password = ""
+			cfg.SQL.TLS = nil
+		}
 	case v1beta1.CassandraDatastore:
 		cfg.Cassandra = persistence.NewCassandraConfigFromDatastoreSpec(store)
 		cfg.Cassandra.Password = fmt.Sprintf("{{ .Env.%s }}", store.GetPasswordEnvVarName())
@@ -96,7 +106,15 @@ func (b *ConfigmapBuilder) buildDatastoreConfig(store *v1beta1.DatastoreSpec) (*
 			return nil, fmt.Errorf("can't get elasticsearch config: %w", err)
 		}
 		cfg.Elasticsearch = esCfg
-		cfg.Elasticsearch.Password = fmt.Sprintf("{{ .Env.%s }}", store.GetPasswordEnvVarName())
+		if cfg.Elasticsearch.Username != "" {
+			cfg.Elasticsearch.Password = fmt.Sprintf("{{ .Env.%s }}", store.GetPasswordEnvVarName())
+		}
+		// Datastores authenticating with API keys are reached through the auth proxy sidecar,
+		// which handles TLS and authentication. Sniffing would bypass the proxy.
+		if proxy, ok := resourcepersistence.GetAuthProxy(b.instance, store); ok {
+			cfg.Elasticsearch.URL = url.URL{Scheme: "http", Host: proxy.Address()}
+			cfg.Elasticsearch.EnableSniff = false
+		}
 	case v1beta1.UnknownDatastore:
 		return nil, errors.New("unknown datastore")
 	}
@@ -116,6 +134,12 @@ func (b *ConfigmapBuilder) buildPersistenceConfig() (*config.Persistence, error)
 		cfg.SecondaryVisibilityStore = b.instance.Spec.Persistence.SecondaryVisibilityStore.Name
 	}
 
+	// Once a visibility migration is promoted, the secondary store is the only visibility store.
+	if b.instance.VisibilityMigrationPhase() == v1beta1.PromotedVisibilityMigrationPhase {
+		cfg.VisibilityStore = b.instance.Spec.Persistence.SecondaryVisibilityStore.Name
+		cfg.SecondaryVisibilityStore = ""
+	}
+
 	// This will be removed for clusters >= 1.23.x
 	if b.instance.Spec.Persistence.AdvancedVisibilityStore != nil {
 		cfg.AdvancedVisibilityStore = b.instance.Spec.Persistence.AdvancedVisibilityStore.Name
@@ -201,6 +225,46 @@ func (b *ConfigmapBuilder) buildArchivalConfig() (*temporalconfig.Archival, *tem
 	return cfg, namespaceDefaults
 }
 
+// buildSpireTLSConfig returns the internode TLS configuration using the SVID and the trust bundle written by the spiffe-helper.
+// All services share the same SVID, which is used as server and client certificate.
+// Frontend mTLS is rejected by the webhook, as only the services pods get a SPIFFE identity.
+func (b *ConfigmapBuilder) buildSpireTLSConfig() temporalconfig.RootTLS {
+	rootTLS := temporalconfig.RootTLS{
+		RefreshInterval:  b.instance.Spec.MTLS.RefreshInterval.Duration,
+		ExpirationChecks: temporalconfig.CertExpirationValidation{},
+	}
+
+	serverTLS := temporalconfig.ServerTLS{
+		CertFile:          spire.SVIDFilePath(),
+		KeyFile:           spire.SVIDKeyFilePath(),
+		ClientCAFiles:     []string{spire.BundleFilePath()},
+		RequireClientAuth: true,
+	}
+
+	if b.instance.Spec.MTLS.InternodeEnabled() {
+		internodeClientTLS := temporalconfig.ClientTLS{
+			ServerName:  b.instance.Spec.MTLS.Internode.ServerName(b.instance),
+			RootCAFiles: []string{spire.BundleFilePath()},
+			ForceTLS:    true,
+		}
+
+		rootTLS.Internode = temporalconfig.GroupTLS{
+			Client: internodeClientTLS,
+			Server: serverTLS,
+		}
+
+		if b.instance.Spec.Services.InternalFrontend.IsEnabled() {
+			rootTLS.SystemWorker = temporalconfig.WorkerTLS{
+				Client:   internodeClientTLS,
+				CertFile: spire.SVIDFilePath(),
+				KeyFile:  spire.SVIDKeyFilePath(),
+			}
+		}
+	}
+
+	return rootTLS
+}
+
 func (b *ConfigmapBuilder) Update(object client.Object) error {
 	configMap := object.(*corev1.ConfigMap)
 
@@ -304,10 +368,10 @@ func (b *ConfigmapBuilder) Update(object client.Object) error {
 		}
 	}
 
-	if b.instance.Spec.DynamicConfig != nil {
+	if dynamicConfig := b.instance.GetDynamicConfig(); dynamicConfig != nil {
 		temporalCfg.DynamicConfigClient = &dynamicconfig.FileBasedClientConfig{
 			Filepath:     "/etc/temporal/config/dynamic_config.yaml",
-			PollInterval: b.instance.Spec.DynamicConfig.PollInterval.Duration,
+			PollInterval: dynamicConfig.PollInterval.Duration,
 		}
 	}
 
@@ -352,7 +416,7 @@ func (b *ConfigmapBuilder) Update(object client.Object) error {
 		}
 	}
 
-	if b.instance.MTLSWithCertManagerEnabled() {
+	if b.instance.MTLSWithCertificatesEnabled() {
 		temporalCfg.Global.TLS = temporalconfig.RootTLS{
 			RefreshInterval:  b.instance.Spec.MTLS.RefreshInterval.Duration,
 			ExpirationChecks: temporalconfig.CertExpirationValidation{},
@@ -436,6 +500,10 @@ func (b *ConfigmapBuilder) Update(object client.Object) error {
 		}
 	}
 
+	if b.instance.MTLSWithSpireEnabled() {
+		temporalCfg.Global.TLS = b.buildSpireTLSConfig()
+	}
+
 	result, err := yaml.Marshal(temporalCfg)
 	if err != nil {
 		return fmt.Errorf("failed marshaling temporal config: %w", err)
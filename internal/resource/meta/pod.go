@@ -23,32 +23,47 @@ import (
 	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/istio"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/linkerd"
 	"github.com/alexandrevilain/temporal-operator/internal/resource/prometheus"
+	"github.com/alexandrevilain/temporal-operator/pkg/version"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
 	configHashKey = "operator.temporal.io/config"
+	// CABundleHashKey is the pod annotation holding the hash of the mTLS CA bundle mounted in the pod.
+	CABundleHashKey = "operator.temporal.io/ca-bundle"
+	// DatastoreSecretsHashKey is the pod annotation holding the hash of the datastore secrets used by the pod.
+	DatastoreSecretsHashKey = "operator.temporal.io/datastore-secrets"
 )
 
 // BuildPodObjectMeta return ObjectMeta for the service (frontend, ui, admintools) of the provided Cluster.
-func BuildPodObjectMeta(instance *v1beta1.TemporalCluster, service, configHash string) metav1.ObjectMeta {
+// The provided version is set as the pods version label.
+// The caBundleHash and datastoreSecretsHash are omitted from the annotations when empty.
+func BuildPodObjectMeta(instance *v1beta1.TemporalCluster, service string, version *version.Version, configHash, caBundleHash, datastoreSecretsHash string) metav1.ObjectMeta {
 	instanceAnnotations := metadata.FilterAnnotations(instance.Annotations, func(k, _ string) bool {
 		return k != "kubectl.kubernetes.io/last-applied-configuration"
 	})
 
+	hashes := map[string]string{
+		configHashKey: configHash,
+	}
+	if caBundleHash != "" {
+		hashes[CABundleHashKey] = caBundleHash
+	}
+	if datastoreSecretsHash != "" {
+		hashes[DatastoreSecretsHashKey] = datastoreSecretsHash
+	}
+
 	return metav1.ObjectMeta{
 		Labels: metadata.Merge(
 			istio.GetLabels(instance),
-			metadata.GetLabels(instance, service, instance.Spec.Version, instance.Labels),
+			metadata.GetLabels(instance, service, version, instance.Labels),
 		),
 		Annotations: metadata.Merge(
 			linkerd.GetAnnotations(instance),
 			istio.GetAnnotations(instance),
 			prometheus.GetAnnotations(instance),
 			metadata.GetAnnotations(instance.Name, instanceAnnotations),
-			map[string]string{
-				configHashKey: configHash,
-			},
+			hashes,
 		),
 	}
 }
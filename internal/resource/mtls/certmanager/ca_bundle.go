@@ -0,0 +1,94 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package certmanager
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// DecodeCertificates decodes all PEM encoded certificates found in the provided data.
+func DecodeCertificates(data []byte) ([]*x509.Certificate, error) {
+	certificates := []*x509.Certificate{}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse certificate: %w", err)
+		}
+
+		certificates = append(certificates, certificate)
+	}
+
+	return certificates, nil
+}
+
+// EncodeCertificates returns the PEM encoded bundle of the provided certificates.
+func EncodeCertificates(certificates []*x509.Certificate) []byte {
+	buf := &bytes.Buffer{}
+	for _, certificate := range certificates {
+		// pem.Encode only fails while writing to the buffer, which never returns an error.
+		_ = pem.Encode(buf, &pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})
+	}
+	return buf.Bytes()
+}
+
+// ContainsCertificate returns true if the provided certificate is part of the provided list.
+func ContainsCertificate(certificates []*x509.Certificate, certificate *x509.Certificate) bool {
+	for _, c := range certificates {
+		if c.Equal(certificate) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsIssuedBy returns true if the first certificate of the provided PEM encoded chain
+// has been signed by the first certificate of the provided PEM encoded CA.
+func IsIssuedBy(chain, ca []byte) (bool, error) {
+	certificates, err := DecodeCertificates(chain)
+	if err != nil {
+		return false, err
+	}
+	if len(certificates) == 0 {
+		return false, errors.New("no certificate found in chain")
+	}
+
+	cas, err := DecodeCertificates(ca)
+	if err != nil {
+		return false, err
+	}
+	if len(cas) == 0 {
+		return false, errors.New("no certificate found in CA")
+	}
+
+	return certificates[0].CheckSignatureFrom(cas[0]) == nil, nil
+}
@@ -0,0 +1,138 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package certmanager_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/certmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateCertificate(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+
+	if parent == nil {
+		parent = template
+		parentKey = key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return certificate, key
+}
+
+func TestEncodeDecodeCertificates(t *testing.T) {
+	previous, _ := generateCertificate(t, "previous", nil, nil)
+	current, _ := generateCertificate(t, "current", nil, nil)
+
+	bundle := certmanager.EncodeCertificates([]*x509.Certificate{previous, current})
+
+	certificates, err := certmanager.DecodeCertificates(bundle)
+	require.NoError(t, err)
+	require.Len(t, certificates, 2)
+	assert.True(t, certificates[0].Equal(previous))
+	assert.True(t, certificates[1].Equal(current))
+
+	certificates, err = certmanager.DecodeCertificates(nil)
+	require.NoError(t, err)
+	assert.Empty(t, certificates)
+}
+
+func TestContainsCertificate(t *testing.T) {
+	previous, _ := generateCertificate(t, "previous", nil, nil)
+	current, _ := generateCertificate(t, "current", nil, nil)
+
+	assert.True(t, certmanager.ContainsCertificate([]*x509.Certificate{previous, current}, current))
+	assert.False(t, certmanager.ContainsCertificate([]*x509.Certificate{previous}, current))
+	assert.False(t, certmanager.ContainsCertificate(nil, current))
+}
+
+func TestIsIssuedBy(t *testing.T) {
+	previousRoot, previousRootKey := generateCertificate(t, "previous root", nil, nil)
+	currentRoot, _ := generateCertificate(t, "current root", nil, nil)
+	intermediate, _ := generateCertificate(t, "intermediate", previousRoot, previousRootKey)
+
+	chain := certmanager.EncodeCertificates([]*x509.Certificate{intermediate, previousRoot})
+
+	tests := map[string]struct {
+		chain       []byte
+		ca          []byte
+		expected    bool
+		expectedErr string
+	}{
+		"issued by the provided CA": {
+			chain:    chain,
+			ca:       certmanager.EncodeCertificates([]*x509.Certificate{previousRoot}),
+			expected: true,
+		},
+		"issued by a previous CA": {
+			chain:    chain,
+			ca:       certmanager.EncodeCertificates([]*x509.Certificate{currentRoot}),
+			expected: false,
+		},
+		"empty chain": {
+			chain:       nil,
+			ca:          certmanager.EncodeCertificates([]*x509.Certificate{currentRoot}),
+			expectedErr: "no certificate found in chain",
+		},
+		"empty CA": {
+			chain:       chain,
+			ca:          nil,
+			expectedErr: "no certificate found in CA",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			issued, err := certmanager.IsIssuedBy(test.chain, test.ca)
+			if test.expectedErr != "" {
+				assert.EqualError(tt, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(tt, err)
+			assert.Equal(tt, test.expected, issued)
+		})
+	}
+}
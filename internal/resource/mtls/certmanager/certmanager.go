@@ -29,11 +29,15 @@ const (
 	frontendIntermediateCAIssuer  = "frontend-intermediate-ca-issuer"
 	internodeIntermediateCAIssuer = "internode-intermediate-ca-issuer"
 
-	rootCaIssuer      = "root-ca-issuer"
-	rootCaCertificate = "root-ca-certificate"
+	rootCaIssuer = "root-ca-issuer"
 )
 
 const (
+	// RootCACertificate is the name of the root CA certificate used to issue intermediate CA certificates.
+	RootCACertificate = "root-ca-certificate"
+	// CABundle is the name of the secret holding the CAs trusted by the cluster components.
+	// During a CA rotation it contains both the previous and the new root CA.
+	CABundle = "ca-bundle"
 	// InternodeCertificate is the name of the certificate used for internode communications.
 	InternodeCertificate = "internode-certificate"
 	// FrontendCertificate is the name of the certificate used by the frontend.
@@ -0,0 +1,74 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package certmanager
+
+import (
+	"fmt"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+type MTLSCABundleSecretBuilder struct {
+	instance *v1beta1.TemporalCluster
+	scheme   *runtime.Scheme
+	// bundle is the PEM encoded list of CAs trusted by the cluster components.
+	bundle []byte
+}
+
+func NewMTLSCABundleSecretBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, bundle []byte) *MTLSCABundleSecretBuilder {
+	return &MTLSCABundleSecretBuilder{
+		instance: instance,
+		scheme:   scheme,
+		bundle:   bundle,
+	}
+}
+
+func (b *MTLSCABundleSecretBuilder) Build() client.Object {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.ChildResourceName(CABundle),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, CABundle, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *MTLSCABundleSecretBuilder) Enabled() bool {
+	return b.instance.MTLSWithCertificatesEnabled()
+}
+
+func (b *MTLSCABundleSecretBuilder) Update(object client.Object) error {
+	secret := object.(*corev1.Secret)
+	secret.Labels = object.GetLabels()
+	secret.Annotations = object.GetAnnotations()
+	secret.Data = map[string][]byte{
+		TLSCA: b.bundle,
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, secret, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+	return nil
+}
@@ -72,9 +72,8 @@ func (b *MTLSFrontendCertificateBuilder) Update(object client.Object) error {
 			Algorithm:      certmanagerv1.RSAKeyAlgorithm,
 			Size:           4096,
 		},
-		DNSNames: []string{
-			b.instance.Spec.MTLS.Frontend.ServerName(b.instance),
-		},
+		// Includes user-supplied extra DNS names and the frontend external hostnames.
+		DNSNames: b.instance.FrontendDNSNames(),
 		IssuerRef: certmanagermeta.ObjectReference{
 			Name: b.instance.ChildResourceName(frontendIntermediateCAIssuer),
 			Kind: certmanagerv1.IssuerKind,
@@ -84,11 +83,6 @@ func (b *MTLSFrontendCertificateBuilder) Update(object client.Object) error {
 		},
 	}
 
-	// Add user-supplied extra DNS names.
-	certificate.Spec.DNSNames = append(certificate.Spec.DNSNames,
-		b.instance.Spec.MTLS.Frontend.ExtraDNSNames...,
-	)
-
 	if err := controllerutil.SetControllerReference(b.instance, certificate, b.scheme); err != nil {
 		return fmt.Errorf("failed setting controller reference: %w", err)
 	}
@@ -0,0 +1,128 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package certmanager_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/certmanager"
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestMTLSFrontendCertificateBuilderDNSNames(t *testing.T) {
+	tests := map[string]struct {
+		extraDNSNames    []string
+		expose           *v1beta1.FrontendExposeSpec
+		expectedDNSNames []string
+	}{
+		"server name only": {
+			expectedDNSNames: []string{"test-frontend.demo.svc.cluster.local"},
+		},
+		"extra dns names": {
+			extraDNSNames: []string{"temporal.internal"},
+			expectedDNSNames: []string{
+				"test-frontend.demo.svc.cluster.local",
+				"temporal.internal",
+			},
+		},
+		"external hostnames": {
+			extraDNSNames: []string{"temporal.internal"},
+			expose: &v1beta1.FrontendExposeSpec{
+				Service: &v1beta1.FrontendExternalServiceSpec{
+					Hostnames: []string{"lb.example.com"},
+				},
+				Ingress: &v1beta1.FrontendIngressSpec{
+					Hosts: []string{"ingress.example.com"},
+				},
+				GRPCRoute: &v1beta1.FrontendRouteSpec{
+					Hostnames: []gatewayv1.Hostname{"grpc.example.com"},
+				},
+				TLSRoute: &v1beta1.FrontendRouteSpec{
+					Hostnames: []gatewayv1.Hostname{"tls.example.com"},
+				},
+			},
+			expectedDNSNames: []string{
+				"test-frontend.demo.svc.cluster.local",
+				"temporal.internal",
+				"lb.example.com",
+				"ingress.example.com",
+				"grpc.example.com",
+				"tls.example.com",
+			},
+		},
+		"duplicated hostnames": {
+			extraDNSNames: []string{"temporal.example.com"},
+			expose: &v1beta1.FrontendExposeSpec{
+				Ingress: &v1beta1.FrontendIngressSpec{
+					Hosts: []string{"temporal.example.com"},
+				},
+				TLSRoute: &v1beta1.FrontendRouteSpec{
+					Hostnames: []gatewayv1.Hostname{"temporal.example.com"},
+				},
+			},
+			expectedDNSNames: []string{
+				"test-frontend.demo.svc.cluster.local",
+				"temporal.example.com",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "demo",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					MTLS: &v1beta1.MTLSSpec{
+						Provider: v1beta1.CertManagerMTLSProvider,
+						Frontend: &v1beta1.FrontendMTLSSpec{
+							Enabled:       true,
+							ExtraDNSNames: test.extraDNSNames,
+						},
+					},
+					Services: &v1beta1.ServicesSpec{
+						Frontend: &v1beta1.FrontendServiceSpec{
+							Expose: test.expose,
+						},
+					},
+				},
+			}
+			cluster.Default()
+
+			scheme := runtime.NewScheme()
+			require.NoError(tt, v1beta1.AddToScheme(scheme))
+
+			builder := certmanager.NewMTLSFrontendCertificateBuilder(cluster, scheme)
+			require.True(tt, builder.Enabled())
+
+			object := builder.Build()
+			require.NoError(tt, builder.Update(object))
+
+			certificate := object.(*certmanagerv1.Certificate)
+			assert.Equal(tt, test.expectedDNSNames, certificate.Spec.DNSNames)
+		})
+	}
+}
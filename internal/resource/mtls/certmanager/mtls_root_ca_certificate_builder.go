@@ -45,9 +45,9 @@ func NewMTLSRootCACertificateBuilder(instance *v1beta1.TemporalCluster, scheme *
 func (b *MTLSRootCACertificateBuilder) Build() client.Object {
 	return &certmanagerv1.Certificate{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        b.instance.ChildResourceName(rootCaCertificate),
+			Name:        b.instance.ChildResourceName(RootCACertificate),
 			Namespace:   b.instance.Namespace,
-			Labels:      metadata.GetLabels(b.instance, rootCaCertificate, b.instance.Spec.Version, b.instance.Labels),
+			Labels:      metadata.GetLabels(b.instance, RootCACertificate, b.instance.Spec.Version, b.instance.Labels),
 			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
 		},
 	}
@@ -65,7 +65,7 @@ func (b *MTLSRootCACertificateBuilder) Update(object client.Object) error {
 		IsCA:        true,
 		Duration:    b.instance.Spec.MTLS.CertificatesDuration.RootCACertificate,
 		RenewBefore: b.instance.Spec.MTLS.RenewBefore,
-		SecretName:  b.instance.ChildResourceName(rootCaCertificate),
+		SecretName:  b.instance.ChildResourceName(RootCACertificate),
 		CommonName:  "Root CA certificate",
 		PrivateKey:  caCertificatePrivateKey,
 		DNSNames: []string{
@@ -32,7 +32,7 @@ func NewMTLSRootCAIssuerBuilder(instance *v1beta1.TemporalCluster, scheme *runti
 			instance:   instance,
 			scheme:     scheme,
 			name:       rootCaIssuer,
-			secretName: rootCaCertificate,
+			secretName: RootCACertificate,
 		},
 	}
 }
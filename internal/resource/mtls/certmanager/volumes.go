@@ -0,0 +1,67 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package certmanager
+
+import (
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+// GetCABundleVolumeSource returns the volume source exposing the CA bundle trusted by the cluster components.
+func GetCABundleVolumeSource(instance *v1beta1.TemporalCluster) corev1.VolumeSource {
+	return corev1.VolumeSource{
+		Secret: &corev1.SecretVolumeSource{
+			SecretName:  instance.ChildResourceName(CABundle),
+			DefaultMode: ptr.To[int32](corev1.SecretVolumeSourceDefaultMode),
+		},
+	}
+}
+
+// GetClientCertificateVolumeSource returns the volume source exposing the provided client certificate key pair
+// along with the CA bundle trusted by the cluster components as the client CA.
+func GetClientCertificateVolumeSource(instance *v1beta1.TemporalCluster, clientCertificate string) corev1.VolumeSource {
+	return corev1.VolumeSource{
+		Projected: &corev1.ProjectedVolumeSource{
+			Sources: []corev1.VolumeProjection{
+				{
+					Secret: &corev1.SecretProjection{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: instance.ChildResourceName(clientCertificate),
+						},
+						Items: []corev1.KeyToPath{
+							{Key: TLSCert, Path: TLSCert},
+							{Key: TLSKey, Path: TLSKey},
+						},
+					},
+				},
+				{
+					Secret: &corev1.SecretProjection{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: instance.ChildResourceName(CABundle),
+						},
+						Items: []corev1.KeyToPath{
+							{Key: TLSCA, Path: TLSCA},
+						},
+					},
+				},
+			},
+			DefaultMode: ptr.To[int32](corev1.ProjectedVolumeSourceDefaultMode),
+		},
+	}
+}
@@ -0,0 +1,256 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package istio
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	"go.temporal.io/server/common/primitives"
+	"google.golang.org/protobuf/proto"
+	istioapisecurityv1beta1 "istio.io/api/security/v1beta1"
+	istioapiv1beta1 "istio.io/api/type/v1beta1"
+	istiosecurityv1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ resource.Builder = (*AuthorizationPolicyBuilder)(nil)
+
+// AuthorizationPolicyBuilder builds the AuthorizationPolicy restricting the callers of a temporal service's pods.
+type AuthorizationPolicyBuilder struct {
+	serviceName string
+	instance    *v1beta1.TemporalCluster
+	scheme      *runtime.Scheme
+	service     *v1beta1.ServiceSpec
+}
+
+func NewAuthorizationPolicyBuilder(serviceName string, instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, service *v1beta1.ServiceSpec) *AuthorizationPolicyBuilder {
+	return &AuthorizationPolicyBuilder{
+		serviceName: serviceName,
+		instance:    instance,
+		scheme:      scheme,
+		service:     service,
+	}
+}
+
+func (b *AuthorizationPolicyBuilder) Build() client.Object {
+	return &istiosecurityv1beta1.AuthorizationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.ChildResourceName(b.serviceName),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, b.serviceName, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *AuthorizationPolicyBuilder) Enabled() bool {
+	if b.serviceName == string(primitives.InternalFrontendService) && !b.instance.Spec.Services.InternalFrontend.IsEnabled() {
+		return false
+	}
+	return b.instance.IstioAuthorizationPoliciesEnabled()
+}
+
+func (b *AuthorizationPolicyBuilder) Update(object client.Object) error {
+	policy := object.(*istiosecurityv1beta1.AuthorizationPolicy)
+
+	internodePorts := []string{port(b.service.MembershipPort)}
+	frontendPorts := []string{}
+	if b.serviceName == string(primitives.FrontendService) {
+		frontendPorts = append(frontendPorts, port(b.service.Port))
+		if b.service.HTTPPort != nil && *b.service.HTTPPort > 0 {
+			frontendPorts = append(frontendPorts, port(b.service.HTTPPort))
+		}
+	} else {
+		internodePorts = append(internodePorts, port(b.service.Port))
+	}
+
+	rules := []*istioapisecurityv1beta1.Rule{
+		allowPrincipals(clusterPrincipals(b.instance), internodePorts),
+	}
+
+	if len(frontendPorts) > 0 {
+		principals := frontendPrincipals(b.instance)
+		// With a waypoint, frontend clients are authorized by the waypoint, which then connects using its own identity.
+		if b.instance.IstioWaypointEnabled() {
+			principals = append(principals, principal(b.instance.Namespace, WaypointName(b.instance)))
+		}
+		rules = append(rules, allowPrincipals(principals, frontendPorts))
+	}
+
+	// Metrics collectors are not restricted.
+	if b.instance.Spec.Metrics.IsEnabled() && b.instance.Spec.Metrics.Prometheus != nil && b.instance.Spec.Metrics.Prometheus.ListenPort != nil {
+		rules = append(rules, &istioapisecurityv1beta1.Rule{
+			To: []*istioapisecurityv1beta1.Rule_To{
+				{
+					Operation: &istioapisecurityv1beta1.Operation{
+						Ports: []string{port(b.instance.Spec.Metrics.Prometheus.ListenPort)},
+					},
+				},
+			},
+		})
+	}
+
+	policy.Spec = istioapisecurityv1beta1.AuthorizationPolicy{
+		Selector: &istioapiv1beta1.WorkloadSelector{
+			MatchLabels: metadata.LabelsSelector(b.instance, b.serviceName),
+		},
+		Action: istioapisecurityv1beta1.AuthorizationPolicy_ALLOW,
+		Rules:  rules,
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, policy, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
+
+func (AuthorizationPolicyBuilder) Equal(x, y *istioapisecurityv1beta1.AuthorizationPolicy) bool {
+	return proto.Equal(x, y)
+}
+
+var _ resource.Builder = (*WaypointAuthorizationPolicyBuilder)(nil)
+
+// WaypointAuthorizationPolicyBuilder builds the AuthorizationPolicy enforced by the waypoint proxy
+// on the traffic sent to the frontend service.
+type WaypointAuthorizationPolicyBuilder struct {
+	instance *v1beta1.TemporalCluster
+	scheme   *runtime.Scheme
+}
+
+func NewWaypointAuthorizationPolicyBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme) *WaypointAuthorizationPolicyBuilder {
+	return &WaypointAuthorizationPolicyBuilder{
+		instance: instance,
+		scheme:   scheme,
+	}
+}
+
+func (b *WaypointAuthorizationPolicyBuilder) Build() client.Object {
+	return &istiosecurityv1beta1.AuthorizationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        WaypointName(b.instance),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, "waypoint", b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *WaypointAuthorizationPolicyBuilder) Enabled() bool {
+	return b.instance.IstioAuthorizationPoliciesEnabled() && b.instance.IstioWaypointEnabled()
+}
+
+func (b *WaypointAuthorizationPolicyBuilder) Update(object client.Object) error {
+	policy := object.(*istiosecurityv1beta1.AuthorizationPolicy)
+	policy.Spec = istioapisecurityv1beta1.AuthorizationPolicy{
+		TargetRefs: []*istioapiv1beta1.PolicyTargetReference{
+			{
+				Kind: "Service",
+				Name: b.instance.ChildResourceName(string(primitives.FrontendService)),
+			},
+		},
+		Action: istioapisecurityv1beta1.AuthorizationPolicy_ALLOW,
+		Rules: []*istioapisecurityv1beta1.Rule{
+			{
+				From: []*istioapisecurityv1beta1.Rule_From{
+					{
+						Source: &istioapisecurityv1beta1.Source{
+							Principals: frontendPrincipals(b.instance),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, policy, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
+
+func (WaypointAuthorizationPolicyBuilder) Equal(x, y *istioapisecurityv1beta1.AuthorizationPolicy) bool {
+	return proto.Equal(x, y)
+}
+
+func allowPrincipals(principals, ports []string) *istioapisecurityv1beta1.Rule {
+	return &istioapisecurityv1beta1.Rule{
+		From: []*istioapisecurityv1beta1.Rule_From{
+			{
+				Source: &istioapisecurityv1beta1.Source{
+					Principals: principals,
+				},
+			},
+		},
+		To: []*istioapisecurityv1beta1.Rule_To{
+			{
+				Operation: &istioapisecurityv1beta1.Operation{
+					Ports: ports,
+				},
+			},
+		},
+	}
+}
+
+func port(p *int32) string {
+	return strconv.Itoa(int(*p))
+}
+
+// principal returns the principal of a service account, whatever the mesh trust domain is.
+func principal(namespace, serviceAccount string) string {
+	return fmt.Sprintf("*/ns/%s/sa/%s", namespace, serviceAccount)
+}
+
+// clusterPrincipals returns the principals of the cluster's services.
+func clusterPrincipals(instance *v1beta1.TemporalCluster) []string {
+	services := []primitives.ServiceName{
+		primitives.FrontendService,
+		primitives.HistoryService,
+		primitives.MatchingService,
+		primitives.WorkerService,
+	}
+	if instance.Spec.Services.InternalFrontend.IsEnabled() {
+		services = append(services, primitives.InternalFrontendService)
+	}
+
+	principals := []string{}
+	for _, service := range services {
+		principals = append(principals, principal(instance.Namespace, instance.ChildResourceName(string(service))))
+	}
+
+	return principals
+}
+
+// frontendPrincipals returns the principals allowed to reach the frontend.
+func frontendPrincipals(instance *v1beta1.TemporalCluster) []string {
+	principals := clusterPrincipals(instance)
+	// UI and admin tools pods run with the namespace's default service account.
+	if (instance.Spec.UI != nil && instance.Spec.UI.Enabled) ||
+		(instance.Spec.AdminTools != nil && instance.Spec.AdminTools.Enabled) {
+		principals = append(principals, principal(instance.Namespace, "default"))
+	}
+	return append(principals, instance.Spec.MTLS.Istio.FrontendPrincipals...)
+}
@@ -0,0 +1,155 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package istio_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/istio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/server/common/primitives"
+	istiosecurityv1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newCluster(spec *v1beta1.IstioMTLSSpec) *v1beta1.TemporalCluster {
+	c := &v1beta1.TemporalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "demo",
+		},
+		Spec: v1beta1.TemporalClusterSpec{
+			MTLS: &v1beta1.MTLSSpec{
+				Provider: v1beta1.IstioMTLSProvider,
+				Istio:    spec,
+			},
+		},
+	}
+	c.Default()
+	return c
+}
+
+func TestAuthorizationPolicyBuilder(t *testing.T) {
+	clusterPrincipals := []string{
+		"*/ns/demo/sa/test-frontend",
+		"*/ns/demo/sa/test-history",
+		"*/ns/demo/sa/test-matching",
+		"*/ns/demo/sa/test-worker",
+	}
+
+	tests := map[string]struct {
+		serviceName   string
+		spec          *v1beta1.IstioMTLSSpec
+		expectedRules []rule
+	}{
+		"history only allows the cluster's services": {
+			serviceName: "history",
+			spec:        &v1beta1.IstioMTLSSpec{AuthorizationPolicies: true},
+			expectedRules: []rule{
+				{ports: []string{"6934", "7234"}, principals: clusterPrincipals},
+			},
+		},
+		"frontend allows configured principals": {
+			serviceName: "frontend",
+			spec: &v1beta1.IstioMTLSSpec{
+				AuthorizationPolicies: true,
+				FrontendPrincipals:    []string{"*/ns/workers/sa/worker"},
+			},
+			expectedRules: []rule{
+				{ports: []string{"6933"}, principals: clusterPrincipals},
+				{ports: []string{"7233", "7243"}, principals: append(clusterPrincipals, "*/ns/workers/sa/worker")},
+			},
+		},
+		"frontend allows the waypoint": {
+			serviceName: "frontend",
+			spec: &v1beta1.IstioMTLSSpec{
+				AuthorizationPolicies: true,
+				Ambient: &v1beta1.IstioAmbientSpec{
+					Enabled:  true,
+					Waypoint: true,
+				},
+			},
+			expectedRules: []rule{
+				{ports: []string{"6933"}, principals: clusterPrincipals},
+				{ports: []string{"7233", "7243"}, principals: append(clusterPrincipals, "*/ns/demo/sa/test-waypoint")},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := newCluster(test.spec)
+			service, err := cluster.Spec.Services.GetServiceSpec(primitives.ServiceName(test.serviceName))
+			require.NoError(tt, err)
+
+			scheme := runtime.NewScheme()
+			require.NoError(tt, v1beta1.AddToScheme(scheme))
+
+			builder := istio.NewAuthorizationPolicyBuilder(test.serviceName, cluster, scheme, service)
+			require.True(tt, builder.Enabled())
+
+			object := builder.Build()
+			require.NoError(tt, builder.Update(object))
+
+			policy := object.(*istiosecurityv1beta1.AuthorizationPolicy)
+			rules := []rule{}
+			for _, r := range policy.Spec.GetRules() {
+				rules = append(rules, rule{
+					ports:      r.GetTo()[0].GetOperation().GetPorts(),
+					principals: r.GetFrom()[0].GetSource().GetPrincipals(),
+				})
+			}
+			assert.Equal(tt, test.expectedRules, rules)
+		})
+	}
+}
+
+func TestGetLabels(t *testing.T) {
+	tests := map[string]struct {
+		spec     *v1beta1.IstioMTLSSpec
+		expected map[string]string
+	}{
+		"sidecar": {
+			expected: map[string]string{"sidecar.istio.io/inject": "true"},
+		},
+		"ambient": {
+			spec: &v1beta1.IstioMTLSSpec{
+				Ambient: &v1beta1.IstioAmbientSpec{Enabled: true},
+			},
+			expected: map[string]string{
+				"istio.io/dataplane-mode": "ambient",
+				"sidecar.istio.io/inject": "false",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := newCluster(test.spec)
+			assert.Equal(tt, test.expected, istio.GetLabels(cluster))
+		})
+	}
+}
+
+type rule struct {
+	ports      []string
+	principals []string
+}
@@ -21,10 +21,26 @@ import (
 	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
 )
 
+const (
+	// DataplaneModeLabel enrolls a namespace or a pod in the ambient mesh.
+	DataplaneModeLabel = "istio.io/dataplane-mode"
+	// UseWaypointLabel makes the traffic to a service go through the referenced waypoint proxy.
+	UseWaypointLabel = "istio.io/use-waypoint"
+
+	ambientDataplaneMode = "ambient"
+)
+
 // GetLabels returns istio labels to enable proxy injection if the provided Cluster
 // instance has mTLS enabled using istio.
+// In ambient mode, pods are enrolled in the ambient mesh and sidecar injection is disabled.
 func GetLabels(instance *v1beta1.TemporalCluster) map[string]string {
-	if instance.Spec.MTLS != nil && instance.Spec.MTLS.Provider == v1beta1.IstioMTLSProvider {
+	if instance.IstioAmbientEnabled() {
+		return map[string]string{
+			DataplaneModeLabel:        ambientDataplaneMode,
+			"sidecar.istio.io/inject": "false",
+		}
+	}
+	if instance.MTLSWithIstioEnabled() {
 		return map[string]string{
 			"sidecar.istio.io/inject": "true",
 		}
@@ -33,12 +49,34 @@ func GetLabels(instance *v1beta1.TemporalCluster) map[string]string {
 }
 
 // GetAnnotations returns istio annotations to delay application startup until the pod proxy is ready to accept traffic.
-// Returned only if the provided Cluster instance has mTLS enabled using istio.
+// Returned only if the provided Cluster instance has mTLS enabled using istio sidecars.
 func GetAnnotations(instance *v1beta1.TemporalCluster) map[string]string {
-	if instance.Spec.MTLS != nil && instance.Spec.MTLS.Provider == v1beta1.IstioMTLSProvider {
+	if instance.MTLSWithIstioEnabled() && !instance.IstioAmbientEnabled() {
 		return map[string]string{
 			"proxy.istio.io/config": `{ "holdApplicationUntilProxyStarts": true }`,
 		}
 	}
 	return map[string]string{}
 }
+
+// GetNamespaceLabels returns the labels enrolling the cluster's namespace in the ambient mesh.
+// Returned only if the provided Cluster instance runs in ambient mode and asks for namespace enrollment.
+func GetNamespaceLabels(instance *v1beta1.TemporalCluster) map[string]string {
+	if instance.IstioAmbientEnabled() && instance.Spec.MTLS.Istio.Ambient.LabelNamespace {
+		return map[string]string{
+			DataplaneModeLabel: ambientDataplaneMode,
+		}
+	}
+	return map[string]string{}
+}
+
+// GetFrontendServiceLabels returns the labels routing the frontend traffic through the cluster's waypoint proxy.
+// Returned only if the provided Cluster instance has a waypoint proxy.
+func GetFrontendServiceLabels(instance *v1beta1.TemporalCluster) map[string]string {
+	if instance.IstioWaypointEnabled() {
+		return map[string]string{
+			UseWaypointLabel: WaypointName(instance),
+		}
+	}
+	return map[string]string{}
+}
@@ -0,0 +1,100 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package istio
+
+import (
+	"fmt"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	waypointGatewayClassName = "istio-waypoint"
+	waypointForLabel         = "istio.io/waypoint-for"
+	// waypointHBONEPort is the port istio waypoints receive mesh traffic on.
+	waypointHBONEPort = 15008
+)
+
+// WaypointName returns the name of the cluster's waypoint proxy.
+func WaypointName(instance *v1beta1.TemporalCluster) string {
+	return instance.ChildResourceName("waypoint")
+}
+
+var _ resource.Builder = (*WaypointBuilder)(nil)
+
+// WaypointBuilder builds the Gateway deploying the cluster's waypoint proxy in ambient mode.
+type WaypointBuilder struct {
+	instance *v1beta1.TemporalCluster
+	scheme   *runtime.Scheme
+}
+
+func NewWaypointBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme) *WaypointBuilder {
+	return &WaypointBuilder{
+		instance: instance,
+		scheme:   scheme,
+	}
+}
+
+func (b *WaypointBuilder) Build() client.Object {
+	return &gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        WaypointName(b.instance),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, "waypoint", b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *WaypointBuilder) Enabled() bool {
+	return b.instance.IstioWaypointEnabled()
+}
+
+func (b *WaypointBuilder) Update(object client.Object) error {
+	gateway := object.(*gatewayv1.Gateway)
+	gateway.Labels = metadata.Merge(
+		object.GetLabels(),
+		map[string]string{
+			// Only service addressed traffic goes through the waypoint, internode traffic targets pods directly.
+			waypointForLabel: "service",
+		},
+	)
+	gateway.Spec = gatewayv1.GatewaySpec{
+		GatewayClassName: waypointGatewayClassName,
+		Listeners: []gatewayv1.Listener{
+			{
+				Name:     "mesh",
+				Port:     waypointHBONEPort,
+				Protocol: "HBONE",
+			},
+		},
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, gateway, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linkerd
+
+import (
+	"fmt"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	policyv1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/linkerd/apis/policy/v1alpha1"
+	policyv1beta1 "github.com/alexandrevilain/temporal-operator/pkg/linkerd/apis/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ resource.Builder = (*AuthorizationPolicyBuilder)(nil)
+
+type AuthorizationPolicyBuilder struct {
+	serviceName string
+	portName    string
+	instance    *v1beta1.TemporalCluster
+	scheme      *runtime.Scheme
+}
+
+func NewAuthorizationPolicyBuilder(serviceName, portName string, instance *v1beta1.TemporalCluster, scheme *runtime.Scheme) *AuthorizationPolicyBuilder {
+	return &AuthorizationPolicyBuilder{
+		serviceName: serviceName,
+		portName:    portName,
+		instance:    instance,
+		scheme:      scheme,
+	}
+}
+
+func (b *AuthorizationPolicyBuilder) Build() client.Object {
+	return &policyv1alpha1.AuthorizationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        serverName(b.instance, b.serviceName, b.portName),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, b.serviceName, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *AuthorizationPolicyBuilder) Enabled() bool {
+	return b.instance.LinkerdAuthorizationPoliciesEnabled() && isPortEnabled(b.instance, b.serviceName, b.portName)
+}
+
+func (b *AuthorizationPolicyBuilder) Update(object client.Object) error {
+	policy := object.(*policyv1alpha1.AuthorizationPolicy)
+
+	authentication := InternodeAuthenticationName(b.instance)
+	if isFrontendPort(b.serviceName, b.portName) {
+		authentication = FrontendAuthenticationName(b.instance)
+	}
+
+	policy.Spec = policyv1alpha1.AuthorizationPolicySpec{
+		TargetRef: policyv1alpha1.PolicyTargetReference{
+			Group: policyv1beta1.GroupVersion.Group,
+			Kind:  "Server",
+			Name:  serverName(b.instance, b.serviceName, b.portName),
+		},
+		RequiredAuthenticationRefs: []policyv1alpha1.PolicyTargetReference{
+			{
+				Group: policyv1alpha1.GroupVersion.Group,
+				Kind:  "MeshTLSAuthentication",
+				Name:  authentication,
+			},
+		},
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, policy, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linkerd_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/linkerd"
+	policyv1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/linkerd/apis/policy/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newCluster(spec *v1beta1.LinkerdMTLSSpec) *v1beta1.TemporalCluster {
+	c := &v1beta1.TemporalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: v1beta1.TemporalClusterSpec{
+			MTLS: &v1beta1.MTLSSpec{
+				Provider: v1beta1.LinkerdMTLSProvider,
+				Linkerd:  spec,
+			},
+		},
+	}
+	c.Default()
+	return c
+}
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+	return scheme
+}
+
+func TestAuthorizationPolicyBuilder(t *testing.T) {
+	tests := map[string]struct {
+		serviceName            string
+		portName               string
+		spec                   *v1beta1.LinkerdMTLSSpec
+		expectedEnabled        bool
+		expectedAuthentication string
+	}{
+		"frontend rpc port allows frontend clients": {
+			serviceName:            "frontend",
+			portName:               linkerd.RPCPort,
+			spec:                   &v1beta1.LinkerdMTLSSpec{AuthorizationPolicies: true},
+			expectedEnabled:        true,
+			expectedAuthentication: "test-frontend-clients",
+		},
+		"frontend http port allows frontend clients": {
+			serviceName:            "frontend",
+			portName:               linkerd.HTTPPort,
+			spec:                   &v1beta1.LinkerdMTLSSpec{AuthorizationPolicies: true},
+			expectedEnabled:        true,
+			expectedAuthentication: "test-frontend-clients",
+		},
+		"frontend membership port is internode": {
+			serviceName:            "frontend",
+			portName:               linkerd.MembershipPort,
+			spec:                   &v1beta1.LinkerdMTLSSpec{AuthorizationPolicies: true},
+			expectedEnabled:        true,
+			expectedAuthentication: "test-internode",
+		},
+		"history rpc port is internode": {
+			serviceName:            "history",
+			portName:               linkerd.RPCPort,
+			spec:                   &v1beta1.LinkerdMTLSSpec{AuthorizationPolicies: true},
+			expectedEnabled:        true,
+			expectedAuthentication: "test-internode",
+		},
+		"history has no http port": {
+			serviceName:     "history",
+			portName:        linkerd.HTTPPort,
+			spec:            &v1beta1.LinkerdMTLSSpec{AuthorizationPolicies: true},
+			expectedEnabled: false,
+		},
+		"disabled internal frontend": {
+			serviceName:     "internal-frontend",
+			portName:        linkerd.RPCPort,
+			spec:            &v1beta1.LinkerdMTLSSpec{AuthorizationPolicies: true},
+			expectedEnabled: false,
+		},
+		"policies not enabled": {
+			serviceName:     "frontend",
+			portName:        linkerd.RPCPort,
+			spec:            &v1beta1.LinkerdMTLSSpec{},
+			expectedEnabled: false,
+		},
+		"no linkerd spec": {
+			serviceName:     "frontend",
+			portName:        linkerd.RPCPort,
+			expectedEnabled: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			builder := linkerd.NewAuthorizationPolicyBuilder(test.serviceName, test.portName, newCluster(test.spec), newScheme(tt))
+			assert.Equal(tt, test.expectedEnabled, builder.Enabled())
+			if !test.expectedEnabled {
+				return
+			}
+
+			object := builder.Build()
+			require.NoError(tt, builder.Update(object))
+
+			policy := object.(*policyv1alpha1.AuthorizationPolicy)
+			assert.Equal(tt, "Server", policy.Spec.TargetRef.Kind)
+			assert.Equal(tt, policy.Name, policy.Spec.TargetRef.Name)
+			require.Len(tt, policy.Spec.RequiredAuthenticationRefs, 1)
+			assert.Equal(tt, test.expectedAuthentication, policy.Spec.RequiredAuthenticationRefs[0].Name)
+		})
+	}
+}
+
+func TestFrontendMeshTLSAuthenticationBuilder(t *testing.T) {
+	cluster := newCluster(&v1beta1.LinkerdMTLSSpec{
+		AuthorizationPolicies: true,
+		FrontendIdentities:    []string{"*.workers.serviceaccount.identity.linkerd.cluster.local"},
+	})
+	cluster.Spec.UI.Enabled = true
+
+	builder := linkerd.NewFrontendMeshTLSAuthenticationBuilder(cluster, newScheme(t))
+	require.True(t, builder.Enabled())
+
+	object := builder.Build()
+	require.NoError(t, builder.Update(object))
+
+	authentication := object.(*policyv1alpha1.MeshTLSAuthentication)
+	assert.Equal(t, []string{"*.workers.serviceaccount.identity.linkerd.cluster.local"}, authentication.Spec.Identities)
+
+	names := []string{}
+	for _, ref := range authentication.Spec.IdentityRefs {
+		assert.Equal(t, "ServiceAccount", ref.Kind)
+		names = append(names, ref.Name)
+	}
+	assert.Equal(t, []string{"test-frontend", "test-history", "test-matching", "test-worker", "default"}, names)
+}
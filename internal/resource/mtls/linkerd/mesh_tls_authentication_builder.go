@@ -0,0 +1,126 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linkerd
+
+import (
+	"fmt"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	policyv1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/linkerd/apis/policy/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ resource.Builder = (*InternodeMeshTLSAuthenticationBuilder)(nil)
+
+// InternodeMeshTLSAuthenticationBuilder builds the MeshTLSAuthentication matching the cluster's own services.
+type InternodeMeshTLSAuthenticationBuilder struct {
+	instance *v1beta1.TemporalCluster
+	scheme   *runtime.Scheme
+}
+
+func NewInternodeMeshTLSAuthenticationBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme) *InternodeMeshTLSAuthenticationBuilder {
+	return &InternodeMeshTLSAuthenticationBuilder{
+		instance: instance,
+		scheme:   scheme,
+	}
+}
+
+func (b *InternodeMeshTLSAuthenticationBuilder) Build() client.Object {
+	return &policyv1alpha1.MeshTLSAuthentication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        InternodeAuthenticationName(b.instance),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, internodeAuthenticationName, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *InternodeMeshTLSAuthenticationBuilder) Enabled() bool {
+	return b.instance.LinkerdAuthorizationPoliciesEnabled()
+}
+
+func (b *InternodeMeshTLSAuthenticationBuilder) Update(object client.Object) error {
+	authentication := object.(*policyv1alpha1.MeshTLSAuthentication)
+	authentication.Spec = policyv1alpha1.MeshTLSAuthenticationSpec{
+		IdentityRefs: clusterServiceAccountRefs(b.instance),
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, authentication, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
+
+var _ resource.Builder = (*FrontendMeshTLSAuthenticationBuilder)(nil)
+
+// FrontendMeshTLSAuthenticationBuilder builds the MeshTLSAuthentication matching the clients allowed to reach the frontend.
+type FrontendMeshTLSAuthenticationBuilder struct {
+	instance *v1beta1.TemporalCluster
+	scheme   *runtime.Scheme
+}
+
+func NewFrontendMeshTLSAuthenticationBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme) *FrontendMeshTLSAuthenticationBuilder {
+	return &FrontendMeshTLSAuthenticationBuilder{
+		instance: instance,
+		scheme:   scheme,
+	}
+}
+
+func (b *FrontendMeshTLSAuthenticationBuilder) Build() client.Object {
+	return &policyv1alpha1.MeshTLSAuthentication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        FrontendAuthenticationName(b.instance),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, frontendAuthenticationName, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *FrontendMeshTLSAuthenticationBuilder) Enabled() bool {
+	return b.instance.LinkerdAuthorizationPoliciesEnabled()
+}
+
+func (b *FrontendMeshTLSAuthenticationBuilder) Update(object client.Object) error {
+	authentication := object.(*policyv1alpha1.MeshTLSAuthentication)
+
+	refs := clusterServiceAccountRefs(b.instance)
+	// UI and admin tools pods run with the namespace's default service account.
+	if (b.instance.Spec.UI != nil && b.instance.Spec.UI.Enabled) ||
+		(b.instance.Spec.AdminTools != nil && b.instance.Spec.AdminTools.Enabled) {
+		refs = append(refs, serviceAccountRef("default"))
+	}
+
+	authentication.Spec = policyv1alpha1.MeshTLSAuthenticationSpec{
+		Identities:   b.instance.Spec.MTLS.Linkerd.FrontendIdentities,
+		IdentityRefs: refs,
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, authentication, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
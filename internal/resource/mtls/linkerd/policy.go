@@ -0,0 +1,126 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linkerd
+
+import (
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	policyv1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/linkerd/apis/policy/v1alpha1"
+	policyv1beta1 "github.com/alexandrevilain/temporal-operator/pkg/linkerd/apis/policy/v1beta1"
+	"go.temporal.io/server/common/primitives"
+)
+
+const (
+	// RPCPort is the name of the gRPC port of temporal services.
+	RPCPort = "rpc"
+	// MembershipPort is the name of the ringpop membership port of temporal services.
+	MembershipPort = "membership"
+	// HTTPPort is the name of the frontend HTTP API port.
+	HTTPPort = "http"
+
+	internodeAuthenticationName = "internode"
+	frontendAuthenticationName  = "frontend-clients"
+)
+
+// ServerPorts returns the names of the ports a linkerd Server could be created for.
+func ServerPorts() []string {
+	return []string{RPCPort, MembershipPort, HTTPPort}
+}
+
+// InternodeAuthenticationName returns the name of the MeshTLSAuthentication matching the cluster's own service accounts.
+func InternodeAuthenticationName(instance *v1beta1.TemporalCluster) string {
+	return instance.ChildResourceName(internodeAuthenticationName)
+}
+
+// FrontendAuthenticationName returns the name of the MeshTLSAuthentication matching the clients allowed to reach the frontend.
+func FrontendAuthenticationName(instance *v1beta1.TemporalCluster) string {
+	return instance.ChildResourceName(frontendAuthenticationName)
+}
+
+func serverName(instance *v1beta1.TemporalCluster, serviceName, portName string) string {
+	return instance.ChildResourceName(serviceName + "-" + portName)
+}
+
+func isServiceEnabled(instance *v1beta1.TemporalCluster, serviceName string) bool {
+	if serviceName == string(primitives.InternalFrontendService) {
+		return instance.Spec.Services.InternalFrontend.IsEnabled()
+	}
+	return true
+}
+
+func isPortEnabled(instance *v1beta1.TemporalCluster, serviceName, portName string) bool {
+	if !isServiceEnabled(instance, serviceName) {
+		return false
+	}
+
+	switch portName {
+	case RPCPort, MembershipPort:
+		return true
+	case HTTPPort:
+		frontend := instance.Spec.Services.Frontend
+		return serviceName == string(primitives.FrontendService) &&
+			frontend != nil && frontend.HTTPPort != nil && *frontend.HTTPPort > 0
+	default:
+		return false
+	}
+}
+
+// isFrontendPort returns true if the port is a public frontend endpoint.
+// All other ports are internode ports only reachable by the cluster's own services.
+func isFrontendPort(serviceName, portName string) bool {
+	return serviceName == string(primitives.FrontendService) && (portName == RPCPort || portName == HTTPPort)
+}
+
+func proxyProtocol(portName string) policyv1beta1.ProxyProtocol {
+	switch portName {
+	case RPCPort:
+		return policyv1beta1.ProxyProtocolHTTP2
+	case HTTPPort:
+		return policyv1beta1.ProxyProtocolHTTP1
+	default:
+		// Ringpop uses TChannel, which isn't a protocol the proxy can parse.
+		return policyv1beta1.ProxyProtocolOpaque
+	}
+}
+
+func serviceAccountRef(name string) policyv1alpha1.PolicyTargetReference {
+	return policyv1alpha1.PolicyTargetReference{
+		Kind: "ServiceAccount",
+		Name: name,
+	}
+}
+
+// clusterServiceAccountRefs returns references to the service accounts of the cluster's services.
+func clusterServiceAccountRefs(instance *v1beta1.TemporalCluster) []policyv1alpha1.PolicyTargetReference {
+	services := []primitives.ServiceName{
+		primitives.FrontendService,
+		primitives.HistoryService,
+		primitives.MatchingService,
+		primitives.WorkerService,
+		primitives.InternalFrontendService,
+	}
+
+	refs := []policyv1alpha1.PolicyTargetReference{}
+	for _, service := range services {
+		if !isServiceEnabled(instance, string(service)) {
+			continue
+		}
+		refs = append(refs, serviceAccountRef(instance.ChildResourceName(string(service))))
+	}
+
+	return refs
+}
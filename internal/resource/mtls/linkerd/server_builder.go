@@ -0,0 +1,82 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package linkerd
+
+import (
+	"fmt"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	policyv1beta1 "github.com/alexandrevilain/temporal-operator/pkg/linkerd/apis/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ resource.Builder = (*ServerBuilder)(nil)
+
+type ServerBuilder struct {
+	serviceName string
+	portName    string
+	instance    *v1beta1.TemporalCluster
+	scheme      *runtime.Scheme
+}
+
+func NewServerBuilder(serviceName, portName string, instance *v1beta1.TemporalCluster, scheme *runtime.Scheme) *ServerBuilder {
+	return &ServerBuilder{
+		serviceName: serviceName,
+		portName:    portName,
+		instance:    instance,
+		scheme:      scheme,
+	}
+}
+
+func (b *ServerBuilder) Build() client.Object {
+	return &policyv1beta1.Server{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        serverName(b.instance, b.serviceName, b.portName),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, b.serviceName, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *ServerBuilder) Enabled() bool {
+	return b.instance.LinkerdAuthorizationPoliciesEnabled() && isPortEnabled(b.instance, b.serviceName, b.portName)
+}
+
+func (b *ServerBuilder) Update(object client.Object) error {
+	server := object.(*policyv1beta1.Server)
+	server.Spec = policyv1beta1.ServerSpec{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: metadata.LabelsSelector(b.instance, b.serviceName),
+		},
+		Port:          intstr.FromString(b.portName),
+		ProxyProtocol: proxyProtocol(b.portName),
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, server, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ resource.Builder = (*CertificateSecretBuilder)(nil)
+
+type CertificateSecretBuilder struct {
+	instance *v1beta1.TemporalCluster
+	scheme   *runtime.Scheme
+	// certificate is the certificate stored in the secret.
+	certificate *Certificate
+	// issuer is the issuing CA secret data, nil for self-signed certificates.
+	issuer map[string][]byte
+	// reissue forces the certificate to be issued again.
+	reissue bool
+}
+
+func NewCertificateSecretBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, certificate *Certificate, issuer map[string][]byte, reissue bool) *CertificateSecretBuilder {
+	return &CertificateSecretBuilder{
+		instance:    instance,
+		scheme:      scheme,
+		certificate: certificate,
+		issuer:      issuer,
+		reissue:     reissue,
+	}
+}
+
+func (b *CertificateSecretBuilder) Build() client.Object {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.certificate.SecretName,
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, b.certificate.Name, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *CertificateSecretBuilder) Enabled() bool {
+	return b.instance.MTLSWithOperatorEnabled() && b.certificate.Enabled
+}
+
+func (b *CertificateSecretBuilder) Update(object client.Object) error {
+	secret := object.(*corev1.Secret)
+	secret.Labels = object.GetLabels()
+	secret.Annotations = object.GetAnnotations()
+
+	// The secret type is immutable, only set it on creation.
+	if secret.Type == "" {
+		secret.Type = corev1.SecretTypeTLS
+	}
+
+	now := time.Now()
+
+	issue := b.reissue
+	if !issue {
+		var err error
+		issue, err = NeedsIssuance(b.certificate, secret.Data, b.issuer, b.instance.Spec.MTLS.RenewBefore, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	if issue {
+		data, err := Issue(b.certificate, b.issuer, now)
+		if err != nil {
+			return fmt.Errorf("can't issue certificate %s: %w", b.certificate.Name, err)
+		}
+		secret.Data = data
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, secret, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
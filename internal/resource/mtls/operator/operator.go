@@ -0,0 +1,167 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package operator issues and renews the cluster mTLS certificates without relying on cert-manager.
+// Certificates are stored in the same secrets, using the same keys, as the ones issued by cert-manager.
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/certmanager"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Certificate describes a certificate issued by the operator.
+type Certificate struct {
+	// Name is the name of the certificate.
+	Name string
+	// SecretName is the name of the secret holding the certificate.
+	SecretName string
+	// IssuerSecretName is the name of the secret holding the issuing CA.
+	// It is empty for the self-signed root CA.
+	IssuerSecretName string
+	// CommonName is the certificate's subject common name.
+	CommonName string
+	// DNSNames is the list of the certificate's subject alternative names.
+	DNSNames []string
+	// IsCA defines if the certificate is a certificate authority.
+	IsCA bool
+	// Duration is the certificate's lifetime.
+	Duration time.Duration
+	// Enabled defines if the certificate is needed by the cluster.
+	Enabled bool
+}
+
+// Certificates returns the certificates needed by the provided cluster.
+// Issuers are always returned before the certificates they issue.
+func Certificates(instance *v1beta1.TemporalCluster) []*Certificate {
+	durations := instance.Spec.MTLS.CertificatesDuration
+	if durations == nil {
+		durations = &v1beta1.CertificatesDurationSpec{}
+	}
+
+	internode := instance.Spec.MTLS.InternodeEnabled()
+	frontend := instance.Spec.MTLS.FrontendEnabled()
+
+	rootCASecretName := instance.ChildResourceName(certmanager.RootCACertificate)
+	internodeCASecretName := instance.ChildResourceName(certmanager.InternodeIntermediateCACertificate)
+	frontendCASecretName := instance.ChildResourceName(certmanager.FrontendIntermediateCACertificate)
+
+	worker := ClientCertificate(instance, "worker")
+	worker.Enabled = frontend && !instance.Spec.Services.InternalFrontend.IsEnabled()
+
+	ui := ClientCertificate(instance, "ui")
+	ui.Enabled = frontend && instance.Spec.UI != nil && instance.Spec.UI.Enabled
+
+	admintools := ClientCertificate(instance, "admintools")
+	admintools.Enabled = frontend && instance.Spec.AdminTools != nil && instance.Spec.AdminTools.Enabled
+
+	return []*Certificate{
+		{
+			Name:       certmanager.RootCACertificate,
+			SecretName: rootCASecretName,
+			CommonName: "Root CA certificate",
+			DNSNames:   []string{instance.ServerName()},
+			IsCA:       true,
+			Duration:   duration(durations.RootCACertificate),
+			Enabled:    internode || frontend,
+		},
+		{
+			Name:             certmanager.InternodeIntermediateCACertificate,
+			SecretName:       internodeCASecretName,
+			IssuerSecretName: rootCASecretName,
+			CommonName:       "Internode intermediate CA certificate",
+			DNSNames:         []string{instance.ServerName()},
+			IsCA:             true,
+			Duration:         duration(durations.IntermediateCAsCertificates),
+			Enabled:          internode,
+		},
+		{
+			Name:             certmanager.FrontendIntermediateCACertificate,
+			SecretName:       frontendCASecretName,
+			IssuerSecretName: rootCASecretName,
+			CommonName:       "Frontend intermediate CA certificate",
+			DNSNames:         []string{instance.ServerName()},
+			IsCA:             true,
+			Duration:         duration(durations.IntermediateCAsCertificates),
+			Enabled:          frontend,
+		},
+		{
+			Name:             certmanager.InternodeCertificate,
+			SecretName:       instance.ChildResourceName(certmanager.InternodeCertificate),
+			IssuerSecretName: internodeCASecretName,
+			CommonName:       "Internode Certificate",
+			DNSNames:         internodeDNSNames(instance),
+			Duration:         duration(durations.InternodeCertificate),
+			Enabled:          internode,
+		},
+		{
+			Name:             certmanager.FrontendCertificate,
+			SecretName:       instance.ChildResourceName(certmanager.FrontendCertificate),
+			IssuerSecretName: frontendCASecretName,
+			CommonName:       "Frontend Certificate",
+			DNSNames:         frontendDNSNames(instance),
+			Duration:         duration(durations.FrontendCertificate),
+			Enabled:          frontend,
+		},
+		worker,
+		ui,
+		admintools,
+	}
+}
+
+// ClientCertificate returns the frontend client certificate for the provided client name.
+func ClientCertificate(instance *v1beta1.TemporalCluster, name string) *Certificate {
+	var clientDuration *metav1.Duration
+	if instance.Spec.MTLS.CertificatesDuration != nil {
+		clientDuration = instance.Spec.MTLS.CertificatesDuration.ClientCertificates
+	}
+
+	return &Certificate{
+		Name:             name,
+		SecretName:       instance.ChildResourceName(certmanager.GetCertificateSecretName(name)),
+		IssuerSecretName: instance.ChildResourceName(certmanager.FrontendIntermediateCACertificate),
+		CommonName:       fmt.Sprintf("%s client certificate", name),
+		DNSNames:         []string{fmt.Sprintf("%s.%s", name, instance.ServerName())},
+		Duration:         duration(clientDuration),
+		Enabled:          instance.Spec.MTLS.FrontendEnabled(),
+	}
+}
+
+func internodeDNSNames(instance *v1beta1.TemporalCluster) []string {
+	if instance.Spec.MTLS.Internode == nil {
+		return nil
+	}
+	return []string{instance.Spec.MTLS.Internode.ServerName(instance)}
+}
+
+func frontendDNSNames(instance *v1beta1.TemporalCluster) []string {
+	if instance.Spec.MTLS.Frontend == nil {
+		return nil
+	}
+	return instance.FrontendDNSNames()
+}
+
+func duration(d *metav1.Duration) time.Duration {
+	if d == nil {
+		return 0
+	}
+	return d.Duration
+}
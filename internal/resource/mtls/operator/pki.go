@@ -0,0 +1,220 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package operator
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/certmanager"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const privateKeyPEMType = "PRIVATE KEY"
+
+// serialNumberLimit is the upper bound of generated certificates serial numbers.
+var serialNumberLimit = new(big.Int).Lsh(big.NewInt(1), 128)
+
+// Issue generates a new private key and certificate for the provided certificate, signed by the provided issuer's secret data.
+// The certificate is self-signed if the issuer is nil.
+// It returns the secret data holding the certificate chain, the private key and the root CA.
+func Issue(certificate *Certificate, issuer map[string][]byte, now time.Time) (map[string][]byte, error) {
+	if certificate.Duration <= 0 {
+		return nil, fmt.Errorf("certificate %s duration must be positive", certificate.Name)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("can't generate private key: %w", err)
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		return nil, fmt.Errorf("can't generate serial number: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName: certificate.CommonName,
+		},
+		DNSNames:              certificate.DNSNames,
+		NotBefore:             now,
+		NotAfter:              now.Add(certificate.Duration),
+		BasicConstraintsValid: true,
+	}
+
+	if certificate.IsCA {
+		template.IsCA = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	} else {
+		template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+		// Cluster components use the same certificates as servers and clients.
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	}
+
+	parent := template
+	var signer crypto.Signer = key
+	chain := []*x509.Certificate{}
+
+	if issuer != nil {
+		issuerChain, err := certmanager.DecodeCertificates(issuer[certmanager.TLSCert])
+		if err != nil {
+			return nil, fmt.Errorf("can't decode issuer certificate: %w", err)
+		}
+		if len(issuerChain) == 0 {
+			return nil, errors.New("no certificate found in issuer")
+		}
+
+		signer, err = decodePrivateKey(issuer[certmanager.TLSKey])
+		if err != nil {
+			return nil, fmt.Errorf("can't decode issuer private key: %w", err)
+		}
+
+		parent = issuerChain[0]
+		// The root CA is distributed using the ca.crt key, keep it out of the chain.
+		for _, issuerCertificate := range issuerChain {
+			if !isSelfSigned(issuerCertificate) {
+				chain = append(chain, issuerCertificate)
+			}
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), signer)
+	if err != nil {
+		return nil, fmt.Errorf("can't create certificate: %w", err)
+	}
+
+	issued, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse issued certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode private key: %w", err)
+	}
+
+	ca := certmanager.EncodeCertificates([]*x509.Certificate{issued})
+	if issuer != nil {
+		ca = issuer[certmanager.TLSCA]
+	}
+
+	return map[string][]byte{
+		certmanager.TLSCert: certmanager.EncodeCertificates(append([]*x509.Certificate{issued}, chain...)),
+		certmanager.TLSKey:  pem.EncodeToMemory(&pem.Block{Type: privateKeyPEMType, Bytes: keyDER}),
+		certmanager.TLSCA:   ca,
+	}, nil
+}
+
+// NeedsIssuance returns true if the provided secret data doesn't hold a valid certificate matching the provided certificate.
+// Leaf certificates also need to be issued if they are not signed by the provided issuer's secret data.
+// CA certificates are reissued by the CA rotation process, which ensures the new CA is trusted before using it.
+func NeedsIssuance(certificate *Certificate, data, issuer map[string][]byte, renewBefore *metav1.Duration, now time.Time) (bool, error) {
+	if len(data[certmanager.TLSCert]) == 0 || len(data[certmanager.TLSKey]) == 0 {
+		return true, nil
+	}
+
+	_, err := tls.X509KeyPair(data[certmanager.TLSCert], data[certmanager.TLSKey])
+	if err != nil {
+		return true, nil
+	}
+
+	certificates, err := certmanager.DecodeCertificates(data[certmanager.TLSCert])
+	if err != nil || len(certificates) == 0 {
+		return true, nil
+	}
+
+	current := certificates[0]
+	if current.Subject.CommonName != certificate.CommonName ||
+		current.IsCA != certificate.IsCA ||
+		!slices.Equal(current.DNSNames, certificate.DNSNames) {
+		return true, nil
+	}
+
+	if !now.Before(RenewalTime(current, renewBefore)) {
+		return true, nil
+	}
+
+	if certificate.IsCA || len(issuer[certmanager.TLSCert]) == 0 {
+		return false, nil
+	}
+
+	issued, err := certmanager.IsIssuedBy(data[certmanager.TLSCert], issuer[certmanager.TLSCert])
+	if err != nil {
+		return false, fmt.Errorf("can't check certificate %s issuer: %w", certificate.Name, err)
+	}
+
+	return !issued, nil
+}
+
+// RenewalTime returns the time at which the provided certificate should be renewed.
+// It defaults to 2/3 of the certificate's lifetime, the same way cert-manager does.
+func RenewalTime(certificate *x509.Certificate, renewBefore *metav1.Duration) time.Time {
+	lifetime := certificate.NotAfter.Sub(certificate.NotBefore)
+	before := lifetime / 3
+	if renewBefore != nil && renewBefore.Duration < lifetime {
+		before = renewBefore.Duration
+	}
+	return certificate.NotAfter.Add(-before)
+}
+
+func isSelfSigned(certificate *x509.Certificate) bool {
+	return certificate.CheckSignatureFrom(certificate) == nil
+}
+
+func decodePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no private key found")
+	}
+
+	var (
+		key any
+		err error
+	)
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return signer, nil
+}
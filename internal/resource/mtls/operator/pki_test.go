@@ -0,0 +1,199 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package operator_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/certmanager"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/operator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	root = &operator.Certificate{
+		Name:       "root",
+		CommonName: "Root CA certificate",
+		IsCA:       true,
+		Duration:   3 * time.Hour,
+	}
+	intermediate = &operator.Certificate{
+		Name:       "intermediate",
+		CommonName: "Intermediate CA certificate",
+		IsCA:       true,
+		Duration:   3 * time.Hour,
+	}
+	leaf = &operator.Certificate{
+		Name:       "leaf",
+		CommonName: "Leaf certificate",
+		DNSNames:   []string{"leaf.example.com"},
+		Duration:   3 * time.Hour,
+	}
+)
+
+func TestIssue(t *testing.T) {
+	now := time.Now()
+
+	rootData, err := operator.Issue(root, nil, now)
+	require.NoError(t, err)
+
+	intermediateData, err := operator.Issue(intermediate, rootData, now)
+	require.NoError(t, err)
+
+	leafData, err := operator.Issue(leaf, intermediateData, now)
+	require.NoError(t, err)
+
+	// The root CA is distributed in ca.crt.
+	assert.Equal(t, rootData[certmanager.TLSCert], rootData[certmanager.TLSCA])
+	assert.Equal(t, rootData[certmanager.TLSCA], intermediateData[certmanager.TLSCA])
+	assert.Equal(t, rootData[certmanager.TLSCA], leafData[certmanager.TLSCA])
+
+	_, err = tls.X509KeyPair(leafData[certmanager.TLSCert], leafData[certmanager.TLSKey])
+	require.NoError(t, err)
+
+	chain, err := certmanager.DecodeCertificates(leafData[certmanager.TLSCert])
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+	assert.Equal(t, leaf.DNSNames, chain[0].DNSNames)
+	assert.False(t, chain[0].IsCA)
+	assert.True(t, chain[1].IsCA)
+
+	roots, err := certmanager.DecodeCertificates(rootData[certmanager.TLSCA])
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(roots[0])
+	intermediates := x509.NewCertPool()
+	intermediates.AddCert(chain[1])
+
+	_, err = chain[0].Verify(x509.VerifyOptions{
+		DNSName:       "leaf.example.com",
+		Roots:         pool,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		CurrentTime:   now.Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	_, err = operator.Issue(&operator.Certificate{Name: "invalid"}, nil, now)
+	require.Error(t, err)
+}
+
+func TestNeedsIssuance(t *testing.T) {
+	now := time.Now()
+
+	rootData, err := operator.Issue(root, nil, now)
+	require.NoError(t, err)
+
+	otherRootData, err := operator.Issue(root, nil, now)
+	require.NoError(t, err)
+
+	intermediateData, err := operator.Issue(intermediate, rootData, now)
+	require.NoError(t, err)
+
+	otherIntermediateData, err := operator.Issue(intermediate, otherRootData, now)
+	require.NoError(t, err)
+
+	leafData, err := operator.Issue(leaf, intermediateData, now)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		certificate *operator.Certificate
+		data        map[string][]byte
+		issuer      map[string][]byte
+		renewBefore *metav1.Duration
+		now         time.Time
+		expected    bool
+	}{
+		"missing certificate": {
+			certificate: leaf,
+			data:        map[string][]byte{},
+			issuer:      intermediateData,
+			now:         now,
+			expected:    true,
+		},
+		"valid certificate": {
+			certificate: leaf,
+			data:        leafData,
+			issuer:      intermediateData,
+			now:         now,
+			expected:    false,
+		},
+		"dns names changed": {
+			certificate: &operator.Certificate{
+				Name:       "leaf",
+				CommonName: "Leaf certificate",
+				DNSNames:   []string{"leaf.example.com", "extra.example.com"},
+				Duration:   3 * time.Hour,
+			},
+			data:     leafData,
+			issuer:   intermediateData,
+			now:      now,
+			expected: true,
+		},
+		"default renewal time reached": {
+			certificate: leaf,
+			data:        leafData,
+			issuer:      intermediateData,
+			now:         now.Add(2 * time.Hour),
+			expected:    true,
+		},
+		"default renewal time not reached": {
+			certificate: leaf,
+			data:        leafData,
+			issuer:      intermediateData,
+			now:         now.Add(time.Hour),
+			expected:    false,
+		},
+		"custom renewal time reached": {
+			certificate: leaf,
+			data:        leafData,
+			issuer:      intermediateData,
+			renewBefore: &metav1.Duration{Duration: 150 * time.Minute},
+			now:         now.Add(time.Hour),
+			expected:    true,
+		},
+		"leaf issued by a previous CA": {
+			certificate: leaf,
+			data:        leafData,
+			issuer:      otherIntermediateData,
+			now:         now,
+			expected:    true,
+		},
+		"CA issued by a previous CA is left to the rotation": {
+			certificate: intermediate,
+			data:        intermediateData,
+			issuer:      otherRootData,
+			now:         now,
+			expected:    false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			result, err := operator.NeedsIssuance(test.certificate, test.data, test.issuer, test.renewBefore, test.now)
+			require.NoError(tt, err)
+			assert.Equal(tt, test.expected, result)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package spire
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ resource.Builder = (*HelperConfigmapBuilder)(nil)
+
+type HelperConfigmapBuilder struct {
+	instance *v1beta1.TemporalCluster
+	scheme   *runtime.Scheme
+}
+
+func NewHelperConfigmapBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme) *HelperConfigmapBuilder {
+	return &HelperConfigmapBuilder{
+		instance: instance,
+		scheme:   scheme,
+	}
+}
+
+func (b *HelperConfigmapBuilder) Build() client.Object {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.ChildResourceName(HelperConfig),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, HelperConfig, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *HelperConfigmapBuilder) Enabled() bool {
+	return b.instance.MTLSWithSpireEnabled()
+}
+
+func (b *HelperConfigmapBuilder) Update(object client.Object) error {
+	configMap := object.(*corev1.ConfigMap)
+
+	configMap.Data = map[string]string{
+		HelperConfigFileName: RenderHelperConfig(b.instance),
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, configMap, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
+
+// RenderHelperConfig returns the spiffe-helper configuration for the provided cluster.
+func RenderHelperConfig(instance *v1beta1.TemporalCluster) string {
+	spec := instance.Spec.MTLS.Spire
+
+	settings := []struct {
+		key   string
+		value any
+	}{
+		{"agent_address", path.Join(workloadAPIMountPath, spec.WorkloadAPI.SocketName)},
+		{"cmd", ""},
+		{"cmd_args", ""},
+		{"cert_dir", CertificatesMountPath},
+		{"svid_file_name", SVIDFileName},
+		{"svid_key_file_name", SVIDKeyFileName},
+		{"svid_bundle_file_name", BundleFileName},
+		// Only the cluster's trust domain is trusted.
+		{"include_federated_domains", false},
+	}
+
+	var sb strings.Builder
+	for _, setting := range settings {
+		switch value := setting.value.(type) {
+		case string:
+			fmt.Fprintf(&sb, "%s = %q\n", setting.key, value)
+		default:
+			fmt.Fprintf(&sb, "%s = %v\n", setting.key, value)
+		}
+	}
+
+	return sb.String()
+}
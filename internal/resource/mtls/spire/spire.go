@@ -0,0 +1,166 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package spire
+
+import (
+	"path"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// HelperConfig is the name of the spiffe-helper configuration.
+	HelperConfig = "spiffe-helper"
+	// HelperConfigFileName is the spiffe-helper configuration file name.
+	HelperConfigFileName = "helper.conf"
+
+	// SVIDFileName is the name of the file holding the X.509 SVID and its intermediates.
+	SVIDFileName = "svid.pem"
+	// SVIDKeyFileName is the name of the file holding the X.509 SVID private key.
+	SVIDKeyFileName = "svid_key.pem"
+	// BundleFileName is the name of the file holding the trust bundle.
+	BundleFileName = "bundle.pem"
+
+	// CertificatesMountPath is the directory the spiffe-helper writes the SVID and the trust bundle to.
+	CertificatesMountPath = "/etc/temporal/config/certs/spiffe"
+
+	workloadAPIMountPath  = "/spiffe-workload-api"
+	helperConfigMountPath = "/etc/spiffe-helper"
+
+	certificatesVolumeName = "spiffe-certs"
+	workloadAPIVolumeName  = "spiffe-workload-api"
+	helperConfigVolumeName = "spiffe-helper-config"
+)
+
+// SVIDFilePath returns the path of the X.509 SVID in the service container.
+func SVIDFilePath() string {
+	return path.Join(CertificatesMountPath, SVIDFileName)
+}
+
+// SVIDKeyFilePath returns the path of the X.509 SVID private key in the service container.
+func SVIDKeyFilePath() string {
+	return path.Join(CertificatesMountPath, SVIDKeyFileName)
+}
+
+// BundleFilePath returns the path of the trust bundle in the service container.
+func BundleFilePath() string {
+	return path.Join(CertificatesMountPath, BundleFileName)
+}
+
+// GetVolumes returns the volumes needed to fetch SVIDs from the SPIRE agent Workload API.
+func GetVolumes(instance *v1beta1.TemporalCluster) []corev1.Volume {
+	workloadAPI := instance.Spec.MTLS.Spire.WorkloadAPI
+
+	workloadAPISource := corev1.VolumeSource{
+		CSI: &corev1.CSIVolumeSource{
+			Driver:   workloadAPI.CSIDriver,
+			ReadOnly: ptr.To(true),
+		},
+	}
+	if workloadAPI.HostPath != "" {
+		workloadAPISource = corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				Path: workloadAPI.HostPath,
+				Type: ptr.To(corev1.HostPathDirectory),
+			},
+		}
+	}
+
+	return []corev1.Volume{
+		{
+			Name:         workloadAPIVolumeName,
+			VolumeSource: workloadAPISource,
+		},
+		{
+			Name: certificatesVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium: corev1.StorageMediumMemory,
+				},
+			},
+		},
+		{
+			Name: helperConfigVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: instance.ChildResourceName(HelperConfig),
+					},
+					DefaultMode: ptr.To[int32](corev1.ConfigMapVolumeSourceDefaultMode),
+				},
+			},
+		},
+	}
+}
+
+// GetCertificatesVolumeMount returns the read-only mount of the SVID and trust bundle directory.
+func GetCertificatesVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      certificatesVolumeName,
+		MountPath: CertificatesMountPath,
+		ReadOnly:  true,
+	}
+}
+
+// GetHelperInitContainer returns the spiffe-helper container writing the SVID and the trust bundle before the service starts.
+func GetHelperInitContainer(instance *v1beta1.TemporalCluster) corev1.Container {
+	container := helperContainer(instance)
+	container.Name = "spiffe-helper-init"
+	container.Args = append(container.Args, "-daemon-mode=false")
+	return container
+}
+
+// GetHelperSidecarContainer returns the spiffe-helper container keeping the SVID and the trust bundle up-to-date.
+func GetHelperSidecarContainer(instance *v1beta1.TemporalCluster) corev1.Container {
+	return helperContainer(instance)
+}
+
+func helperContainer(instance *v1beta1.TemporalCluster) corev1.Container {
+	return corev1.Container{
+		Name:                     "spiffe-helper",
+		Image:                    instance.Spec.MTLS.Spire.HelperImage,
+		ImagePullPolicy:          corev1.PullIfNotPresent,
+		Args:                     []string{"-config", path.Join(helperConfigMountPath, HelperConfigFileName)},
+		TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr.To(false),
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{"ALL"},
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      workloadAPIVolumeName,
+				MountPath: workloadAPIMountPath,
+				ReadOnly:  true,
+			},
+			{
+				Name:      certificatesVolumeName,
+				MountPath: CertificatesMountPath,
+			},
+			{
+				Name:      helperConfigVolumeName,
+				MountPath: helperConfigMountPath,
+				ReadOnly:  true,
+			},
+		},
+	}
+}
@@ -0,0 +1,111 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package spire_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/mtls/spire"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newCluster(spec *v1beta1.SpireMTLSSpec) *v1beta1.TemporalCluster {
+	c := &v1beta1.TemporalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: v1beta1.TemporalClusterSpec{
+			MTLS: &v1beta1.MTLSSpec{
+				Provider: v1beta1.SpireMTLSProvider,
+				Internode: &v1beta1.InternodeMTLSSpec{
+					Enabled: true,
+				},
+				Spire: spec,
+			},
+		},
+	}
+	c.Default()
+	return c
+}
+
+func TestRenderHelperConfig(t *testing.T) {
+	tests := map[string]struct {
+		spec     *v1beta1.SpireMTLSSpec
+		expected string
+	}{
+		"trust domain only": {
+			spec: &v1beta1.SpireMTLSSpec{
+				TrustDomain: "example.org",
+			},
+			expected: `agent_address = "/spiffe-workload-api/spire-agent.sock"
+cmd = ""
+cmd_args = ""
+cert_dir = "/etc/temporal/config/certs/spiffe"
+svid_file_name = "svid.pem"
+svid_key_file_name = "svid_key.pem"
+svid_bundle_file_name = "bundle.pem"
+include_federated_domains = false
+`,
+		},
+		"custom socket name": {
+			spec: &v1beta1.SpireMTLSSpec{
+				TrustDomain: "example.org",
+				WorkloadAPI: &v1beta1.SpireWorkloadAPISpec{
+					SocketName: "agent.sock",
+				},
+			},
+			expected: `agent_address = "/spiffe-workload-api/agent.sock"
+cmd = ""
+cmd_args = ""
+cert_dir = "/etc/temporal/config/certs/spiffe"
+svid_file_name = "svid.pem"
+svid_key_file_name = "svid_key.pem"
+svid_bundle_file_name = "bundle.pem"
+include_federated_domains = false
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, spire.RenderHelperConfig(newCluster(test.spec)))
+		})
+	}
+}
+
+func TestGetVolumes(t *testing.T) {
+	volumes := spire.GetVolumes(newCluster(&v1beta1.SpireMTLSSpec{TrustDomain: "example.org"}))
+	require.Len(t, volumes, 3)
+	require.NotNil(t, volumes[0].CSI)
+	assert.Equal(t, "csi.spiffe.io", volumes[0].CSI.Driver)
+
+	volumes = spire.GetVolumes(newCluster(&v1beta1.SpireMTLSSpec{
+		TrustDomain: "example.org",
+		WorkloadAPI: &v1beta1.SpireWorkloadAPISpec{
+			HostPath: "/run/spire/agent-sockets",
+		},
+	}))
+	require.Len(t, volumes, 3)
+	assert.Nil(t, volumes[0].CSI)
+	require.NotNil(t, volumes[0].HostPath)
+	assert.Equal(t, "/run/spire/agent-sockets", volumes[0].HostPath.Path)
+}
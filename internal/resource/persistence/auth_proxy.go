@@ -0,0 +1,186 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package persistence
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/schemarunner"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	authProxyBinary = "/datastore-auth-proxy"
+	// authProxyVolumeName is the name of the volume holding the schema runner configuration, read by the auth proxies.
+	authProxyVolumeName = "schema-runner"
+	authProxyHost       = "127.0.0.1"
+	// authProxyBasePort is the port of the first store auth proxy, the other stores use the following ports.
+	authProxyBasePort = 15432
+
+	// azureWorkloadIdentityLabel opts pods in Azure workload identity.
+	azureWorkloadIdentityLabel = "azure.workload.identity/use"
+)
+
+// authProxyStores lists the stores in a fixed order, giving each store auth proxy a stable port.
+var authProxyStores = []schemarunner.Store{
+	schemarunner.DefaultStore,
+	schemarunner.VisibilityStore,
+	schemarunner.SecondaryVisibilityStore,
+	schemarunner.AdvancedVisibilityStore,
+}
+
+// AuthProxy is a sidecar forwarding the services connections to a datastore authenticating with tokens,
+// or to an elasticsearch datastore authenticating with an API key.
+type AuthProxy struct {
+	Store     schemarunner.Store
+	Datastore *v1beta1.DatastoreSpec
+	Port      int32
+}
+
+// Address returns the address the services connect to.
+func (p AuthProxy) Address() string {
+	return net.JoinHostPort(authProxyHost, strconv.Itoa(int(p.Port)))
+}
+
+// GetAuthProxies returns the auth proxies needed by the cluster datastores.
+func GetAuthProxies(instance *v1beta1.TemporalCluster) []AuthProxy {
+	persistence := instance.Spec.Persistence
+	datastores := map[schemarunner.Store]*v1beta1.DatastoreSpec{
+		schemarunner.DefaultStore:             persistence.DefaultStore,
+		schemarunner.VisibilityStore:          persistence.VisibilityStore,
+		schemarunner.SecondaryVisibilityStore: persistence.SecondaryVisibilityStore,
+		schemarunner.AdvancedVisibilityStore:  persistence.AdvancedVisibilityStore,
+	}
+
+	proxies := []AuthProxy{}
+	for i, store := range authProxyStores {
+		datastore := datastores[store]
+		if datastore == nil || (!datastore.IsSQLTokenAuth() && !datastore.IsElasticsearchAPIKeyAuth()) {
+			continue
+		}
+
+		proxies = append(proxies, AuthProxy{
+			Store:     store,
+			Datastore: datastore,
+			Port:      int32(authProxyBasePort + i),
+		})
+	}
+	return proxies
+}
+
+// GetAuthProxy returns the auth proxy of the provided datastore, if any.
+func GetAuthProxy(instance *v1beta1.TemporalCluster, datastore *v1beta1.DatastoreSpec) (AuthProxy, bool) {
+	for _, proxy := range GetAuthProxies(instance) {
+		if proxy.Datastore.Name == datastore.Name {
+			return proxy, true
+		}
+	}
+	return AuthProxy{}, false
+}
+
+// GetAuthProxyContainers returns the auth proxies sidecars, started as restartable init containers
+// so they're listening before the services start.
+func GetAuthProxyContainers(instance *v1beta1.TemporalCluster, image string) []corev1.Container {
+	proxies := GetAuthProxies(instance)
+	containers := make([]corev1.Container, 0, len(proxies))
+
+	for _, proxy := range proxies {
+		volumeMounts := []corev1.VolumeMount{
+			{
+				Name:      authProxyVolumeName,
+				MountPath: schemaRunnerConfigPath,
+				ReadOnly:  true,
+			},
+		}
+		volumeMounts = append(volumeMounts, GetDatastoresVolumeMounts([]*v1beta1.DatastoreSpec{proxy.Datastore})...)
+
+		containers = append(containers, corev1.Container{
+			Name:                     fmt.Sprintf("%s-auth-proxy", strings.ToLower(string(proxy.Store))),
+			Image:                    image,
+			ImagePullPolicy:          corev1.PullIfNotPresent,
+			RestartPolicy:            ptr.To(corev1.ContainerRestartPolicyAlways),
+			TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+			Command: []string{
+				authProxyBinary,
+				"--config", path.Join(schemaRunnerConfigPath, SchemaRunnerConfigFileName),
+				"--listen", proxy.Address(),
+			},
+			Args: []string{string(proxy.Store)},
+			Env:  GetDatastoresEnvironmentVariables([]*v1beta1.DatastoreSpec{proxy.Datastore}),
+			StartupProbe: &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{
+					TCPSocket: &corev1.TCPSocketAction{
+						Host: authProxyHost,
+						Port: intstr.FromInt32(proxy.Port),
+					},
+				},
+				PeriodSeconds:    1,
+				FailureThreshold: 30,
+			},
+			SecurityContext: &corev1.SecurityContext{
+				AllowPrivilegeEscalation: ptr.To(false),
+				Capabilities: &corev1.Capabilities{
+					Drop: []corev1.Capability{"ALL"},
+				},
+			},
+			VolumeMounts: volumeMounts,
+		})
+	}
+
+	return containers
+}
+
+// GetAuthProxyVolumes returns the volumes needed by the auth proxies sidecars.
+func GetAuthProxyVolumes(instance *v1beta1.TemporalCluster) []corev1.Volume {
+	if len(GetAuthProxies(instance)) == 0 {
+		return []corev1.Volume{}
+	}
+
+	return []corev1.Volume{
+		{
+			Name: authProxyVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: instance.ChildResourceName("schema-runner"),
+					},
+					DefaultMode: ptr.To[int32](corev1.ConfigMapVolumeSourceDefaultMode),
+				},
+			},
+		},
+	}
+}
+
+// GetDatastoresPodLabels returns the labels the pods connecting to the provided datastores need.
+func GetDatastoresPodLabels(datastores []*v1beta1.DatastoreSpec) map[string]string {
+	labels := map[string]string{}
+	for _, datastore := range datastores {
+		if datastore.IsSQLTokenAuth() && datastore.SQL.Auth.AzureAD != nil {
+			labels[azureWorkloadIdentityLabel] = "true"
+		}
+	}
+	return labels
+}
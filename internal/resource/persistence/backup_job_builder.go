@@ -0,0 +1,379 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package persistence
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	"github.com/alexandrevilain/temporal-operator/pkg/kubernetes"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// BackupContainerName is the name of the container running the backup in backup jobs.
+	BackupContainerName = "backup"
+
+	backupVolumeName = "backup"
+	backupMountPath  = "/backup"
+
+	defaultPostgresBackupImage  = "postgres:17"
+	defaultMySQLBackupImage     = "mysql:8.4"
+	defaultCassandraBackupImage = "cassandra:4.1"
+	defaultAWSCLIImage          = "amazon/aws-cli:2.17.0"
+	defaultCassandraJMXPort     = 7199
+
+	defaultPostgresPort = "5432"
+	defaultMySQLPort    = "3306"
+)
+
+// cassandraSnapshotScript takes a snapshot of the keyspace on every provided host.
+const cassandraSnapshotScript = `for host in $CASSANDRA_HOSTS; do nodetool -h "$host" -p "$CASSANDRA_JMX_PORT" snapshot -t "$SNAPSHOT_TAG" "$CASSANDRA_KEYSPACE" || exit 1; done`
+
+// BackupJobBuilder builds the job backing up a datastore before its schema is updated.
+type BackupJobBuilder struct {
+	instance *v1beta1.TemporalCluster
+	scheme   *runtime.Scheme
+	// name is the name of the job
+	name string
+	// datastore is the datastore to backup
+	datastore *v1beta1.DatastoreSpec
+}
+
+func NewBackupJobBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, name string, datastore *v1beta1.DatastoreSpec) *BackupJobBuilder {
+	return &BackupJobBuilder{
+		instance:  instance,
+		scheme:    scheme,
+		name:      name,
+		datastore: datastore,
+	}
+}
+
+func (b *BackupJobBuilder) Enabled() bool {
+	return b.datastore.Backup != nil && (b.datastore.Backup.Job != nil || b.datastore.Backup.Preset != nil)
+}
+
+func (b *BackupJobBuilder) Build() client.Object {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.ChildResourceName(b.name),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, b.name, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *BackupJobBuilder) Update(object client.Object) error {
+	job := object.(*batchv1.Job)
+
+	template, err := b.podTemplate()
+	if err != nil {
+		return err
+	}
+
+	job.Spec.TTLSecondsAfterFinished = b.instance.Spec.JobTTLSecondsAfterFinished
+	job.Spec.Template = *template
+
+	if err := controllerutil.SetOwnerReference(b.instance, job, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+	return nil
+}
+
+func (b *BackupJobBuilder) podTemplate() (*corev1.PodTemplateSpec, error) {
+	datastores := []*v1beta1.DatastoreSpec{b.datastore}
+
+	template := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      metadata.GetLabels(b.instance, b.name, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                 corev1.RestartPolicyOnFailure,
+			ImagePullSecrets:              b.instance.Spec.ImagePullSecrets,
+			ServiceAccountName:            b.instance.ChildResourceName(ServiceNameSuffix),
+			TerminationGracePeriodSeconds: ptr.To[int64](30),
+			Volumes:                       GetDatastoresVolumes(datastores),
+		},
+	}
+
+	backup := b.datastore.Backup
+	if backup.Job != nil {
+		err := kubernetes.ApplyPodTemplateSpecOverrides(template, &backup.Job.Template)
+		if err != nil {
+			return nil, fmt.Errorf("can't apply backup job template: %w", err)
+		}
+
+		// Provide the datastore password and TLS files to the user containers.
+		for i := range template.Spec.Containers {
+			container := &template.Spec.Containers[i]
+			container.Env = append(container.Env, GetDatastoresEnvironmentVariables(datastores)...)
+			container.VolumeMounts = append(container.VolumeMounts, GetDatastoresVolumeMounts(datastores)...)
+		}
+
+		return template, nil
+	}
+
+	dump, err := b.presetContainer()
+	if err != nil {
+		return nil, err
+	}
+
+	preset := backup.Preset
+	switch {
+	case preset.S3 != nil:
+		// Dump to a local volume first, then upload the dump.
+		template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+			Name:         backupVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		dump.Name = "dump"
+		template.Spec.InitContainers = []corev1.Container{*dump}
+		template.Spec.Containers = []corev1.Container{b.s3UploadContainer()}
+	case preset.PersistentVolumeClaim != nil:
+		template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+			Name: backupVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: preset.PersistentVolumeClaim.ClaimName,
+				},
+			},
+		})
+		template.Spec.Containers = []corev1.Container{*dump}
+	default:
+		template.Spec.Containers = []corev1.Container{*dump}
+	}
+
+	return template, nil
+}
+
+// presetContainer returns the container running the datastore native backup tool.
+func (b *BackupJobBuilder) presetContainer() (*corev1.Container, error) {
+	datastores := []*v1beta1.DatastoreSpec{b.datastore}
+
+	container := &corev1.Container{
+		Name:            BackupContainerName,
+		Image:           b.datastore.Backup.Preset.Image,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Resources:       b.instance.Spec.JobResources,
+		Env:             GetDatastoresEnvironmentVariables(datastores),
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr.To(false),
+		},
+		VolumeMounts: GetDatastoresVolumeMounts(datastores),
+	}
+
+	if b.datastore.Backup.Preset.PersistentVolumeClaim != nil || b.datastore.Backup.Preset.S3 != nil {
+		container.VolumeMounts = append(container.VolumeMounts, b.backupVolumeMount())
+	}
+
+	switch b.datastore.GetType() {
+	case v1beta1.PostgresSQLDatastore, v1beta1.PostgresSQL12Datastore:
+		if container.Image == "" {
+			container.Image = defaultPostgresBackupImage
+		}
+		host, port := splitHostPort(b.datastore.SQL.ConnectAddr, defaultPostgresPort)
+		container.Command = []string{"pg_dump", "--format=custom", "--file=" + path.Join(backupMountPath, BackupFileName(b.instance, b.datastore))}
+		container.Env = append(container.Env,
+			corev1.EnvVar{Name: "PGHOST", Value: host},
+			corev1.EnvVar{Name: "PGPORT", Value: port},
+			corev1.EnvVar{Name: "PGUSER", Value: b.datastore.SQL.User},
+			corev1.EnvVar{Name: "PGDATABASE", Value: b.datastore.SQL.DatabaseName},
+		)
+		if b.datastore.PasswordSecretRef != nil {
+			container.Env = append(container.Env, corev1.EnvVar{Name: "PGPASSWORD", Value: fmt.Sprintf("$(%s)", b.datastore.GetPasswordEnvVarName())})
+		}
+		if b.datastore.TLS != nil && b.datastore.TLS.Enabled {
+			container.Env = append(container.Env, corev1.EnvVar{Name: "PGSSLMODE", Value: "require"})
+			if p := b.datastore.GetTLSCaFileMountPath(); p != "" {
+				container.Env = append(container.Env, corev1.EnvVar{Name: "PGSSLROOTCERT", Value: p})
+			}
+			if p := b.datastore.GetTLSCertFileMountPath(); p != "" {
+				container.Env = append(container.Env, corev1.EnvVar{Name: "PGSSLCERT", Value: p})
+			}
+			if p := b.datastore.GetTLSKeyFileMountPath(); p != "" {
+				container.Env = append(container.Env, corev1.EnvVar{Name: "PGSSLKEY", Value: p})
+			}
+		}
+	case v1beta1.MySQLDatastore, v1beta1.MySQL8Datastore:
+		if container.Image == "" {
+			container.Image = defaultMySQLBackupImage
+		}
+		host, port := splitHostPort(b.datastore.SQL.ConnectAddr, defaultMySQLPort)
+		container.Command = []string{
+			"mysqldump",
+			"--host=" + host,
+			"--port=" + port,
+			"--user=" + b.datastore.SQL.User,
+			"--single-transaction",
+			"--routines",
+			"--result-file=" + path.Join(backupMountPath, BackupFileName(b.instance, b.datastore)),
+		}
+		if b.datastore.TLS != nil && b.datastore.TLS.Enabled {
+			container.Command = append(container.Command, "--ssl-mode=REQUIRED")
+			if p := b.datastore.GetTLSCaFileMountPath(); p != "" {
+				container.Command = append(container.Command, "--ssl-ca="+p)
+			}
+			if p := b.datastore.GetTLSCertFileMountPath(); p != "" {
+				container.Command = append(container.Command, "--ssl-cert="+p)
+			}
+			if p := b.datastore.GetTLSKeyFileMountPath(); p != "" {
+				container.Command = append(container.Command, "--ssl-key="+p)
+			}
+		}
+		container.Command = append(container.Command, b.datastore.SQL.DatabaseName)
+		if b.datastore.PasswordSecretRef != nil {
+			container.Env = append(container.Env, corev1.EnvVar{Name: "MYSQL_PWD", Value: fmt.Sprintf("$(%s)", b.datastore.GetPasswordEnvVarName())})
+		}
+	case v1beta1.CassandraDatastore:
+		if container.Image == "" {
+			container.Image = defaultCassandraBackupImage
+		}
+		jmxPort := int32(defaultCassandraJMXPort)
+		if b.datastore.Backup.Preset.JMXPort != nil {
+			jmxPort = *b.datastore.Backup.Preset.JMXPort
+		}
+		container.Command = []string{"sh", "-c", cassandraSnapshotScript}
+		container.Env = append(container.Env,
+			corev1.EnvVar{Name: "CASSANDRA_HOSTS", Value: strings.Join(b.datastore.Cassandra.Hosts, " ")},
+			corev1.EnvVar{Name: "CASSANDRA_JMX_PORT", Value: strconv.Itoa(int(jmxPort))},
+			corev1.EnvVar{Name: "CASSANDRA_KEYSPACE", Value: b.datastore.Cassandra.Keyspace},
+			corev1.EnvVar{Name: "SNAPSHOT_TAG", Value: CassandraSnapshotTag(b.instance)},
+		)
+	case v1beta1.ElasticsearchDatastore, v1beta1.UnknownDatastore:
+		return nil, fmt.Errorf("backup preset is not supported for %s datastores", b.datastore.GetType())
+	}
+
+	return container, nil
+}
+
+// s3UploadContainer returns the container uploading the dump to the S3 destination.
+func (b *BackupJobBuilder) s3UploadContainer() corev1.Container {
+	s3 := b.datastore.Backup.Preset.S3
+
+	image := s3.Image
+	if image == "" {
+		image = defaultAWSCLIImage
+	}
+
+	file := BackupFileName(b.instance, b.datastore)
+	command := []string{"aws", "s3", "cp", path.Join(backupMountPath, file), backupS3URL(s3, file)}
+	if s3.Endpoint != nil {
+		command = append(command, "--endpoint-url", *s3.Endpoint)
+	}
+
+	env := []corev1.EnvVar{}
+	if s3.Region != "" {
+		env = append(env, corev1.EnvVar{Name: "AWS_REGION", Value: s3.Region})
+	}
+	if s3.Credentials != nil {
+		env = append(env,
+			corev1.EnvVar{Name: "AWS_ACCESS_KEY_ID", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: s3.Credentials.AccessKeyIDRef}},
+			corev1.EnvVar{Name: "AWS_SECRET_ACCESS_KEY", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: s3.Credentials.SecretAccessKeyRef}},
+		)
+	}
+
+	return corev1.Container{
+		Name:            BackupContainerName,
+		Image:           image,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Resources:       b.instance.Spec.JobResources,
+		Command:         command,
+		Env:             env,
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr.To(false),
+		},
+		VolumeMounts: []corev1.VolumeMount{b.backupVolumeMount()},
+	}
+}
+
+func (b *BackupJobBuilder) backupVolumeMount() corev1.VolumeMount {
+	mount := corev1.VolumeMount{
+		Name:      backupVolumeName,
+		MountPath: backupMountPath,
+	}
+	if pvc := b.datastore.Backup.Preset.PersistentVolumeClaim; pvc != nil && b.datastore.Backup.Preset.S3 == nil {
+		mount.SubPath = strings.Trim(pvc.Path, "/")
+	}
+	return mount
+}
+
+// BackupFileName returns the name of the dump file written by the backup preset.
+func BackupFileName(instance *v1beta1.TemporalCluster, datastore *v1beta1.DatastoreSpec) string {
+	extension := "sql"
+	if datastore.GetType() == v1beta1.PostgresSQLDatastore || datastore.GetType() == v1beta1.PostgresSQL12Datastore {
+		extension = "dump"
+	}
+	return fmt.Sprintf("%s-%s-%s.%s", instance.Name, datastore.LowerCaseName(), instance.Spec.Version.String(), extension)
+}
+
+// CassandraSnapshotTag returns the tag of the snapshot taken by the cassandra backup preset.
+func CassandraSnapshotTag(instance *v1beta1.TemporalCluster) string {
+	return fmt.Sprintf("%s-v-%s", instance.Name, strings.ReplaceAll(instance.Spec.Version.String(), ".", "-"))
+}
+
+// BackupLocation returns where the backup named name of the provided datastore is stored.
+func BackupLocation(instance *v1beta1.TemporalCluster, datastore *v1beta1.DatastoreSpec, name string) string {
+	backup := datastore.Backup
+	switch {
+	case backup.Job != nil:
+		if backup.Job.Location != "" {
+			return backup.Job.Location
+		}
+		return path.Join("job", instance.ChildResourceName(name))
+	case backup.VolumeSnapshot != nil:
+		return path.Join("volumesnapshot", instance.ChildResourceName(name))
+	case backup.Preset != nil:
+		if datastore.GetType() == v1beta1.CassandraDatastore {
+			return path.Join("snapshot", datastore.Cassandra.Keyspace, CassandraSnapshotTag(instance))
+		}
+		file := BackupFileName(instance, datastore)
+		if backup.Preset.S3 != nil {
+			return backupS3URL(backup.Preset.S3, file)
+		}
+		if backup.Preset.PersistentVolumeClaim != nil {
+			return path.Join("pvc", backup.Preset.PersistentVolumeClaim.ClaimName, backup.Preset.PersistentVolumeClaim.Path, file)
+		}
+	}
+	return ""
+}
+
+func backupS3URL(s3 *v1beta1.BackupS3Destination, file string) string {
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(s3.URL, "/"), file)
+}
+
+// splitHostPort splits the provided address, using defaultPort if it has none.
+func splitHostPort(addr, defaultPort string) (string, string) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, defaultPort
+	}
+	return host, port
+}
@@ -0,0 +1,189 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package persistence_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/persistence"
+	"github.com/alexandrevilain/temporal-operator/pkg/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+func backupCluster(datastore *v1beta1.DatastoreSpec) *v1beta1.TemporalCluster {
+	return &v1beta1.TemporalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prod",
+			Namespace: "temporal",
+		},
+		Spec: v1beta1.TemporalClusterSpec{
+			Version: version.MustNewVersionFromString("1.28.1"),
+			Persistence: v1beta1.TemporalPersistenceSpec{
+				DefaultStore: datastore,
+			},
+		},
+	}
+}
+
+func TestBackupJobBuilder(t *testing.T) {
+	password := &v1beta1.SecretKeyReference{Name: "postgres", Key: "password"}
+
+	tests := map[string]struct {
+		datastore              *v1beta1.DatastoreSpec
+		expectedInitContainers []string
+		expectedCommand        []string
+		expectedEnv            []string
+		expectedLocation       string
+	}{
+		"pg_dump to s3": {
+			datastore: &v1beta1.DatastoreSpec{
+				Name:              "default",
+				SQL:               &v1beta1.SQLSpec{PluginName: "postgres12", ConnectAddr: "postgres:5433", User: "temporal", DatabaseName: "temporal"},
+				PasswordSecretRef: password,
+				Backup: &v1beta1.DatastoreBackupSpec{
+					Preset: &v1beta1.DatastoreBackupPresetSpec{
+						S3: &v1beta1.BackupS3Destination{URL: "s3://backups/temporal/", Endpoint: ptr.To("http://minio:9000")},
+					},
+				},
+			},
+			expectedInitContainers: []string{"dump"},
+			expectedCommand:        []string{"aws", "s3", "cp", "/backup/prod-default-1.28.1.dump", "s3://backups/temporal/prod-default-1.28.1.dump", "--endpoint-url", "http://minio:9000"},
+			expectedLocation:       "s3://backups/temporal/prod-default-1.28.1.dump",
+		},
+		"mysqldump to a volume claim": {
+			datastore: &v1beta1.DatastoreSpec{
+				Name: "default",
+				SQL:  &v1beta1.SQLSpec{PluginName: "mysql8", ConnectAddr: "mysql", User: "temporal", DatabaseName: "temporal"},
+				Backup: &v1beta1.DatastoreBackupSpec{
+					Preset: &v1beta1.DatastoreBackupPresetSpec{
+						PersistentVolumeClaim: &v1beta1.BackupPersistentVolumeClaimDestination{ClaimName: "backups", Path: "temporal"},
+					},
+				},
+			},
+			expectedCommand:  []string{"mysqldump", "--host=mysql", "--port=3306", "--user=temporal", "--single-transaction", "--routines", "--result-file=/backup/prod-default-1.28.1.sql", "temporal"},
+			expectedLocation: "pvc/backups/temporal/prod-default-1.28.1.sql",
+		},
+		"nodetool snapshot": {
+			datastore: &v1beta1.DatastoreSpec{
+				Name:      "default",
+				Cassandra: &v1beta1.CassandraSpec{Hosts: []string{"cassandra-0", "cassandra-1"}, Keyspace: "temporal"},
+				Backup: &v1beta1.DatastoreBackupSpec{
+					Preset: &v1beta1.DatastoreBackupPresetSpec{},
+				},
+			},
+			expectedEnv:      []string{"CASSANDRA_HOSTS=cassandra-0 cassandra-1", "CASSANDRA_JMX_PORT=7199", "CASSANDRA_KEYSPACE=temporal", "SNAPSHOT_TAG=prod-v-1-28-1"},
+			expectedLocation: "snapshot/temporal/prod-v-1-28-1",
+		},
+		"user job": {
+			datastore: &v1beta1.DatastoreSpec{
+				Name:              "default",
+				SQL:               &v1beta1.SQLSpec{PluginName: "postgres12"},
+				PasswordSecretRef: password,
+				Backup: &v1beta1.DatastoreBackupSpec{
+					Job: &v1beta1.DatastoreBackupJobSpec{
+						Template: v1beta1.PodTemplateSpecOverride{
+							Spec: &apiextensionsv1.JSON{Raw: []byte(`{"containers":[{"name":"backup","image":"backup:latest","command":["backup"]}]}`)},
+						},
+					},
+				},
+			},
+			expectedCommand:  []string{"backup"},
+			expectedEnv:      []string{"TEMPORAL_DEFAULT_DATASTORE_PASSWORD="},
+			expectedLocation: "job/prod-backup-default-v-1-28-1",
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := backupCluster(test.datastore)
+			builder := persistence.NewBackupJobBuilder(cluster, scheme, "backup-default-v-1-28-1", test.datastore)
+			assert.True(tt, builder.Enabled())
+
+			job := builder.Build().(*batchv1.Job)
+			require.NoError(tt, builder.Update(job))
+
+			assert.Equal(tt, "prod-backup-default-v-1-28-1", job.Name)
+			assert.Len(tt, job.OwnerReferences, 1)
+
+			pod := job.Spec.Template.Spec
+			initContainers := []string{}
+			for _, container := range pod.InitContainers {
+				initContainers = append(initContainers, container.Name)
+			}
+			assert.ElementsMatch(tt, test.expectedInitContainers, initContainers)
+
+			require.Len(tt, pod.Containers, 1)
+			container := pod.Containers[0]
+			if test.expectedCommand != nil {
+				assert.Equal(tt, test.expectedCommand, container.Command)
+			}
+
+			env := []string{}
+			for _, v := range container.Env {
+				env = append(env, v.Name+"="+v.Value)
+			}
+			assert.Subset(tt, env, test.expectedEnv)
+
+			assert.Equal(tt, test.expectedLocation, persistence.BackupLocation(cluster, test.datastore, "backup-default-v-1-28-1"))
+		})
+	}
+}
+
+func TestBackupJobBuilderPostgresEnv(t *testing.T) {
+	datastore := &v1beta1.DatastoreSpec{
+		Name:              "default",
+		SQL:               &v1beta1.SQLSpec{PluginName: "postgres12", ConnectAddr: "postgres:5433", User: "temporal", DatabaseName: "temporal"},
+		PasswordSecretRef: &v1beta1.SecretKeyReference{Name: "postgres"},
+		Backup: &v1beta1.DatastoreBackupSpec{
+			Preset: &v1beta1.DatastoreBackupPresetSpec{
+				PersistentVolumeClaim: &v1beta1.BackupPersistentVolumeClaimDestination{ClaimName: "backups"},
+			},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	builder := persistence.NewBackupJobBuilder(backupCluster(datastore), scheme, "backup-default-v-1-28-1", datastore)
+	job := builder.Build().(*batchv1.Job)
+	require.NoError(t, builder.Update(job))
+
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "postgres:17", container.Image)
+	assert.Equal(t, []string{"pg_dump", "--format=custom", "--file=/backup/prod-default-1.28.1.dump"}, container.Command)
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "PGHOST", Value: "postgres"})
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "PGPORT", Value: "5433"})
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "PGPASSWORD", Value: "$(TEMPORAL_DEFAULT_DATASTORE_PASSWORD)"})
+	assert.Contains(t, job.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: "backup",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "backups"},
+		},
+	})
+}
@@ -0,0 +1,402 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package persistence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	cnpgv1 "github.com/alexandrevilain/temporal-operator/pkg/cnpg/apis/postgresql/v1"
+	k8ssandrav1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/k8ssandra/apis/k8ssandra/v1alpha1"
+	pxcv1 "github.com/alexandrevilain/temporal-operator/pkg/percona/apis/pxc/v1"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// managedClusterComponent is the component label set on the database clusters created by the operator.
+	managedClusterComponent = "persistence"
+	// managedClusterOwner owns the application database of the CloudNativePG clusters created by the operator.
+	managedClusterOwner = "temporal"
+
+	postgresPort  = 5432
+	mysqlPort     = 3306
+	cassandraPort = 9042
+
+	managedCACertKey = "ca.crt"
+	perconaRootUser  = "root"
+)
+
+var kubernetesNameInvalidChars = regexp.MustCompile(`[^a-z0-9-]`)
+
+// ManagedConnection holds the datastore connection details derived from a managed database cluster.
+type ManagedConnection struct {
+	// Host is the host the datastore connects to.
+	Host string
+	// Port is the port the datastore connects to.
+	Port int
+	// User is the user the datastore connects as, when it isn't read from the password secret.
+	User string
+	// UserKey is the key of the password secret holding the user, if any.
+	UserKey string
+	// PasswordSecretRef references the secret holding the password.
+	PasswordSecretRef *v1beta1.SecretKeyReference
+	// CaFileRef references the secret holding the CA, if the datastore connects using TLS.
+	CaFileRef *v1beta1.SecretKeyReference
+	// ServerName is the name verified in the database cluster certificate.
+	// Host verification is disabled when empty.
+	ServerName string
+	// Datacenter is the Cassandra datacenter.
+	Datacenter string
+}
+
+// Address returns the address the datastore connects to.
+func (c *ManagedConnection) Address() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+// Secrets returns the name of the secrets the connection details are read from.
+func (c *ManagedConnection) Secrets() []string {
+	secrets := []string{c.PasswordSecretRef.Name}
+	if c.CaFileRef != nil && c.CaFileRef.Name != c.PasswordSecretRef.Name {
+		secrets = append(secrets, c.CaFileRef.Name)
+	}
+	return secrets
+}
+
+// Apply sets the connection details in the datastore spec.
+// The user is the user read from the password secret, if the connection reads it from there.
+func (c *ManagedConnection) Apply(datastore *v1beta1.DatastoreSpec, user string) {
+	if c.UserKey == "" {
+		user = c.User
+	}
+
+	switch datastore.Managed.GetType() {
+	case v1beta1.CassandraDatastore:
+		datastore.Cassandra = &v1beta1.CassandraSpec{
+			Hosts:      []string{c.Host},
+			Port:       c.Port,
+			User:       user,
+			Keyspace:   datastore.Managed.Database,
+			Datacenter: c.Datacenter,
+		}
+	default:
+		datastore.SQL = &v1beta1.SQLSpec{
+			User:         user,
+			PluginName:   string(datastore.Managed.GetType()),
+			DatabaseName: datastore.Managed.Database,
+			ConnectAddr:  c.Address(),
+		}
+	}
+
+	datastore.PasswordSecretRef = c.PasswordSecretRef.DeepCopy()
+
+	if c.CaFileRef != nil {
+		datastore.TLS = &v1beta1.DatastoreTLSSpec{
+			Enabled:                true,
+			CaFileRef:              c.CaFileRef.DeepCopy(),
+			EnableHostVerification: c.ServerName != "",
+			ServerName:             c.ServerName,
+		}
+	}
+
+	datastore.Default()
+}
+
+// ManagedClusterReference returns the reference to the database cluster of the managed datastore.
+func ManagedClusterReference(managed *v1beta1.ManagedDatastoreSpec) *v1beta1.ManagedClusterReference {
+	switch {
+	case managed.CloudNativePG != nil:
+		return managed.CloudNativePG
+	case managed.PerconaXtraDB != nil:
+		return managed.PerconaXtraDB
+	case managed.K8ssandra != nil:
+		return managed.K8ssandra
+	}
+	return nil
+}
+
+// NewManagedClusterObject returns an empty object of the database cluster referenced by the managed datastore.
+func NewManagedClusterObject(instance *v1beta1.TemporalCluster, managed *v1beta1.ManagedDatastoreSpec) (client.Object, error) {
+	var obj client.Object
+	switch {
+	case managed.CloudNativePG != nil:
+		obj = &cnpgv1.Cluster{}
+	case managed.PerconaXtraDB != nil:
+		obj = &pxcv1.PerconaXtraDBCluster{}
+	case managed.K8ssandra != nil:
+		obj = &k8ssandrav1alpha1.K8ssandraCluster{}
+	default:
+		return nil, errors.New("managed datastore doesn't reference any database cluster")
+	}
+
+	obj.SetName(ManagedClusterReference(managed).Name)
+	obj.SetNamespace(instance.GetNamespace())
+
+	return obj, nil
+}
+
+// BuildManagedCluster returns the database cluster to create for the managed datastore.
+// The user provided spec is merged into the default spec.
+func BuildManagedCluster(instance *v1beta1.TemporalCluster, managed *v1beta1.ManagedDatastoreSpec) (*unstructured.Unstructured, error) {
+	ref := ManagedClusterReference(managed)
+	if ref == nil {
+		return nil, errors.New("managed datastore doesn't reference any database cluster")
+	}
+
+	var (
+		gvk  = cnpgv1.GroupVersion.WithKind("Cluster")
+		spec map[string]any
+	)
+	switch {
+	case managed.CloudNativePG != nil:
+		spec = map[string]any{
+			"instances": 1,
+			"storage": map[string]any{
+				"size": "1Gi",
+			},
+			"bootstrap": map[string]any{
+				"initdb": map[string]any{
+					"database": managed.Database,
+					"owner":    managedClusterOwner,
+					// The schema runner creates the other datastores databases.
+					"postInitSQL": []any{fmt.Sprintf("ALTER ROLE %s CREATEDB", managedClusterOwner)},
+				},
+			},
+		}
+	case managed.PerconaXtraDB != nil:
+		gvk = pxcv1.GroupVersion.WithKind("PerconaXtraDBCluster")
+		spec = map[string]any{
+			"pxc": map[string]any{
+				"size": 3,
+				"volumeSpec": map[string]any{
+					"persistentVolumeClaim": map[string]any{
+						"resources": map[string]any{
+							"requests": map[string]any{
+								"storage": "1Gi",
+							},
+						},
+					},
+				},
+			},
+			"haproxy": map[string]any{
+				"enabled": true,
+				"size":    3,
+			},
+		}
+	case managed.K8ssandra != nil:
+		gvk = k8ssandrav1alpha1.GroupVersion.WithKind("K8ssandraCluster")
+		spec = map[string]any{
+			"cassandra": map[string]any{
+				"serverVersion": "4.1.5",
+				"datacenters": []any{
+					map[string]any{
+						"metadata": map[string]any{
+							"name": "dc1",
+						},
+						"size": 1,
+						"storageConfig": map[string]any{
+							"cassandraDataVolumeClaimSpec": map[string]any{
+								"accessModes": []any{string(corev1.ReadWriteOnce)},
+								"resources": map[string]any{
+									"requests": map[string]any{
+										"storage": "1Gi",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("can't marshal default spec: %w", err)
+	}
+
+	if ref.Create != nil && ref.Create.Spec != nil {
+		raw, err = jsonpatch.MergePatch(raw, ref.Create.Spec.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("can't merge spec: %w", err)
+		}
+	}
+
+	// Unstructured objects only hold JSON compatible values.
+	spec = map[string]any{}
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return nil, fmt.Errorf("can't unmarshal spec: %w", err)
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(ref.Name)
+	obj.SetNamespace(instance.GetNamespace())
+	obj.SetLabels(metadata.LabelsSelector(instance, managedClusterComponent))
+	if err := unstructured.SetNestedField(obj.Object, spec, "spec"); err != nil {
+		return nil, fmt.Errorf("can't set spec: %w", err)
+	}
+
+	return obj, nil
+}
+
+// IsManagedClusterReady returns true if the database cluster accepts connections.
+func IsManagedClusterReady(obj client.Object) bool {
+	switch cluster := obj.(type) {
+	case *cnpgv1.Cluster:
+		// Older CloudNativePG versions don't report conditions.
+		if len(cluster.Status.Conditions) == 0 {
+			return cluster.Status.Phase == cnpgv1.PhaseHealthy
+		}
+		return meta.IsStatusConditionTrue(cluster.Status.Conditions, cnpgv1.ConditionClusterReady)
+	case *pxcv1.PerconaXtraDBCluster:
+		return cluster.Status.Status == pxcv1.AppStateReady
+	case *k8ssandrav1alpha1.K8ssandraCluster:
+		for _, condition := range cluster.Status.Conditions {
+			if condition.Type == k8ssandrav1alpha1.CassandraInitialized {
+				return condition.Status == corev1.ConditionTrue
+			}
+		}
+	}
+	return false
+}
+
+// GetManagedConnection returns the connection details of the database cluster.
+func GetManagedConnection(obj client.Object) (*ManagedConnection, error) {
+	switch cluster := obj.(type) {
+	case *cnpgv1.Cluster:
+		return cloudNativePGConnection(cluster), nil
+	case *pxcv1.PerconaXtraDBCluster:
+		return perconaXtraDBConnection(cluster)
+	case *k8ssandrav1alpha1.K8ssandraCluster:
+		return k8ssandraConnection(cluster)
+	}
+	return nil, fmt.Errorf("unsupported database cluster type %T", obj)
+}
+
+func cloudNativePGConnection(cluster *cnpgv1.Cluster) *ManagedConnection {
+	service := cluster.Status.WriteService
+	if service == "" {
+		service = cluster.GetName() + "-rw"
+	}
+	host := fmt.Sprintf("%s.%s.svc", service, cluster.GetNamespace())
+
+	secret := cluster.GetName() + "-app"
+	if cluster.Spec.Bootstrap != nil && cluster.Spec.Bootstrap.InitDB != nil && cluster.Spec.Bootstrap.InitDB.Secret != nil {
+		secret = cluster.Spec.Bootstrap.InitDB.Secret.Name
+	}
+
+	ca := cluster.Status.Certificates.ServerCASecret
+	if ca == "" {
+		ca = cluster.GetName() + "-ca"
+	}
+
+	return &ManagedConnection{
+		Host:              host,
+		Port:              postgresPort,
+		UserKey:           corev1.BasicAuthUsernameKey,
+		PasswordSecretRef: &v1beta1.SecretKeyReference{Name: secret, Key: corev1.BasicAuthPasswordKey},
+		CaFileRef:         &v1beta1.SecretKeyReference{Name: ca, Key: managedCACertKey},
+		ServerName:        host,
+	}
+}
+
+func perconaXtraDBConnection(cluster *pxcv1.PerconaXtraDBCluster) (*ManagedConnection, error) {
+	if cluster.Status.Host == "" {
+		return nil, fmt.Errorf("PerconaXtraDBCluster %s doesn't report its host", cluster.GetName())
+	}
+
+	secret := cluster.Spec.SecretsName
+	if secret == "" {
+		secret = cluster.GetName() + "-secrets"
+	}
+
+	ca := cluster.Spec.SSLSecretName
+	if ca == "" {
+		ca = cluster.GetName() + "-ssl"
+	}
+
+	// The certificates generated by the Percona operator don't include the proxies hostnames.
+	return &ManagedConnection{
+		Host:              cluster.Status.Host,
+		Port:              mysqlPort,
+		User:              perconaRootUser,
+		PasswordSecretRef: &v1beta1.SecretKeyReference{Name: secret, Key: perconaRootUser},
+		CaFileRef:         &v1beta1.SecretKeyReference{Name: ca, Key: managedCACertKey},
+	}, nil
+}
+
+func k8ssandraConnection(cluster *k8ssandrav1alpha1.K8ssandraCluster) (*ManagedConnection, error) {
+	if cluster.Spec.Cassandra == nil || len(cluster.Spec.Cassandra.Datacenters) == 0 {
+		return nil, fmt.Errorf("K8ssandraCluster %s doesn't define any datacenter", cluster.GetName())
+	}
+
+	clusterName := cluster.Spec.Cassandra.ClusterName
+	if clusterName == "" {
+		clusterName = cluster.GetName()
+	}
+	clusterName = cleanupForKubernetes(clusterName)
+
+	dc := cluster.Spec.Cassandra.Datacenters[0]
+	datacenter := dc.DatacenterName
+	if datacenter == "" {
+		datacenter = dc.Meta.Name
+	}
+
+	secret := cluster.Spec.Cassandra.SuperuserSecretRef.Name
+	if secret == "" {
+		secret = clusterName + "-superuser"
+	}
+
+	return &ManagedConnection{
+		Host:              fmt.Sprintf("%s-%s-service.%s.svc", clusterName, cleanupForKubernetes(datacenter), cluster.GetNamespace()),
+		Port:              cassandraPort,
+		UserKey:           corev1.BasicAuthUsernameKey,
+		PasswordSecretRef: &v1beta1.SecretKeyReference{Name: secret, Key: corev1.BasicAuthPasswordKey},
+		Datacenter:        datacenter,
+	}, nil
+}
+
+// cleanupForKubernetes sanitizes a Cassandra name the way cass-operator does to name resources.
+func cleanupForKubernetes(name string) string {
+	return kubernetesNameInvalidChars.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// ManagedClusterKind returns the kind of the database cluster.
+func ManagedClusterKind(obj client.Object) string {
+	switch obj.(type) {
+	case *cnpgv1.Cluster:
+		return "Cluster"
+	case *pxcv1.PerconaXtraDBCluster:
+		return "PerconaXtraDBCluster"
+	case *k8ssandrav1alpha1.K8ssandraCluster:
+		return "K8ssandraCluster"
+	}
+	return ""
+}
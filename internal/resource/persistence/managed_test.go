@@ -0,0 +1,250 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package persistence_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/persistence"
+	cnpgv1 "github.com/alexandrevilain/temporal-operator/pkg/cnpg/apis/postgresql/v1"
+	k8ssandrav1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/k8ssandra/apis/k8ssandra/v1alpha1"
+	pxcv1 "github.com/alexandrevilain/temporal-operator/pkg/percona/apis/pxc/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestManagedConnectionApply(t *testing.T) {
+	tests := map[string]struct {
+		managed           *v1beta1.ManagedDatastoreSpec
+		cluster           client.Object
+		user              string
+		expectedDatastore *v1beta1.DatastoreSpec
+	}{
+		"cloudnative-pg cluster": {
+			managed: &v1beta1.ManagedDatastoreSpec{
+				CloudNativePG: &v1beta1.ManagedClusterReference{Name: "postgres"},
+				Database:      "temporal",
+			},
+			cluster: &cnpgv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "demo"},
+				Status: cnpgv1.ClusterStatus{
+					WriteService: "postgres-rw",
+					Certificates: cnpgv1.CertificatesStatus{ServerCASecret: "postgres-ca"},
+				},
+			},
+			user: "app",
+			expectedDatastore: &v1beta1.DatastoreSpec{
+				SQL: &v1beta1.SQLSpec{
+					User:            "app",
+					PluginName:      "postgres12",
+					DatabaseName:    "temporal",
+					ConnectAddr:     "postgres-rw.demo.svc:5432",
+					ConnectProtocol: "tcp",
+				},
+				PasswordSecretRef: &v1beta1.SecretKeyReference{Name: "postgres-app", Key: "password"},
+				TLS: &v1beta1.DatastoreTLSSpec{
+					Enabled:                true,
+					CaFileRef:              &v1beta1.SecretKeyReference{Name: "postgres-ca", Key: "ca.crt"},
+					EnableHostVerification: true,
+					ServerName:             "postgres-rw.demo.svc",
+				},
+			},
+		},
+		"percona xtradb cluster": {
+			managed: &v1beta1.ManagedDatastoreSpec{
+				PerconaXtraDB: &v1beta1.ManagedClusterReference{Name: "mysql"},
+				Database:      "temporal",
+			},
+			cluster: &pxcv1.PerconaXtraDBCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "mysql", Namespace: "demo"},
+				Spec:       pxcv1.PerconaXtraDBClusterSpec{SecretsName: "mysql-users"},
+				Status:     pxcv1.PerconaXtraDBClusterStatus{Host: "mysql-haproxy.demo"},
+			},
+			expectedDatastore: &v1beta1.DatastoreSpec{
+				SQL: &v1beta1.SQLSpec{
+					User:            "root",
+					PluginName:      "mysql8",
+					DatabaseName:    "temporal",
+					ConnectAddr:     "mysql-haproxy.demo:3306",
+					ConnectProtocol: "tcp",
+				},
+				PasswordSecretRef: &v1beta1.SecretKeyReference{Name: "mysql-users", Key: "root"},
+				TLS: &v1beta1.DatastoreTLSSpec{
+					Enabled:   true,
+					CaFileRef: &v1beta1.SecretKeyReference{Name: "mysql-ssl", Key: "ca.crt"},
+				},
+			},
+		},
+		"k8ssandra cluster": {
+			managed: &v1beta1.ManagedDatastoreSpec{
+				K8ssandra: &v1beta1.ManagedClusterReference{Name: "cassandra"},
+				Database:  "temporal",
+			},
+			cluster: &k8ssandrav1alpha1.K8ssandraCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "cassandra", Namespace: "demo"},
+				Spec: k8ssandrav1alpha1.K8ssandraClusterSpec{
+					Cassandra: &k8ssandrav1alpha1.CassandraClusterTemplate{
+						ClusterName: "Temporal_Cluster",
+						Datacenters: []k8ssandrav1alpha1.CassandraDatacenterTemplate{
+							{Meta: k8ssandrav1alpha1.EmbeddedObjectMeta{Name: "dc1"}},
+						},
+					},
+				},
+			},
+			user: "temporal-cluster-superuser",
+			expectedDatastore: &v1beta1.DatastoreSpec{
+				Cassandra: &v1beta1.CassandraSpec{
+					Hosts:          []string{"temporal-cluster-dc1-service.demo.svc"},
+					Port:           9042,
+					User:           "temporal-cluster-superuser",
+					Keyspace:       "temporal",
+					Datacenter:     "dc1",
+					ConnectTimeout: &metav1.Duration{Duration: 10000000000},
+				},
+				PasswordSecretRef: &v1beta1.SecretKeyReference{Name: "temporal-cluster-superuser", Key: "password"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			connection, err := persistence.GetManagedConnection(test.cluster)
+			assert.NoError(tt, err)
+
+			datastore := &v1beta1.DatastoreSpec{Managed: test.managed}
+			connection.Apply(datastore, test.user)
+
+			test.expectedDatastore.Managed = test.managed
+			assert.Equal(tt, test.expectedDatastore, datastore)
+		})
+	}
+}
+
+func TestIsManagedClusterReady(t *testing.T) {
+	tests := map[string]struct {
+		cluster  client.Object
+		expected bool
+	}{
+		"cloudnative-pg cluster ready": {
+			cluster: &cnpgv1.Cluster{
+				Status: cnpgv1.ClusterStatus{
+					Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}},
+				},
+			},
+			expected: true,
+		},
+		"cloudnative-pg cluster not ready": {
+			cluster: &cnpgv1.Cluster{
+				Status: cnpgv1.ClusterStatus{
+					Phase:      cnpgv1.PhaseHealthy,
+					Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionFalse}},
+				},
+			},
+			expected: false,
+		},
+		"cloudnative-pg cluster without conditions": {
+			cluster: &cnpgv1.Cluster{
+				Status: cnpgv1.ClusterStatus{Phase: cnpgv1.PhaseHealthy},
+			},
+			expected: true,
+		},
+		"percona xtradb cluster initializing": {
+			cluster: &pxcv1.PerconaXtraDBCluster{
+				Status: pxcv1.PerconaXtraDBClusterStatus{Status: "initializing"},
+			},
+			expected: false,
+		},
+		"k8ssandra cluster initialized": {
+			cluster: &k8ssandrav1alpha1.K8ssandraCluster{
+				Status: k8ssandrav1alpha1.K8ssandraClusterStatus{
+					Conditions: []k8ssandrav1alpha1.K8ssandraClusterCondition{
+						{Type: k8ssandrav1alpha1.CassandraInitialized, Status: corev1.ConditionTrue},
+					},
+				},
+			},
+			expected: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, persistence.IsManagedClusterReady(test.cluster))
+		})
+	}
+}
+
+func TestBuildManagedCluster(t *testing.T) {
+	instance := &v1beta1.TemporalCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "temporal", Namespace: "demo"},
+	}
+
+	managed := &v1beta1.ManagedDatastoreSpec{
+		CloudNativePG: &v1beta1.ManagedClusterReference{
+			Name: "postgres",
+			Create: &v1beta1.ManagedClusterCreateSpec{
+				Spec: &apiextensionsv1.JSON{Raw: []byte(`{"instances":3,"storage":{"storageClass":"fast"}}`)},
+			},
+		},
+		Database: "temporal",
+	}
+
+	obj, err := persistence.BuildManagedCluster(instance, managed)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "postgresql.cnpg.io/v1", obj.GetAPIVersion())
+	assert.Equal(t, "Cluster", obj.GetKind())
+	assert.Equal(t, "postgres", obj.GetName())
+	assert.Equal(t, "demo", obj.GetNamespace())
+
+	instances, _, _ := unstructured.NestedFloat64(obj.Object, "spec", "instances")
+	assert.Equal(t, float64(3), instances)
+
+	storage, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "storage")
+	assert.Equal(t, map[string]string{"size": "1Gi", "storageClass": "fast"}, storage)
+
+	database, _, _ := unstructured.NestedString(obj.Object, "spec", "bootstrap", "initdb", "database")
+	assert.Equal(t, "temporal", database)
+}
+
+func TestBuildManagedClusterDefaults(t *testing.T) {
+	instance := &v1beta1.TemporalCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "temporal", Namespace: "demo"},
+	}
+
+	managed := &v1beta1.ManagedDatastoreSpec{
+		K8ssandra: &v1beta1.ManagedClusterReference{
+			Name:   "cassandra",
+			Create: &v1beta1.ManagedClusterCreateSpec{},
+		},
+		Database: "temporal",
+	}
+
+	obj, err := persistence.BuildManagedCluster(instance, managed)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "k8ssandra.io/v1alpha1", obj.GetAPIVersion())
+	assert.Equal(t, "K8ssandraCluster", obj.GetKind())
+
+	datacenters, _, _ := unstructured.NestedSlice(obj.Object, "spec", "cassandra", "datacenters")
+	assert.Len(t, datacenters, 1)
+}
@@ -0,0 +1,178 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	"github.com/alexandrevilain/temporal-operator/pkg/schemarunner"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+type Schema string
+
+const (
+	DefaultSchema    Schema = "default"
+	VisibilitySchema Schema = "visibility"
+
+	// SchemaRunnerConfigFileName is the name of the schema runner configuration file.
+	SchemaRunnerConfigFileName = "config.json"
+
+	// schemasPath is the path of the temporal schemas in the admin-tools image.
+	schemasPath = "/etc/temporal/schema"
+
+	defaultSchemaPath    = "temporal"
+	visibilitySchemaPath = "visibility"
+
+	postgreSQLSchemaPath          = "postgresql"
+	postgreSQLVersionSchemaPath   = "v96"
+	postgreSQL12VersionSchemaPath = "v12"
+
+	mysqlSchemaPath         = "mysql"
+	mysqlVersionSchemaPath  = "v57"
+	mysql8VersionSchemaPath = "v8"
+
+	cassandraSchemaPath        = "cassandra"
+	cassandraVersionSchemaPath = ""
+
+	elasticsearchSchemaPath        = "elasticsearch"
+	elasticsearchVersionSchemaPath = ""
+)
+
+// SchemaRunnerConfigmapBuilder builds the configmap holding the schema runner configuration.
+type SchemaRunnerConfigmapBuilder struct {
+	instance *v1beta1.TemporalCluster
+	scheme   *runtime.Scheme
+}
+
+func NewSchemaRunnerConfigmapBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme) *SchemaRunnerConfigmapBuilder {
+	return &SchemaRunnerConfigmapBuilder{
+		instance: instance,
+		scheme:   scheme,
+	}
+}
+
+func (b *SchemaRunnerConfigmapBuilder) Build() client.Object {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.ChildResourceName("schema-runner"),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, "schema-runner", b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *SchemaRunnerConfigmapBuilder) Enabled() bool {
+	return true
+}
+
+func (b *SchemaRunnerConfigmapBuilder) computeSchemaDir(storeType v1beta1.DatastoreType, targetSchema Schema) string {
+	storeSchemaPath := ""
+	storeVersionSchemaPath := ""
+	switch storeType {
+	case v1beta1.PostgresSQLDatastore:
+		storeSchemaPath = postgreSQLSchemaPath
+		storeVersionSchemaPath = postgreSQLVersionSchemaPath
+	case v1beta1.PostgresSQL12Datastore:
+		storeSchemaPath = postgreSQLSchemaPath
+		storeVersionSchemaPath = postgreSQL12VersionSchemaPath
+	case v1beta1.MySQLDatastore:
+		storeSchemaPath = mysqlSchemaPath
+		storeVersionSchemaPath = mysqlVersionSchemaPath
+	case v1beta1.MySQL8Datastore:
+		storeSchemaPath = mysqlSchemaPath
+		storeVersionSchemaPath = mysql8VersionSchemaPath
+	case v1beta1.CassandraDatastore:
+		storeSchemaPath = cassandraSchemaPath
+		storeVersionSchemaPath = cassandraVersionSchemaPath
+	case v1beta1.ElasticsearchDatastore:
+		storeSchemaPath = elasticsearchSchemaPath
+		storeVersionSchemaPath = elasticsearchVersionSchemaPath
+	case v1beta1.UnknownDatastore:
+		storeSchemaPath = ""
+		storeVersionSchemaPath = ""
+	}
+
+	tagetSchemaPath := defaultSchemaPath
+	if targetSchema == VisibilitySchema {
+		tagetSchemaPath = visibilitySchemaPath
+	}
+
+	return path.Join(schemasPath, storeSchemaPath, storeVersionSchemaPath, tagetSchemaPath, "versioned")
+}
+
+func (b *SchemaRunnerConfigmapBuilder) storeConfig(spec *v1beta1.DatastoreSpec, targetSchema Schema) *schemarunner.StoreConfig {
+	return &schemarunner.StoreConfig{
+		Datastore: spec,
+		SchemaDir: b.computeSchemaDir(spec.GetType(), targetSchema),
+	}
+}
+
+// Config returns the schema runner configuration for the cluster stores.
+func (b *SchemaRunnerConfigmapBuilder) Config() *schemarunner.Config {
+	persistence := b.instance.Spec.Persistence
+
+	config := &schemarunner.Config{
+		Stores: map[schemarunner.Store]*schemarunner.StoreConfig{
+			schemarunner.DefaultStore:    b.storeConfig(persistence.DefaultStore, DefaultSchema),
+			schemarunner.VisibilityStore: b.storeConfig(persistence.VisibilityStore, VisibilitySchema),
+		},
+	}
+
+	if b.instance.Spec.MTLS != nil {
+		config.MTLSProvider = b.instance.Spec.MTLS.Provider
+	}
+
+	if persistence.SecondaryVisibilityStore != nil {
+		config.Stores[schemarunner.SecondaryVisibilityStore] = b.storeConfig(persistence.SecondaryVisibilityStore, VisibilitySchema)
+	}
+
+	if persistence.AdvancedVisibilityStore != nil {
+		config.Stores[schemarunner.AdvancedVisibilityStore] = b.storeConfig(persistence.AdvancedVisibilityStore, VisibilitySchema)
+	}
+
+	return config
+}
+
+func (b *SchemaRunnerConfigmapBuilder) Update(object client.Object) error {
+	configMap := object.(*corev1.ConfigMap)
+
+	content, err := json.Marshal(b.Config())
+	if err != nil {
+		return fmt.Errorf("can't marshal schema runner config: %w", err)
+	}
+
+	configMap.Data = map[string]string{
+		SchemaRunnerConfigFileName: string(content),
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, configMap, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
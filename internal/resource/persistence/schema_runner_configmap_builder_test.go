@@ -0,0 +1,111 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package persistence_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/resource/persistence"
+	"github.com/alexandrevilain/temporal-operator/pkg/schemarunner"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestSchemaRunnerConfigmapBuilderConfig(t *testing.T) {
+	postgres := &v1beta1.DatastoreSpec{
+		Name: "default",
+		SQL:  &v1beta1.SQLSpec{PluginName: "postgres12"},
+	}
+	mysql := &v1beta1.DatastoreSpec{
+		Name: "visibility",
+		SQL:  &v1beta1.SQLSpec{PluginName: "mysql8"},
+	}
+	cassandra := &v1beta1.DatastoreSpec{
+		Name:      "default",
+		Cassandra: &v1beta1.CassandraSpec{Keyspace: "temporal"},
+	}
+	elasticsearch := &v1beta1.DatastoreSpec{
+		Name:          "advancedVisibility",
+		Elasticsearch: &v1beta1.ElasticsearchSpec{Version: "v7"},
+	}
+
+	tests := map[string]struct {
+		persistence v1beta1.TemporalPersistenceSpec
+		mtls        *v1beta1.MTLSSpec
+		expected    *schemarunner.Config
+	}{
+		"sql stores": {
+			persistence: v1beta1.TemporalPersistenceSpec{
+				DefaultStore:    postgres,
+				VisibilityStore: mysql,
+			},
+			expected: &schemarunner.Config{
+				Stores: map[schemarunner.Store]*schemarunner.StoreConfig{
+					schemarunner.DefaultStore: {
+						Datastore: postgres,
+						SchemaDir: "/etc/temporal/schema/postgresql/v12/temporal/versioned",
+					},
+					schemarunner.VisibilityStore: {
+						Datastore: mysql,
+						SchemaDir: "/etc/temporal/schema/mysql/v8/visibility/versioned",
+					},
+				},
+			},
+		},
+		"cassandra and advanced visibility with linkerd": {
+			persistence: v1beta1.TemporalPersistenceSpec{
+				DefaultStore:            cassandra,
+				VisibilityStore:         mysql,
+				AdvancedVisibilityStore: elasticsearch,
+			},
+			mtls: &v1beta1.MTLSSpec{Provider: v1beta1.LinkerdMTLSProvider},
+			expected: &schemarunner.Config{
+				MTLSProvider: v1beta1.LinkerdMTLSProvider,
+				Stores: map[schemarunner.Store]*schemarunner.StoreConfig{
+					schemarunner.DefaultStore: {
+						Datastore: cassandra,
+						SchemaDir: "/etc/temporal/schema/cassandra/temporal/versioned",
+					},
+					schemarunner.VisibilityStore: {
+						Datastore: mysql,
+						SchemaDir: "/etc/temporal/schema/mysql/v8/visibility/versioned",
+					},
+					schemarunner.AdvancedVisibilityStore: {
+						Datastore: elasticsearch,
+						SchemaDir: "/etc/temporal/schema/elasticsearch/visibility/versioned",
+					},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := &v1beta1.TemporalCluster{
+				Spec: v1beta1.TemporalClusterSpec{
+					Persistence: test.persistence,
+					MTLS:        test.mtls,
+				},
+			}
+
+			builder := persistence.NewSchemaRunnerConfigmapBuilder(cluster, runtime.NewScheme())
+			assert.Equal(tt, test.expected, builder.Config())
+		})
+	}
+}
@@ -19,6 +19,7 @@ package persistence
 
 import (
 	"fmt"
+	"path"
 
 	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
 	"github.com/alexandrevilain/temporal-operator/internal/metadata"
@@ -37,21 +38,35 @@ import (
 // ServiceNameSuffix is used as suffix in resource names for persistence setup jobs in place of a ServiceName.
 const ServiceNameSuffix = "schema-setup"
 
+const (
+	// SchemaRunnerContainerName is the name of the container running the schema runner in schema jobs.
+	SchemaRunnerContainerName = "schema-runner"
+
+	schemaRunnerBinary     = "/schema-runner"
+	schemaRunnerConfigPath = "/etc/schema-runner"
+	// schemasVolumeMountPath is where the schemas are copied by the init container,
+	// the volume is then mounted at the parent of schemasPath in the runner container.
+	schemasVolumeMountPath = "/schemas"
+)
+
 type SchemaJobBuilder struct {
 	instance *v1beta1.TemporalCluster
 	scheme   *runtime.Scheme
 	// name is the name of the job
 	name string
-	// command is the command the job should run
+	// command is the schema runner operation and store the job should run
 	command []string
+	// image is the schema runner image
+	image string
 }
 
-func NewSchemaJobBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, name string, command []string) *SchemaJobBuilder {
+func NewSchemaJobBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, name string, command []string, image string) *SchemaJobBuilder {
 	return &SchemaJobBuilder{
 		instance: instance,
 		scheme:   scheme,
 		name:     name,
 		command:  command,
+		image:    image,
 	}
 }
 
@@ -62,18 +77,16 @@ func (b *SchemaJobBuilder) Enabled() bool {
 func (b *SchemaJobBuilder) Build() client.Object {
 	datastores := b.instance.Spec.Persistence.GetDatastores()
 
-	envVars := []corev1.EnvVar{
-		{
-			Name:  "TEMPORAL_CLI_ADDRESS",
-			Value: fmt.Sprintf("%s:%d", b.instance.ChildResourceName("frontend"), *b.instance.Spec.Services.Frontend.Port),
-		},
-	}
-	envVars = append(envVars, GetDatastoresEnvironmentVariables(datastores)...)
+	envVars := GetDatastoresEnvironmentVariables(datastores)
 
 	volumeMounts := []corev1.VolumeMount{
 		{
-			Name:      "scripts",
-			MountPath: "/etc/scripts",
+			Name:      "config",
+			MountPath: schemaRunnerConfigPath,
+		},
+		{
+			Name:      "schemas",
+			MountPath: path.Dir(schemasPath),
 		},
 	}
 
@@ -81,20 +94,47 @@ func (b *SchemaJobBuilder) Build() client.Object {
 
 	volumes := []corev1.Volume{
 		{
-			Name: "scripts",
+			Name: "config",
 			VolumeSource: corev1.VolumeSource{
 				ConfigMap: &corev1.ConfigMapVolumeSource{
 					LocalObjectReference: corev1.LocalObjectReference{
-						Name: b.instance.ChildResourceName("schema-scripts"),
+						Name: b.instance.ChildResourceName("schema-runner"),
 					},
-					DefaultMode: ptr.To[int32](0o777),
 				},
 			},
 		},
+		{
+			Name: "schemas",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
 	}
 
 	volumes = append(volumes, GetDatastoresVolumes(datastores)...)
 
+	// The schemas matching the cluster version are shipped in the admin-tools image,
+	// copy them so the runner applies the versioned schemas of the cluster version.
+	initContainers := append([]corev1.Container{}, b.instance.Spec.JobInitContainers...)
+	initContainers = append(initContainers, corev1.Container{
+		Name:                     "copy-schemas",
+		Image:                    fmt.Sprintf("%s:%s", b.instance.Spec.AdminTools.Image, version.DefaultAdminToolTag(b.instance.Spec.Version)),
+		ImagePullPolicy:          corev1.PullIfNotPresent,
+		Resources:                b.instance.Spec.JobResources,
+		TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		Command:                  []string{"cp", "-RP", schemasPath, schemasVolumeMountPath},
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr.To(false),
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "schemas",
+				MountPath: schemasVolumeMountPath,
+			},
+		},
+	})
+
 	return &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        b.instance.ChildResourceName(b.name),
@@ -108,6 +148,7 @@ func (b *SchemaJobBuilder) Build() client.Object {
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: metadata.Merge(
 						istio.GetLabels(b.instance),
+						GetDatastoresPodLabels(datastores),
 						metadata.GetLabels(b.instance, b.name, b.instance.Spec.Version, b.instance.Labels),
 					),
 					Annotations: metadata.Merge(
@@ -123,13 +164,14 @@ func (b *SchemaJobBuilder) Build() client.Object {
 					DeprecatedServiceAccount: b.instance.ChildResourceName(ServiceNameSuffix),
 					Containers: []corev1.Container{
 						{
-							Name:                     "schema-script-runner",
-							Image:                    fmt.Sprintf("%s:%s", b.instance.Spec.AdminTools.Image, version.DefaultAdminToolTag(b.instance.Spec.Version)),
+							Name:                     SchemaRunnerContainerName,
+							Image:                    b.image,
 							ImagePullPolicy:          corev1.PullIfNotPresent,
 							Resources:                b.instance.Spec.JobResources,
 							TerminationMessagePath:   corev1.TerminationMessagePathDefault,
 							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
-							Command:                  append([]string{"/bin/sh", "-c"}, b.command...),
+							Command:                  []string{schemaRunnerBinary, "--config", path.Join(schemaRunnerConfigPath, SchemaRunnerConfigFileName)},
+							Args:                     b.command,
 							Env:                      envVars,
 							SecurityContext: &corev1.SecurityContext{
 								AllowPrivilegeEscalation: ptr.To(false),
@@ -137,7 +179,7 @@ func (b *SchemaJobBuilder) Build() client.Object {
 							VolumeMounts: volumeMounts,
 						},
 					},
-					InitContainers:                b.instance.Spec.JobInitContainers,
+					InitContainers:                initContainers,
 					TerminationGracePeriodSeconds: ptr.To[int64](30),
 					DNSPolicy:                     corev1.DNSClusterFirst,
 					SecurityContext:               &corev1.PodSecurityContext{},
@@ -27,26 +27,81 @@ import (
 
 const (
 	defaultPasswordSecretKey = "password"
+	defaultAPIKeySecretKey   = "apiKey"
 )
 
+// secretKeyRef returns a copy of the provided secret reference, using the default key if none is set.
+func secretKeyRef(ref *v1beta1.SecretKeyReference, defaultKey string) *v1beta1.SecretKeyReference {
+	result := ref.DeepCopy()
+	if result.Key == "" {
+		result.Key = defaultKey
+	}
+	return result
+}
+
+// DatastoreSecretRefs returns the secret references used to connect to the datastore.
+// Their key is defaulted the same way as when mounting them in the services pods.
+func DatastoreSecretRefs(datastore *v1beta1.DatastoreSpec) []*v1beta1.SecretKeyReference {
+	refs := DatastoreTLSSecretRefs(datastore)
+	if datastore.PasswordSecretRef != nil {
+		refs = append(refs, secretKeyRef(datastore.PasswordSecretRef, defaultPasswordSecretKey))
+	}
+	if datastore.IsElasticsearchAPIKeyAuth() {
+		refs = append(refs, secretKeyRef(&datastore.Elasticsearch.Auth.APIKey.SecretRef, defaultAPIKeySecretKey))
+	}
+	return refs
+}
+
+// DatastoreTLSSecretRefs returns the secret references holding the datastore TLS files.
+// Their key is defaulted the same way as when mounting them in the services pods.
+func DatastoreTLSSecretRefs(datastore *v1beta1.DatastoreSpec) []*v1beta1.SecretKeyReference {
+	refs := []*v1beta1.SecretKeyReference{}
+	if datastore.TLS == nil {
+		return refs
+	}
+	if datastore.TLS.CaFileRef != nil {
+		refs = append(refs, secretKeyRef(datastore.TLS.CaFileRef, v1beta1.DataStoreClientTLSCaFileName))
+	}
+	if datastore.TLS.CertFileRef != nil {
+		refs = append(refs, secretKeyRef(datastore.TLS.CertFileRef, v1beta1.DataStoreClientTLSCertFileName))
+	}
+	if datastore.TLS.KeyFileRef != nil {
+		refs = append(refs, secretKeyRef(datastore.TLS.KeyFileRef, v1beta1.DataStoreClientTLSKeyFileName))
+	}
+	return refs
+}
+
 // GetDatastoresEnvironmentVariables returns needed env vars for the provided datastores list.
 func GetDatastoresEnvironmentVariables(datastores []*v1beta1.DatastoreSpec) []corev1.EnvVar {
 	vars := []corev1.EnvVar{}
 	for _, datastore := range datastores {
 		if datastore.PasswordSecretRef != nil {
-			key := datastore.PasswordSecretRef.Key
-			if key == "" {
-				key = defaultPasswordSecretKey
-			}
+			ref := secretKeyRef(datastore.PasswordSecretRef, defaultPasswordSecretKey)
 			vars = append(vars,
 				corev1.EnvVar{
 					Name: datastore.GetPasswordEnvVarName(),
 					ValueFrom: &corev1.EnvVarSource{
 						SecretKeyRef: &corev1.SecretKeySelector{
 							LocalObjectReference: corev1.LocalObjectReference{
-								Name: datastore.PasswordSecretRef.Name,
+								Name: ref.Name,
+							},
+							Key: ref.Key,
+						},
+					},
+				},
+			)
+		}
+		if datastore.IsElasticsearchAPIKeyAuth() {
+			ref := secretKeyRef(&datastore.Elasticsearch.Auth.APIKey.SecretRef, defaultAPIKeySecretKey)
+			vars = append(vars,
+				corev1.EnvVar{
+					Name: datastore.GetAPIKeyEnvVarName(),
+					ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: ref.Name,
 							},
-							Key: key,
+							Key: ref.Key,
 						},
 					},
 				},
@@ -62,10 +117,7 @@ func GetDatastoresVolumes(datastores []*v1beta1.DatastoreSpec) []corev1.Volume {
 	for _, datastore := range datastores {
 		if datastore.TLS != nil && datastore.TLS.Enabled {
 			if datastore.TLS.CaFileRef != nil {
-				key := datastore.TLS.CaFileRef.Key
-				if key == "" {
-					key = v1beta1.DataStoreClientTLSCaFileName
-				}
+				key := secretKeyRef(datastore.TLS.CaFileRef, v1beta1.DataStoreClientTLSCaFileName).Key
 				volumes = append(volumes,
 					corev1.Volume{
 						Name: fmt.Sprintf("%s-tls-ca-file", datastore.LowerCaseName()),
@@ -84,10 +136,7 @@ func GetDatastoresVolumes(datastores []*v1beta1.DatastoreSpec) []corev1.Volume {
 				)
 			}
 			if datastore.TLS.CertFileRef != nil {
-				key := datastore.TLS.CertFileRef.Key
-				if key == "" {
-					key = v1beta1.DataStoreClientTLSCertFileName
-				}
+				key := secretKeyRef(datastore.TLS.CertFileRef, v1beta1.DataStoreClientTLSCertFileName).Key
 				volumes = append(volumes,
 					corev1.Volume{
 						Name: fmt.Sprintf("%s-tls-cert-file", datastore.LowerCaseName()),
@@ -107,10 +156,7 @@ func GetDatastoresVolumes(datastores []*v1beta1.DatastoreSpec) []corev1.Volume {
 			}
 
 			if datastore.TLS.KeyFileRef != nil {
-				key := datastore.TLS.KeyFileRef.Key
-				if key == "" {
-					key = v1beta1.DataStoreClientTLSKeyFileName
-				}
+				key := secretKeyRef(datastore.TLS.KeyFileRef, v1beta1.DataStoreClientTLSKeyFileName).Key
 				volumes = append(volumes,
 					corev1.Volume{
 						Name: fmt.Sprintf("%s-tls-key-file", datastore.LowerCaseName()),
@@ -124,6 +124,35 @@ func TestGetDatastoresEnvironmentVariables(t *testing.T) {
 				},
 			},
 		},
+		"elasticsearch datastore with api key": {
+			datastores: []*v1beta1.DatastoreSpec{
+				{
+					Name: "test",
+					Elasticsearch: &v1beta1.ElasticsearchSpec{
+						Auth: &v1beta1.ElasticsearchAuthSpec{
+							APIKey: &v1beta1.ElasticsearchAPIKeyAuthSpec{
+								SecretRef: v1beta1.SecretKeyReference{
+									Name: "testSecret",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedEnvVars: []corev1.EnvVar{
+				{
+					Name: "TEMPORAL_TEST_DATASTORE_API_KEY",
+					ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: "testSecret",
+							},
+							Key: "apiKey",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -134,6 +163,97 @@ func TestGetDatastoresEnvironmentVariables(t *testing.T) {
 	}
 }
 
+func TestDatastoreSecretRefs(t *testing.T) {
+	tests := map[string]struct {
+		datastore    *v1beta1.DatastoreSpec
+		expectedRefs []*v1beta1.SecretKeyReference
+	}{
+		"no secret": {
+			datastore:    &v1beta1.DatastoreSpec{Name: "test"},
+			expectedRefs: []*v1beta1.SecretKeyReference{},
+		},
+		"password without secret key defined": {
+			datastore: &v1beta1.DatastoreSpec{
+				Name: "test",
+				PasswordSecretRef: &v1beta1.SecretKeyReference{
+					Name: "testSecret",
+				},
+			},
+			expectedRefs: []*v1beta1.SecretKeyReference{
+				{Name: "testSecret", Key: "password"},
+			},
+		},
+		"password with secret key defined": {
+			datastore: &v1beta1.DatastoreSpec{
+				Name: "test",
+				PasswordSecretRef: &v1beta1.SecretKeyReference{
+					Name: "testSecret",
+					Key:  "my-password",
+				},
+			},
+			expectedRefs: []*v1beta1.SecretKeyReference{
+				{Name: "testSecret", Key: "my-password"},
+			},
+		},
+		"tls files without secret keys defined": {
+			datastore: &v1beta1.DatastoreSpec{
+				Name: "test",
+				TLS: &v1beta1.DatastoreTLSSpec{
+					Enabled:     true,
+					CaFileRef:   &v1beta1.SecretKeyReference{Name: "tls"},
+					CertFileRef: &v1beta1.SecretKeyReference{Name: "tls"},
+					KeyFileRef:  &v1beta1.SecretKeyReference{Name: "tls", Key: "my-key"},
+				},
+			},
+			expectedRefs: []*v1beta1.SecretKeyReference{
+				{Name: "tls", Key: v1beta1.DataStoreClientTLSCaFileName},
+				{Name: "tls", Key: v1beta1.DataStoreClientTLSCertFileName},
+				{Name: "tls", Key: "my-key"},
+			},
+		},
+		"elasticsearch api key without secret key defined": {
+			datastore: &v1beta1.DatastoreSpec{
+				Name: "test",
+				Elasticsearch: &v1beta1.ElasticsearchSpec{
+					Auth: &v1beta1.ElasticsearchAuthSpec{
+						APIKey: &v1beta1.ElasticsearchAPIKeyAuthSpec{
+							SecretRef: v1beta1.SecretKeyReference{Name: "es"},
+						},
+					},
+				},
+			},
+			expectedRefs: []*v1beta1.SecretKeyReference{
+				{Name: "es", Key: "apiKey"},
+			},
+		},
+		"elasticsearch api key with secret key defined": {
+			datastore: &v1beta1.DatastoreSpec{
+				Name: "test",
+				Elasticsearch: &v1beta1.ElasticsearchSpec{
+					Auth: &v1beta1.ElasticsearchAuthSpec{
+						APIKey: &v1beta1.ElasticsearchAPIKeyAuthSpec{
+							SecretRef: v1beta1.SecretKeyReference{Name: "es", Key: "encoded"},
+						},
+					},
+				},
+			},
+			expectedRefs: []*v1beta1.SecretKeyReference{
+				{Name: "es", Key: "encoded"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			original := test.datastore.DeepCopy()
+			result := persistence.DatastoreSecretRefs(test.datastore)
+			assert.EqualValues(tt, test.expectedRefs, result)
+			// The datastore spec is left untouched.
+			assert.Equal(tt, original, test.datastore)
+		})
+	}
+}
+
 func TestGetDatastoresVolumes(t *testing.T) {
 	tests := map[string]struct {
 		datastores      []*v1beta1.DatastoreSpec
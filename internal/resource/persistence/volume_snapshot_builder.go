@@ -0,0 +1,85 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package persistence
+
+import (
+	"fmt"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	snapshotv1 "github.com/alexandrevilain/temporal-operator/pkg/snapshotter/apis/volumesnapshot/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// VolumeSnapshotBuilder builds the VolumeSnapshot backing up a datastore volume before its schema is updated.
+type VolumeSnapshotBuilder struct {
+	instance *v1beta1.TemporalCluster
+	scheme   *runtime.Scheme
+	// name is the name of the snapshot
+	name string
+	// datastore is the datastore to backup
+	datastore *v1beta1.DatastoreSpec
+}
+
+func NewVolumeSnapshotBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, name string, datastore *v1beta1.DatastoreSpec) *VolumeSnapshotBuilder {
+	return &VolumeSnapshotBuilder{
+		instance:  instance,
+		scheme:    scheme,
+		name:      name,
+		datastore: datastore,
+	}
+}
+
+func (b *VolumeSnapshotBuilder) Enabled() bool {
+	return b.datastore.Backup != nil && b.datastore.Backup.VolumeSnapshot != nil
+}
+
+func (b *VolumeSnapshotBuilder) Build() client.Object {
+	return &snapshotv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.instance.ChildResourceName(b.name),
+			Namespace:   b.instance.Namespace,
+			Labels:      metadata.GetLabels(b.instance, b.name, b.instance.Spec.Version, b.instance.Labels),
+			Annotations: metadata.GetAnnotations(b.instance.Name, b.instance.Annotations),
+		},
+	}
+}
+
+func (b *VolumeSnapshotBuilder) Update(object client.Object) error {
+	snapshot := object.(*snapshotv1.VolumeSnapshot)
+
+	// The snapshot spec is immutable, only set it on creation.
+	if snapshot.CreationTimestamp.IsZero() {
+		spec := b.datastore.Backup.VolumeSnapshot
+		snapshot.Spec = snapshotv1.VolumeSnapshotSpec{
+			Source: snapshotv1.VolumeSnapshotSource{
+				PersistentVolumeClaimName: ptr.To(spec.PersistentVolumeClaimName),
+			},
+			VolumeSnapshotClassName: spec.VolumeSnapshotClassName,
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(b.instance, snapshot, b.scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+	return nil
+}
@@ -39,16 +39,18 @@ const (
 )
 
 type DeploymentBuilder struct {
-	instance   *v1beta1.TemporalCluster
-	scheme     *runtime.Scheme
-	configHash string
+	instance     *v1beta1.TemporalCluster
+	scheme       *runtime.Scheme
+	configHash   string
+	caBundleHash string
 }
 
-func NewDeploymentBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, configHash string) *DeploymentBuilder {
+func NewDeploymentBuilder(instance *v1beta1.TemporalCluster, scheme *runtime.Scheme, configHash, caBundleHash string) *DeploymentBuilder {
 	return &DeploymentBuilder{
-		instance:   instance,
-		scheme:     scheme,
-		configHash: configHash,
+		instance:     instance,
+		scheme:       scheme,
+		configHash:   configHash,
+		caBundleHash: caBundleHash,
 	}
 }
 
@@ -92,7 +94,11 @@ func (b *DeploymentBuilder) Update(object client.Object) error {
 		},
 	}
 
-	if b.instance.MTLSWithCertManagerEnabled() && b.instance.Spec.MTLS.FrontendEnabled() {
+	env = append(env, authEnvironmentVariables(b.instance.Spec.UI.Auth)...)
+	env = append(env, codecEnvironmentVariables(b.instance)...)
+	env = append(env, featuresEnvironmentVariables(b.instance.Spec.UI.Features)...)
+
+	if b.instance.MTLSWithCertificatesEnabled() && b.instance.Spec.MTLS.FrontendEnabled() {
 		volumeMounts = append(volumeMounts,
 			corev1.VolumeMount{
 				Name:      certmanager.UIFrontendClientCertificate,
@@ -102,13 +108,8 @@ func (b *DeploymentBuilder) Update(object client.Object) error {
 
 		volumes = append(volumes,
 			corev1.Volume{
-				Name: certmanager.UIFrontendClientCertificate,
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName:  b.instance.ChildResourceName(certmanager.UIFrontendClientCertificate),
-						DefaultMode: ptr.To[int32](corev1.SecretVolumeSourceDefaultMode),
-					},
-				},
+				Name:         certmanager.UIFrontendClientCertificate,
+				VolumeSource: certmanager.GetClientCertificateVolumeSource(b.instance, certmanager.UIFrontendClientCertificate),
 			},
 		)
 
@@ -121,7 +122,7 @@ func (b *DeploymentBuilder) Update(object client.Object) error {
 		MatchLabels: metadata.LabelsSelector(b.instance, "ui"),
 	}
 	deployment.Spec.Template = corev1.PodTemplateSpec{
-		ObjectMeta: meta.BuildPodObjectMeta(b.instance, "ui", b.configHash),
+		ObjectMeta: meta.BuildPodObjectMeta(b.instance, "ui", b.instance.Spec.Version, b.configHash, b.caBundleHash, ""),
 		Spec: corev1.PodSpec{
 			ImagePullSecrets: b.instance.Spec.ImagePullSecrets,
 			Containers: []corev1.Container{
@@ -0,0 +1,128 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// authEnvironmentVariables returns the environment variables configuring the UI OIDC authentication.
+func authEnvironmentVariables(auth *v1beta1.TemporalUIAuthSpec) []corev1.EnvVar {
+	if auth == nil {
+		return nil
+	}
+
+	env := []corev1.EnvVar{
+		{Name: "TEMPORAL_AUTH_ENABLED", Value: "true"},
+		{Name: "TEMPORAL_AUTH_TYPE", Value: "oidc"},
+		{Name: "TEMPORAL_AUTH_PROVIDER_URL", Value: auth.ProviderURL},
+		{Name: "TEMPORAL_AUTH_CLIENT_ID", Value: auth.ClientID},
+		{
+			Name: "TEMPORAL_AUTH_CLIENT_SECRET",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: auth.ClientSecretRef,
+			},
+		},
+		{Name: "TEMPORAL_AUTH_CALLBACK_URL", Value: auth.CallbackURL},
+	}
+
+	if auth.IssuerURL != "" {
+		env = append(env, corev1.EnvVar{Name: "TEMPORAL_AUTH_ISSUER_URL", Value: auth.IssuerURL})
+	}
+	if len(auth.Scopes) > 0 {
+		env = append(env, corev1.EnvVar{Name: "TEMPORAL_AUTH_SCOPES", Value: strings.Join(auth.Scopes, ",")})
+	}
+	if auth.Label != "" {
+		env = append(env, corev1.EnvVar{Name: "TEMPORAL_AUTH_LABEL", Value: auth.Label})
+	}
+	if auth.UseIDTokenAsBearer {
+		env = append(env, corev1.EnvVar{Name: "TEMPORAL_AUTH_USE_ID_TOKEN_AS_BEARER", Value: "true"})
+	}
+	if auth.MaxSessionDuration != nil {
+		env = append(env, corev1.EnvVar{Name: "TEMPORAL_AUTH_MAX_SESSION_DURATION", Value: auth.MaxSessionDuration.Duration.String()})
+	}
+
+	return env
+}
+
+// codecEnvironmentVariables returns the environment variables configuring the UI remote codec.
+// If no endpoint is provided, the codec server deployed by the operator is used.
+func codecEnvironmentVariables(instance *v1beta1.TemporalCluster) []corev1.EnvVar {
+	codec := instance.Spec.UI.Codec
+	if codec == nil {
+		codec = &v1beta1.TemporalUICodecSpec{}
+	}
+
+	endpoint := codec.Endpoint
+	if endpoint == "" {
+		endpoint = instance.CodecServerEndpoint()
+	}
+
+	if endpoint == "" {
+		return nil
+	}
+
+	return []corev1.EnvVar{
+		{Name: "TEMPORAL_CODEC_ENDPOINT", Value: endpoint},
+		{Name: "TEMPORAL_CODEC_PASS_ACCESS_TOKEN", Value: strconv.FormatBool(codec.PassAccessToken)},
+		{Name: "TEMPORAL_CODEC_INCLUDE_CREDENTIALS", Value: strconv.FormatBool(codec.IncludeCredentials)},
+	}
+}
+
+// featuresEnvironmentVariables returns the environment variables configuring the UI features.
+// Only enabled flags are set, so that the UI defaults are kept otherwise.
+func featuresEnvironmentVariables(features *v1beta1.TemporalUIFeaturesSpec) []corev1.EnvVar {
+	if features == nil {
+		return nil
+	}
+
+	env := []corev1.EnvVar{}
+
+	flags := []struct {
+		name    string
+		enabled bool
+	}{
+		{"TEMPORAL_DISABLE_WRITE_ACTIONS", features.DisableWriteActions},
+		{"TEMPORAL_WORKFLOW_TERMINATE_DISABLED", features.WorkflowTerminateDisabled},
+		{"TEMPORAL_WORKFLOW_CANCEL_DISABLED", features.WorkflowCancelDisabled},
+		{"TEMPORAL_WORKFLOW_SIGNAL_DISABLED", features.WorkflowSignalDisabled},
+		{"TEMPORAL_WORKFLOW_RESET_DISABLED", features.WorkflowResetDisabled},
+		{"TEMPORAL_BATCH_ACTIONS_DISABLED", features.BatchActionsDisabled},
+		{"TEMPORAL_START_WORKFLOW_DISABLED", features.StartWorkflowDisabled},
+		{"TEMPORAL_HIDE_WORKFLOW_QUERY_ERRORS", features.HideWorkflowQueryErrors},
+		{"TEMPORAL_SHOW_TEMPORAL_SYSTEM_NAMESPACE", features.ShowTemporalSystemNamespace},
+	}
+	for _, flag := range flags {
+		if flag.enabled {
+			env = append(env, corev1.EnvVar{Name: flag.name, Value: "true"})
+		}
+	}
+
+	if features.FeedbackURL != "" {
+		env = append(env, corev1.EnvVar{Name: "TEMPORAL_FEEDBACK_URL", Value: features.FeedbackURL})
+	}
+	if len(features.CORSOrigins) > 0 {
+		env = append(env, corev1.EnvVar{Name: "TEMPORAL_CORS_ORIGINS", Value: strings.Join(features.CORSOrigins, ",")})
+	}
+
+	return env
+}
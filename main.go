@@ -36,16 +36,28 @@ import (
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	istionetworkingv1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
 	istiosecurityv1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	"github.com/alexandrevilain/controller-tools/pkg/discovery"
 	temporaliov1beta1 "github.com/alexandrevilain/temporal-operator/api/v1beta1"
 	"github.com/alexandrevilain/temporal-operator/controllers"
 	internaldiscovery "github.com/alexandrevilain/temporal-operator/internal/discovery"
+	cnpgv1 "github.com/alexandrevilain/temporal-operator/pkg/cnpg/apis/postgresql/v1"
+	k8ssandrav1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/k8ssandra/apis/k8ssandra/v1alpha1"
+	kedav1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/keda/apis/keda/v1alpha1"
+	linkerdpolicyv1alpha1 "github.com/alexandrevilain/temporal-operator/pkg/linkerd/apis/policy/v1alpha1"
+	linkerdpolicyv1beta1 "github.com/alexandrevilain/temporal-operator/pkg/linkerd/apis/policy/v1beta1"
+	pxcv1 "github.com/alexandrevilain/temporal-operator/pkg/percona/apis/pxc/v1"
+	snapshotv1 "github.com/alexandrevilain/temporal-operator/pkg/snapshotter/apis/volumesnapshot/v1"
 	"github.com/alexandrevilain/temporal-operator/webhooks"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	//+kubebuilder:scaffold:imports
 )
 
+// defaultSchemaRunnerImage is used when the schema runner image is neither set by flag nor by environment variable.
+const defaultSchemaRunnerImage = "ghcr.io/alexandrevilain/temporal-operator:latest"
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -58,6 +70,15 @@ func init() {
 	utilruntime.Must(istionetworkingv1beta1.AddToScheme(scheme))
 	utilruntime.Must(temporaliov1beta1.AddToScheme(scheme))
 	utilruntime.Must(monitoringv1.AddToScheme(scheme))
+	utilruntime.Must(gatewayv1.Install(scheme))
+	utilruntime.Must(gatewayv1alpha2.Install(scheme))
+	utilruntime.Must(linkerdpolicyv1beta1.AddToScheme(scheme))
+	utilruntime.Must(linkerdpolicyv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(kedav1alpha1.AddToScheme(scheme))
+	utilruntime.Must(snapshotv1.AddToScheme(scheme))
+	utilruntime.Must(cnpgv1.AddToScheme(scheme))
+	utilruntime.Must(pxcv1.AddToScheme(scheme))
+	utilruntime.Must(k8ssandrav1alpha1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -66,12 +87,15 @@ func main() {
 		metricsAddr          string
 		enableLeaderElection bool
 		probeAddr            string
+		schemaRunnerImage    string
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&schemaRunnerImage, "schema-runner-image", getEnvOrDefault("SCHEMA_RUNNER_IMAGE", defaultSchemaRunnerImage),
+		"The image running persistence schema jobs, it should be the operator image.")
 
 	opts := zap.Options{
 		Development: true,
@@ -108,8 +132,10 @@ func main() {
 	}
 
 	if err = (&controllers.TemporalClusterReconciler{
-		Base:          controllers.New(mgr.GetClient(), mgr.GetScheme(), mgr.GetEventRecorderFor("cluster-controller"), discoveryManager),
-		AvailableAPIs: availableAPIs,
+		Base:              controllers.New(mgr.GetClient(), mgr.GetScheme(), mgr.GetEventRecorderFor("cluster-controller"), discoveryManager),
+		AvailableAPIs:     availableAPIs,
+		APIReader:         mgr.GetAPIReader(),
+		SchemaRunnerImage: schemaRunnerImage,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Cluster")
 		os.Exit(1)
@@ -162,3 +188,10 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return defaultValue
+}
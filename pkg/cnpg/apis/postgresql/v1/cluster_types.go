@@ -0,0 +1,120 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionClusterReady is the condition type set when the cluster is ready.
+	ConditionClusterReady = "Ready"
+	// PhaseHealthy is the phase of a healthy cluster.
+	PhaseHealthy = "Cluster in healthy state"
+)
+
+// LocalObjectReference contains enough information to let you locate a
+// local object with a known type inside the same namespace.
+type LocalObjectReference struct {
+	// Name of the referent.
+	Name string `json:"name"`
+}
+
+// BootstrapInitDB is the configuration of the bootstrap process when
+// initdb is used.
+type BootstrapInitDB struct {
+	// Database is the name of the database used by the application.
+	// +optional
+	Database string `json:"database,omitempty"`
+	// Owner is the name of the role owning the application database.
+	// +optional
+	Owner string `json:"owner,omitempty"`
+	// Secret is the name of the secret containing the initial credentials for the owner of the user database.
+	// +optional
+	Secret *LocalObjectReference `json:"secret,omitempty"`
+}
+
+// BootstrapConfiguration contains information about how to create the PostgreSQL cluster.
+type BootstrapConfiguration struct {
+	// InitDB bootstraps the cluster from an empty database.
+	// +optional
+	InitDB *BootstrapInitDB `json:"initdb,omitempty"`
+}
+
+// ClusterSpec defines the desired state of Cluster.
+type ClusterSpec struct {
+	// Instances is the number of instances required in the cluster.
+	Instances int `json:"instances"`
+	// Bootstrap holds the instructions to bootstrap the cluster.
+	// +optional
+	Bootstrap *BootstrapConfiguration `json:"bootstrap,omitempty"`
+}
+
+// CertificatesStatus contains configuration certificates and related expiration dates.
+type CertificatesStatus struct {
+	// ServerCASecret is the secret containing the Server CA certificate.
+	// +optional
+	ServerCASecret string `json:"serverCASecret,omitempty"`
+}
+
+// ClusterStatus defines the observed state of Cluster.
+type ClusterStatus struct {
+	// Instances is the total number of PVC Groups detected in the cluster.
+	// +optional
+	Instances int `json:"instances,omitempty"`
+	// ReadyInstances is the total number of ready instances in the cluster.
+	// +optional
+	ReadyInstances int `json:"readyInstances,omitempty"`
+	// Phase is the current phase of the cluster.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// WriteService is the name of the service accepting read-write connections.
+	// +optional
+	WriteService string `json:"writeService,omitempty"`
+	// Certificates holds the certificates used by the cluster.
+	// +optional
+	Certificates CertificatesStatus `json:"certificates,omitempty"`
+	// Conditions for cluster object.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Cluster is the Schema for the PostgreSQL API.
+type Cluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterSpec `json:"spec"`
+	// +optional
+	Status ClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterList contains a list of Cluster.
+type ClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Cluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Cluster{}, &ClusterList{})
+}
@@ -15,24 +15,8 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package persistence
-
-import (
-	"strings"
-	"testing"
-
-	"github.com/stretchr/testify/assert"
-)
-
-func TestTemplates(t *testing.T) {
-	var s strings.Builder
-	assert.NoError(t, templates[createDatabaseTemplate].Execute(&s, struct {
-		MTLSProvider   string
-		Tool           string
-		ConnectionArgs string
-		DatabaseName   string
-	}{
-		MTLSProvider: "linkerd",
-	}))
-	assert.Contains(t, s.String(), "curl -X POST http://localhost:4191/shutdown")
-}
+// Package v1 contains the subset of the CloudNativePG postgresql.cnpg.io/v1 API read by the operator.
+// +kubebuilder:object:generate=true
+// +kubebuilder:skip
+// +groupName=postgresql.cnpg.io
+package v1
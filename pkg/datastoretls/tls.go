@@ -0,0 +1,101 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package datastoretls holds the TLS helpers used by the operator tools
+// connecting to the datastores: the schema runner and the auth proxies.
+package datastoretls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+)
+
+// postgresSSLRequestCode is the code of the postgres SSLRequest message.
+const postgresSSLRequestCode = 80877103
+
+// ClientConfig builds the client TLS configuration from the files mounted from the datastore TLS secrets.
+// When the datastore TLS is not enabled, the server certificate is verified using the system roots.
+func ClientConfig(spec *v1beta1.DatastoreSpec, host string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName: host,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if spec.TLS == nil || !spec.TLS.Enabled {
+		return cfg, nil
+	}
+
+	if spec.TLS.ServerName != "" {
+		cfg.ServerName = spec.TLS.ServerName
+	}
+	// Mirror the temporal server behavior which only verifies the server certificate when host verification is enabled.
+	cfg.InsecureSkipVerify = !spec.TLS.EnableHostVerification //nolint:gosec
+
+	if caFile := spec.GetTLSCaFileMountPath(); caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't read ca file: %w", err)
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("can't parse ca file %s", caFile)
+		}
+	}
+
+	certFile, keyFile := spec.GetTLSCertFileMountPath(), spec.GetTLSKeyFileMountPath()
+	if certFile != "" && keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// PostgresStartTLS sends the postgres SSLRequest message and waits for the server to accept it.
+func PostgresStartTLS(conn net.Conn) error {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], postgresSSLRequestCode)
+
+	_, err := conn.Write(request)
+	if err != nil {
+		return err
+	}
+
+	response := make([]byte, 1)
+	_, err = io.ReadFull(conn, response)
+	if err != nil {
+		return err
+	}
+
+	if response[0] != 'S' {
+		return errors.New("server doesn't support TLS")
+	}
+	return nil
+}
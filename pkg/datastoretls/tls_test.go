@@ -0,0 +1,141 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package datastoretls_test
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/datastoretls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientConfig(t *testing.T) {
+	tests := map[string]struct {
+		tls                        *v1beta1.DatastoreTLSSpec
+		expectedServerName         string
+		expectedInsecureSkipVerify bool
+	}{
+		"no tls": {
+			expectedServerName: "db.example.com",
+		},
+		"tls disabled": {
+			tls: &v1beta1.DatastoreTLSSpec{
+				Enabled:    false,
+				ServerName: "other.example.com",
+			},
+			expectedServerName: "db.example.com",
+		},
+		"tls without host verification": {
+			tls: &v1beta1.DatastoreTLSSpec{
+				Enabled: true,
+			},
+			expectedServerName:         "db.example.com",
+			expectedInsecureSkipVerify: true,
+		},
+		"tls with host verification and server name": {
+			tls: &v1beta1.DatastoreTLSSpec{
+				Enabled:                true,
+				EnableHostVerification: true,
+				ServerName:             "other.example.com",
+			},
+			expectedServerName: "other.example.com",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			spec := &v1beta1.DatastoreSpec{
+				Name: "default",
+				TLS:  test.tls,
+			}
+
+			cfg, err := datastoretls.ClientConfig(spec, "db.example.com")
+			require.NoError(tt, err)
+
+			assert.Equal(tt, test.expectedServerName, cfg.ServerName)
+			assert.Equal(tt, test.expectedInsecureSkipVerify, cfg.InsecureSkipVerify)
+			assert.Nil(tt, cfg.RootCAs)
+			assert.Empty(tt, cfg.Certificates)
+		})
+	}
+}
+
+func TestClientConfigMissingCAFile(t *testing.T) {
+	spec := &v1beta1.DatastoreSpec{
+		Name: "default",
+		TLS: &v1beta1.DatastoreTLSSpec{
+			Enabled:   true,
+			CaFileRef: &v1beta1.SecretKeyReference{Name: "ca"},
+		},
+	}
+
+	_, err := datastoretls.ClientConfig(spec, "db.example.com")
+	assert.ErrorContains(t, err, "can't read ca file")
+}
+
+func TestPostgresStartTLS(t *testing.T) {
+	tests := map[string]struct {
+		response    byte
+		expectedErr string
+	}{
+		"accepted": {
+			response: 'S',
+		},
+		"refused": {
+			response:    'N',
+			expectedErr: "server doesn't support TLS",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			defer server.Close()
+
+			request := make(chan []byte, 1)
+			go func() {
+				buf := make([]byte, 8)
+				_, err := io.ReadFull(server, buf)
+				if err != nil {
+					close(request)
+					return
+				}
+				request <- buf
+				_, _ = server.Write([]byte{test.response})
+			}()
+
+			err := datastoretls.PostgresStartTLS(client)
+			if test.expectedErr != "" {
+				assert.EqualError(tt, err, test.expectedErr)
+			} else {
+				assert.NoError(tt, err)
+			}
+
+			buf := <-request
+			require.Len(tt, buf, 8)
+			assert.Equal(tt, uint32(8), binary.BigEndian.Uint32(buf[0:4]))
+			assert.Equal(tt, uint32(80877103), binary.BigEndian.Uint32(buf[4:8]))
+		})
+	}
+}
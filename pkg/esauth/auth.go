@@ -0,0 +1,134 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package esauth authenticates the requests sent to elasticsearch datastores.
+// It's used by the schema runner and by the auth proxy forwarding the services requests
+// to datastores using an authentication the temporal elasticsearch client doesn't support.
+package esauth
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+// awsSigningService is the service name used to sign Amazon OpenSearch Service requests.
+const awsSigningService = "es"
+
+// Authenticator authenticates the requests sent to an elasticsearch datastore.
+// Passwords and API keys are read from the environment variables populated from the datastore secrets.
+type Authenticator struct {
+	spec   *v1beta1.DatastoreSpec
+	signer *v4.Signer
+}
+
+// NewAuthenticator returns an authenticator for the provided elasticsearch datastore.
+func NewAuthenticator(spec *v1beta1.DatastoreSpec) (*Authenticator, error) {
+	authenticator := &Authenticator{spec: spec}
+
+	if spec.IsElasticsearchAWSRequestSigning() {
+		// The default credentials chain uses the web identity token mounted by IRSA.
+		sess, err := session.NewSession(&aws.Config{
+			Region: aws.String(spec.Elasticsearch.Auth.AWSRequestSigning.Region),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("can't create aws session: %w", err)
+		}
+		authenticator.signer = v4.NewSigner(sess.Config.Credentials)
+	}
+
+	return authenticator, nil
+}
+
+// Identity describes how requests are authenticated.
+func (a *Authenticator) Identity() string {
+	switch {
+	case a.spec.IsElasticsearchAWSRequestSigning():
+		return fmt.Sprintf("signed with AWS credentials for %s", a.spec.Elasticsearch.Auth.AWSRequestSigning.Region)
+	case a.spec.IsElasticsearchAPIKeyAuth():
+		return "authenticated with an API key"
+	case a.spec.Elasticsearch.GetUsername() != "":
+		return fmt.Sprintf("authenticated as %s", a.spec.Elasticsearch.GetUsername())
+	default:
+		return "connected anonymously"
+	}
+}
+
+// Authenticate adds the datastore credentials to the provided request.
+// The body is needed to sign requests, it replaces the request body.
+func (a *Authenticator) Authenticate(req *http.Request, body []byte) error {
+	switch {
+	case a.signer != nil:
+		_, err := a.signer.Sign(req, bytes.NewReader(body), awsSigningService, a.spec.Elasticsearch.Auth.AWSRequestSigning.Region, time.Now())
+		if err != nil {
+			return fmt.Errorf("can't sign request: %w", err)
+		}
+	case a.spec.IsElasticsearchAPIKeyAuth():
+		req.Header.Set("Authorization", "ApiKey "+os.Getenv(a.spec.GetAPIKeyEnvVarName()))
+	case a.spec.Elasticsearch.GetUsername() != "":
+		password := ""
+		if a.spec.PasswordSecretRef != nil {
+			password = os.Getenv(a.spec.GetPasswordEnvVarName())
+		}
+		req.SetBasicAuth(a.spec.Elasticsearch.GetUsername(), password)
+	}
+
+	return nil
+}
+
+// Transport returns a round tripper authenticating the requests before sending them using the provided transport.
+func (a *Authenticator) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{authenticator: a, base: base}
+}
+
+type transport struct {
+	authenticator *Authenticator
+	base          http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("can't read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	err := t.authenticator.Authenticate(req, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.base.RoundTrip(req)
+}
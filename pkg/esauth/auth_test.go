@@ -0,0 +1,136 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package esauth_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/esauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticator(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("TEMPORAL_VISIBILITY_DATASTORE_PASSWORD", "pa$$word")
+	t.Setenv("TEMPORAL_VISIBILITY_DATASTORE_API_KEY", "ZW5jb2RlZA==")
+
+	tests := map[string]struct {
+		elasticsearch     *v1beta1.ElasticsearchSpec
+		passwordSecretRef *v1beta1.SecretKeyReference
+		expectedIdentity  string
+		assertRequest     func(t *testing.T, r *http.Request)
+	}{
+		"anonymous": {
+			elasticsearch:    &v1beta1.ElasticsearchSpec{},
+			expectedIdentity: "connected anonymously",
+			assertRequest: func(t *testing.T, r *http.Request) {
+				assert.Empty(t, r.Header.Get("Authorization"))
+			},
+		},
+		"legacy username": {
+			elasticsearch:     &v1beta1.ElasticsearchSpec{Username: "temporal"},
+			passwordSecretRef: &v1beta1.SecretKeyReference{Name: "es-password"},
+			expectedIdentity:  "authenticated as temporal",
+			assertRequest: func(t *testing.T, r *http.Request) {
+				username, password, ok := r.BasicAuth()
+				assert.True(t, ok)
+				assert.Equal(t, "temporal", username)
+				assert.Equal(t, "pa$$word", password)
+			},
+		},
+		"basic": {
+			elasticsearch: &v1beta1.ElasticsearchSpec{
+				Auth: &v1beta1.ElasticsearchAuthSpec{
+					Basic: &v1beta1.ElasticsearchBasicAuthSpec{Username: "admin"},
+				},
+			},
+			passwordSecretRef: &v1beta1.SecretKeyReference{Name: "es-password"},
+			expectedIdentity:  "authenticated as admin",
+			assertRequest: func(t *testing.T, r *http.Request) {
+				username, password, ok := r.BasicAuth()
+				assert.True(t, ok)
+				assert.Equal(t, "admin", username)
+				assert.Equal(t, "pa$$word", password)
+			},
+		},
+		"api key": {
+			elasticsearch: &v1beta1.ElasticsearchSpec{
+				Auth: &v1beta1.ElasticsearchAuthSpec{
+					APIKey: &v1beta1.ElasticsearchAPIKeyAuthSpec{
+						SecretRef: v1beta1.SecretKeyReference{Name: "es-api-key"},
+					},
+				},
+			},
+			expectedIdentity: "authenticated with an API key",
+			assertRequest: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "ApiKey ZW5jb2RlZA==", r.Header.Get("Authorization"))
+			},
+		},
+		"aws request signing": {
+			elasticsearch: &v1beta1.ElasticsearchSpec{
+				Auth: &v1beta1.ElasticsearchAuthSpec{
+					AWSRequestSigning: &v1beta1.ElasticsearchAWSRequestSigningSpec{Region: "eu-west-1"},
+				},
+			},
+			expectedIdentity: "signed with AWS credentials for eu-west-1",
+			assertRequest: func(t *testing.T, r *http.Request) {
+				authorization := r.Header.Get("Authorization")
+				assert.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIAEXAMPLE/"))
+				assert.Contains(t, authorization, "/eu-west-1/es/aws4_request")
+				assert.NotEmpty(t, r.Header.Get("X-Amz-Date"))
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(tt, err)
+				assert.Equal(tt, `{"query":{}}`, string(body))
+
+				test.assertRequest(tt, r)
+			}))
+			defer server.Close()
+
+			spec := &v1beta1.DatastoreSpec{
+				Name:              "visibility",
+				Elasticsearch:     test.elasticsearch,
+				PasswordSecretRef: test.passwordSecretRef,
+			}
+
+			authenticator, err := esauth.NewAuthenticator(spec)
+			require.NoError(tt, err)
+			assert.Equal(tt, test.expectedIdentity, authenticator.Identity())
+
+			client := &http.Client{Transport: authenticator.Transport(nil)}
+			resp, err := client.Post(server.URL+"/temporal_visibility_v1/_search", "application/json", strings.NewReader(`{"query":{}}`))
+			require.NoError(tt, err)
+			defer resp.Body.Close()
+
+			assert.Equal(tt, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package esauth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/datastoretls"
+)
+
+// NewProxy returns a reverse proxy forwarding the requests to the datastore elasticsearch URL.
+// Requests are authenticated using the datastore credentials, the ones sent by the clients are dropped.
+func NewProxy(spec *v1beta1.DatastoreSpec, authenticator *Authenticator) (*httputil.ReverseProxy, error) {
+	target, err := url.Parse(spec.Elasticsearch.URL)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse elasticsearch url: %w", err)
+	}
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	if target.Scheme == "https" {
+		base.TLSClientConfig, err = datastoretls.ClientConfig(spec, target.Hostname())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			r.Out.Header.Del("Authorization")
+		},
+		Transport: authenticator.Transport(base),
+	}, nil
+}
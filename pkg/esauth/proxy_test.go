@@ -0,0 +1,77 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package esauth_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/esauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy(t *testing.T) {
+	t.Setenv("TEMPORAL_VISIBILITY_DATASTORE_API_KEY", "ZW5jb2RlZA==")
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/temporal_visibility_v1/_doc/1", r.URL.Path)
+		assert.Equal(t, "ApiKey ZW5jb2RlZA==", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"found":true}`))
+	}))
+	defer upstream.Close()
+
+	spec := &v1beta1.DatastoreSpec{
+		Name: "visibility",
+		Elasticsearch: &v1beta1.ElasticsearchSpec{
+			URL: upstream.URL,
+			Auth: &v1beta1.ElasticsearchAuthSpec{
+				APIKey: &v1beta1.ElasticsearchAPIKeyAuthSpec{
+					SecretRef: v1beta1.SecretKeyReference{Name: "es-api-key"},
+				},
+			},
+		},
+	}
+
+	authenticator, err := esauth.NewAuthenticator(spec)
+	require.NoError(t, err)
+
+	proxy, err := esauth.NewProxy(spec, authenticator)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(proxy)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/temporal_visibility_v1/_doc/1", nil)
+	require.NoError(t, err)
+	// Credentials sent by the services are replaced.
+	req.SetBasicAuth("temporal", "")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"found":true}`, string(body))
+}
@@ -0,0 +1,106 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CassandraInitialized is the condition type set once the Cassandra datacenters are ready.
+const CassandraInitialized = "CassandraInitialized"
+
+// EmbeddedObjectMeta contains a subset of the fields of an object metadata.
+type EmbeddedObjectMeta struct {
+	// Name of the object.
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// CassandraDatacenterTemplate defines a Cassandra datacenter.
+type CassandraDatacenterTemplate struct {
+	// Meta holds the datacenter metadata.
+	// +optional
+	Meta EmbeddedObjectMeta `json:"metadata,omitempty"`
+	// DatacenterName allows to override the name of the Cassandra datacenter.
+	// +optional
+	DatacenterName string `json:"datacenterName,omitempty"`
+}
+
+// CassandraClusterTemplate defines the Cassandra cluster.
+type CassandraClusterTemplate struct {
+	// ClusterName is the name of the Cassandra cluster. Defaults to the K8ssandraCluster name.
+	// +optional
+	ClusterName string `json:"clusterName,omitempty"`
+	// SuperuserSecretRef is a reference to the secret holding the Cassandra superuser credentials.
+	// +optional
+	SuperuserSecretRef corev1.LocalObjectReference `json:"superuserSecretRef,omitempty"`
+	// Datacenters lists the Cassandra datacenters.
+	// +optional
+	Datacenters []CassandraDatacenterTemplate `json:"datacenters,omitempty"`
+}
+
+// K8ssandraClusterSpec defines the desired state of K8ssandraCluster.
+type K8ssandraClusterSpec struct {
+	// Cassandra is the Cassandra cluster definition.
+	// +optional
+	Cassandra *CassandraClusterTemplate `json:"cassandra,omitempty"`
+}
+
+// K8ssandraClusterCondition describes the state of a K8ssandraCluster.
+type K8ssandraClusterCondition struct {
+	// Type of the condition.
+	Type string `json:"type"`
+	// Status of the condition.
+	Status corev1.ConditionStatus `json:"status"`
+	// LastTransitionTime is the last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// K8ssandraClusterStatus defines the observed state of K8ssandraCluster.
+type K8ssandraClusterStatus struct {
+	// Conditions of the cluster.
+	// +optional
+	Conditions []K8ssandraClusterCondition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// K8ssandraCluster is the Schema for the k8ssandraclusters API.
+type K8ssandraCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec K8ssandraClusterSpec `json:"spec,omitempty"`
+	// +optional
+	Status K8ssandraClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// K8ssandraClusterList contains a list of K8ssandraCluster.
+type K8ssandraClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []K8ssandraCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&K8ssandraCluster{}, &K8ssandraClusterList{})
+}
@@ -0,0 +1,66 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kubernetes
+
+import (
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// HTTPRouteSpec returns the HTTPRoute spec routing the provided route spec matches to the provided service port.
+// If no path match is provided, all requests are routed to the service.
+func HTTPRouteSpec(spec *v1beta1.GatewayHTTPRouteSpec, serviceName string, servicePort int32) gatewayv1.HTTPRouteSpec {
+	matches := make([]gatewayv1.HTTPRouteMatch, 0, len(spec.PathMatches))
+	for _, path := range spec.PathMatches {
+		matches = append(matches, gatewayv1.HTTPRouteMatch{
+			Path: path.DeepCopy(),
+		})
+	}
+
+	if len(matches) == 0 {
+		matches = append(matches, gatewayv1.HTTPRouteMatch{
+			Path: &gatewayv1.HTTPPathMatch{
+				Type:  ptr.To(gatewayv1.PathMatchPathPrefix),
+				Value: ptr.To("/"),
+			},
+		})
+	}
+
+	return gatewayv1.HTTPRouteSpec{
+		CommonRouteSpec: gatewayv1.CommonRouteSpec{
+			ParentRefs: spec.ParentRefs,
+		},
+		Hostnames: spec.Hostnames,
+		Rules: []gatewayv1.HTTPRouteRule{
+			{
+				Matches: matches,
+				BackendRefs: []gatewayv1.HTTPBackendRef{
+					{
+						BackendRef: gatewayv1.BackendRef{
+							BackendObjectReference: gatewayv1.BackendObjectReference{
+								Name: gatewayv1.ObjectName(serviceName),
+								Port: ptr.To(gatewayv1.PortNumber(servicePort)),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,119 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kubernetes_test
+
+import (
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestHTTPRouteSpec(t *testing.T) {
+	parentRefs := []gatewayv1.ParentReference{{Name: "gateway"}}
+	backendRefs := []gatewayv1.HTTPBackendRef{
+		{
+			BackendRef: gatewayv1.BackendRef{
+				BackendObjectReference: gatewayv1.BackendObjectReference{
+					Name: "test-ui",
+					Port: ptr.To[gatewayv1.PortNumber](8080),
+				},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		spec     *v1beta1.GatewayHTTPRouteSpec
+		expected gatewayv1.HTTPRouteSpec
+	}{
+		"defaults to a root prefix match": {
+			spec: &v1beta1.GatewayHTTPRouteSpec{
+				ParentRefs: parentRefs,
+				Hostnames:  []gatewayv1.Hostname{"temporal.example.com"},
+			},
+			expected: gatewayv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{
+					ParentRefs: parentRefs,
+				},
+				Hostnames: []gatewayv1.Hostname{"temporal.example.com"},
+				Rules: []gatewayv1.HTTPRouteRule{
+					{
+						Matches: []gatewayv1.HTTPRouteMatch{
+							{
+								Path: &gatewayv1.HTTPPathMatch{
+									Type:  ptr.To(gatewayv1.PathMatchPathPrefix),
+									Value: ptr.To("/"),
+								},
+							},
+						},
+						BackendRefs: backendRefs,
+					},
+				},
+			},
+		},
+		"uses provided path matches": {
+			spec: &v1beta1.GatewayHTTPRouteSpec{
+				ParentRefs: parentRefs,
+				PathMatches: []gatewayv1.HTTPPathMatch{
+					{
+						Type:  ptr.To(gatewayv1.PathMatchExact),
+						Value: ptr.To("/temporal"),
+					},
+					{
+						Type:  ptr.To(gatewayv1.PathMatchPathPrefix),
+						Value: ptr.To("/temporal/"),
+					},
+				},
+			},
+			expected: gatewayv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{
+					ParentRefs: parentRefs,
+				},
+				Rules: []gatewayv1.HTTPRouteRule{
+					{
+						Matches: []gatewayv1.HTTPRouteMatch{
+							{
+								Path: &gatewayv1.HTTPPathMatch{
+									Type:  ptr.To(gatewayv1.PathMatchExact),
+									Value: ptr.To("/temporal"),
+								},
+							},
+							{
+								Path: &gatewayv1.HTTPPathMatch{
+									Type:  ptr.To(gatewayv1.PathMatchPathPrefix),
+									Value: ptr.To("/temporal/"),
+								},
+							},
+						},
+						BackendRefs: backendRefs,
+					},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			result := kubernetes.HTTPRouteSpec(test.spec, "test-ui", 8080)
+			assert.Equal(tt, test.expected, result)
+		})
+	}
+}
@@ -132,6 +132,51 @@ func ApplyDeploymentOverrides(deployment *appsv1.Deployment, override *v1beta1.D
 	return nil
 }
 
+// ApplyStatefulSetOverrides applies the provided DeploymentOverride to the provided StatefulSet.
+// The JSON patch is applied to the StatefulSet.
+func ApplyStatefulSetOverrides(statefulSet *appsv1.StatefulSet, override *v1beta1.DeploymentOverride) error {
+	if override == nil {
+		return nil
+	}
+
+	if override.ObjectMetaOverride != nil {
+		if len(override.Labels) > 0 {
+			statefulSet.Labels = metadata.Merge(statefulSet.Labels, override.Labels)
+		}
+
+		if len(override.Annotations) > 0 {
+			statefulSet.Annotations = metadata.Merge(statefulSet.Annotations, override.Annotations)
+		}
+	}
+
+	if override.Spec != nil {
+		err := ApplyPodTemplateSpecOverrides(&statefulSet.Spec.Template, override.Spec.Template)
+		if err != nil {
+			return err
+		}
+	}
+
+	if override.JSONPatch != nil {
+		patch, err := jsonpatch.DecodePatch(override.JSONPatch.Raw)
+		if err != nil {
+			return fmt.Errorf("can't decode json patch: %w", err)
+		}
+
+		original, err := json.Marshal(statefulSet)
+		if err != nil {
+			return fmt.Errorf("can't marshal statefulset spec: %w", err)
+		}
+
+		patched, err := patch.Apply(original)
+		if err != nil {
+			return fmt.Errorf("can't apply json patch: %w", err)
+		}
+		return json.Unmarshal(patched, &statefulSet)
+	}
+
+	return nil
+}
+
 // ApplyServiceOverrides applies the provided ServiceOverride to the provided Service.
 func ApplyServiceOverrides(service *corev1.Service, override *v1beta1.ObjectMetaOverride) error {
 	if override == nil {
@@ -0,0 +1,51 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AuthorizationPolicySpec authorizes clients to access a target once they are authenticated.
+type AuthorizationPolicySpec struct {
+	// TargetRef references the resource the policy applies to (e.g. a Server).
+	TargetRef PolicyTargetReference `json:"targetRef"`
+	// RequiredAuthenticationRefs references the authentications clients must satisfy.
+	RequiredAuthenticationRefs []PolicyTargetReference `json:"requiredAuthenticationRefs"`
+}
+
+// AuthorizationPolicy is the Schema for the linkerd authorizationpolicies API.
+// +kubebuilder:object:root=true
+type AuthorizationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AuthorizationPolicySpec `json:"spec,omitempty"`
+}
+
+// AuthorizationPolicyList contains a list of AuthorizationPolicy.
+// +kubebuilder:object:root=true
+type AuthorizationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AuthorizationPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AuthorizationPolicy{}, &AuthorizationPolicyList{})
+}
@@ -0,0 +1,33 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v1alpha1
+
+// PolicyTargetReference identifies an API object, optionally in another namespace.
+type PolicyTargetReference struct {
+	// Group is the group of the referent.
+	// +optional
+	Group string `json:"group,omitempty"`
+	// Kind is the kind of the referent.
+	Kind string `json:"kind"`
+	// Name is the name of the referent.
+	Name string `json:"name"`
+	// Namespace is the namespace of the referent.
+	// When unspecified, the local namespace is inferred.
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+}
@@ -0,0 +1,54 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MeshTLSAuthenticationSpec defines the meshed identities a client must have.
+type MeshTLSAuthenticationSpec struct {
+	// Identities is a list of proxy identity strings (as provided via mTLS) that are authenticated.
+	// "*" matches all identities.
+	// +optional
+	Identities []string `json:"identities,omitempty"`
+	// IdentityRefs is a list of references to resources (e.g. ServiceAccounts) whose identities are authenticated.
+	// +optional
+	IdentityRefs []PolicyTargetReference `json:"identityRefs,omitempty"`
+}
+
+// MeshTLSAuthentication is the Schema for the linkerd meshtlsauthentications API.
+// +kubebuilder:object:root=true
+type MeshTLSAuthentication struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MeshTLSAuthenticationSpec `json:"spec,omitempty"`
+}
+
+// MeshTLSAuthenticationList contains a list of MeshTLSAuthentication.
+// +kubebuilder:object:root=true
+type MeshTLSAuthenticationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MeshTLSAuthentication `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MeshTLSAuthentication{}, &MeshTLSAuthenticationList{})
+}
@@ -0,0 +1,67 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ProxyProtocol is the protocol used by the linkerd proxy to handle inbound connections on a Server.
+type ProxyProtocol string
+
+const (
+	ProxyProtocolUnknown ProxyProtocol = "unknown"
+	ProxyProtocolHTTP1   ProxyProtocol = "HTTP/1"
+	ProxyProtocolHTTP2   ProxyProtocol = "HTTP/2"
+	ProxyProtocolGRPC    ProxyProtocol = "gRPC"
+	ProxyProtocolOpaque  ProxyProtocol = "opaque"
+	ProxyProtocolTLS     ProxyProtocol = "TLS"
+)
+
+// ServerSpec selects a port on a set of pods.
+type ServerSpec struct {
+	// PodSelector selects the pods exposing the port.
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+	// Port is the name or the number of the pods port.
+	Port intstr.IntOrString `json:"port"`
+	// ProxyProtocol configures protocol discovery for inbound connections.
+	// +optional
+	ProxyProtocol ProxyProtocol `json:"proxyProtocol,omitempty"`
+}
+
+// Server is the Schema for the linkerd servers API.
+// +kubebuilder:object:root=true
+type Server struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ServerSpec `json:"spec,omitempty"`
+}
+
+// ServerList contains a list of Server.
+// +kubebuilder:object:root=true
+type ServerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Server `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Server{}, &ServerList{})
+}
@@ -0,0 +1,76 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AppState is the state of a PerconaXtraDBCluster.
+type AppState string
+
+// AppStateReady is the state of a ready cluster.
+const AppStateReady AppState = "ready"
+
+// PerconaXtraDBClusterSpec defines the desired state of PerconaXtraDBCluster.
+type PerconaXtraDBClusterSpec struct {
+	// SecretsName is the name of the secret holding the system users passwords.
+	// +optional
+	SecretsName string `json:"secretsName,omitempty"`
+	// SSLSecretName is the name of the secret holding the external TLS certificates.
+	// +optional
+	SSLSecretName string `json:"sslSecretName,omitempty"`
+}
+
+// PerconaXtraDBClusterStatus defines the observed state of PerconaXtraDBCluster.
+type PerconaXtraDBClusterStatus struct {
+	// Status is the state of the cluster.
+	// +optional
+	Status AppState `json:"state,omitempty"`
+	// Host is the endpoint clients should connect to.
+	// +optional
+	Host string `json:"host,omitempty"`
+	// Messages lists the errors reported by the cluster.
+	// +optional
+	Messages []string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PerconaXtraDBCluster is the Schema for the perconaxtradbclusters API.
+type PerconaXtraDBCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PerconaXtraDBClusterSpec `json:"spec,omitempty"`
+	// +optional
+	Status PerconaXtraDBClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PerconaXtraDBClusterList contains a list of PerconaXtraDBCluster.
+type PerconaXtraDBClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PerconaXtraDBCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PerconaXtraDBCluster{}, &PerconaXtraDBClusterList{})
+}
@@ -0,0 +1,276 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schemarunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/temporal/persistence"
+	"github.com/gocql/gocql"
+	"go.temporal.io/server/common/config"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/metrics"
+	commongocql "go.temporal.io/server/common/persistence/nosql/nosqlplugin/cassandra/gocql"
+	"go.temporal.io/server/common/resolver"
+	"go.temporal.io/server/tools/common/schema"
+)
+
+// The statements below mirror the ones used by temporal-cassandra-tool.
+const (
+	cassandraSystemKeyspace         = "system"
+	cassandraDefaultPort            = 9042
+	cassandraReplicationFactor      = 1
+	cassandraSchemaAgreementTimeout = 30 * time.Second
+
+	readSchemaVersionCQL        = `SELECT curr_version from schema_version where keyspace_name=?`
+	writeSchemaVersionCQL       = `INSERT into schema_version(keyspace_name, creation_time, curr_version, min_compatible_version) VALUES (?,?,?,?)`
+	writeSchemaUpdateHistoryCQL = `INSERT into schema_update_history(year, month, update_time, old_version, new_version, manifest_md5, description) VALUES(?,?,?,?,?,?,?)`
+
+	createSchemaVersionTableCQL = `CREATE TABLE IF NOT EXISTS schema_version(keyspace_name text PRIMARY KEY, ` +
+		`creation_time timestamp, ` +
+		`curr_version text, ` +
+		`min_compatible_version text);`
+
+	createSchemaUpdateHistoryTableCQL = `CREATE TABLE IF NOT EXISTS schema_update_history(` +
+		`year int, ` +
+		`month int, ` +
+		`update_time timestamp, ` +
+		`description text, ` +
+		`manifest_md5 text, ` +
+		`new_version text, ` +
+		`old_version text, ` +
+		`PRIMARY KEY ((year, month), update_time));`
+
+	createKeyspaceCQL = `CREATE KEYSPACE IF NOT EXISTS %v ` +
+		`WITH replication = { 'class' : 'SimpleStrategy', 'replication_factor' : %v};`
+
+	createKeyspaceNetworkTopologyCQL = `CREATE KEYSPACE IF NOT EXISTS %v ` +
+		`WITH replication = { 'class' : 'NetworkTopologyStrategy', '%v' : %v};`
+)
+
+var _ schema.DB = (*cqlDB)(nil)
+
+// cqlDB implements the schema tool database interface on top of a cassandra session.
+type cqlDB struct {
+	keyspace string
+	session  commongocql.Session
+}
+
+func newCQLDB(cfg *config.Cassandra, logger log.Logger) (*cqlDB, error) {
+	session, err := commongocql.NewSession(
+		func() (*gocql.ClusterConfig, error) {
+			return commongocql.NewCassandraCluster(*cfg, resolver.NewNoopResolver())
+		},
+		logger,
+		metrics.NoopMetricsHandler,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't connect to cassandra: %w", err)
+	}
+
+	return &cqlDB{
+		keyspace: cfg.Keyspace,
+		session:  session,
+	}, nil
+}
+
+func (db *cqlDB) Exec(stmt string, args ...any) error {
+	err := db.session.Query(stmt, args...).Exec()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cassandraSchemaAgreementTimeout)
+	defer cancel()
+
+	return db.session.AwaitSchemaAgreement(ctx)
+}
+
+func (db *cqlDB) DropAllTables() error {
+	return errors.New("dropping tables is not supported")
+}
+
+func (db *cqlDB) CreateSchemaVersionTables() error {
+	err := db.Exec(createSchemaVersionTableCQL)
+	if err != nil {
+		return err
+	}
+	return db.Exec(createSchemaUpdateHistoryTableCQL)
+}
+
+func (db *cqlDB) ReadSchemaVersion() (string, error) {
+	iter := db.session.Query(readSchemaVersionCQL, db.keyspace).Iter()
+
+	var version string
+	found := iter.Scan(&version)
+	err := iter.Close()
+	if err == nil && !found {
+		err = fmt.Errorf("no schema version found for keyspace %q", db.keyspace)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to get current schema version from cassandra: %w", err)
+	}
+
+	return version, nil
+}
+
+func (db *cqlDB) UpdateSchemaVersion(newVersion string, minCompatibleVersion string) error {
+	return db.session.Query(writeSchemaVersionCQL, db.keyspace, time.Now().UTC(), newVersion, minCompatibleVersion).Exec()
+}
+
+func (db *cqlDB) WriteSchemaUpdateLog(oldVersion string, newVersion string, manifestMD5 string, desc string) error {
+	now := time.Now().UTC()
+	return db.session.Query(writeSchemaUpdateHistoryCQL, now.Year(), int(now.Month()), now, oldVersion, newVersion, manifestMD5, desc).Exec()
+}
+
+func (db *cqlDB) Close() {
+	db.session.Close()
+}
+
+func (db *cqlDB) Type() string {
+	return "cassandra"
+}
+
+// cassandraTool runs schema operations on cassandra datastores.
+type cassandraTool struct {
+	spec      *v1beta1.DatastoreSpec
+	schemaDir string
+	logger    log.Logger
+}
+
+func (t *cassandraTool) config() *config.Cassandra {
+	cfg := persistence.NewCassandraConfigFromDatastoreSpec(t.spec)
+	cfg.Password = password(t.spec)
+	return cfg
+}
+
+func (t *cassandraTool) Create(_ context.Context, _ *v1beta1.SchemaOperationStatus) error {
+	// The keyspace can't be used before being created, connect to the system keyspace instead.
+	cfg := t.config()
+	cfg.Keyspace = cassandraSystemKeyspace
+
+	db, err := newCQLDB(cfg, t.logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	stmt := fmt.Sprintf(createKeyspaceCQL, t.spec.Cassandra.Keyspace, cassandraReplicationFactor)
+	if t.spec.Cassandra.Datacenter != "" {
+		stmt = fmt.Sprintf(createKeyspaceNetworkTopologyCQL, t.spec.Cassandra.Keyspace, t.spec.Cassandra.Datacenter, cassandraReplicationFactor)
+	}
+
+	err = db.Exec(stmt)
+	if err != nil {
+		return fmt.Errorf("can't create keyspace: %w", err)
+	}
+	return nil
+}
+
+func (t *cassandraTool) Setup(_ context.Context, result *v1beta1.SchemaOperationStatus) error {
+	db, err := newCQLDB(t.config(), t.logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return setupSchema(db, t.logger, result)
+}
+
+func (t *cassandraTool) Update(_ context.Context, result *v1beta1.SchemaOperationStatus) error {
+	db, err := newCQLDB(t.config(), t.logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return updateSchema(db, t.schemaDir, t.logger, result)
+}
+
+func (t *cassandraTool) Plan(_ context.Context, result *v1beta1.SchemaOperationStatus) error {
+	db, err := newCQLDB(t.config(), t.logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return planSchema(db, t.schemaDir, t.logger, result)
+}
+
+func (t *cassandraTool) Check(ctx context.Context, result *v1beta1.SchemaOperationStatus) error {
+	port := t.spec.Cassandra.Port
+	if port == 0 {
+		port = cassandraDefaultPort
+	}
+
+	tlsEnabled := t.spec.TLS != nil && t.spec.TLS.Enabled
+	err := checkNetwork(ctx, t.spec, hostPorts(t.spec.Cassandra.Hosts, port), tlsEnabled, nil, result)
+	if err != nil {
+		return err
+	}
+
+	var db *cqlDB
+	err = runCheck(result, authenticationCheck, func() (string, error) {
+		cfg := t.config()
+		d, err := newCQLDB(cfg, t.logger)
+		if err != nil && !t.spec.SkipCreate {
+			// The keyspace may not be created yet, connect to the system keyspace as the create operation does.
+			cfg.Keyspace = cassandraSystemKeyspace
+			d, err = newCQLDB(cfg, t.logger)
+			if err == nil {
+				defer d.Close()
+				return fmt.Sprintf("authenticated as %s, keyspace %s will be created", t.spec.Cassandra.User, t.spec.Cassandra.Keyspace), nil
+			}
+		}
+		if err != nil {
+			return "", fmt.Errorf("can't connect to keyspace %s as %s: %w", t.spec.Cassandra.Keyspace, t.spec.Cassandra.User, err)
+		}
+
+		db = d
+		return fmt.Sprintf("authenticated as %s on keyspace %s", t.spec.Cassandra.User, t.spec.Cassandra.Keyspace), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if db == nil {
+		// Privileges on the keyspace can't be checked until it's created.
+		return runCheck(result, privilegesCheck, func() (string, error) {
+			return fmt.Sprintf("keyspace %s doesn't exist yet, privileges are checked by the schema operations", t.spec.Cassandra.Keyspace), nil
+		})
+	}
+	defer db.Close()
+
+	return runCheck(result, privilegesCheck, func() (string, error) {
+		err := db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id int PRIMARY KEY)", preflightTable))
+		if err != nil {
+			return "", fmt.Errorf("can't create table in keyspace %s: %w", t.spec.Cassandra.Keyspace, err)
+		}
+
+		err = db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", preflightTable))
+		if err != nil {
+			return "", fmt.Errorf("can't drop table in keyspace %s: %w", t.spec.Cassandra.Keyspace, err)
+		}
+
+		return "user can create and drop tables", nil
+	})
+}
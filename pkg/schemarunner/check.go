@@ -0,0 +1,225 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schemarunner
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/datastoretls"
+)
+
+// Names of the preflight checks, in the order they run.
+const (
+	dnsCheck            = "dns"
+	tcpCheck            = "tcp"
+	tlsCheck            = "tls"
+	authenticationCheck = "authentication"
+	privilegesCheck     = "privileges"
+)
+
+const (
+	// checkTimeout is the timeout of network checks.
+	checkTimeout = 5 * time.Second
+	// preflightTable is the table created and dropped to check the datastore privileges.
+	preflightTable = "temporal_operator_preflight"
+)
+
+// startTLSFunc negotiates TLS on a plain connection, for protocols which
+// don't start with the TLS handshake.
+type startTLSFunc func(conn net.Conn) error
+
+// runCheck runs the provided check and records its outcome in the result.
+// The returned error is prefixed by the check name.
+func runCheck(result *v1beta1.SchemaOperationStatus, name string, check func() (string, error)) error {
+	message, err := check()
+
+	outcome := v1beta1.PreflightCheck{
+		Name:    name,
+		Passed:  err == nil,
+		Message: message,
+	}
+	if err != nil {
+		outcome.Message = err.Error()
+	}
+	result.Checks = append(result.Checks, outcome)
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// checkNetwork checks the provided addresses resolve, accept TCP connections and,
+// when TLS is enabled, complete a TLS handshake using the datastore TLS files.
+func checkNetwork(ctx context.Context, spec *v1beta1.DatastoreSpec, addresses []string, tlsEnabled bool, startTLS startTLSFunc, result *v1beta1.SchemaOperationStatus) error {
+	err := runCheck(result, dnsCheck, func() (string, error) {
+		for _, address := range addresses {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return "", fmt.Errorf("invalid address %s: %w", address, err)
+			}
+
+			if net.ParseIP(host) != nil {
+				continue
+			}
+
+			lookupCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+			_, err = net.DefaultResolver.LookupHost(lookupCtx, host)
+			cancel()
+			if err != nil {
+				return "", fmt.Errorf("can't resolve %s: %w", host, err)
+			}
+		}
+		return fmt.Sprintf("resolved %s", strings.Join(addresses, ", ")), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = runCheck(result, tcpCheck, func() (string, error) {
+		for _, address := range addresses {
+			conn, err := dial(ctx, address)
+			if err != nil {
+				return "", err
+			}
+			conn.Close()
+		}
+		return fmt.Sprintf("connected to %s", strings.Join(addresses, ", ")), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return runCheck(result, tlsCheck, func() (string, error) {
+		if !tlsEnabled {
+			return "TLS is disabled", nil
+		}
+
+		for _, address := range addresses {
+			err := handshake(ctx, spec, address, startTLS)
+			if err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("handshake completed with %s", strings.Join(addresses, ", ")), nil
+	})
+}
+
+func dial(ctx context.Context, address string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: checkTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("can't connect to %s: %w", address, err)
+	}
+	return conn, nil
+}
+
+// handshake completes a TLS handshake with the provided address.
+func handshake(ctx context.Context, spec *v1beta1.DatastoreSpec, address string, startTLS startTLSFunc) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %w", address, err)
+	}
+
+	cfg, err := datastoretls.ClientConfig(spec, host)
+	if err != nil {
+		return err
+	}
+
+	conn, err := dial(ctx, address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	err = conn.SetDeadline(time.Now().Add(checkTimeout))
+	if err != nil {
+		return fmt.Errorf("can't set deadline: %w", err)
+	}
+
+	if startTLS != nil {
+		err = startTLS(conn)
+		if err != nil {
+			return fmt.Errorf("can't negotiate TLS with %s: %w", address, err)
+		}
+	}
+
+	err = tls.Client(conn, cfg).HandshakeContext(ctx)
+	if err != nil {
+		return fmt.Errorf("handshake with %s failed: %w", address, err)
+	}
+	return nil
+}
+
+// mysqlStartTLS reads the mysql server handshake and answers with an SSL request packet.
+func mysqlStartTLS(conn net.Conn) error {
+	const (
+		clientLongPassword     = 0x00000001
+		clientProtocol41       = 0x00000200
+		clientSSL              = 0x00000800
+		clientSecureConnection = 0x00008000
+		utf8mb4GeneralCI       = 45
+		maxPacketSize          = 1 << 24
+	)
+
+	header := make([]byte, 4)
+	_, err := io.ReadFull(conn, header)
+	if err != nil {
+		return err
+	}
+
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	handshake := make([]byte, length)
+	_, err = io.ReadFull(conn, handshake)
+	if err != nil {
+		return err
+	}
+
+	if length > 0 && handshake[0] == 0xff {
+		return fmt.Errorf("server returned an error: %s", handshake[min(length, 3):])
+	}
+
+	// The payload is made of the capability flags, the max packet size,
+	// the character set and 23 bytes of filler.
+	packet := make([]byte, 4+32)
+	packet[0] = 32
+	packet[3] = header[3] + 1
+	binary.LittleEndian.PutUint32(packet[4:8], clientLongPassword|clientProtocol41|clientSSL|clientSecureConnection)
+	binary.LittleEndian.PutUint32(packet[8:12], maxPacketSize)
+	packet[12] = utf8mb4GeneralCI
+
+	_, err = conn.Write(packet)
+	return err
+}
+
+// hostPorts returns the addresses made of the provided hosts and port.
+func hostPorts(hosts []string, port int) []string {
+	addresses := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		addresses = append(addresses, net.JoinHostPort(host, fmt.Sprint(port)))
+	}
+	return addresses
+}
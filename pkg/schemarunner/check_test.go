@@ -0,0 +1,292 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schemarunner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/datastoretls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serverTLSConfig returns a TLS configuration using a self-signed certificate for localhost.
+func serverTLSConfig(t *testing.T) *tls.Config {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+// listen serves each connection accepted on a local address with the provided handler.
+// It returns the listener address.
+func listen(t *testing.T, serve func(conn net.Conn)) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				serve(conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// closedAddress returns a local address nothing listens on.
+func closedAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	return address
+}
+
+func TestCheckNetwork(t *testing.T) {
+	serverTLS := serverTLSConfig(t)
+
+	serveTLS := func(conn net.Conn) {
+		_ = tls.Server(conn, serverTLS).Handshake()
+	}
+
+	// servePostgresStartTLS answers the postgres SSLRequest message.
+	servePostgresStartTLS := func(accept bool) func(conn net.Conn) {
+		return func(conn net.Conn) {
+			request := make([]byte, 8)
+			_, err := io.ReadFull(conn, request)
+			if err != nil {
+				return
+			}
+			if !accept {
+				_, _ = conn.Write([]byte{'N'})
+				return
+			}
+			_, _ = conn.Write([]byte{'S'})
+			serveTLS(conn)
+		}
+	}
+
+	// serveMySQLStartTLS sends the provided mysql handshake payload and waits for the client SSL request.
+	serveMySQLStartTLS := func(payload []byte) func(conn net.Conn) {
+		return func(conn net.Conn) {
+			packet := append([]byte{byte(len(payload)), 0, 0, 0}, payload...)
+			_, err := conn.Write(packet)
+			if err != nil || payload[0] == 0xff {
+				return
+			}
+			request := make([]byte, 36)
+			_, err = io.ReadFull(conn, request)
+			if err != nil {
+				return
+			}
+			serveTLS(conn)
+		}
+	}
+
+	tlsWithoutHostVerification := &v1beta1.DatastoreTLSSpec{Enabled: true}
+
+	tests := map[string]struct {
+		address        func(t *testing.T) string
+		tls            *v1beta1.DatastoreTLSSpec
+		startTLS       startTLSFunc
+		expectedPassed []string
+		expectedFailed string
+		expectedErr    string
+	}{
+		"tls disabled": {
+			address: func(t *testing.T) string {
+				return listen(t, func(net.Conn) {})
+			},
+			expectedPassed: []string{dnsCheck, tcpCheck, tlsCheck},
+		},
+		"hostname is resolved": {
+			address: func(t *testing.T) string {
+				_, port, _ := net.SplitHostPort(listen(t, func(net.Conn) {}))
+				return net.JoinHostPort("localhost", port)
+			},
+			expectedPassed: []string{dnsCheck, tcpCheck, tlsCheck},
+		},
+		"unresolvable hostname": {
+			address: func(*testing.T) string {
+				return "temporal-operator.invalid:5432"
+			},
+			expectedFailed: dnsCheck,
+			expectedErr:    "dns: can't resolve temporal-operator.invalid",
+		},
+		"invalid address": {
+			address: func(*testing.T) string {
+				return "postgres"
+			},
+			expectedFailed: dnsCheck,
+			expectedErr:    "dns: invalid address postgres",
+		},
+		"connection refused": {
+			address:        closedAddress,
+			expectedPassed: []string{dnsCheck},
+			expectedFailed: tcpCheck,
+			expectedErr:    "tcp: can't connect to",
+		},
+		"tls handshake": {
+			address: func(t *testing.T) string {
+				return listen(t, serveTLS)
+			},
+			tls:            tlsWithoutHostVerification,
+			expectedPassed: []string{dnsCheck, tcpCheck, tlsCheck},
+		},
+		"untrusted server certificate": {
+			address: func(t *testing.T) string {
+				return listen(t, serveTLS)
+			},
+			tls:            &v1beta1.DatastoreTLSSpec{Enabled: true, EnableHostVerification: true},
+			expectedPassed: []string{dnsCheck, tcpCheck},
+			expectedFailed: tlsCheck,
+			expectedErr:    "certificate signed by unknown authority",
+		},
+		"server without tls": {
+			address: func(t *testing.T) string {
+				return listen(t, func(net.Conn) {})
+			},
+			tls:            tlsWithoutHostVerification,
+			expectedPassed: []string{dnsCheck, tcpCheck},
+			expectedFailed: tlsCheck,
+			expectedErr:    "tls: handshake with",
+		},
+		"postgres starttls": {
+			address: func(t *testing.T) string {
+				return listen(t, servePostgresStartTLS(true))
+			},
+			tls:            tlsWithoutHostVerification,
+			startTLS:       datastoretls.PostgresStartTLS,
+			expectedPassed: []string{dnsCheck, tcpCheck, tlsCheck},
+		},
+		"postgres starttls refused": {
+			address: func(t *testing.T) string {
+				return listen(t, servePostgresStartTLS(false))
+			},
+			tls:            tlsWithoutHostVerification,
+			startTLS:       datastoretls.PostgresStartTLS,
+			expectedPassed: []string{dnsCheck, tcpCheck},
+			expectedFailed: tlsCheck,
+			expectedErr:    "server doesn't support TLS",
+		},
+		"mysql starttls": {
+			address: func(t *testing.T) string {
+				return listen(t, serveMySQLStartTLS(append([]byte{0x0a}, "8.4.0\x00"...)))
+			},
+			tls:            tlsWithoutHostVerification,
+			startTLS:       mysqlStartTLS,
+			expectedPassed: []string{dnsCheck, tcpCheck, tlsCheck},
+		},
+		"mysql starttls error": {
+			address: func(t *testing.T) string {
+				return listen(t, serveMySQLStartTLS(append([]byte{0xff, 0x69, 0x04}, "Host is blocked"...)))
+			},
+			tls:            tlsWithoutHostVerification,
+			startTLS:       mysqlStartTLS,
+			expectedPassed: []string{dnsCheck, tcpCheck},
+			expectedFailed: tlsCheck,
+			expectedErr:    "server returned an error: Host is blocked",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			spec := &v1beta1.DatastoreSpec{
+				Name: "default",
+				TLS:  test.tls,
+			}
+			result := &v1beta1.SchemaOperationStatus{}
+
+			err := checkNetwork(context.Background(), spec, []string{test.address(tt)}, test.tls != nil, test.startTLS, result)
+			if test.expectedErr != "" {
+				require.Error(tt, err)
+				assert.Contains(tt, err.Error(), test.expectedErr)
+			} else {
+				require.NoError(tt, err)
+			}
+
+			assertChecks(tt, result, test.expectedPassed, test.expectedFailed)
+		})
+	}
+}
+
+// assertChecks asserts the provided checks passed, in order, followed by the failed check if any.
+func assertChecks(t *testing.T, result *v1beta1.SchemaOperationStatus, passed []string, failed string) {
+	t.Helper()
+
+	expected := len(passed)
+	if failed != "" {
+		expected++
+	}
+	require.Len(t, result.Checks, expected)
+
+	for i, name := range passed {
+		assert.Equal(t, name, result.Checks[i].Name)
+		assert.True(t, result.Checks[i].Passed, result.Checks[i].Message)
+	}
+
+	if failed != "" {
+		check := result.Checks[len(passed)]
+		assert.Equal(t, failed, check.Name)
+		assert.False(t, check.Passed)
+	}
+}
@@ -0,0 +1,78 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schemarunner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+)
+
+// Store is the name of a store the runner can operate on.
+type Store string
+
+const (
+	DefaultStore             Store = "default"
+	VisibilityStore          Store = "visibility"
+	SecondaryVisibilityStore Store = "secondaryVisibility"
+	AdvancedVisibilityStore  Store = "advancedVisibility"
+)
+
+// Config is the schema runner configuration, rendered by the operator.
+type Config struct {
+	// MTLSProvider is the mTLS provider of the cluster. When set to linkerd or istio,
+	// the runner asks the sidecar proxy to shut down once it's done so the job can complete.
+	MTLSProvider v1beta1.MTLSProvider `json:"mtlsProvider,omitempty"`
+	// Stores holds the configuration of each store of the cluster.
+	Stores map[Store]*StoreConfig `json:"stores"`
+}
+
+// StoreConfig is the configuration of a single store.
+type StoreConfig struct {
+	// Datastore is the store specification.
+	Datastore *v1beta1.DatastoreSpec `json:"datastore"`
+	// SchemaDir is the directory holding the versioned schemas used to update the store.
+	SchemaDir string `json:"schemaDir,omitempty"`
+}
+
+// LoadConfig reads the runner configuration from the provided path.
+func LoadConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read config file: %w", err)
+	}
+
+	cfg := &Config{}
+	err = json.Unmarshal(content, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse config file: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Store returns the configuration of the provided store.
+func (c *Config) Store(store Store) (*StoreConfig, error) {
+	storeConfig, ok := c.Stores[store]
+	if !ok || storeConfig.Datastore == nil {
+		return nil, fmt.Errorf("store %q is not configured", store)
+	}
+	return storeConfig, nil
+}
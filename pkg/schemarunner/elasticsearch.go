@@ -0,0 +1,461 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schemarunner
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/esauth"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/log/tag"
+)
+
+// mappings holds the visibility index mapping introduced by each schema version,
+// extracted from https://github.com/temporalio/temporal/tree/main/schema/elasticsearch/visibility/versioned.
+//
+//go:embed mappings/*.json
+var mappings embed.FS
+
+const (
+	// baseSchemaMarker is only present in mappings from schema v1 onward.
+	baseSchemaMarker = "ExecutionDuration"
+	// healthCheckInterval is the interval between two index health checks.
+	healthCheckInterval = 1 * time.Second
+)
+
+var (
+	// templatePatternRegexp matches the index pattern used by upstream index templates.
+	templatePatternRegexp   = regexp.MustCompile(`temporal_visibility_v1.`)
+	versionedTemplateRegexp = regexp.MustCompile(`versioned/v(\d+)/`)
+)
+
+// elasticsearchError is returned when elasticsearch answers with an unexpected status code.
+type elasticsearchError struct {
+	statusCode int
+	body       string
+}
+
+func (e *elasticsearchError) Error() string {
+	return fmt.Sprintf("elasticsearch returned status %d: %s", e.statusCode, e.body)
+}
+
+// elasticsearchTool runs schema operations on elasticsearch datastores.
+type elasticsearchTool struct {
+	spec *v1beta1.DatastoreSpec
+	// schemaDir is the directory holding versioned elasticsearch schemas,
+	// its parent holds the index templates and cluster settings of the current version.
+	schemaDir string
+	// client authenticates requests using the authenticator.
+	client        *http.Client
+	authenticator *esauth.Authenticator
+	logger        log.Logger
+}
+
+// version returns the version used in schema file names.
+func (t *elasticsearchTool) version() string {
+	version := t.spec.Elasticsearch.Version
+	if version == "v8" {
+		// For now, when elasticsearch version 8 is specified, it uses v7 schema.
+		// See: https://github.com/temporalio/temporal/tree/v1.20.3/schema/elasticsearch/visibility
+		version = "v7"
+	}
+	return version
+}
+
+func (t *elasticsearchTool) schemaFile(name string) string {
+	return filepath.Join(filepath.Dir(t.schemaDir), name)
+}
+
+func (t *elasticsearchTool) do(ctx context.Context, method, path string, body []byte, out any) error {
+	url := strings.TrimSuffix(t.spec.Elasticsearch.URL, "/") + path
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("can't create request: %w", err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("can't reach elasticsearch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("can't read elasticsearch response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return &elasticsearchError{statusCode: resp.StatusCode, body: string(content)}
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	err = json.Unmarshal(content, out)
+	if err != nil {
+		return fmt.Errorf("can't parse elasticsearch response: %w", err)
+	}
+	return nil
+}
+
+func (t *elasticsearchTool) Create(_ context.Context, _ *v1beta1.SchemaOperationStatus) error {
+	// Indices are created by the setup operation.
+	return nil
+}
+
+func (t *elasticsearchTool) Setup(ctx context.Context, result *v1beta1.SchemaOperationStatus) error {
+	version := t.version()
+	indices := t.spec.Elasticsearch.Indices
+
+	settings, err := os.ReadFile(t.schemaFile(fmt.Sprintf("cluster_settings_%s.json", version)))
+	if err != nil {
+		return fmt.Errorf("can't read cluster settings: %w", err)
+	}
+
+	err = t.do(ctx, http.MethodPut, "/_cluster/settings", settings, nil)
+	if err != nil {
+		return fmt.Errorf("can't put cluster settings: %w", err)
+	}
+
+	template, err := os.ReadFile(t.schemaFile(fmt.Sprintf("index_template_%s.json", version)))
+	if err != nil {
+		return fmt.Errorf("can't read index template: %w", err)
+	}
+
+	// Change index_patterns from temporal_visibility_v1* to the configured visibility index.
+	template = templatePatternRegexp.ReplaceAll(template, []byte(indices.Visibility+"*"))
+
+	err = t.do(ctx, http.MethodPut, fmt.Sprintf("/_template/%s_template", indices.Visibility), template, nil)
+	if err != nil {
+		return fmt.Errorf("can't put index template: %w", err)
+	}
+
+	for _, index := range []string{indices.Visibility, indices.SecondaryVisibility} {
+		if index == "" {
+			continue
+		}
+
+		err = t.createIndex(ctx, index)
+		if err != nil {
+			return err
+		}
+	}
+
+	expected, err := t.expectedVersion()
+	if err != nil {
+		return err
+	}
+	result.SchemaVersion = schemaVersionName(expected)
+
+	return nil
+}
+
+// createIndex creates the provided index, if it doesn't already exist.
+func (t *elasticsearchTool) createIndex(ctx context.Context, index string) error {
+	err := t.do(ctx, http.MethodPut, "/"+index, nil, nil)
+
+	esErr := &elasticsearchError{}
+	if errors.As(err, &esErr) && strings.Contains(esErr.body, "resource_already_exists_exception") {
+		t.logger.Info("Index already exists", tag.NewStringTag("index", index))
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("can't create index %s: %w", index, err)
+	}
+	return nil
+}
+
+func (t *elasticsearchTool) Update(ctx context.Context, result *v1beta1.SchemaOperationStatus) error {
+	index := t.spec.Elasticsearch.Indices.Visibility
+
+	expected, err := t.expectedVersion()
+	if err != nil {
+		return err
+	}
+
+	current, err := t.currentVersion(ctx, index)
+	if err != nil {
+		return err
+	}
+
+	t.logger.Info("Checking elasticsearch schema version",
+		tag.NewStringTag("current", schemaVersionName(current)),
+		tag.NewStringTag("expected", schemaVersionName(expected)),
+	)
+
+	result.SchemaVersion = schemaVersionName(current)
+	if current >= expected {
+		return nil
+	}
+
+	for version := current + 1; version <= expected; version++ {
+		err := t.upgrade(ctx, index, version)
+		if err != nil {
+			return err
+		}
+
+		result.AppliedVersions = append(result.AppliedVersions, schemaVersionName(version))
+		result.SchemaVersion = schemaVersionName(version)
+	}
+
+	return t.waitForGreen(ctx, index)
+}
+
+func (t *elasticsearchTool) Plan(ctx context.Context, result *v1beta1.SchemaOperationStatus) error {
+	index := t.spec.Elasticsearch.Indices.Visibility
+
+	expected, err := t.expectedVersion()
+	if err != nil {
+		return err
+	}
+
+	current, err := t.currentVersion(ctx, index)
+	if err != nil {
+		return err
+	}
+
+	result.SchemaVersion = schemaVersionName(current)
+	result.PlannedMigrations = []v1beta1.SchemaMigration{}
+
+	for version := current + 1; version <= expected; version++ {
+		file := fmt.Sprintf("mappings/v%d.json", version)
+		mapping, err := mappings.ReadFile(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("elasticsearch schema %s is not supported", schemaVersionName(version))
+		}
+		if err != nil {
+			return fmt.Errorf("can't read mapping: %w", err)
+		}
+
+		t.logger.Info("Planned schema migration",
+			tag.NewStringTag("version", schemaVersionName(version)),
+			tag.NewStringTag("index", index),
+			tag.NewStringTag("mapping", string(mapping)),
+		)
+
+		result.PlannedMigrations = append(result.PlannedMigrations, v1beta1.SchemaMigration{
+			Version:   schemaVersionName(version),
+			Directory: fmt.Sprintf("versioned/%s", schemaVersionName(version)),
+			Files:     []string{file},
+		})
+	}
+
+	return nil
+}
+
+func (t *elasticsearchTool) Check(ctx context.Context, result *v1beta1.SchemaOperationStatus) error {
+	parsedURL, err := url.Parse(t.spec.Elasticsearch.URL)
+	if err != nil {
+		return fmt.Errorf("can't parse elasticsearch url: %w", err)
+	}
+
+	port := parsedURL.Port()
+	if port == "" {
+		port = "80"
+		if parsedURL.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	address := net.JoinHostPort(parsedURL.Hostname(), port)
+	err = checkNetwork(ctx, t.spec, []string{address}, parsedURL.Scheme == "https", nil, result)
+	if err != nil {
+		return err
+	}
+
+	err = runCheck(result, authenticationCheck, func() (string, error) {
+		err := t.do(ctx, http.MethodGet, "/", nil, nil)
+		if err != nil {
+			return "", err
+		}
+		return t.authenticator.Identity(), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return runCheck(result, privilegesCheck, func() (string, error) {
+		err := t.do(ctx, http.MethodGet, "/_cluster/health", nil, nil)
+		if err != nil {
+			return "", fmt.Errorf("can't read cluster health: %w", err)
+		}
+		return "user can monitor the cluster", nil
+	})
+}
+
+// upgrade applies the mapping introduced by the provided schema version.
+func (t *elasticsearchTool) upgrade(ctx context.Context, index string, version int) error {
+	mapping, err := mappings.ReadFile(fmt.Sprintf("mappings/v%d.json", version))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("elasticsearch schema %s is not supported", schemaVersionName(version))
+	}
+	if err != nil {
+		return fmt.Errorf("can't read mapping: %w", err)
+	}
+
+	path := fmt.Sprintf("/%s/_mapping", index)
+	if t.spec.Elasticsearch.Version == "v6" {
+		// Elasticsearch v6 doesn't support date_nanos and still requires the mapping type.
+		mapping = bytes.ReplaceAll(mapping, []byte(`"date_nanos"`), []byte(`"date"`))
+		path = fmt.Sprintf("/%s/_doc/_mapping", index)
+	}
+
+	t.logger.Info("Upgrading elasticsearch schema", tag.NewStringTag("version", schemaVersionName(version)))
+
+	err = t.do(ctx, http.MethodPut, path, mapping, nil)
+	if err != nil {
+		return fmt.Errorf("can't upgrade to schema %s: %w", schemaVersionName(version), err)
+	}
+	return nil
+}
+
+// expectedVersion returns the schema version shipped with the temporal version,
+// by resolving the link of the index template to the versioned schemas.
+func (t *elasticsearchTool) expectedVersion() (int, error) {
+	target, err := filepath.EvalSymlinks(t.schemaFile("index_template_v7.json"))
+	if err != nil {
+		return 0, fmt.Errorf("can't resolve index template: %w", err)
+	}
+
+	matches := versionedTemplateRegexp.FindStringSubmatch(target)
+	if matches == nil {
+		return 0, fmt.Errorf("can't find schema version from index template path %s", target)
+	}
+
+	return strconv.Atoi(matches[1])
+}
+
+// currentVersion guesses the schema version of the index from its mapping.
+// The version is the last one whose properties are all present in the mapping.
+func (t *elasticsearchTool) currentVersion(ctx context.Context, index string) (int, error) {
+	properties, err := t.properties(ctx, index)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, ok := properties[baseSchemaMarker]; !ok {
+		return 0, errors.New("can't do upgrade from v0 schema, version needing advanced visibility schema v1 are not supported by the operator")
+	}
+
+	version := 1
+	for {
+		content, err := mappings.ReadFile(fmt.Sprintf("mappings/v%d.json", version+1))
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("can't read mapping: %w", err)
+		}
+
+		mapping := struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		}{}
+		err = json.Unmarshal(content, &mapping)
+		if err != nil {
+			return 0, fmt.Errorf("can't parse mapping: %w", err)
+		}
+
+		for property := range mapping.Properties {
+			if _, ok := properties[property]; !ok {
+				return version, nil
+			}
+		}
+
+		version++
+	}
+}
+
+// properties returns the mapping properties of the provided index.
+func (t *elasticsearchTool) properties(ctx context.Context, index string) (map[string]json.RawMessage, error) {
+	type indexMapping struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		// Doc holds the properties of elasticsearch v6 typed mappings.
+		Doc *indexMapping `json:"_doc"`
+	}
+
+	response := map[string]struct {
+		Mappings indexMapping `json:"mappings"`
+	}{}
+	err := t.do(ctx, http.MethodGet, "/"+index, nil, &response)
+	if err != nil {
+		return nil, fmt.Errorf("can't get index %s: %w", index, err)
+	}
+
+	// The response is keyed by the concrete index name, which differs when index is an alias.
+	for _, mapping := range response {
+		if mapping.Mappings.Doc != nil {
+			return mapping.Mappings.Doc.Properties, nil
+		}
+		return mapping.Mappings.Properties, nil
+	}
+
+	return nil, fmt.Errorf("index %s not found", index)
+}
+
+// waitForGreen waits for the index health to become green.
+func (t *elasticsearchTool) waitForGreen(ctx context.Context, index string) error {
+	for {
+		health := struct {
+			Status string `json:"status"`
+		}{}
+		err := t.do(ctx, http.MethodGet, "/_cluster/health/"+index, nil, &health)
+		if err != nil {
+			return fmt.Errorf("can't get index health: %w", err)
+		}
+
+		if health.Status == "green" {
+			return nil
+		}
+
+		t.logger.Info("Waiting for elasticsearch index to become green", tag.NewStringTag("index", index))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(healthCheckInterval):
+		}
+	}
+}
+
+func schemaVersionName(version int) string {
+	return fmt.Sprintf("v%d", version)
+}
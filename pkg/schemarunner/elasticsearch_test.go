@@ -0,0 +1,295 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schemarunner_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/schemarunner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/server/common/log"
+)
+
+const testIndex = "temporal_visibility_v1_dev"
+
+// fakeElasticsearch is a minimal elasticsearch server holding a single index.
+type fakeElasticsearch struct {
+	mu         sync.Mutex
+	properties map[string]any
+	indices    map[string]bool
+	requests   map[string]string
+}
+
+func newFakeElasticsearch(properties ...string) *fakeElasticsearch {
+	es := &fakeElasticsearch{
+		properties: map[string]any{},
+		indices:    map[string]bool{},
+		requests:   map[string]string{},
+	}
+	for _, property := range properties {
+		es.properties[property] = map[string]string{"type": "keyword"}
+	}
+	if len(properties) > 0 {
+		es.indices[testIndex] = true
+	}
+	return es
+}
+
+func (es *fakeElasticsearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	es.requests[fmt.Sprintf("%s %s", r.Method, r.URL.Path)] = string(body)
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/"+testIndex:
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			testIndex: map[string]any{"mappings": map[string]any{"properties": es.properties}},
+		})
+	case r.Method == http.MethodPut && r.URL.Path == fmt.Sprintf("/%s/_mapping", testIndex):
+		mapping := struct {
+			Properties map[string]any `json:"properties"`
+		}{}
+		_ = json.Unmarshal(body, &mapping)
+		for property, value := range mapping.Properties {
+			es.properties[property] = value
+		}
+	case r.Method == http.MethodGet && r.URL.Path == "/":
+		_, _ = w.Write([]byte(`{"version":{"number":"7.17.0"}}`))
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/_cluster/health"):
+		_, _ = w.Write([]byte(`{"status":"green"}`))
+	case r.Method == http.MethodPut && (r.URL.Path == "/_cluster/settings" || strings.HasPrefix(r.URL.Path, "/_template/")):
+	case r.Method == http.MethodPut:
+		index := strings.TrimPrefix(r.URL.Path, "/")
+		if es.indices[index] {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"type":"resource_already_exists_exception"}}`))
+			return
+		}
+		es.indices[index] = true
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// schemaDir creates the elasticsearch schemas layout shipped in admin-tools, for the provided schema version.
+func schemaDir(t *testing.T, version string) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "elasticsearch", "visibility")
+	versioned := filepath.Join(dir, "versioned", version)
+	require.NoError(t, os.MkdirAll(versioned, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(versioned, "index_template_v7.json"), []byte(`{"index_patterns":["temporal_visibility_v1*"]}`), 0o600))
+	require.NoError(t, os.Symlink(filepath.Join("versioned", version, "index_template_v7.json"), filepath.Join(dir, "index_template_v7.json")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cluster_settings_v7.json"), []byte(`{"persistent":{}}`), 0o600))
+
+	return filepath.Join(dir, "versioned")
+}
+
+func elasticsearchRunner(url, schemaDir string) *schemarunner.Runner {
+	config := &schemarunner.Config{
+		Stores: map[schemarunner.Store]*schemarunner.StoreConfig{
+			schemarunner.VisibilityStore: {
+				Datastore: &v1beta1.DatastoreSpec{
+					Name: "visibility",
+					Elasticsearch: &v1beta1.ElasticsearchSpec{
+						Version: "v7",
+						URL:     url,
+						Indices: v1beta1.ElasticsearchIndices{
+							Visibility:          testIndex,
+							SecondaryVisibility: testIndex + "_secondary",
+						},
+					},
+				},
+				SchemaDir: schemaDir,
+			},
+		},
+	}
+
+	return schemarunner.New(config, log.NewNoopLogger())
+}
+
+func TestElasticsearchUpdate(t *testing.T) {
+	v1Properties := []string{"ExecutionDuration"}
+	v7Properties := slices.Concat(v1Properties, []string{
+		"TemporalScheduledStartTime", "TemporalScheduledById", "TemporalSchedulePaused",
+		"TemporalNamespaceDivision", "HistorySizeBytes", "BuildIds",
+		"ParentWorkflowId", "ParentRunId", "RootWorkflowId", "RootRunId",
+	})
+	v9Properties := slices.Concat(v7Properties, []string{
+		"TemporalPauseInfo",
+		"TemporalWorkerDeploymentVersion", "TemporalWorkflowVersioningBehavior", "TemporalWorkerDeployment",
+	})
+
+	tests := map[string]struct {
+		properties              []string
+		expectedSchemaVersion   string
+		expectedAppliedVersions []string
+		expectedError           string
+	}{
+		"applies missing versions": {
+			properties:              v7Properties,
+			expectedSchemaVersion:   "v9",
+			expectedAppliedVersions: []string{"v8", "v9"},
+		},
+		"applies all versions from v1": {
+			properties:              v1Properties,
+			expectedSchemaVersion:   "v9",
+			expectedAppliedVersions: []string{"v2", "v3", "v4", "v5", "v6", "v7", "v8", "v9"},
+		},
+		"already up to date": {
+			properties:            v9Properties,
+			expectedSchemaVersion: "v9",
+		},
+		"v0 is not supported": {
+			properties:    []string{"WorkflowId"},
+			expectedError: "can't do upgrade from v0 schema",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			es := newFakeElasticsearch(test.properties...)
+			server := httptest.NewServer(es)
+			defer server.Close()
+
+			result := elasticsearchRunner(server.URL, schemaDir(tt, "v9")).Run(context.Background(), v1beta1.UpdateSchemaOperation, schemarunner.VisibilityStore)
+
+			assert.Equal(tt, v1beta1.UpdateSchemaOperation, result.Operation)
+			assert.NotNil(tt, result.Duration)
+			if test.expectedError != "" {
+				assert.Contains(tt, result.Error, test.expectedError)
+				return
+			}
+
+			assert.Empty(tt, result.Error)
+			assert.Equal(tt, test.expectedSchemaVersion, result.SchemaVersion)
+			assert.Equal(tt, test.expectedAppliedVersions, result.AppliedVersions)
+			for _, property := range v9Properties {
+				assert.Contains(tt, es.properties, property)
+			}
+		})
+	}
+}
+
+func TestElasticsearchSetup(t *testing.T) {
+	// The visibility index already exists, the secondary one doesn't.
+	es := newFakeElasticsearch("ExecutionDuration")
+	server := httptest.NewServer(es)
+	defer server.Close()
+
+	result := elasticsearchRunner(server.URL, schemaDir(t, "v9")).Run(context.Background(), v1beta1.SetupSchemaOperation, schemarunner.VisibilityStore)
+
+	assert.Empty(t, result.Error)
+	assert.Equal(t, "v9", result.SchemaVersion)
+	assert.Equal(t, `{"persistent":{}}`, es.requests["PUT /_cluster/settings"])
+	assert.Equal(t, `{"index_patterns":["temporal_visibility_v1_dev*"]}`, es.requests[fmt.Sprintf("PUT /_template/%s_template", testIndex)])
+	assert.True(t, es.indices[testIndex+"_secondary"])
+}
+
+func TestElasticsearchPlan(t *testing.T) {
+	es := newFakeElasticsearch(
+		"ExecutionDuration", "TemporalScheduledStartTime", "TemporalScheduledById", "TemporalSchedulePaused",
+		"TemporalNamespaceDivision", "HistorySizeBytes", "BuildIds",
+		"ParentWorkflowId", "ParentRunId", "RootWorkflowId", "RootRunId",
+	)
+	server := httptest.NewServer(es)
+	defer server.Close()
+
+	result := elasticsearchRunner(server.URL, schemaDir(t, "v9")).Run(context.Background(), v1beta1.PlanSchemaOperation, schemarunner.VisibilityStore)
+
+	assert.Empty(t, result.Error)
+	assert.Equal(t, "v7", result.SchemaVersion)
+	assert.Empty(t, result.AppliedVersions)
+	assert.Equal(t, []v1beta1.SchemaMigration{
+		{Version: "v8", Directory: "versioned/v8", Files: []string{"mappings/v8.json"}},
+		{Version: "v9", Directory: "versioned/v9", Files: []string{"mappings/v9.json"}},
+	}, result.PlannedMigrations)
+	assert.NotContains(t, es.requests, fmt.Sprintf("PUT /%s/_mapping", testIndex))
+}
+
+func TestElasticsearchCheck(t *testing.T) {
+	tests := map[string]struct {
+		handler        http.Handler
+		closed         bool
+		expectedChecks []string
+		expectedError  string
+	}{
+		"all checks pass": {
+			handler:        newFakeElasticsearch(),
+			expectedChecks: []string{"dns", "tcp", "tls", "authentication", "privileges"},
+		},
+		"unreachable": {
+			handler:        newFakeElasticsearch(),
+			closed:         true,
+			expectedChecks: []string{"dns", "tcp"},
+			expectedError:  "tcp: can't connect to",
+		},
+		"unauthorized": {
+			handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			}),
+			expectedChecks: []string{"dns", "tcp", "tls", "authentication"},
+			expectedError:  "authentication: elasticsearch returned status 401",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			server := httptest.NewServer(test.handler)
+			if test.closed {
+				server.Close()
+			} else {
+				defer server.Close()
+			}
+
+			result := elasticsearchRunner(server.URL, schemaDir(tt, "v9")).Run(context.Background(), v1beta1.CheckSchemaOperation, schemarunner.VisibilityStore)
+
+			checks := []string{}
+			for _, check := range result.Checks {
+				checks = append(checks, check.Name)
+			}
+			assert.Equal(tt, test.expectedChecks, checks)
+
+			if test.expectedError != "" {
+				assert.Contains(tt, result.Error, test.expectedError)
+				assert.False(tt, result.Checks[len(result.Checks)-1].Passed)
+				return
+			}
+
+			assert.Empty(tt, result.Error)
+			for _, check := range result.Checks {
+				assert.True(tt, check.Passed, check.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schemarunner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// maxErrorLength bounds the error reported in the result, as kubernetes
+// truncates termination messages larger than 4096 bytes.
+const maxErrorLength = 2048
+
+// WriteResult writes the result as JSON to w and to the termination message file at path.
+// An empty path skips the termination message.
+func WriteResult(w io.Writer, path string, result *v1beta1.SchemaOperationStatus) error {
+	if len(result.Error) > maxErrorLength {
+		result.Error = result.Error[:maxErrorLength]
+	}
+
+	content, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("can't marshal result: %w", err)
+	}
+
+	_, err = fmt.Fprintln(w, string(content))
+	if err != nil {
+		return fmt.Errorf("can't write result: %w", err)
+	}
+
+	if path == "" {
+		return nil
+	}
+
+	err = os.WriteFile(path, content, 0o600)
+	if err != nil {
+		return fmt.Errorf("can't write termination message: %w", err)
+	}
+
+	return nil
+}
+
+// ParseResult parses a result written by WriteResult.
+func ParseResult(message string) (*v1beta1.SchemaOperationStatus, error) {
+	result := &v1beta1.SchemaOperationStatus{}
+	err := json.Unmarshal([]byte(message), result)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse schema runner result: %w", err)
+	}
+	return result, nil
+}
+
+// ResultFromPods returns the result reported by the provided container of the most recent pod.
+// It returns nil if the container didn't report any result yet.
+func ResultFromPods(pods []corev1.Pod, container string) (*v1beta1.SchemaOperationStatus, error) {
+	var latest *corev1.Pod
+	for i := range pods {
+		if latest == nil || latest.CreationTimestamp.Before(&pods[i].CreationTimestamp) {
+			latest = &pods[i]
+		}
+	}
+
+	if latest == nil {
+		return nil, nil
+	}
+
+	for _, status := range latest.Status.ContainerStatuses {
+		if status.Name != container {
+			continue
+		}
+
+		// When the container restarted after a failure, the result is held by the last termination state.
+		terminated := status.State.Terminated
+		if terminated == nil {
+			terminated = status.LastTerminationState.Terminated
+		}
+
+		if terminated == nil || terminated.Message == "" {
+			return nil, nil
+		}
+
+		return ParseResult(terminated.Message)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,133 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schemarunner_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/schemarunner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWriteResult(t *testing.T) {
+	result := &v1beta1.SchemaOperationStatus{
+		Operation:       v1beta1.UpdateSchemaOperation,
+		SchemaVersion:   "1.14",
+		AppliedVersions: []string{"1.13", "1.14"},
+		Duration:        &metav1.Duration{Duration: 2 * time.Second},
+	}
+
+	var stdout bytes.Buffer
+	path := filepath.Join(t.TempDir(), "termination-log")
+	require.NoError(t, schemarunner.WriteResult(&stdout, path, result))
+
+	message, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(message)+"\n", stdout.String())
+
+	parsed, err := schemarunner.ParseResult(string(message))
+	require.NoError(t, err)
+	assert.Equal(t, result, parsed)
+}
+
+func schemaRunnerPod(name string, created time.Time, status corev1.ContainerStatus) corev1.Pod {
+	status.Name = "schema-runner"
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{status},
+		},
+	}
+}
+
+func TestResultFromPods(t *testing.T) {
+	now := time.Now()
+	succeeded := corev1.ContainerStatus{
+		State: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{Message: `{"operation":"update","schemaVersion":"1.14"}`},
+		},
+	}
+	restarting := corev1.ContainerStatus{
+		State: corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+		},
+		LastTerminationState: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{Message: `{"operation":"update","error":"connection refused"}`},
+		},
+	}
+	running := corev1.ContainerStatus{
+		State: corev1.ContainerState{
+			Running: &corev1.ContainerStateRunning{},
+		},
+	}
+
+	tests := map[string]struct {
+		pods     []corev1.Pod
+		expected *v1beta1.SchemaOperationStatus
+	}{
+		"no pods": {
+			pods: nil,
+		},
+		"terminated container": {
+			pods: []corev1.Pod{schemaRunnerPod("a", now, succeeded)},
+			expected: &v1beta1.SchemaOperationStatus{
+				Operation:     v1beta1.UpdateSchemaOperation,
+				SchemaVersion: "1.14",
+			},
+		},
+		"restarting container reports last termination": {
+			pods: []corev1.Pod{schemaRunnerPod("a", now, restarting)},
+			expected: &v1beta1.SchemaOperationStatus{
+				Operation: v1beta1.UpdateSchemaOperation,
+				Error:     "connection refused",
+			},
+		},
+		"running container": {
+			pods: []corev1.Pod{schemaRunnerPod("a", now, running)},
+		},
+		"uses most recent pod": {
+			pods: []corev1.Pod{
+				schemaRunnerPod("a", now.Add(-time.Minute), restarting),
+				schemaRunnerPod("b", now, succeeded),
+			},
+			expected: &v1beta1.SchemaOperationStatus{
+				Operation:     v1beta1.UpdateSchemaOperation,
+				SchemaVersion: "1.14",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			result, err := schemarunner.ResultFromPods(test.pods, "schema-runner")
+			require.NoError(tt, err)
+			assert.Equal(tt, test.expected, result)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package schemarunner creates, sets up and updates temporal datastores schemas.
+// It drives the temporal schema tools libraries directly and reports a structured
+// result the operator reads back from the job pod termination message.
+package schemarunner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/esauth"
+	"go.temporal.io/server/common/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// tool runs schema operations on a datastore.
+type tool interface {
+	Create(ctx context.Context, result *v1beta1.SchemaOperationStatus) error
+	Setup(ctx context.Context, result *v1beta1.SchemaOperationStatus) error
+	Update(ctx context.Context, result *v1beta1.SchemaOperationStatus) error
+	Plan(ctx context.Context, result *v1beta1.SchemaOperationStatus) error
+	Check(ctx context.Context, result *v1beta1.SchemaOperationStatus) error
+}
+
+// Runner runs schema operations on the configured stores.
+type Runner struct {
+	config *Config
+	client *http.Client
+	logger log.Logger
+}
+
+// New returns a new runner for the provided configuration.
+func New(config *Config, logger log.Logger) *Runner {
+	return &Runner{
+		config: config,
+		client: &http.Client{},
+		logger: logger,
+	}
+}
+
+// Run runs the operation on the provided store and returns its result.
+func (r *Runner) Run(ctx context.Context, operation v1beta1.SchemaOperation, store Store) *v1beta1.SchemaOperationStatus {
+	result := &v1beta1.SchemaOperationStatus{
+		Operation: operation,
+	}
+
+	start := time.Now()
+	err := r.run(ctx, operation, store, result)
+	result.Duration = &metav1.Duration{Duration: time.Since(start).Round(time.Millisecond)}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func (r *Runner) run(ctx context.Context, operation v1beta1.SchemaOperation, store Store, result *v1beta1.SchemaOperationStatus) error {
+	storeConfig, err := r.config.Store(store)
+	if err != nil {
+		return err
+	}
+
+	t, err := r.tool(storeConfig)
+	if err != nil {
+		return err
+	}
+
+	switch operation {
+	case v1beta1.CreateSchemaOperation:
+		if storeConfig.Datastore.SkipCreate {
+			return nil
+		}
+		return t.Create(ctx, result)
+	case v1beta1.SetupSchemaOperation:
+		return t.Setup(ctx, result)
+	case v1beta1.UpdateSchemaOperation:
+		return t.Update(ctx, result)
+	case v1beta1.PlanSchemaOperation:
+		return t.Plan(ctx, result)
+	case v1beta1.CheckSchemaOperation:
+		return t.Check(ctx, result)
+	default:
+		return fmt.Errorf("unsupported operation: %s", operation)
+	}
+}
+
+func (r *Runner) tool(storeConfig *StoreConfig) (tool, error) {
+	spec := storeConfig.Datastore
+
+	switch spec.GetType() {
+	case v1beta1.PostgresSQLDatastore,
+		v1beta1.PostgresSQL12Datastore,
+		v1beta1.MySQLDatastore,
+		v1beta1.MySQL8Datastore:
+		return &sqlTool{spec: spec, schemaDir: storeConfig.SchemaDir, logger: r.logger}, nil
+	case v1beta1.CassandraDatastore:
+		return &cassandraTool{spec: spec, schemaDir: storeConfig.SchemaDir, logger: r.logger}, nil
+	case v1beta1.ElasticsearchDatastore:
+		authenticator, err := esauth.NewAuthenticator(spec)
+		if err != nil {
+			return nil, err
+		}
+		client := &http.Client{Transport: authenticator.Transport(r.client.Transport)}
+		return &elasticsearchTool{spec: spec, schemaDir: storeConfig.SchemaDir, client: client, authenticator: authenticator, logger: r.logger}, nil
+	case v1beta1.UnknownDatastore:
+	}
+
+	return nil, fmt.Errorf("unsupported datastore: %s", spec.GetType())
+}
+
+// ShutdownProxy asks the mesh sidecar proxy to exit, so the job pod can complete.
+func (r *Runner) ShutdownProxy(ctx context.Context) error {
+	var url string
+	switch r.config.MTLSProvider {
+	case v1beta1.LinkerdMTLSProvider:
+		url = "http://localhost:4191/shutdown"
+	case v1beta1.IstioMTLSProvider:
+		url = "http://127.0.0.1:15020/quitquitquit"
+	default:
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return fmt.Errorf("can't create request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("can't shutdown proxy: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
@@ -0,0 +1,107 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schemarunner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/server/common/log"
+)
+
+// roundTripperFunc records the requests sent by the runner instead of reaching the sidecar.
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRunnerShutdownProxy(t *testing.T) {
+	tests := map[string]struct {
+		mTLSProvider     v1beta1.MTLSProvider
+		statusCode       int
+		transportErr     error
+		expectedRequest  string
+		expectedErr      string
+		expectNoRequests bool
+	}{
+		"no mesh sidecar": {
+			mTLSProvider:     v1beta1.CertManagerMTLSProvider,
+			expectNoRequests: true,
+		},
+		"linkerd": {
+			mTLSProvider:    v1beta1.LinkerdMTLSProvider,
+			statusCode:      http.StatusOK,
+			expectedRequest: "POST http://localhost:4191/shutdown",
+		},
+		"istio": {
+			mTLSProvider:    v1beta1.IstioMTLSProvider,
+			statusCode:      http.StatusOK,
+			expectedRequest: "POST http://127.0.0.1:15020/quitquitquit",
+		},
+		"failing shutdown is tolerated": {
+			mTLSProvider:    v1beta1.LinkerdMTLSProvider,
+			statusCode:      http.StatusInternalServerError,
+			expectedRequest: "POST http://localhost:4191/shutdown",
+		},
+		"unreachable sidecar": {
+			mTLSProvider:    v1beta1.IstioMTLSProvider,
+			transportErr:    errors.New("connection refused"),
+			expectedRequest: "POST http://127.0.0.1:15020/quitquitquit",
+			expectedErr:     "connection refused",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			requests := []string{}
+			runner := New(&Config{MTLSProvider: test.mTLSProvider}, log.NewNoopLogger())
+			runner.client.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				requests = append(requests, req.Method+" "+req.URL.String())
+				if test.transportErr != nil {
+					return nil, test.transportErr
+				}
+				return &http.Response{
+					StatusCode: test.statusCode,
+					Body:       io.NopCloser(strings.NewReader("")),
+				}, nil
+			})
+
+			err := runner.ShutdownProxy(context.Background())
+			if test.expectedErr != "" {
+				require.Error(tt, err)
+				assert.Contains(tt, err.Error(), test.expectedErr)
+			} else {
+				require.NoError(tt, err)
+			}
+
+			if test.expectNoRequests {
+				assert.Empty(tt, requests)
+				return
+			}
+			assert.Equal(tt, []string{test.expectedRequest}, requests)
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schemarunner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/log/tag"
+	"go.temporal.io/server/tools/common/schema"
+)
+
+// initialSchemaVersion is the version set when setting up a new schema,
+// versioned schemas are then applied on top of it by updates.
+const initialSchemaVersion = "0.0"
+
+// manifestFileName is the name of the manifest describing a versioned schema directory.
+const manifestFileName = "manifest.json"
+
+var versionDirectoryRegexp = regexp.MustCompile(`^v[\d.]+$`)
+
+// manifest is the content of a versioned schema directory manifest.
+type manifest struct {
+	CurrVersion          string
+	Description          string
+	SchemaUpdateCqlFiles []string
+}
+
+// recordingDB records the schema versions written by the schema tasks.
+type recordingDB struct {
+	schema.DB
+	versions []string
+}
+
+func (db *recordingDB) UpdateSchemaVersion(newVersion string, minCompatibleVersion string) error {
+	err := db.DB.UpdateSchemaVersion(newVersion, minCompatibleVersion)
+	if err != nil {
+		return err
+	}
+
+	db.versions = append(db.versions, newVersion)
+	return nil
+}
+
+// setupSchema creates the schema version tables and sets the initial schema version.
+func setupSchema(db schema.DB, logger log.Logger, result *v1beta1.SchemaOperationStatus) error {
+	recorder := &recordingDB{DB: db}
+	err := schema.NewSetupSchemaTask(recorder, &schema.SetupConfig{InitialVersion: initialSchemaVersion}, logger).Run()
+	if err != nil {
+		return fmt.Errorf("can't setup schema: %w", err)
+	}
+
+	return recordVersions(recorder, result)
+}
+
+// updateSchema applies the versioned schemas found in schemaDir.
+func updateSchema(db schema.DB, schemaDir string, logger log.Logger, result *v1beta1.SchemaOperationStatus) error {
+	if schemaDir == "" {
+		return fmt.Errorf("no schema directory configured")
+	}
+
+	recorder := &recordingDB{DB: db}
+	err := schema.NewUpdateSchemaTask(recorder, &schema.UpdateConfig{SchemaDir: schemaDir}, logger).Run()
+	if err != nil {
+		return fmt.Errorf("can't update schema: %w", err)
+	}
+
+	return recordVersions(recorder, result)
+}
+
+// planSchema lists the versioned schemas found in schemaDir that an update would apply,
+// without modifying the database.
+func planSchema(db schema.DB, schemaDir string, logger log.Logger, result *v1beta1.SchemaOperationStatus) error {
+	if schemaDir == "" {
+		return fmt.Errorf("no schema directory configured")
+	}
+
+	current, err := db.ReadSchemaVersion()
+	if err != nil {
+		return fmt.Errorf("can't read schema version: %w", err)
+	}
+	result.SchemaVersion = current
+
+	migrations, err := pendingMigrations(schemaDir, current)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		logger.Info("Planned schema migration",
+			tag.NewStringTag("version", migration.Version),
+			tag.NewStringTag("directory", migration.Directory),
+			tag.NewStringTag("description", migration.Description),
+		)
+		for _, file := range migration.Files {
+			content, err := os.ReadFile(filepath.Join(schemaDir, migration.Directory, file))
+			if err != nil {
+				return fmt.Errorf("can't read schema file %s: %w", file, err)
+			}
+			logger.Info("Planned schema file",
+				tag.NewStringTag("file", path.Join(migration.Directory, file)),
+				tag.NewStringTag("content", string(content)),
+			)
+		}
+	}
+
+	result.PlannedMigrations = migrations
+	return nil
+}
+
+// pendingMigrations returns the versioned schemas of schemaDir newer than the current version,
+// sorted by version.
+func pendingMigrations(schemaDir, current string) ([]v1beta1.SchemaMigration, error) {
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse schema version %s: %w", current, err)
+	}
+
+	entries, err := os.ReadDir(schemaDir)
+	if err != nil {
+		return nil, fmt.Errorf("can't read schema directory: %w", err)
+	}
+
+	type versionedDir struct {
+		name    string
+		version *semver.Version
+	}
+
+	dirs := []versionedDir{}
+	for _, entry := range entries {
+		if !entry.IsDir() || !versionDirectoryRegexp.MatchString(entry.Name()) {
+			continue
+		}
+
+		v, err := semver.NewVersion(strings.TrimPrefix(entry.Name(), "v"))
+		if err != nil {
+			continue
+		}
+
+		if v.GreaterThan(currentVersion) {
+			dirs = append(dirs, versionedDir{name: entry.Name(), version: v})
+		}
+	}
+
+	sort.Slice(dirs, func(i, j int) bool {
+		return dirs[i].version.LessThan(dirs[j].version)
+	})
+
+	migrations := make([]v1beta1.SchemaMigration, 0, len(dirs))
+	for _, dir := range dirs {
+		m, err := readManifest(filepath.Join(schemaDir, dir.name))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, v1beta1.SchemaMigration{
+			Version:     m.CurrVersion,
+			Directory:   dir.name,
+			Description: m.Description,
+			Files:       m.SchemaUpdateCqlFiles,
+		})
+	}
+
+	return migrations, nil
+}
+
+func readManifest(dir string) (*manifest, error) {
+	content, err := os.ReadFile(filepath.Join(dir, manifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("can't read manifest: %w", err)
+	}
+
+	m := &manifest{}
+	err = json.Unmarshal(content, m)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse manifest %s: %w", filepath.Join(dir, manifestFileName), err)
+	}
+
+	return m, nil
+}
+
+func recordVersions(db *recordingDB, result *v1beta1.SchemaOperationStatus) error {
+	result.AppliedVersions = db.versions
+
+	version, err := db.ReadSchemaVersion()
+	if err != nil {
+		return fmt.Errorf("can't read schema version: %w", err)
+	}
+	result.SchemaVersion = version
+
+	return nil
+}
+
+// password returns the datastore password from the environment variable
+// populated from the datastore password secret.
+func password(spec *v1beta1.DatastoreSpec) string {
+	if spec.PasswordSecretRef == nil {
+		return ""
+	}
+	return os.Getenv(spec.GetPasswordEnvVarName())
+}
@@ -0,0 +1,159 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schemarunner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/server/common/log"
+	temporalschema "go.temporal.io/server/schema"
+	"go.temporal.io/server/tools/common/schema"
+)
+
+// versionDB is a schema database only able to read its schema version.
+// Any other call panics, ensuring plans never modify the database.
+type versionDB struct {
+	schema.DB
+	version string
+	err     error
+}
+
+func (db *versionDB) ReadSchemaVersion() (string, error) {
+	return db.version, db.err
+}
+
+// temporalSchemaDir extracts the schemas shipped with temporal and returns the path of the provided directory.
+func temporalSchemaDir(t *testing.T, dir string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	require.NoError(t, os.CopyFS(root, temporalschema.Assets()))
+	return filepath.Join(root, dir)
+}
+
+func TestPlanSchema(t *testing.T) {
+	sqlDir := temporalSchemaDir(t, "postgresql/v12/temporal/versioned")
+	cassandraDir := temporalSchemaDir(t, "cassandra/temporal/versioned")
+
+	tests := map[string]struct {
+		schemaDir          string
+		db                 *versionDB
+		expectedVersions   []string
+		expectedDirectory  string
+		expectedFirstFiles []string
+		expectedErr        string
+	}{
+		"sql schema behind": {
+			schemaDir:          sqlDir,
+			db:                 &versionDB{version: "1.14"},
+			expectedVersions:   []string{"1.15", "1.16", "1.17"},
+			expectedDirectory:  "v1.15",
+			expectedFirstFiles: []string{"add_current_executions_data.sql"},
+		},
+		"cassandra schema behind": {
+			schemaDir:          cassandraDir,
+			db:                 &versionDB{version: "1.10"},
+			expectedVersions:   []string{"1.11", "1.12"},
+			expectedDirectory:  "v1.11",
+			expectedFirstFiles: []string{"nexus_endpoints.cql"},
+		},
+		"schema up to date": {
+			schemaDir:        cassandraDir,
+			db:               &versionDB{version: "1.12"},
+			expectedVersions: []string{},
+		},
+		"no schema directory": {
+			db:          &versionDB{version: "1.0"},
+			expectedErr: "no schema directory configured",
+		},
+		"unreadable schema version": {
+			schemaDir:   sqlDir,
+			db:          &versionDB{err: errors.New("connection refused")},
+			expectedErr: "can't read schema version: connection refused",
+		},
+		"invalid schema version": {
+			schemaDir:   sqlDir,
+			db:          &versionDB{version: "latest"},
+			expectedErr: "can't parse schema version latest",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			result := &v1beta1.SchemaOperationStatus{}
+
+			err := planSchema(test.db, test.schemaDir, log.NewNoopLogger(), result)
+			if test.expectedErr != "" {
+				assert.ErrorContains(tt, err, test.expectedErr)
+				return
+			}
+			require.NoError(tt, err)
+
+			assert.Equal(tt, test.db.version, result.SchemaVersion)
+			assert.Empty(tt, result.AppliedVersions)
+
+			versions := []string{}
+			for _, migration := range result.PlannedMigrations {
+				versions = append(versions, migration.Version)
+			}
+			assert.Equal(tt, test.expectedVersions, versions)
+
+			if len(result.PlannedMigrations) > 0 {
+				assert.Equal(tt, test.expectedDirectory, result.PlannedMigrations[0].Directory)
+				assert.Equal(tt, test.expectedFirstFiles, result.PlannedMigrations[0].Files)
+				assert.NotEmpty(tt, result.PlannedMigrations[0].Description)
+			}
+		})
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	schemaDir := t.TempDir()
+
+	for _, dir := range []struct {
+		name     string
+		manifest string
+	}{
+		{name: "v1.2", manifest: `{"CurrVersion": "1.2", "Description": "second", "SchemaUpdateCqlFiles": ["b.sql"]}`},
+		{name: "v1.10", manifest: `{"CurrVersion": "1.10", "Description": "tenth", "SchemaUpdateCqlFiles": ["c.sql", "d.sql"]}`},
+		{name: "v1.1", manifest: `{"CurrVersion": "1.1", "Description": "first", "SchemaUpdateCqlFiles": ["a.sql"]}`},
+		{name: "notes", manifest: `{}`},
+	} {
+		require.NoError(t, os.Mkdir(filepath.Join(schemaDir, dir.name), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(schemaDir, dir.name, manifestFileName), []byte(dir.manifest), 0o600))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(schemaDir, "v1.3"), nil, 0o600))
+
+	migrations, err := pendingMigrations(schemaDir, "1.1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []v1beta1.SchemaMigration{
+		{Version: "1.2", Directory: "v1.2", Description: "second", Files: []string{"b.sql"}},
+		{Version: "1.10", Directory: "v1.10", Description: "tenth", Files: []string{"c.sql", "d.sql"}},
+	}, migrations)
+
+	require.NoError(t, os.WriteFile(filepath.Join(schemaDir, "v1.2", manifestFileName), []byte("{"), 0o600))
+	_, err = pendingMigrations(schemaDir, "1.1")
+	assert.ErrorContains(t, err, "can't parse manifest")
+}
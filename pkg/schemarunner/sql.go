@@ -0,0 +1,241 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schemarunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/datastoretls"
+	"github.com/alexandrevilain/temporal-operator/pkg/sqlauth"
+	"github.com/alexandrevilain/temporal-operator/pkg/temporal/persistence"
+	"github.com/jmoiron/sqlx"
+	"go.temporal.io/server/common/config"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/persistence/sql/sqlplugin"
+	"go.temporal.io/server/common/persistence/sql/sqlplugin/mysql"
+	mysqlsession "go.temporal.io/server/common/persistence/sql/sqlplugin/mysql/session"
+	"go.temporal.io/server/common/persistence/sql/sqlplugin/postgresql"
+	postgresqldriver "go.temporal.io/server/common/persistence/sql/sqlplugin/postgresql/driver"
+	postgresqlsession "go.temporal.io/server/common/persistence/sql/sqlplugin/postgresql/session"
+	"go.temporal.io/server/common/resolver"
+	sqltool "go.temporal.io/server/tools/sql"
+)
+
+// postgresDefaultDatabaseNames are the databases the postgres plugin connects to when no database is provided.
+var postgresDefaultDatabaseNames = []string{"postgres", "defaultdb"}
+
+// sqlTool runs schema operations on SQL datastores.
+type sqlTool struct {
+	spec      *v1beta1.DatastoreSpec
+	schemaDir string
+	logger    log.Logger
+}
+
+func (t *sqlTool) config(ctx context.Context) (*config.SQL, error) {
+	cfg := persistence.NewSQLConfigFromDatastoreSpec(t.spec)
+	cfg.Password = password(t.spec)
+
+	if t.spec.IsSQLTokenAuth() {
+		tokens, err := sqlauth.NewTokenSource(ctx, t.spec)
+		if err != nil {
+			return nil, fmt.Errorf("can't create token source: %w", err)
+		}
+
+		// The runner is short-lived, a single token is enough to run the operation.
+		cfg.Password, err = tokens.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("can't get token: %w", err)
+		}
+		cfg.ConnectAttributes = sqlauth.ConnectAttributes(t.spec)
+	}
+
+	return cfg, nil
+}
+
+func (t *sqlTool) Create(ctx context.Context, _ *v1beta1.SchemaOperationStatus) error {
+	cfg, err := t.config(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = sqltool.DoCreateDatabase(cfg, "", t.logger)
+	if err != nil {
+		return fmt.Errorf("can't create database: %w", err)
+	}
+	return nil
+}
+
+func (t *sqlTool) Setup(ctx context.Context, result *v1beta1.SchemaOperationStatus) error {
+	cfg, err := t.config(ctx)
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqltool.NewConnection(cfg, t.logger)
+	if err != nil {
+		return fmt.Errorf("can't connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	return setupSchema(conn, t.logger, result)
+}
+
+func (t *sqlTool) Update(ctx context.Context, result *v1beta1.SchemaOperationStatus) error {
+	cfg, err := t.config(ctx)
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqltool.NewConnection(cfg, t.logger)
+	if err != nil {
+		return fmt.Errorf("can't connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	return updateSchema(conn, t.schemaDir, t.logger, result)
+}
+
+func (t *sqlTool) Plan(ctx context.Context, result *v1beta1.SchemaOperationStatus) error {
+	cfg, err := t.config(ctx)
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqltool.NewConnection(cfg, t.logger)
+	if err != nil {
+		return fmt.Errorf("can't connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	return planSchema(conn, t.schemaDir, t.logger, result)
+}
+
+func (t *sqlTool) Check(ctx context.Context, result *v1beta1.SchemaOperationStatus) error {
+	startTLS := datastoretls.PostgresStartTLS
+	if t.spec.GetType() == v1beta1.MySQLDatastore || t.spec.GetType() == v1beta1.MySQL8Datastore {
+		startTLS = mysqlStartTLS
+	}
+
+	tlsEnabled := t.spec.TLS != nil && t.spec.TLS.Enabled
+	err := checkNetwork(ctx, t.spec, []string{t.spec.SQL.ConnectAddr}, tlsEnabled, startTLS, result)
+	if err != nil {
+		return err
+	}
+
+	var db *sqlx.DB
+	err = runCheck(result, authenticationCheck, func() (string, error) {
+		cfg, err := t.config(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		d, err := connect(cfg)
+		if err != nil && !t.spec.SkipCreate {
+			// The database may not be created yet, connect to the default database as the create operation does.
+			cfg.DatabaseName = ""
+			d, err = connect(cfg)
+			if err == nil {
+				defer d.Close()
+				return fmt.Sprintf("authenticated as %s, database %s will be created", t.spec.SQL.User, t.spec.SQL.DatabaseName), nil
+			}
+		}
+		if err != nil {
+			return "", fmt.Errorf("can't connect to database %s as %s: %w", t.spec.SQL.DatabaseName, t.spec.SQL.User, err)
+		}
+
+		_, err = d.ExecContext(ctx, "SELECT 1")
+		if err != nil {
+			d.Close()
+			return "", fmt.Errorf("can't query database %s: %w", t.spec.SQL.DatabaseName, err)
+		}
+
+		db = d
+		return fmt.Sprintf("authenticated as %s on database %s", t.spec.SQL.User, t.spec.SQL.DatabaseName), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if db == nil {
+		// Privileges on the database can't be checked until it's created.
+		return runCheck(result, privilegesCheck, func() (string, error) {
+			return fmt.Sprintf("database %s doesn't exist yet, privileges are checked by the schema operations", t.spec.SQL.DatabaseName), nil
+		})
+	}
+	defer db.Close()
+
+	return runCheck(result, privilegesCheck, func() (string, error) {
+		_, err := db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id INT PRIMARY KEY)", preflightTable))
+		if err != nil {
+			return "", fmt.Errorf("can't create table in database %s: %w", t.spec.SQL.DatabaseName, err)
+		}
+
+		_, err = db.ExecContext(ctx, fmt.Sprintf("DROP TABLE %s", preflightTable))
+		if err != nil {
+			return "", fmt.Errorf("can't drop table in database %s: %w", t.spec.SQL.DatabaseName, err)
+		}
+
+		return "user can create and drop tables", nil
+	})
+}
+
+// connect opens a connection to the datastore the same way the temporal SQL plugins do.
+// Unlike sqltool.NewConnection, which only logs connection failures, it returns the connection error.
+func connect(cfg *config.SQL) (*sqlx.DB, error) {
+	r := resolver.NewNoopResolver()
+
+	switch cfg.PluginName {
+	case mysql.PluginName:
+		s, err := mysqlsession.NewSession(sqlplugin.DbKindUnknown, cfg, r)
+		if err != nil {
+			return nil, err
+		}
+		return s.DB, nil
+	case postgresql.PluginName, postgresql.PluginNamePGX:
+		var d postgresqldriver.Driver = &postgresqldriver.PQDriver{}
+		if cfg.PluginName == postgresql.PluginNamePGX {
+			d = &postgresqldriver.PGXDriver{}
+		}
+
+		if cfg.DatabaseName != "" {
+			s, err := postgresqlsession.NewSession(cfg, d, r)
+			if err != nil {
+				return nil, err
+			}
+			return s.DB, nil
+		}
+
+		// Like the plugin, try the default databases when no database is provided.
+		errs := []error{}
+		for _, name := range postgresDefaultDatabaseNames {
+			defaultCfg := *cfg
+			defaultCfg.DatabaseName = name
+			s, err := postgresqlsession.NewSession(&defaultCfg, d, r)
+			if err == nil {
+				return s.DB, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
+	}
+
+	return nil, fmt.Errorf("unsupported sql plugin %s", cfg.PluginName)
+}
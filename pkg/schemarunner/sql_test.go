@@ -0,0 +1,241 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schemarunner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/server/common/log"
+)
+
+// postgresSSLRequestCode is the code of the postgres SSLRequest message.
+const postgresSSLRequestCode = 80877103
+
+// fakePostgres speaks enough of the postgres protocol for the SQL tool to authenticate and run statements.
+type fakePostgres struct {
+	password string
+	// databases are the existing databases.
+	databases []string
+	// denied are the statement prefixes rejected for insufficient privileges.
+	denied []string
+}
+
+func (s *fakePostgres) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+
+	params, err := s.readStartup(r, conn)
+	if err != nil {
+		return
+	}
+
+	// Request a cleartext password.
+	writePostgresMessage(conn, 'R', binary.BigEndian.AppendUint32(nil, 3))
+
+	typ, body, err := readPostgresMessage(r)
+	if err != nil || typ != 'p' {
+		return
+	}
+
+	if string(bytes.TrimSuffix(body, []byte{0})) != s.password {
+		writePostgresError(conn, "FATAL", "28P01", fmt.Sprintf("password authentication failed for user %q", params["user"]))
+		return
+	}
+
+	if !slices.Contains(s.databases, params["database"]) {
+		writePostgresError(conn, "FATAL", "3D000", fmt.Sprintf("database %q does not exist", params["database"]))
+		return
+	}
+
+	writePostgresMessage(conn, 'R', binary.BigEndian.AppendUint32(nil, 0))
+	writePostgresMessage(conn, 'Z', []byte{'I'})
+
+	for {
+		typ, body, err := readPostgresMessage(r)
+		if err != nil || typ != 'Q' {
+			return
+		}
+
+		query := strings.TrimSpace(string(bytes.TrimSuffix(body, []byte{0})))
+		switch {
+		case query == ";":
+			writePostgresMessage(conn, 'I', nil)
+		case slices.ContainsFunc(s.denied, func(prefix string) bool { return strings.HasPrefix(query, prefix) }):
+			writePostgresError(conn, "ERROR", "42501", "permission denied for schema public")
+		default:
+			tag := strings.Join(strings.Fields(query)[:2], " ")
+			writePostgresMessage(conn, 'C', append([]byte(tag), 0))
+		}
+		writePostgresMessage(conn, 'Z', []byte{'I'})
+	}
+}
+
+// readStartup reads the client startup message, refusing TLS, and returns its parameters.
+func (s *fakePostgres) readStartup(r *bufio.Reader, conn net.Conn) (map[string]string, error) {
+	for {
+		header := make([]byte, 8)
+		_, err := io.ReadFull(r, header)
+		if err != nil {
+			return nil, err
+		}
+
+		body := make([]byte, binary.BigEndian.Uint32(header[0:4])-8)
+		_, err = io.ReadFull(r, body)
+		if err != nil {
+			return nil, err
+		}
+
+		if binary.BigEndian.Uint32(header[4:8]) == postgresSSLRequestCode {
+			_, err = conn.Write([]byte{'N'})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		params := map[string]string{}
+		fields := strings.Split(string(body), "\x00")
+		for i := 0; i+1 < len(fields); i += 2 {
+			params[fields[i]] = fields[i+1]
+		}
+		return params, nil
+	}
+}
+
+func readPostgresMessage(r *bufio.Reader) (byte, []byte, error) {
+	header := make([]byte, 5)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	body := make([]byte, binary.BigEndian.Uint32(header[1:5])-4)
+	_, err = io.ReadFull(r, body)
+	return header[0], body, err
+}
+
+func writePostgresMessage(w io.Writer, typ byte, body []byte) {
+	message := binary.BigEndian.AppendUint32([]byte{typ}, uint32(len(body)+4))
+	_, _ = w.Write(append(message, body...))
+}
+
+func writePostgresError(w io.Writer, severity, code, message string) {
+	body := fmt.Sprintf("S%s\x00C%s\x00M%s\x00\x00", severity, code, message)
+	writePostgresMessage(w, 'E', []byte(body))
+}
+
+func TestSQLCheck(t *testing.T) {
+	tests := map[string]struct {
+		server                 *fakePostgres
+		tls                    *v1beta1.DatastoreTLSSpec
+		skipCreate             bool
+		expectedPassed         []string
+		expectedFailed         string
+		expectedErr            string
+		expectedAuthentication string
+		expectedPrivileges     string
+	}{
+		"authenticated with privileges": {
+			server:                 &fakePostgres{password: "secret", databases: []string{"postgres", "temporal"}},
+			expectedPassed:         []string{dnsCheck, tcpCheck, tlsCheck, authenticationCheck, privilegesCheck},
+			expectedAuthentication: "authenticated as temporal on database temporal",
+			expectedPrivileges:     "user can create and drop tables",
+		},
+		"wrong password": {
+			server:         &fakePostgres{password: "other", databases: []string{"postgres", "temporal"}},
+			expectedPassed: []string{dnsCheck, tcpCheck, tlsCheck},
+			expectedFailed: authenticationCheck,
+			expectedErr:    "authentication: can't connect to database temporal as temporal",
+		},
+		"database not created yet": {
+			server:                 &fakePostgres{password: "secret", databases: []string{"postgres"}},
+			expectedPassed:         []string{dnsCheck, tcpCheck, tlsCheck, authenticationCheck, privilegesCheck},
+			expectedAuthentication: "authenticated as temporal, database temporal will be created",
+			expectedPrivileges:     "database temporal doesn't exist yet, privileges are checked by the schema operations",
+		},
+		"database not created with skip create": {
+			server:         &fakePostgres{password: "secret", databases: []string{"postgres"}},
+			skipCreate:     true,
+			expectedPassed: []string{dnsCheck, tcpCheck, tlsCheck},
+			expectedFailed: authenticationCheck,
+			expectedErr:    `database "temporal" does not exist`,
+		},
+		"missing privileges": {
+			server:         &fakePostgres{password: "secret", databases: []string{"postgres", "temporal"}, denied: []string{"CREATE TABLE"}},
+			expectedPassed: []string{dnsCheck, tcpCheck, tlsCheck, authenticationCheck},
+			expectedFailed: privilegesCheck,
+			expectedErr:    "privileges: can't create table in database temporal",
+		},
+		"starttls refused": {
+			server:         &fakePostgres{password: "secret", databases: []string{"postgres", "temporal"}},
+			tls:            &v1beta1.DatastoreTLSSpec{Enabled: true},
+			expectedPassed: []string{dnsCheck, tcpCheck},
+			expectedFailed: tlsCheck,
+			expectedErr:    "server doesn't support TLS",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			spec := &v1beta1.DatastoreSpec{
+				Name: "default",
+				SQL: &v1beta1.SQLSpec{
+					PluginName:   "postgres12",
+					ConnectAddr:  listen(tt, test.server.serve),
+					User:         "temporal",
+					DatabaseName: "temporal",
+				},
+				PasswordSecretRef: &v1beta1.SecretKeyReference{Name: "postgres", Key: "password"},
+				SkipCreate:        test.skipCreate,
+				TLS:               test.tls,
+			}
+			tt.Setenv(spec.GetPasswordEnvVarName(), "secret")
+
+			tool := &sqlTool{spec: spec, logger: log.NewNoopLogger()}
+			result := &v1beta1.SchemaOperationStatus{}
+
+			err := tool.Check(context.Background(), result)
+			if test.expectedErr != "" {
+				require.Error(tt, err)
+				assert.Contains(tt, err.Error(), test.expectedErr)
+			} else {
+				require.NoError(tt, err)
+			}
+
+			assertChecks(tt, result, test.expectedPassed, test.expectedFailed)
+
+			if test.expectedAuthentication != "" {
+				assert.Equal(tt, test.expectedAuthentication, result.Checks[3].Message)
+			}
+			if test.expectedPrivileges != "" {
+				assert.Equal(tt, test.expectedPrivileges, result.Checks[4].Message)
+			}
+		})
+	}
+}
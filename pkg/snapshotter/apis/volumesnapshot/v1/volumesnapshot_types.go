@@ -0,0 +1,98 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VolumeSnapshotSource specifies whether the underlying snapshot should be
+// dynamically taken upon creation or if a pre-existing VolumeSnapshotContent
+// object should be used.
+type VolumeSnapshotSource struct {
+	// PersistentVolumeClaimName specifies the name of the PersistentVolumeClaim
+	// object representing the volume from which a snapshot should be created.
+	// +optional
+	PersistentVolumeClaimName *string `json:"persistentVolumeClaimName,omitempty"`
+	// VolumeSnapshotContentName specifies the name of a pre-existing VolumeSnapshotContent
+	// object representing an existing volume snapshot.
+	// +optional
+	VolumeSnapshotContentName *string `json:"volumeSnapshotContentName,omitempty"`
+}
+
+// VolumeSnapshotSpec describes the common attributes of a volume snapshot.
+type VolumeSnapshotSpec struct {
+	// Source specifies where a snapshot will be created from.
+	Source VolumeSnapshotSource `json:"source"`
+	// VolumeSnapshotClassName is the name of the VolumeSnapshotClass requested by the VolumeSnapshot.
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+// VolumeSnapshotError describes an error encountered during snapshot creation.
+type VolumeSnapshotError struct {
+	// Time is the timestamp when the error was encountered.
+	// +optional
+	Time *metav1.Time `json:"time,omitempty"`
+	// Message is a string detailing the encountered error during snapshot creation.
+	// +optional
+	Message *string `json:"message,omitempty"`
+}
+
+// VolumeSnapshotStatus is the status of the VolumeSnapshot.
+type VolumeSnapshotStatus struct {
+	// BoundVolumeSnapshotContentName is the name of the VolumeSnapshotContent object
+	// to which this VolumeSnapshot object intends to bind to.
+	// +optional
+	BoundVolumeSnapshotContentName *string `json:"boundVolumeSnapshotContentName,omitempty"`
+	// CreationTime is the timestamp when the point-in-time snapshot is taken by the underlying storage system.
+	// +optional
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+	// ReadyToUse indicates if the snapshot is ready to be used to restore a volume.
+	// +optional
+	ReadyToUse *bool `json:"readyToUse,omitempty"`
+	// Error is the last observed error during snapshot creation, if any.
+	// +optional
+	Error *VolumeSnapshotError `json:"error,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VolumeSnapshot is a user's request for either creating a point-in-time
+// snapshot of a persistent volume, or binding to a pre-existing snapshot.
+type VolumeSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VolumeSnapshotSpec `json:"spec"`
+	// +optional
+	Status *VolumeSnapshotStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VolumeSnapshotList is a list of VolumeSnapshot resources.
+type VolumeSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []VolumeSnapshot `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VolumeSnapshot{}, &VolumeSnapshotList{})
+}
@@ -0,0 +1,394 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqlauth
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+)
+
+const (
+	mysqlClientConnectWithDB              = 0x00000008
+	mysqlClientCompress                   = 0x00000020
+	mysqlClientSSL                        = 0x00000800
+	mysqlClientSecureConnection           = 0x00008000
+	mysqlClientPluginAuth                 = 0x00080000
+	mysqlClientConnectAttrs               = 0x00100000
+	mysqlClientPluginAuthLenEncClientData = 0x00200000
+	mysqlClientZstdCompression            = 0x04000000
+
+	mysqlOKPacket         = 0x00
+	mysqlAuthSwitchPacket = 0xfe
+	mysqlErrorPacket      = 0xff
+
+	// mysqlHandshakeResponseHeaderLength is the length of the capabilities, max packet size,
+	// character set and filler at the beginning of handshake responses.
+	mysqlHandshakeResponseHeaderLength = 32
+
+	mysqlClearPasswordPlugin = "mysql_clear_password"
+)
+
+// mysqlHandshake forwards the datastore greeting to the client, then rewrites the client handshake response
+// to authenticate with the token using the cleartext password plugin. The client only receives the authentication result.
+func (p *Proxy) mysqlHandshake(ctx context.Context, client, conn net.Conn, token string) (net.Conn, error) {
+	greeting, greetingSeq, err := readMySQLPacket(conn)
+	if err != nil {
+		return nil, fmt.Errorf("can't read datastore greeting: %w", err)
+	}
+
+	if len(greeting) > 0 && greeting[0] == mysqlErrorPacket {
+		_ = writeMySQLPacket(client, greetingSeq, greeting)
+		return nil, fmt.Errorf("datastore rejected the connection: %s", mysqlErrorMessage(greeting))
+	}
+
+	serverCapabilities, err := mysqlGreetingCapabilities(greeting)
+	if err != nil {
+		return nil, err
+	}
+
+	// The client connects to the proxy from within the pod, encryption and compression aren't needed.
+	err = setMySQLGreetingCapabilities(greeting, serverCapabilities&^(mysqlClientSSL|mysqlClientCompress|mysqlClientZstdCompression))
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeMySQLPacket(client, greetingSeq, greeting)
+	if err != nil {
+		return nil, fmt.Errorf("can't send greeting to client: %w", err)
+	}
+
+	response, clientSeq, err := readMySQLPacket(client)
+	if err != nil {
+		return nil, fmt.Errorf("can't read client handshake response: %w", err)
+	}
+
+	handshake, err := parseMySQLHandshakeResponse(response)
+	if err != nil {
+		return nil, err
+	}
+
+	handshake.capabilities |= mysqlClientPluginAuth
+	if serverCapabilities&mysqlClientPluginAuthLenEncClientData != 0 {
+		handshake.capabilities |= mysqlClientPluginAuthLenEncClientData
+	} else if len(token)+1 > 250 {
+		return nil, errors.New("datastore doesn't support long authentication data")
+	}
+
+	seq := greetingSeq + 1
+	upstream, err := p.startTLS(ctx, conn, func() error {
+		sslRequest := make([]byte, mysqlHandshakeResponseHeaderLength)
+		copy(sslRequest, response[:mysqlHandshakeResponseHeaderLength])
+		binary.LittleEndian.PutUint32(sslRequest[0:4], handshake.capabilities|mysqlClientSSL)
+		err := writeMySQLPacket(conn, seq, sslRequest)
+		seq++
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tlsConfig != nil {
+		handshake.capabilities |= mysqlClientSSL
+	}
+	handshake.authResponse = append([]byte(token), 0)
+	handshake.pluginName = mysqlClearPasswordPlugin
+
+	err = writeMySQLPacket(upstream, seq, handshake.encode())
+	if err != nil {
+		return nil, fmt.Errorf("can't send handshake response: %w", err)
+	}
+
+	for {
+		packet, packetSeq, err := readMySQLPacket(upstream)
+		if err != nil {
+			return nil, fmt.Errorf("can't read datastore packet: %w", err)
+		}
+
+		if len(packet) == 0 {
+			return nil, errors.New("empty packet received")
+		}
+
+		switch packet[0] {
+		case mysqlOKPacket:
+			// Sequence ids differ between both sides, the client expects the answer right after its handshake response.
+			err = writeMySQLPacket(client, clientSeq+1, packet)
+			return upstream, err
+		case mysqlErrorPacket:
+			_ = writeMySQLPacket(client, clientSeq+1, packet)
+			return nil, fmt.Errorf("datastore rejected the connection: %s", mysqlErrorMessage(packet))
+		case mysqlAuthSwitchPacket:
+			plugin, _, _ := bytes.Cut(packet[1:], []byte{0})
+			if string(plugin) != mysqlClearPasswordPlugin {
+				return nil, fmt.Errorf("unsupported authentication plugin %s, the datastore must accept %s", plugin, mysqlClearPasswordPlugin)
+			}
+
+			err = writeMySQLPacket(upstream, packetSeq+1, append([]byte(token), 0))
+			if err != nil {
+				return nil, fmt.Errorf("can't send token: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected packet 0x%x during authentication", packet[0])
+		}
+	}
+}
+
+// mysqlHandshakeResponse is a protocol 4.1 handshake response.
+type mysqlHandshakeResponse struct {
+	capabilities uint32
+	header       []byte
+	user         []byte
+	authResponse []byte
+	database     []byte
+	pluginName   string
+	connectAttrs []byte
+}
+
+func parseMySQLHandshakeResponse(packet []byte) (*mysqlHandshakeResponse, error) {
+	malformed := errors.New("malformed client handshake response")
+
+	if len(packet) <= mysqlHandshakeResponseHeaderLength {
+		return nil, malformed
+	}
+
+	handshake := &mysqlHandshakeResponse{
+		capabilities: binary.LittleEndian.Uint32(packet[0:4]),
+		header:       packet[:mysqlHandshakeResponseHeaderLength],
+	}
+
+	if handshake.capabilities&mysqlClientSSL != 0 {
+		return nil, errors.New("client requested TLS which isn't supported by the proxy")
+	}
+
+	rest := packet[mysqlHandshakeResponseHeaderLength:]
+
+	user, rest, ok := bytes.Cut(rest, []byte{0})
+	if !ok {
+		return nil, malformed
+	}
+	handshake.user = user
+
+	// The client authenticates with an empty password, skip its answer.
+	switch {
+	case handshake.capabilities&mysqlClientPluginAuthLenEncClientData != 0:
+		length, n := readMySQLLengthEncodedInteger(rest)
+		if n == 0 || uint64(len(rest)-n) < length {
+			return nil, malformed
+		}
+		rest = rest[n+int(length):]
+	case handshake.capabilities&mysqlClientSecureConnection != 0:
+		if len(rest) == 0 || len(rest)-1 < int(rest[0]) {
+			return nil, malformed
+		}
+		rest = rest[1+int(rest[0]):]
+	default:
+		_, rest, ok = bytes.Cut(rest, []byte{0})
+		if !ok {
+			return nil, malformed
+		}
+	}
+
+	if handshake.capabilities&mysqlClientConnectWithDB != 0 {
+		handshake.database, rest, ok = bytes.Cut(rest, []byte{0})
+		if !ok {
+			return nil, malformed
+		}
+	}
+
+	if handshake.capabilities&mysqlClientPluginAuth != 0 {
+		_, rest, _ = bytes.Cut(rest, []byte{0})
+	}
+
+	if handshake.capabilities&mysqlClientConnectAttrs != 0 {
+		handshake.connectAttrs = rest
+	}
+
+	return handshake, nil
+}
+
+func (h *mysqlHandshakeResponse) encode() []byte {
+	packet := make([]byte, mysqlHandshakeResponseHeaderLength)
+	copy(packet, h.header)
+	binary.LittleEndian.PutUint32(packet[0:4], h.capabilities)
+
+	packet = append(packet, h.user...)
+	packet = append(packet, 0)
+
+	if h.capabilities&mysqlClientPluginAuthLenEncClientData != 0 {
+		packet = appendMySQLLengthEncodedInteger(packet, uint64(len(h.authResponse)))
+	} else {
+		packet = append(packet, byte(len(h.authResponse)))
+	}
+	packet = append(packet, h.authResponse...)
+
+	if h.capabilities&mysqlClientConnectWithDB != 0 {
+		packet = append(packet, h.database...)
+		packet = append(packet, 0)
+	}
+
+	packet = append(packet, h.pluginName...)
+	packet = append(packet, 0)
+
+	if h.capabilities&mysqlClientConnectAttrs != 0 {
+		packet = append(packet, h.connectAttrs...)
+	}
+
+	return packet
+}
+
+// mysqlGreetingCapabilities returns the capabilities announced in a protocol 10 greeting.
+func mysqlGreetingCapabilities(greeting []byte) (uint32, error) {
+	lower, upper, err := mysqlGreetingCapabilitiesOffsets(greeting)
+	if err != nil {
+		return 0, err
+	}
+
+	capabilities := uint32(binary.LittleEndian.Uint16(greeting[lower:]))
+	if upper > 0 {
+		capabilities |= uint32(binary.LittleEndian.Uint16(greeting[upper:])) << 16
+	}
+	return capabilities, nil
+}
+
+func setMySQLGreetingCapabilities(greeting []byte, capabilities uint32) error {
+	lower, upper, err := mysqlGreetingCapabilitiesOffsets(greeting)
+	if err != nil {
+		return err
+	}
+
+	binary.LittleEndian.PutUint16(greeting[lower:], uint16(capabilities))
+	if upper > 0 {
+		binary.LittleEndian.PutUint16(greeting[upper:], uint16(capabilities>>16))
+	}
+	return nil
+}
+
+// mysqlGreetingCapabilitiesOffsets returns the offsets of the lower and upper capability flags of a greeting.
+// The upper offset is 0 if the greeting doesn't hold the upper flags.
+func mysqlGreetingCapabilitiesOffsets(greeting []byte) (int, int, error) {
+	const protocolVersion = 10
+
+	if len(greeting) == 0 || greeting[0] != protocolVersion {
+		return 0, 0, errors.New("unsupported mysql protocol version")
+	}
+
+	end := bytes.IndexByte(greeting[1:], 0)
+	if end < 0 {
+		return 0, 0, errors.New("malformed datastore greeting")
+	}
+
+	// The server version is followed by the connection id, the first part of the auth data and a filler.
+	lower := 1 + end + 1 + 4 + 8 + 1
+	if len(greeting) < lower+2 {
+		return 0, 0, errors.New("malformed datastore greeting")
+	}
+
+	// The lower flags are followed by the character set and the status flags.
+	upper := lower + 2 + 1 + 2
+	if len(greeting) < upper+2 {
+		return lower, 0, nil
+	}
+	return lower, upper, nil
+}
+
+func readMySQLPacket(conn net.Conn) ([]byte, byte, error) {
+	header := make([]byte, 4)
+	_, err := io.ReadFull(conn, header)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	packet := make([]byte, length)
+	_, err = io.ReadFull(conn, packet)
+	if err != nil {
+		return nil, 0, err
+	}
+	return packet, header[3], nil
+}
+
+func writeMySQLPacket(conn net.Conn, seq byte, payload []byte) error {
+	packet := make([]byte, 4, 4+len(payload))
+	packet[0] = byte(len(payload))
+	packet[1] = byte(len(payload) >> 8)
+	packet[2] = byte(len(payload) >> 16)
+	packet[3] = seq
+	packet = append(packet, payload...)
+
+	_, err := conn.Write(packet)
+	return err
+}
+
+// readMySQLLengthEncodedInteger returns the integer and the number of bytes read, 0 if the integer is malformed.
+func readMySQLLengthEncodedInteger(b []byte) (uint64, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+
+	size := 0
+	switch b[0] {
+	case 0xfc:
+		size = 2
+	case 0xfd:
+		size = 3
+	case 0xfe:
+		size = 8
+	default:
+		return uint64(b[0]), 1
+	}
+
+	if len(b) < 1+size {
+		return 0, 0
+	}
+
+	var value uint64
+	for i := size; i > 0; i-- {
+		value = value<<8 | uint64(b[i])
+	}
+	return value, 1 + size
+}
+
+func appendMySQLLengthEncodedInteger(b []byte, value uint64) []byte {
+	switch {
+	case value < 251:
+		return append(b, byte(value))
+	case value < 1<<16:
+		return append(b, 0xfc, byte(value), byte(value>>8))
+	case value < 1<<24:
+		return append(b, 0xfd, byte(value), byte(value>>8), byte(value>>16))
+	default:
+		b = append(b, 0xfe)
+		return binary.LittleEndian.AppendUint64(b, value)
+	}
+}
+
+// mysqlErrorMessage returns the message of an error packet.
+func mysqlErrorMessage(packet []byte) string {
+	// The error code is followed by the SQL state marker and the SQL state.
+	if len(packet) > 9 && packet[3] == '#' {
+		return string(packet[9:])
+	}
+	if len(packet) > 3 {
+		return string(packet[3:])
+	}
+	return ""
+}
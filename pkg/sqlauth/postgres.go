@@ -0,0 +1,217 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqlauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/alexandrevilain/temporal-operator/pkg/datastoretls"
+)
+
+const (
+	postgresSSLRequestCode    = 80877103
+	postgresGSSENCRequestCode = 80877104
+	postgresCancelRequestCode = 80877102
+	// postgresMaxStartupLength is the maximum length of a startup message accepted by postgres.
+	postgresMaxStartupLength = 10000
+
+	postgresAuthenticationMessage = 'R'
+	postgresErrorMessage          = 'E'
+	postgresPasswordMessage       = 'p'
+
+	postgresAuthenticationOk                = 0
+	postgresAuthenticationCleartextPassword = 3
+	postgresAuthenticationMD5Password       = 5
+)
+
+// postgresHandshake forwards the client startup message to the datastore and answers
+// the password requests using the token, the client only receives the authentication result.
+func (p *Proxy) postgresHandshake(ctx context.Context, client, conn net.Conn, token string) (net.Conn, error) {
+	var startup []byte
+	for startup == nil {
+		msg, err := readPostgresStartupMessage(client)
+		if err != nil {
+			return nil, fmt.Errorf("can't read client startup message: %w", err)
+		}
+
+		switch binary.BigEndian.Uint32(msg[4:8]) {
+		case postgresSSLRequestCode, postgresGSSENCRequestCode:
+			// The client connects to the proxy from within the pod, encryption isn't needed.
+			_, err = client.Write([]byte{'N'})
+			if err != nil {
+				return nil, err
+			}
+		default:
+			startup = msg
+		}
+	}
+
+	upstream, err := p.startTLS(ctx, conn, func() error { return datastoretls.PostgresStartTLS(conn) })
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = upstream.Write(startup)
+	if err != nil {
+		return nil, fmt.Errorf("can't send startup message: %w", err)
+	}
+
+	// Cancel requests don't get any answer.
+	if binary.BigEndian.Uint32(startup[4:8]) == postgresCancelRequestCode {
+		return upstream, nil
+	}
+
+	user := postgresStartupParameter(startup, "user")
+
+	for {
+		msgType, msg, err := readPostgresMessage(upstream)
+		if err != nil {
+			return nil, fmt.Errorf("can't read datastore message: %w", err)
+		}
+
+		switch msgType {
+		case postgresAuthenticationMessage:
+			body := msg[5:]
+			if len(body) < 4 {
+				return nil, errors.New("malformed authentication message")
+			}
+
+			switch code := binary.BigEndian.Uint32(body[0:4]); code {
+			case postgresAuthenticationOk:
+				_, err = client.Write(msg)
+				return upstream, err
+			case postgresAuthenticationCleartextPassword:
+				err = writePostgresMessage(upstream, postgresPasswordMessage, append([]byte(token), 0))
+			case postgresAuthenticationMD5Password:
+				if len(body) < 8 {
+					return nil, errors.New("malformed md5 authentication message")
+				}
+				err = writePostgresMessage(upstream, postgresPasswordMessage, append([]byte(postgresMD5Password(user, token, body[4:8])), 0))
+			default:
+				return nil, fmt.Errorf("unsupported authentication method %d, the datastore must ask for a password", code)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("can't send token: %w", err)
+			}
+		case postgresErrorMessage:
+			_, _ = client.Write(msg)
+			return nil, fmt.Errorf("datastore rejected the connection: %s", postgresErrorField(msg[5:], 'M'))
+		default:
+			// Forward the other messages, like protocol version negotiation, as is.
+			_, err = client.Write(msg)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// readPostgresStartupMessage reads a message without type, only sent by clients when opening connections.
+func readPostgresStartupMessage(conn net.Conn) ([]byte, error) {
+	header := make([]byte, 4)
+	_, err := io.ReadFull(conn, header)
+	if err != nil {
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header)
+	if length < 8 || length > postgresMaxStartupLength {
+		return nil, fmt.Errorf("invalid startup message length %d", length)
+	}
+
+	msg := make([]byte, length)
+	copy(msg, header)
+	_, err = io.ReadFull(conn, msg[4:])
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// readPostgresMessage reads a typed message, it returns the message including its header.
+func readPostgresMessage(conn net.Conn) (byte, []byte, error) {
+	header := make([]byte, 5)
+	_, err := io.ReadFull(conn, header)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header[1:5])
+	if length < 4 {
+		return 0, nil, fmt.Errorf("invalid message length %d", length)
+	}
+
+	msg := make([]byte, 1+length)
+	copy(msg, header)
+	_, err = io.ReadFull(conn, msg[5:])
+	if err != nil {
+		return 0, nil, err
+	}
+	return header[0], msg, nil
+}
+
+func writePostgresMessage(conn net.Conn, msgType byte, body []byte) error {
+	msg := make([]byte, 5, 5+len(body))
+	msg[0] = msgType
+	binary.BigEndian.PutUint32(msg[1:5], uint32(4+len(body)))
+	msg = append(msg, body...)
+
+	_, err := conn.Write(msg)
+	return err
+}
+
+// postgresStartupParameter returns the value of the provided parameter of a startup message.
+func postgresStartupParameter(startup []byte, name string) string {
+	fields := bytes.Split(startup[8:], []byte{0})
+	for i := 0; i+1 < len(fields); i += 2 {
+		if string(fields[i]) == name {
+			return string(fields[i+1])
+		}
+	}
+	return ""
+}
+
+// postgresErrorField returns the value of the provided field of an error message.
+func postgresErrorField(body []byte, field byte) string {
+	for len(body) > 0 && body[0] != 0 {
+		end := bytes.IndexByte(body[1:], 0)
+		if end < 0 {
+			return ""
+		}
+		if body[0] == field {
+			return string(body[1 : end+1])
+		}
+		body = body[end+2:]
+	}
+	return ""
+}
+
+// postgresMD5Password returns the md5 password answer: "md5" + md5(md5(password + user) + salt).
+func postgresMD5Password(user, password string, salt []byte) string {
+	inner := md5.Sum([]byte(password + user))                               //nolint:gosec
+	outer := md5.Sum(append([]byte(hex.EncodeToString(inner[:])), salt...)) //nolint:gosec
+	return "md5" + hex.EncodeToString(outer[:])
+}
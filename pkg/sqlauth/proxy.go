@@ -0,0 +1,178 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqlauth
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/datastoretls"
+	"go.temporal.io/server/common/log"
+	"go.temporal.io/server/common/log/tag"
+)
+
+// dialTimeout is the timeout to connect and authenticate with the datastore.
+const dialTimeout = 30 * time.Second
+
+// Proxy forwards the connections of the temporal services to a SQL datastore,
+// authenticating each of them with a fresh token.
+// The services connect to the proxy without password nor TLS, the proxy
+// connects to the datastore using the datastore TLS settings.
+type Proxy struct {
+	spec      *v1beta1.DatastoreSpec
+	tokens    TokenSource
+	tlsConfig *tls.Config
+	logger    log.Logger
+}
+
+// NewProxy returns a proxy for the provided datastore.
+func NewProxy(spec *v1beta1.DatastoreSpec, tokens TokenSource, logger log.Logger) (*Proxy, error) {
+	if spec.SQL == nil {
+		return nil, errors.New("datastore is not a SQL datastore")
+	}
+
+	proxy := &Proxy{
+		spec:   spec,
+		tokens: tokens,
+		logger: logger,
+	}
+
+	if spec.TLS != nil && spec.TLS.Enabled {
+		host, _, err := net.SplitHostPort(spec.SQL.ConnectAddr)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse connect address: %w", err)
+		}
+
+		cfg, err := datastoretls.ClientConfig(spec, host)
+		if err != nil {
+			return nil, err
+		}
+		proxy.tlsConfig = cfg
+	}
+
+	return proxy, nil
+}
+
+// Serve accepts connections on the provided listener until the context is done.
+func (p *Proxy) Serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("can't accept connection: %w", err)
+		}
+
+		go p.handle(ctx, conn)
+	}
+}
+
+func (p *Proxy) handle(ctx context.Context, client net.Conn) {
+	defer client.Close()
+
+	upstream, err := p.connect(ctx, client)
+	if err != nil {
+		p.logger.Warn("Unable to proxy connection", tag.NewStringTag("datastore", p.spec.Name), tag.Error(err))
+		return
+	}
+	defer upstream.Close()
+
+	done := make(chan struct{}, 2)
+	forward := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		done <- struct{}{}
+	}
+
+	go forward(upstream, client)
+	go forward(client, upstream)
+
+	// Closing both connections once one side is done unblocks the other copy.
+	<-done
+}
+
+// connect opens an authenticated connection to the datastore on behalf of the client.
+func (p *Proxy) connect(ctx context.Context, client net.Conn) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", p.spec.SQL.ConnectAddr)
+	if err != nil {
+		return nil, fmt.Errorf("can't connect to %s: %w", p.spec.SQL.ConnectAddr, err)
+	}
+
+	token, err := p.tokens.Token(ctx)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("can't get token: %w", err)
+	}
+
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+	_ = client.SetDeadline(deadline)
+
+	var upstream net.Conn
+	switch p.spec.GetType() {
+	case v1beta1.MySQLDatastore, v1beta1.MySQL8Datastore:
+		upstream, err = p.mysqlHandshake(ctx, client, conn, token)
+	default:
+		upstream, err = p.postgresHandshake(ctx, client, conn, token)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	_ = upstream.SetDeadline(time.Time{})
+	_ = client.SetDeadline(time.Time{})
+
+	return upstream, nil
+}
+
+// startTLS upgrades the connection to the datastore when TLS is enabled,
+// after running the protocol specific negotiation.
+func (p *Proxy) startTLS(ctx context.Context, conn net.Conn, negotiate func() error) (net.Conn, error) {
+	if p.tlsConfig == nil {
+		return conn, nil
+	}
+
+	err := negotiate()
+	if err != nil {
+		return nil, fmt.Errorf("can't negotiate TLS: %w", err)
+	}
+
+	tlsConn := tls.Client(conn, p.tlsConfig)
+	err = tlsConn.HandshakeContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("TLS handshake failed: %w", err)
+	}
+
+	return tlsConn, nil
+}
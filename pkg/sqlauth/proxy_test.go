@@ -0,0 +1,297 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqlauth_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/sqlauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/server/common/log"
+)
+
+const testToken = "token"
+
+type staticTokenSource string
+
+func (s staticTokenSource) Token(_ context.Context) (string, error) {
+	return string(s), nil
+}
+
+// startProxy starts a proxy forwarding connections to a fake datastore running the provided handler.
+func startProxy(t *testing.T, pluginName string, handler func(conn net.Conn)) net.Conn {
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { upstream.Close() })
+
+	go func() {
+		conn, err := upstream.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handler(conn)
+	}()
+
+	spec := &v1beta1.DatastoreSpec{
+		Name: "default",
+		SQL: &v1beta1.SQLSpec{
+			User:        "temporal",
+			PluginName:  pluginName,
+			ConnectAddr: upstream.Addr().String(),
+		},
+	}
+
+	proxy, err := sqlauth.NewProxy(spec, staticTokenSource(testToken), log.NewNoopLogger())
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = proxy.Serve(ctx, listener) }()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func readPostgresMessage(t *testing.T, conn net.Conn) (byte, []byte) {
+	header := make([]byte, 5)
+	_, err := io.ReadFull(conn, header)
+	require.NoError(t, err)
+
+	body := make([]byte, binary.BigEndian.Uint32(header[1:5])-4)
+	_, err = io.ReadFull(conn, body)
+	require.NoError(t, err)
+	return header[0], body
+}
+
+func writePostgresMessage(t *testing.T, conn net.Conn, msgType byte, body []byte) {
+	msg := []byte{msgType, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(msg[1:5], uint32(4+len(body)))
+	_, err := conn.Write(append(msg, body...))
+	require.NoError(t, err)
+}
+
+func postgresAuthenticationRequest(code uint32, data ...byte) []byte {
+	return append(binary.BigEndian.AppendUint32(nil, code), data...)
+}
+
+func TestProxyPostgres(t *testing.T) {
+	salt := []byte{1, 2, 3, 4}
+	inner := md5.Sum([]byte(testToken + "temporal"))                        //nolint:gosec
+	outer := md5.Sum(append([]byte(hex.EncodeToString(inner[:])), salt...)) //nolint:gosec
+
+	tests := map[string]struct {
+		authenticationRequest []byte
+		expectedPassword      string
+		reject                bool
+		expectedType          byte
+	}{
+		"cleartext password": {
+			authenticationRequest: postgresAuthenticationRequest(3),
+			expectedPassword:      testToken,
+			expectedType:          'R',
+		},
+		"md5 password": {
+			authenticationRequest: postgresAuthenticationRequest(5, salt...),
+			expectedPassword:      "md5" + hex.EncodeToString(outer[:]),
+			expectedType:          'R',
+		},
+		"rejected token": {
+			authenticationRequest: postgresAuthenticationRequest(3),
+			expectedPassword:      testToken,
+			reject:                true,
+			expectedType:          'E',
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			client := startProxy(tt, "postgres12", func(conn net.Conn) {
+				header := make([]byte, 4)
+				_, err := io.ReadFull(conn, header)
+				assert.NoError(tt, err)
+				startup := make([]byte, binary.BigEndian.Uint32(header)-4)
+				_, err = io.ReadFull(conn, startup)
+				assert.NoError(tt, err)
+				assert.Contains(tt, string(startup), "user\x00temporal\x00")
+
+				writePostgresMessage(tt, conn, 'R', test.authenticationRequest)
+
+				msgType, body := readPostgresMessage(tt, conn)
+				assert.Equal(tt, byte('p'), msgType)
+				assert.Equal(tt, test.expectedPassword, string(bytes.TrimSuffix(body, []byte{0})))
+
+				if test.reject {
+					writePostgresMessage(tt, conn, 'E', []byte("SFATAL\x00Mpassword authentication failed\x00\x00"))
+					return
+				}
+				writePostgresMessage(tt, conn, 'R', postgresAuthenticationRequest(0))
+				writePostgresMessage(tt, conn, 'Z', []byte{'I'})
+			})
+
+			// The proxy refuses TLS, the client then sends its startup message in plaintext.
+			_, err := client.Write([]byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f})
+			require.NoError(tt, err)
+			response := make([]byte, 1)
+			_, err = io.ReadFull(client, response)
+			require.NoError(tt, err)
+			assert.Equal(tt, byte('N'), response[0])
+
+			params := []byte("user\x00temporal\x00database\x00temporal\x00\x00")
+			startup := binary.BigEndian.AppendUint32(nil, uint32(8+len(params)))
+			startup = binary.BigEndian.AppendUint32(startup, 196608)
+			_, err = client.Write(append(startup, params...))
+			require.NoError(tt, err)
+
+			msgType, body := readPostgresMessage(tt, client)
+			require.Equal(tt, test.expectedType, msgType)
+			if test.reject {
+				assert.Contains(tt, string(body), "password authentication failed")
+				return
+			}
+
+			assert.Equal(tt, postgresAuthenticationRequest(0), body)
+			msgType, _ = readPostgresMessage(tt, client)
+			assert.Equal(tt, byte('Z'), msgType)
+		})
+	}
+}
+
+func readMySQLPacket(t *testing.T, conn net.Conn) ([]byte, byte) {
+	header := make([]byte, 4)
+	_, err := io.ReadFull(conn, header)
+	require.NoError(t, err)
+
+	packet := make([]byte, int(header[0])|int(header[1])<<8|int(header[2])<<16)
+	_, err = io.ReadFull(conn, packet)
+	require.NoError(t, err)
+	return packet, header[3]
+}
+
+func writeMySQLPacket(t *testing.T, conn net.Conn, seq byte, payload []byte) {
+	header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), seq}
+	_, err := conn.Write(append(header, payload...))
+	require.NoError(t, err)
+}
+
+func TestProxyMySQL(t *testing.T) {
+	const (
+		clientConnectWithDB       = 0x00000008
+		clientSSL                 = 0x00000800
+		clientProtocol41          = 0x00000200
+		clientSecureConnection    = 0x00008000
+		clientPluginAuth          = 0x00080000
+		clientPluginAuthLenEncode = 0x00200000
+	)
+
+	serverCapabilities := uint32(clientConnectWithDB | clientSSL | clientProtocol41 | clientSecureConnection | clientPluginAuth | clientPluginAuthLenEncode)
+
+	// Protocol version, server version, connection id, auth data, filler, lower capabilities,
+	// character set, status, upper capabilities, auth data length, reserved, auth data and plugin name.
+	greeting := []byte{10}
+	greeting = append(greeting, "8.0.36\x00"...)
+	greeting = append(greeting, 1, 0, 0, 0)
+	greeting = append(greeting, "12345678"...)
+	greeting = append(greeting, 0)
+	greeting = binary.LittleEndian.AppendUint16(greeting, uint16(serverCapabilities))
+	greeting = append(greeting, 45, 2, 0)
+	greeting = binary.LittleEndian.AppendUint16(greeting, uint16(serverCapabilities>>16))
+	greeting = append(greeting, 21)
+	greeting = append(greeting, make([]byte, 10)...)
+	greeting = append(greeting, "123456789012\x00"...)
+	greeting = append(greeting, "caching_sha2_password\x00"...)
+
+	tests := map[string]struct {
+		authSwitch bool
+	}{
+		"token sent in handshake response": {},
+		"token sent after auth switch": {
+			authSwitch: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			client := startProxy(tt, "mysql8", func(conn net.Conn) {
+				writeMySQLPacket(tt, conn, 0, append([]byte{}, greeting...))
+
+				response, seq := readMySQLPacket(tt, conn)
+				assert.Equal(tt, byte(1), seq)
+
+				capabilities := binary.LittleEndian.Uint32(response[0:4])
+				assert.NotZero(tt, capabilities&clientPluginAuthLenEncode)
+
+				rest := response[32:]
+				user, rest, _ := bytes.Cut(rest, []byte{0})
+				assert.Equal(tt, "temporal", string(user))
+				assert.Equal(tt, byte(len(testToken)+1), rest[0])
+				assert.Equal(tt, testToken+"\x00", string(rest[1:1+len(testToken)+1]))
+				rest = rest[1+len(testToken)+1:]
+				database, rest, _ := bytes.Cut(rest, []byte{0})
+				assert.Equal(tt, "temporal", string(database))
+				plugin, _, _ := bytes.Cut(rest, []byte{0})
+				assert.Equal(tt, "mysql_clear_password", string(plugin))
+
+				seq++
+				if test.authSwitch {
+					writeMySQLPacket(tt, conn, seq, []byte("\xfemysql_clear_password\x00"))
+					password, passwordSeq := readMySQLPacket(tt, conn)
+					assert.Equal(tt, seq+1, passwordSeq)
+					assert.Equal(tt, testToken+"\x00", string(password))
+					seq = passwordSeq + 1
+				}
+
+				writeMySQLPacket(tt, conn, seq, []byte{0, 0, 0, 2, 0, 0, 0})
+			})
+
+			received, seq := readMySQLPacket(tt, client)
+			assert.Equal(tt, byte(0), seq)
+			capabilities := uint32(binary.LittleEndian.Uint16(received[1+7+4+8+1:]))
+			assert.Zero(tt, capabilities&clientSSL, "TLS must not be offered to the client")
+
+			response := binary.LittleEndian.AppendUint32(nil, clientConnectWithDB|clientProtocol41|clientSecureConnection|clientPluginAuth)
+			response = binary.LittleEndian.AppendUint32(response, 1<<24)
+			response = append(response, 45)
+			response = append(response, make([]byte, 23)...)
+			response = append(response, "temporal\x00"...)
+			response = append(response, 0)
+			response = append(response, "temporal\x00"...)
+			response = append(response, "caching_sha2_password\x00"...)
+			writeMySQLPacket(tt, client, 1, response)
+
+			ok, seq := readMySQLPacket(tt, client)
+			assert.Equal(tt, byte(2), seq)
+			assert.Equal(tt, byte(0), ok[0])
+		})
+	}
+}
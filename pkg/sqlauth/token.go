@@ -0,0 +1,246 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package sqlauth authenticates with SQL datastores using short-lived cloud provider tokens.
+// Tokens are sent as the connection password, either by the schema runner directly
+// or by the proxy running next to the temporal services.
+package sqlauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rds/rdsutils"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// gcpSQLLoginScope is the OAuth2 scope required to log in Cloud SQL instances.
+	gcpSQLLoginScope = "https://www.googleapis.com/auth/sqlservice.login"
+
+	// azureOSSRDBMSScope is the scope of Azure Database for PostgreSQL and MySQL tokens.
+	azureOSSRDBMSScope        = "https://ossrdbms-aad.database.windows.net/.default"
+	azureDefaultAuthorityHost = "https://login.microsoftonline.com/"
+	azureClientAssertionType  = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	azureTokenRequestTimeout  = 30 * time.Second
+	azureTokenExpiryDelta     = 5 * time.Minute
+
+	// Environment variables injected by the azure workload identity webhook.
+	azureClientIDEnvVar       = "AZURE_CLIENT_ID"
+	azureTenantIDEnvVar       = "AZURE_TENANT_ID"
+	azureFederatedTokenEnvVar = "AZURE_FEDERATED_TOKEN_FILE"
+	azureAuthorityHostEnvVar  = "AZURE_AUTHORITY_HOST"
+
+	// mysqlAllowCleartextPasswords allows the mysql driver to send the token using the cleartext authentication plugin.
+	mysqlAllowCleartextPasswords = "allowCleartextPasswords"
+)
+
+// TokenSource provides the tokens used as password to authenticate with a SQL datastore.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// NewTokenSource returns the token source matching the authentication mode of the provided datastore.
+func NewTokenSource(ctx context.Context, spec *v1beta1.DatastoreSpec) (TokenSource, error) {
+	if !spec.IsSQLTokenAuth() {
+		return nil, errors.New("datastore doesn't authenticate with tokens")
+	}
+
+	auth := spec.SQL.Auth
+	switch {
+	case auth.AWSIAM != nil:
+		return newAWSTokenSource(spec)
+	case auth.AzureAD != nil:
+		return newAzureTokenSource(auth.AzureAD)
+	case auth.GCPIAM != nil:
+		return newGCPTokenSource(ctx)
+	default:
+		return nil, errors.New("no authentication mode set")
+	}
+}
+
+// ConnectAttributes returns the connect attributes needed to send a token as password to the provided datastore.
+// MySQL drivers refuse to send cleartext passwords unless explicitly allowed.
+func ConnectAttributes(spec *v1beta1.DatastoreSpec) map[string]string {
+	attributes := make(map[string]string, len(spec.SQL.ConnectAttributes)+1)
+	for key, value := range spec.SQL.ConnectAttributes {
+		attributes[key] = value
+	}
+
+	if spec.GetType() == v1beta1.MySQLDatastore || spec.GetType() == v1beta1.MySQL8Datastore {
+		attributes[mysqlAllowCleartextPasswords] = "true"
+	}
+
+	return attributes
+}
+
+// awsTokenSource generates RDS IAM authentication tokens.
+// Tokens are signed locally using the pod credentials, each connection gets a new one.
+type awsTokenSource struct {
+	endpoint    string
+	region      string
+	user        string
+	credentials *credentials.Credentials
+}
+
+func newAWSTokenSource(spec *v1beta1.DatastoreSpec) (*awsTokenSource, error) {
+	// The default credentials chain uses the web identity token mounted by IRSA.
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(spec.SQL.Auth.AWSIAM.Region),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't create aws session: %w", err)
+	}
+
+	return &awsTokenSource{
+		endpoint:    spec.SQL.ConnectAddr,
+		region:      spec.SQL.Auth.AWSIAM.Region,
+		user:        spec.SQL.User,
+		credentials: sess.Config.Credentials,
+	}, nil
+}
+
+func (s *awsTokenSource) Token(_ context.Context) (string, error) {
+	token, err := rdsutils.BuildAuthToken(s.endpoint, s.region, s.user, s.credentials)
+	if err != nil {
+		return "", fmt.Errorf("can't build rds auth token: %w", err)
+	}
+	return token, nil
+}
+
+// oauth2TokenSource returns the access token of an OAuth2 token source.
+type oauth2TokenSource struct {
+	source oauth2.TokenSource
+}
+
+func (s *oauth2TokenSource) Token(_ context.Context) (string, error) {
+	token, err := s.source.Token()
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+func newGCPTokenSource(ctx context.Context) (*oauth2TokenSource, error) {
+	// On GKE, the default credentials are the ones of the GCP service account bound using workload identity.
+	source, err := google.DefaultTokenSource(ctx, gcpSQLLoginScope)
+	if err != nil {
+		return nil, fmt.Errorf("can't find gcp credentials: %w", err)
+	}
+	return &oauth2TokenSource{source: source}, nil
+}
+
+// azureTokenSource exchanges the federated token projected by Azure workload identity for an Entra ID token.
+type azureTokenSource struct {
+	client        *http.Client
+	authorityHost string
+	tenantID      string
+	clientID      string
+	tokenFile     string
+}
+
+func newAzureTokenSource(spec *v1beta1.SQLAzureADAuthSpec) (*oauth2TokenSource, error) {
+	// The workload identity webhook injects the environment variables matching the service account annotations.
+	source := &azureTokenSource{
+		client:        &http.Client{Timeout: azureTokenRequestTimeout},
+		authorityHost: os.Getenv(azureAuthorityHostEnvVar),
+		tenantID:      spec.TenantID,
+		clientID:      spec.ClientID,
+		tokenFile:     os.Getenv(azureFederatedTokenEnvVar),
+	}
+
+	if source.authorityHost == "" {
+		source.authorityHost = azureDefaultAuthorityHost
+	}
+	if source.tenantID == "" {
+		source.tenantID = os.Getenv(azureTenantIDEnvVar)
+	}
+	if source.clientID == "" {
+		source.clientID = os.Getenv(azureClientIDEnvVar)
+	}
+
+	if source.tenantID == "" || source.tokenFile == "" {
+		return nil, fmt.Errorf("%s and %s must be set, is azure workload identity enabled for the pod?", azureTenantIDEnvVar, azureFederatedTokenEnvVar)
+	}
+
+	return &oauth2TokenSource{
+		source: oauth2.ReuseTokenSourceWithExpiry(nil, source, azureTokenExpiryDelta),
+	}, nil
+}
+
+type azureTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (s *azureTokenSource) Token() (*oauth2.Token, error) {
+	// The federated token is rotated by the kubelet, read it for each exchange.
+	assertion, err := os.ReadFile(s.tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("can't read federated token: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_id":             {s.clientID},
+		"client_assertion_type": {azureClientAssertionType},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+		"scope":                 {azureOSSRDBMSScope},
+	}
+
+	endpoint := fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(s.authorityHost, "/"), s.tenantID)
+	resp, err := s.client.PostForm(endpoint, form)
+	if err != nil {
+		return nil, fmt.Errorf("can't request entra id token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("can't read entra id response: %w", err)
+	}
+
+	result := &azureTokenResponse{}
+	err = json.Unmarshal(content, result)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse entra id response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || result.AccessToken == "" {
+		return nil, fmt.Errorf("entra id returned %s: %s: %s", resp.Status, result.Error, result.ErrorDescription)
+	}
+
+	return &oauth2.Token{
+		AccessToken: result.AccessToken,
+		Expiry:      time.Now().Add(time.Duration(result.ExpiresIn) * time.Second),
+	}, nil
+}
@@ -0,0 +1,149 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqlauth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/sqlauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSTokenSource(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	spec := &v1beta1.DatastoreSpec{
+		SQL: &v1beta1.SQLSpec{
+			User:        "temporal",
+			ConnectAddr: "db.eu-west-1.rds.amazonaws.com:5432",
+			Auth: &v1beta1.SQLAuthSpec{
+				AWSIAM: &v1beta1.SQLAWSIAMAuthSpec{Region: "eu-west-1", Role: "arn:aws:iam::123456789012:role/temporal"},
+			},
+		},
+	}
+
+	tokens, err := sqlauth.NewTokenSource(context.Background(), spec)
+	require.NoError(t, err)
+
+	token, err := tokens.Token(context.Background())
+	require.NoError(t, err)
+
+	assert.Contains(t, token, "db.eu-west-1.rds.amazonaws.com:5432?Action=connect")
+	assert.Contains(t, token, "DBUser=temporal")
+	assert.Contains(t, token, "X-Amz-Credential=AKIAEXAMPLE")
+}
+
+func TestAzureTokenSource(t *testing.T) {
+	tests := map[string]struct {
+		status        int
+		response      string
+		expectedToken string
+		expectedErr   string
+	}{
+		"token is exchanged": {
+			status:        http.StatusOK,
+			response:      `{"access_token":"entra-token","expires_in":3600}`,
+			expectedToken: "entra-token",
+		},
+		"exchange error is reported": {
+			status:      http.StatusUnauthorized,
+			response:    `{"error":"invalid_client","error_description":"federated credential not found"}`,
+			expectedErr: "invalid_client: federated credential not found",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(tt, "/tenant/oauth2/v2.0/token", r.URL.Path)
+				assert.NoError(tt, r.ParseForm())
+				assert.Equal(tt, "client", r.PostForm.Get("client_id"))
+				assert.Equal(tt, "federated-token", r.PostForm.Get("client_assertion"))
+				assert.Equal(tt, "https://ossrdbms-aad.database.windows.net/.default", r.PostForm.Get("scope"))
+
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.response))
+			}))
+			defer server.Close()
+
+			tokenFile := filepath.Join(tt.TempDir(), "token")
+			require.NoError(tt, os.WriteFile(tokenFile, []byte("federated-token\n"), 0o600))
+
+			tt.Setenv("AZURE_AUTHORITY_HOST", server.URL)
+			tt.Setenv("AZURE_TENANT_ID", "tenant")
+			tt.Setenv("AZURE_FEDERATED_TOKEN_FILE", tokenFile)
+
+			spec := &v1beta1.DatastoreSpec{
+				SQL: &v1beta1.SQLSpec{
+					Auth: &v1beta1.SQLAuthSpec{
+						AzureAD: &v1beta1.SQLAzureADAuthSpec{ClientID: "client"},
+					},
+				},
+			}
+
+			tokens, err := sqlauth.NewTokenSource(context.Background(), spec)
+			require.NoError(tt, err)
+
+			token, err := tokens.Token(context.Background())
+			if test.expectedErr != "" {
+				require.ErrorContains(tt, err, test.expectedErr)
+				return
+			}
+			require.NoError(tt, err)
+			assert.Equal(tt, test.expectedToken, token)
+		})
+	}
+}
+
+func TestConnectAttributes(t *testing.T) {
+	tests := map[string]struct {
+		pluginName string
+		expected   map[string]string
+	}{
+		"postgres attributes are kept": {
+			pluginName: "postgres12",
+			expected:   map[string]string{"application_name": "temporal"},
+		},
+		"mysql allows cleartext passwords": {
+			pluginName: "mysql8",
+			expected:   map[string]string{"application_name": "temporal", "allowCleartextPasswords": "true"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			spec := &v1beta1.DatastoreSpec{
+				SQL: &v1beta1.SQLSpec{
+					PluginName:        test.pluginName,
+					ConnectAttributes: map[string]string{"application_name": "temporal"},
+				},
+			}
+
+			assert.Equal(tt, test.expected, sqlauth.ConnectAttributes(spec))
+			assert.Len(tt, spec.SQL.ConnectAttributes, 1)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package status
+
+import (
+	"time"
+
+	"github.com/alexandrevilain/controller-tools/pkg/resource"
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/internal/metadata"
+	"go.temporal.io/server/common/primitives"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// canaryPromotionPollInterval is the interval at which a canary promotion is checked.
+const canaryPromotionPollInterval = 5 * time.Second
+
+// canaryWorkload returns the canary deployment of the provided service from the list of reconciled objects.
+func canaryWorkload(c *v1beta1.TemporalCluster, serviceName string, objects []client.Object) client.Object {
+	for _, object := range objects {
+		if object.GetObjectKind().GroupVersionKind() != deployGVK {
+			continue
+		}
+		if object.GetName() == c.CanaryResourceName(serviceName) && object.GetNamespace() == c.GetNamespace() {
+			return object
+		}
+	}
+	return nil
+}
+
+// previousCanaryStatus returns the canary status of the provided service reported by the previous reconciliation.
+func previousCanaryStatus(c *v1beta1.TemporalCluster, serviceName string) *v1beta1.CanaryStatus {
+	for _, serviceStatus := range c.Status.Services {
+		if serviceStatus.Name == serviceName {
+			return serviceStatus.Canary
+		}
+	}
+	return nil
+}
+
+// stableWorkloadSelectsCanary returns true if the selector of the provided stable workload also matches the canary pods.
+func stableWorkloadSelectsCanary(stable client.Object) bool {
+	switch workload := stable.(type) {
+	case *appsv1.Deployment:
+		return !metadata.SelectsTrack(workload.Spec.Selector)
+	case *appsv1.StatefulSet:
+		return !metadata.SelectsTrack(workload.Spec.Selector)
+	default:
+		return false
+	}
+}
+
+// canaryStatus returns the status of the provided service canary.
+// The service status is used to know when a promoted canary configuration is rolled out to the stable pods.
+// The stable workload is used to know if the canary pods can run next to the stable pods.
+func canaryStatus(c *v1beta1.TemporalCluster, service primitives.ServiceName, serviceStatus *v1beta1.ServiceStatus, stable client.Object, objects []client.Object, now time.Time) (*v1beta1.CanaryStatus, error) {
+	if c.Spec.Services == nil {
+		return nil, nil
+	}
+
+	spec, err := c.Spec.Services.GetServiceSpec(service)
+	if err != nil {
+		return nil, err
+	}
+
+	if spec == nil || spec.Canary == nil {
+		return nil, nil
+	}
+
+	if spec.Canary.GetAction() == v1beta1.AbortCanaryAction {
+		return &v1beta1.CanaryStatus{Phase: v1beta1.AbortedCanaryPhase}, nil
+	}
+
+	serviceName := string(service)
+	previous := previousCanaryStatus(c, serviceName)
+
+	result := &v1beta1.CanaryStatus{}
+	if previous != nil {
+		result = previous.DeepCopy()
+	}
+
+	if object := canaryWorkload(c, serviceName, objects); object != nil {
+		status, err := resource.GetStatus(object)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Version = object.GetLabels()["app.kubernetes.io/version"]
+		result.Ready = status.Ready
+		if !status.Ready {
+			result.ReadySince = nil
+		} else if result.ReadySince == nil {
+			result.ReadySince = &metav1.Time{Time: now}
+		}
+	} else {
+		result.Ready = false
+		result.ReadySince = nil
+	}
+
+	switch spec.Canary.GetAction() {
+	case v1beta1.PromoteCanaryAction:
+		// The stable pods status is only trusted once the promotion was recorded by a previous reconciliation,
+		// as it may have been computed before the promoted configuration was applied.
+		promoting := previous != nil &&
+			(previous.Phase == v1beta1.PromotingCanaryPhase || previous.Phase == v1beta1.PromotedCanaryPhase)
+		rolledOut := serviceStatus.Ready && serviceStatus.Version == desiredServiceVersion(c, serviceName)
+
+		switch {
+		case previous != nil && previous.Phase == v1beta1.PromotedCanaryPhase:
+			result.Phase = v1beta1.PromotedCanaryPhase
+		case promoting && rolledOut:
+			result.Phase = v1beta1.PromotedCanaryPhase
+		default:
+			result.Phase = v1beta1.PromotingCanaryPhase
+		}
+	default:
+		switch {
+		case stableWorkloadSelectsCanary(stable):
+			result.Phase = v1beta1.BlockedCanaryPhase
+		case !result.Ready:
+			result.Phase = v1beta1.ProgressingCanaryPhase
+		case now.Sub(result.ReadySince.Time) >= canaryDuration(spec.Canary):
+			result.Phase = v1beta1.HealthyCanaryPhase
+		default:
+			result.Phase = v1beta1.ObservingCanaryPhase
+		}
+	}
+
+	return result, nil
+}
+
+func canaryDuration(canary *v1beta1.ServiceCanarySpec) time.Duration {
+	if canary.Duration == nil {
+		return 0
+	}
+	return canary.Duration.Duration
+}
+
+// CanaryRequeueAfter returns the duration after which the services canaries status has to be computed again.
+// It returns 0 if no canary status is expected to change without a change of the cluster resources.
+func CanaryRequeueAfter(c *v1beta1.TemporalCluster, objects []client.Object, now time.Time) time.Duration {
+	var requeueAfter time.Duration
+
+	for _, serviceStatus := range c.Status.Services {
+		if serviceStatus.Canary == nil || c.Spec.Services == nil {
+			continue
+		}
+
+		spec, err := c.Spec.Services.GetServiceSpec(primitives.ServiceName(serviceStatus.Name))
+		if err != nil || spec == nil || spec.Canary == nil {
+			continue
+		}
+
+		var after time.Duration
+		switch serviceStatus.Canary.Phase {
+		case v1beta1.ObservingCanaryPhase:
+			after = serviceStatus.Canary.ReadySince.Add(canaryDuration(spec.Canary)).Sub(now)
+		case v1beta1.PromotingCanaryPhase:
+			after = canaryPromotionPollInterval
+		case v1beta1.PromotedCanaryPhase:
+			// The canary deployment is deleted by the next reconciliation.
+			if canaryWorkload(c, serviceStatus.Name, objects) != nil {
+				after = time.Second
+			}
+		}
+
+		if after > 0 && (requeueAfter == 0 || after < requeueAfter) {
+			requeueAfter = after
+		}
+	}
+
+	return requeueAfter
+}
@@ -0,0 +1,302 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package status_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/status"
+	"github.com/alexandrevilain/temporal-operator/pkg/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func canaryTestDeployment(name, version string, ready bool) *appsv1.Deployment {
+	track := "stable"
+	if strings.HasSuffix(name, "-canary") {
+		track = "canary"
+	}
+
+	deployment := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				"app.kubernetes.io/version": version,
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app.kubernetes.io/track": track,
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 1,
+			UpdatedReplicas:    1,
+			Replicas:           1,
+		},
+	}
+	if ready {
+		deployment.Status.ReadyReplicas = 1
+		deployment.Status.AvailableReplicas = 1
+		deployment.Status.Conditions = []appsv1.DeploymentCondition{
+			{
+				Type:   appsv1.DeploymentAvailable,
+				Status: corev1.ConditionTrue,
+			},
+		}
+	}
+	return deployment
+}
+
+// legacyCanaryTestDeployment returns a ready stable deployment created before pods had a track label.
+func legacyCanaryTestDeployment(name, version string) *appsv1.Deployment {
+	deployment := canaryTestDeployment(name, version, true)
+	deployment.Spec.Selector.MatchLabels = map[string]string{
+		"app.kubernetes.io/name": "test",
+	}
+	return deployment
+}
+
+func canaryTestCluster(canary *v1beta1.ServiceCanarySpec, previous *v1beta1.CanaryStatus) *v1beta1.TemporalCluster {
+	return &v1beta1.TemporalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: v1beta1.TemporalClusterSpec{
+			Version: version.MustNewVersionFromString("1.24.0"),
+			Services: &v1beta1.ServicesSpec{
+				History: &v1beta1.ServiceSpec{
+					Canary: canary,
+				},
+			},
+		},
+		Status: v1beta1.TemporalClusterStatus{
+			Services: []v1beta1.ServiceStatus{
+				{
+					Name:    "history",
+					Version: "1.24.0",
+					Ready:   true,
+					Canary:  previous,
+				},
+			},
+		},
+	}
+}
+
+func TestCanaryStatus(t *testing.T) {
+	tests := map[string]struct {
+		canary        *v1beta1.ServiceCanarySpec
+		previous      *v1beta1.CanaryStatus
+		objects       []client.Object
+		expectedPhase v1beta1.CanaryPhase
+		expectedReady bool
+	}{
+		"canary not ready": {
+			canary: &v1beta1.ServiceCanarySpec{},
+			objects: []client.Object{
+				canaryTestDeployment("test-history", "1.24.0", true),
+				canaryTestDeployment("test-history-canary", "1.25.0", false),
+			},
+			expectedPhase: v1beta1.ProgressingCanaryPhase,
+		},
+		"canary ready for less than its duration": {
+			canary: &v1beta1.ServiceCanarySpec{
+				Duration: &metav1.Duration{Duration: time.Hour},
+			},
+			objects: []client.Object{
+				canaryTestDeployment("test-history", "1.24.0", true),
+				canaryTestDeployment("test-history-canary", "1.25.0", true),
+			},
+			expectedPhase: v1beta1.ObservingCanaryPhase,
+			expectedReady: true,
+		},
+		"canary ready for its duration": {
+			canary: &v1beta1.ServiceCanarySpec{
+				Duration: &metav1.Duration{Duration: time.Hour},
+			},
+			previous: &v1beta1.CanaryStatus{
+				Phase:      v1beta1.ObservingCanaryPhase,
+				Ready:      true,
+				ReadySince: &metav1.Time{Time: time.Now().Add(-2 * time.Hour)},
+			},
+			objects: []client.Object{
+				canaryTestDeployment("test-history", "1.24.0", true),
+				canaryTestDeployment("test-history-canary", "1.25.0", true),
+			},
+			expectedPhase: v1beta1.HealthyCanaryPhase,
+			expectedReady: true,
+		},
+		"stable workload selecting the canary pods": {
+			canary: &v1beta1.ServiceCanarySpec{},
+			objects: []client.Object{
+				legacyCanaryTestDeployment("test-history", "1.24.0"),
+			},
+			expectedPhase: v1beta1.BlockedCanaryPhase,
+		},
+		"promotion just requested": {
+			canary: &v1beta1.ServiceCanarySpec{
+				Action: v1beta1.PromoteCanaryAction,
+			},
+			previous: &v1beta1.CanaryStatus{
+				Phase: v1beta1.HealthyCanaryPhase,
+				Ready: true,
+			},
+			objects: []client.Object{
+				canaryTestDeployment("test-history", "1.24.0", true),
+				canaryTestDeployment("test-history-canary", "1.24.0", true),
+			},
+			expectedPhase: v1beta1.PromotingCanaryPhase,
+			expectedReady: true,
+		},
+		"promotion rolled out": {
+			canary: &v1beta1.ServiceCanarySpec{
+				Action: v1beta1.PromoteCanaryAction,
+			},
+			previous: &v1beta1.CanaryStatus{
+				Phase: v1beta1.PromotingCanaryPhase,
+				Ready: true,
+			},
+			objects: []client.Object{
+				canaryTestDeployment("test-history", "1.24.0", true),
+				canaryTestDeployment("test-history-canary", "1.24.0", true),
+			},
+			expectedPhase: v1beta1.PromotedCanaryPhase,
+			expectedReady: true,
+		},
+		"promotion of a new version not rolled out": {
+			canary: &v1beta1.ServiceCanarySpec{
+				Version: version.MustNewVersionFromString("1.25.0"),
+				Action:  v1beta1.PromoteCanaryAction,
+			},
+			previous: &v1beta1.CanaryStatus{
+				Phase: v1beta1.PromotingCanaryPhase,
+				Ready: true,
+			},
+			objects: []client.Object{
+				canaryTestDeployment("test-history", "1.24.0", true),
+				canaryTestDeployment("test-history-canary", "1.25.0", true),
+			},
+			expectedPhase: v1beta1.PromotingCanaryPhase,
+			expectedReady: true,
+		},
+		"canary aborted": {
+			canary: &v1beta1.ServiceCanarySpec{
+				Action: v1beta1.AbortCanaryAction,
+			},
+			previous: &v1beta1.CanaryStatus{
+				Phase: v1beta1.ObservingCanaryPhase,
+				Ready: true,
+			},
+			objects: []client.Object{
+				canaryTestDeployment("test-history", "1.24.0", true),
+			},
+			expectedPhase: v1beta1.AbortedCanaryPhase,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := canaryTestCluster(test.canary, test.previous)
+
+			statuses, err := status.ReconciledObjectsToServiceStatuses(cluster, test.objects)
+			require.NoError(tt, err)
+			require.Len(tt, statuses, 1)
+			require.NotNil(tt, statuses[0].Canary)
+
+			assert.Equal(tt, test.expectedPhase, statuses[0].Canary.Phase)
+			assert.Equal(tt, test.expectedReady, statuses[0].Canary.Ready)
+			assert.Equal(tt, test.expectedReady, statuses[0].Canary.ReadySince != nil)
+		})
+	}
+}
+
+func TestCanaryRequeueAfter(t *testing.T) {
+	now := time.Now()
+
+	tests := map[string]struct {
+		canary   *v1beta1.ServiceCanarySpec
+		status   *v1beta1.CanaryStatus
+		objects  []client.Object
+		expected time.Duration
+	}{
+		"no canary": {
+			expected: 0,
+		},
+		"observing canary": {
+			canary: &v1beta1.ServiceCanarySpec{
+				Duration: &metav1.Duration{Duration: time.Hour},
+			},
+			status: &v1beta1.CanaryStatus{
+				Phase:      v1beta1.ObservingCanaryPhase,
+				ReadySince: &metav1.Time{Time: now.Add(-10 * time.Minute)},
+			},
+			expected: 50 * time.Minute,
+		},
+		"healthy canary": {
+			canary: &v1beta1.ServiceCanarySpec{},
+			status: &v1beta1.CanaryStatus{
+				Phase: v1beta1.HealthyCanaryPhase,
+			},
+			expected: 0,
+		},
+		"promoted canary not deleted yet": {
+			canary: &v1beta1.ServiceCanarySpec{
+				Action: v1beta1.PromoteCanaryAction,
+			},
+			status: &v1beta1.CanaryStatus{
+				Phase: v1beta1.PromotedCanaryPhase,
+			},
+			objects: []client.Object{
+				canaryTestDeployment("test-history-canary", "1.24.0", true),
+			},
+			expected: time.Second,
+		},
+		"promoted canary deleted": {
+			canary: &v1beta1.ServiceCanarySpec{
+				Action: v1beta1.PromoteCanaryAction,
+			},
+			status: &v1beta1.CanaryStatus{
+				Phase: v1beta1.PromotedCanaryPhase,
+			},
+			expected: 0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := canaryTestCluster(test.canary, test.status)
+			assert.Equal(tt, test.expected, status.CanaryRequeueAfter(cluster, test.objects, now))
+		})
+	}
+}
@@ -18,6 +18,8 @@
 package status
 
 import (
+	"time"
+
 	"github.com/alexandrevilain/controller-tools/pkg/resource"
 	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
 	"go.temporal.io/server/common/primitives"
@@ -25,14 +27,29 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// desiredServiceVersion returns the desired version of the provided service.
+// Services may override the cluster version.
+func desiredServiceVersion(c *v1beta1.TemporalCluster, serviceName string) string {
+	if c.Spec.Services == nil {
+		return c.Spec.Version.String()
+	}
+
+	spec, err := c.Spec.Services.GetServiceSpec(primitives.ServiceName(serviceName))
+	if err != nil {
+		return c.Spec.Version.String()
+	}
+
+	return c.ServiceVersion(spec).String()
+}
+
 // ObservedVersionMatchesDesiredVersion returns true if all services status
-// versions are matching the desired cluster version.
+// versions are matching their desired version.
 func ObservedVersionMatchesDesiredVersion(c *v1beta1.TemporalCluster) bool {
 	if len(c.Status.Services) == 0 {
 		return false
 	}
 	for _, serviceStatus := range c.Status.Services {
-		if serviceStatus.Version != c.Spec.Version.String() {
+		if serviceStatus.Version != desiredServiceVersion(c, serviceStatus.Name) {
 			return false
 		}
 	}
@@ -45,7 +62,7 @@ func IsClusterReady(c *v1beta1.TemporalCluster) bool {
 		return false
 	}
 	for _, serviceStatus := range c.Status.Services {
-		if !serviceStatus.Ready || serviceStatus.Version != c.Spec.Version.String() {
+		if !serviceStatus.Ready || serviceStatus.Version != desiredServiceVersion(c, serviceStatus.Name) {
 			return false
 		}
 	}
@@ -58,8 +75,30 @@ var deployGVK = schema.GroupVersionKind{
 	Kind:    "Deployment",
 }
 
+var statefulSetGVK = schema.GroupVersionKind{
+	Group:   "apps",
+	Version: "v1",
+	Kind:    "StatefulSet",
+}
+
+// serviceWorkloadKind returns the workload kind of the provided service.
+func serviceWorkloadKind(c *v1beta1.TemporalCluster, service primitives.ServiceName) v1beta1.WorkloadKind {
+	if c.Spec.Services == nil {
+		return v1beta1.DeploymentWorkloadKind
+	}
+
+	spec, err := c.Spec.Services.GetServiceSpec(service)
+	if err != nil {
+		return v1beta1.DeploymentWorkloadKind
+	}
+
+	return spec.GetWorkloadKind()
+}
+
 // ReconciledObjectsToServiceStatuses returns a list of service statuses from a list of reconciled objects.
-// It filters for deployments and only returns the ones that match the cluster's services.
+// It filters for deployments and statefulsets and only returns the ones that match the cluster's services.
+// While a service is migrated to another workload kind, only the workload of the desired kind is reported.
+// Services canaries status is computed from their canary deployment.
 func ReconciledObjectsToServiceStatuses(c *v1beta1.TemporalCluster, objects []client.Object) ([]*v1beta1.ServiceStatus, error) {
 	services := []primitives.ServiceName{
 		primitives.FrontendService,
@@ -72,7 +111,8 @@ func ReconciledObjectsToServiceStatuses(c *v1beta1.TemporalCluster, objects []cl
 	result := []*v1beta1.ServiceStatus{}
 
 	for _, object := range objects {
-		if object.GetObjectKind().GroupVersionKind() != deployGVK {
+		gvk := object.GetObjectKind().GroupVersionKind()
+		if gvk != deployGVK && gvk != statefulSetGVK {
 			continue
 		}
 
@@ -83,6 +123,10 @@ func ReconciledObjectsToServiceStatuses(c *v1beta1.TemporalCluster, objects []cl
 				continue
 			}
 
+			if gvk.Kind != string(serviceWorkloadKind(c, service)) {
+				continue
+			}
+
 			version, ok := object.GetLabels()["app.kubernetes.io/version"]
 			if !ok {
 				version = "0.0.0"
@@ -93,11 +137,18 @@ func ReconciledObjectsToServiceStatuses(c *v1beta1.TemporalCluster, objects []cl
 				return nil, err
 			}
 
-			result = append(result, &v1beta1.ServiceStatus{
+			serviceStatus := &v1beta1.ServiceStatus{
 				Name:    serviceName,
 				Version: version,
 				Ready:   status.Ready,
-			})
+			}
+
+			serviceStatus.Canary, err = canaryStatus(c, service, serviceStatus, object, objects, time.Now())
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, serviceStatus)
 		}
 	}
 
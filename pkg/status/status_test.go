@@ -23,6 +23,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
 	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
 	"github.com/alexandrevilain/temporal-operator/pkg/status"
@@ -76,6 +77,52 @@ func TestObservedVersionMatchesDesiredVersion(t *testing.T) {
 			},
 			expected: false,
 		},
+		"service matches its overridden version": {
+			cluster: &v1beta1.TemporalCluster{
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.16.0"),
+					Services: &v1beta1.ServicesSpec{
+						History: &v1beta1.ServiceSpec{
+							Version: version.MustNewVersionFromString("1.16.1"),
+						},
+					},
+				},
+				Status: v1beta1.TemporalClusterStatus{
+					Services: []v1beta1.ServiceStatus{
+						{
+							Name:    "history",
+							Version: "1.16.1",
+						},
+						{
+							Name:    "matching",
+							Version: "1.16.0",
+						},
+					},
+				},
+			},
+			expected: true,
+		},
+		"service does not match its overridden version": {
+			cluster: &v1beta1.TemporalCluster{
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.16.0"),
+					Services: &v1beta1.ServicesSpec{
+						History: &v1beta1.ServiceSpec{
+							Version: version.MustNewVersionFromString("1.16.1"),
+						},
+					},
+				},
+				Status: v1beta1.TemporalClusterStatus{
+					Services: []v1beta1.ServiceStatus{
+						{
+							Name:    "history",
+							Version: "1.16.0",
+						},
+					},
+				},
+			},
+			expected: false,
+		},
 		"empty status": {
 			cluster: &v1beta1.TemporalCluster{
 				Spec: v1beta1.TemporalClusterSpec{
@@ -306,6 +353,70 @@ func TestReconciledObjectsToServiceStatuses(t *testing.T) {
 				},
 			},
 		},
+		"history statefulset ready while migrating from a deployment": {
+			cluster: &v1beta1.TemporalCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "default",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Services: &v1beta1.ServicesSpec{
+						History: &v1beta1.ServiceSpec{
+							WorkloadKind: v1beta1.StatefulSetWorkloadKind,
+						},
+					},
+				},
+			},
+			objects: []client.Object{
+				&appsv1.Deployment{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "Deployment",
+						APIVersion: "apps/v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-history",
+						Namespace: "default",
+						Labels: map[string]string{
+							"app.kubernetes.io/version": "1.2.2",
+						},
+					},
+				},
+				&appsv1.StatefulSet{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "StatefulSet",
+						APIVersion: "apps/v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       "test-history",
+						Namespace:  "default",
+						Generation: 1,
+						Labels: map[string]string{
+							"app.kubernetes.io/version": "1.2.3",
+						},
+					},
+					Spec: appsv1.StatefulSetSpec{
+						Replicas: ptr.To[int32](1),
+					},
+					Status: appsv1.StatefulSetStatus{
+						ObservedGeneration: 1,
+						Replicas:           1,
+						ReadyReplicas:      1,
+						CurrentReplicas:    1,
+						UpdatedReplicas:    1,
+						AvailableReplicas:  1,
+						CurrentRevision:    "rev",
+						UpdateRevision:     "rev",
+					},
+				},
+			},
+			expected: []*v1beta1.ServiceStatus{
+				{
+					Name:    "history",
+					Ready:   true,
+					Version: "1.2.3",
+				},
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -0,0 +1,115 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package status
+
+import (
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// nextVisibilityMigrationPhase returns the phase following the provided visibility migration phase.
+func nextVisibilityMigrationPhase(phase v1beta1.VisibilityMigrationPhase) v1beta1.VisibilityMigrationPhase {
+	switch phase {
+	case v1beta1.SecondarySetupVisibilityMigrationPhase:
+		return v1beta1.DualWriteVisibilityMigrationPhase
+	case v1beta1.DualWriteVisibilityMigrationPhase:
+		return v1beta1.ReadSecondaryVisibilityMigrationPhase
+	default:
+		return v1beta1.PromotedVisibilityMigrationPhase
+	}
+}
+
+// isSecondaryVisibilityStoreSetup returns true if the secondary visibility store schema is set up.
+func isSecondaryVisibilityStoreSetup(c *v1beta1.TemporalCluster) bool {
+	status := c.Status.Persistence.SecondaryVisibilityStore
+	return status != nil && status.Created && status.Setup && status.SchemaVersion != nil
+}
+
+// ReconcileVisibilityMigration moves the visibility migration to its next phase once the current one is completed.
+// It must be called once the cluster datastores are reconciled.
+// It returns the duration after which the migration has to be reconciled again,
+// or 0 if the phase isn't expected to change without a change of the cluster.
+func ReconcileVisibilityMigration(c *v1beta1.TemporalCluster, now time.Time) time.Duration {
+	if c.Status.Persistence == nil {
+		c.Status.Persistence = new(v1beta1.TemporalPersistenceStatus)
+	}
+
+	spec := c.Spec.Persistence.VisibilityMigration
+	secondary := c.Spec.Persistence.SecondaryVisibilityStore
+	if spec == nil || secondary == nil {
+		c.Status.Persistence.VisibilityMigration = nil
+		return 0
+	}
+
+	// Restart the migration if it targets another store.
+	migration := c.Status.Persistence.VisibilityMigration
+	if migration == nil || migration.Store != secondary.Name {
+		migration = &v1beta1.VisibilityMigrationStatus{
+			Store: secondary.Name,
+			Phase: v1beta1.SecondarySetupVisibilityMigrationPhase,
+			Phases: []v1beta1.VisibilityMigrationPhaseStatus{
+				{
+					Phase:     v1beta1.SecondarySetupVisibilityMigrationPhase,
+					StartedAt: metav1.Time{Time: now},
+				},
+			},
+		}
+		c.Status.Persistence.VisibilityMigration = migration
+	}
+
+	if spec.Paused || migration.Phase == v1beta1.PromotedVisibilityMigrationPhase {
+		return 0
+	}
+
+	switch migration.Phase {
+	case v1beta1.SecondarySetupVisibilityMigrationPhase:
+		if !isSecondaryVisibilityStoreSetup(c) {
+			return 0
+		}
+	default:
+		var startedAt time.Time
+		if len(migration.Phases) > 0 {
+			startedAt = migration.Phases[len(migration.Phases)-1].StartedAt.Time
+		}
+		if remaining := startedAt.Add(spec.SoakPeriod.Duration).Sub(now); remaining > 0 {
+			return remaining
+		}
+	}
+
+	if len(migration.Phases) > 0 {
+		migration.Phases[len(migration.Phases)-1].CompletedAt = &metav1.Time{Time: now}
+	}
+
+	migration.Phase = nextVisibilityMigrationPhase(migration.Phase)
+	migration.Phases = append(migration.Phases, v1beta1.VisibilityMigrationPhaseStatus{
+		Phase:     migration.Phase,
+		StartedAt: metav1.Time{Time: now},
+	})
+
+	if migration.Phase == v1beta1.PromotedVisibilityMigrationPhase {
+		return 0
+	}
+
+	if spec.SoakPeriod.Duration <= 0 {
+		return time.Second
+	}
+
+	return spec.SoakPeriod.Duration
+}
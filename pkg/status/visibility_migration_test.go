@@ -0,0 +1,229 @@
+// Licensed to Alexandre VILAIN under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Alexandre VILAIN licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package status_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"github.com/alexandrevilain/temporal-operator/pkg/status"
+	"github.com/alexandrevilain/temporal-operator/pkg/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func visibilityMigrationTestCluster(migration *v1beta1.VisibilityMigrationSpec, previous *v1beta1.VisibilityMigrationStatus, secondarySetup bool) *v1beta1.TemporalCluster {
+	secondaryStatus := &v1beta1.DatastoreStatus{}
+	if secondarySetup {
+		secondaryStatus = &v1beta1.DatastoreStatus{
+			Created:       true,
+			Setup:         true,
+			SchemaVersion: version.MustNewVersionFromString("1.24.0"),
+		}
+	}
+
+	return &v1beta1.TemporalCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: v1beta1.TemporalClusterSpec{
+			Version: version.MustNewVersionFromString("1.24.0"),
+			Persistence: v1beta1.TemporalPersistenceSpec{
+				SecondaryVisibilityStore: &v1beta1.DatastoreSpec{
+					Name: "es-visibility",
+				},
+				VisibilityMigration: migration,
+			},
+		},
+		Status: v1beta1.TemporalClusterStatus{
+			Persistence: &v1beta1.TemporalPersistenceStatus{
+				SecondaryVisibilityStore: secondaryStatus,
+				VisibilityMigration:      previous,
+			},
+		},
+	}
+}
+
+func visibilityMigrationTestStatus(store string, phase v1beta1.VisibilityMigrationPhase, startedAt time.Time) *v1beta1.VisibilityMigrationStatus {
+	return &v1beta1.VisibilityMigrationStatus{
+		Store: store,
+		Phase: phase,
+		Phases: []v1beta1.VisibilityMigrationPhaseStatus{
+			{
+				Phase:     phase,
+				StartedAt: metav1.Time{Time: startedAt},
+			},
+		},
+	}
+}
+
+func TestReconcileVisibilityMigration(t *testing.T) {
+	now := time.Now()
+	soak := &v1beta1.VisibilityMigrationSpec{SoakPeriod: metav1.Duration{Duration: time.Hour}}
+
+	tests := map[string]struct {
+		migration            *v1beta1.VisibilityMigrationSpec
+		previous             *v1beta1.VisibilityMigrationStatus
+		secondarySetup       bool
+		expectedPhase        v1beta1.VisibilityMigrationPhase
+		expectedPhases       int
+		expectedRequeueAfter time.Duration
+	}{
+		"no migration": {
+			previous: visibilityMigrationTestStatus("es-visibility", v1beta1.DualWriteVisibilityMigrationPhase, now),
+		},
+		"secondary store not set up": {
+			migration:      soak,
+			expectedPhase:  v1beta1.SecondarySetupVisibilityMigrationPhase,
+			expectedPhases: 1,
+		},
+		"secondary store set up": {
+			migration:            soak,
+			secondarySetup:       true,
+			expectedPhase:        v1beta1.DualWriteVisibilityMigrationPhase,
+			expectedPhases:       2,
+			expectedRequeueAfter: time.Hour,
+		},
+		"dual write soaking": {
+			migration:            soak,
+			previous:             visibilityMigrationTestStatus("es-visibility", v1beta1.DualWriteVisibilityMigrationPhase, now.Add(-20*time.Minute)),
+			secondarySetup:       true,
+			expectedPhase:        v1beta1.DualWriteVisibilityMigrationPhase,
+			expectedPhases:       1,
+			expectedRequeueAfter: 40 * time.Minute,
+		},
+		"dual write soaked": {
+			migration:            soak,
+			previous:             visibilityMigrationTestStatus("es-visibility", v1beta1.DualWriteVisibilityMigrationPhase, now.Add(-2*time.Hour)),
+			secondarySetup:       true,
+			expectedPhase:        v1beta1.ReadSecondaryVisibilityMigrationPhase,
+			expectedPhases:       2,
+			expectedRequeueAfter: time.Hour,
+		},
+		"read from secondary soaked": {
+			migration:      soak,
+			previous:       visibilityMigrationTestStatus("es-visibility", v1beta1.ReadSecondaryVisibilityMigrationPhase, now.Add(-2*time.Hour)),
+			secondarySetup: true,
+			expectedPhase:  v1beta1.PromotedVisibilityMigrationPhase,
+			expectedPhases: 2,
+		},
+		"paused": {
+			migration: &v1beta1.VisibilityMigrationSpec{
+				SoakPeriod: metav1.Duration{Duration: time.Hour},
+				Paused:     true,
+			},
+			previous:       visibilityMigrationTestStatus("es-visibility", v1beta1.DualWriteVisibilityMigrationPhase, now.Add(-2*time.Hour)),
+			secondarySetup: true,
+			expectedPhase:  v1beta1.DualWriteVisibilityMigrationPhase,
+			expectedPhases: 1,
+		},
+		"secondary store changed": {
+			migration:      soak,
+			previous:       visibilityMigrationTestStatus("old-visibility", v1beta1.ReadSecondaryVisibilityMigrationPhase, now.Add(-2*time.Hour)),
+			expectedPhase:  v1beta1.SecondarySetupVisibilityMigrationPhase,
+			expectedPhases: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := visibilityMigrationTestCluster(test.migration, test.previous, test.secondarySetup)
+
+			requeueAfter := status.ReconcileVisibilityMigration(cluster, now)
+			assert.Equal(tt, test.expectedRequeueAfter, requeueAfter)
+
+			migration := cluster.Status.Persistence.VisibilityMigration
+			if test.expectedPhase == "" {
+				assert.Nil(tt, migration)
+				return
+			}
+
+			require.NotNil(tt, migration)
+			assert.Equal(tt, "es-visibility", migration.Store)
+			assert.Equal(tt, test.expectedPhase, migration.Phase)
+			assert.Equal(tt, test.expectedPhase, cluster.VisibilityMigrationPhase())
+			require.Len(tt, migration.Phases, test.expectedPhases)
+			assert.Equal(tt, test.expectedPhase, migration.Phases[len(migration.Phases)-1].Phase)
+			assert.Nil(tt, migration.Phases[len(migration.Phases)-1].CompletedAt)
+		})
+	}
+}
+
+func TestVisibilityMigrationDynamicConfig(t *testing.T) {
+	tests := map[string]struct {
+		phase                     v1beta1.VisibilityMigrationPhase
+		expectedWritingMode       string
+		expectedReadFromSecondary string
+	}{
+		"secondary setup": {
+			phase:                     v1beta1.SecondarySetupVisibilityMigrationPhase,
+			expectedWritingMode:       `"off"`,
+			expectedReadFromSecondary: "false",
+		},
+		"dual write": {
+			phase:                     v1beta1.DualWriteVisibilityMigrationPhase,
+			expectedWritingMode:       `"dual"`,
+			expectedReadFromSecondary: "false",
+		},
+		"read from secondary": {
+			phase:                     v1beta1.ReadSecondaryVisibilityMigrationPhase,
+			expectedWritingMode:       `"dual"`,
+			expectedReadFromSecondary: "true",
+		},
+		"promoted": {
+			phase: v1beta1.PromotedVisibilityMigrationPhase,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			cluster := visibilityMigrationTestCluster(
+				&v1beta1.VisibilityMigrationSpec{},
+				visibilityMigrationTestStatus("es-visibility", test.phase, time.Now()),
+				true,
+			)
+			cluster.Spec.DynamicConfig = &v1beta1.DynamicConfigSpec{
+				Values: map[string][]v1beta1.ConstrainedValue{
+					v1beta1.SecondaryVisibilityWritingModeDynamicConfigKey: {{}},
+				},
+			}
+
+			dynamicConfig := cluster.GetDynamicConfig()
+			require.NotNil(tt, dynamicConfig)
+			// The spec must not be mutated.
+			assert.Len(tt, cluster.Spec.DynamicConfig.Values, 1)
+
+			if test.expectedWritingMode == "" {
+				assert.NotContains(tt, dynamicConfig.Values, v1beta1.SecondaryVisibilityWritingModeDynamicConfigKey)
+				assert.NotContains(tt, dynamicConfig.Values, v1beta1.EnableReadFromSecondaryVisibilityDynamicConfigKey)
+				return
+			}
+
+			writingMode := dynamicConfig.Values[v1beta1.SecondaryVisibilityWritingModeDynamicConfigKey]
+			require.Len(tt, writingMode, 1)
+			assert.Equal(tt, test.expectedWritingMode, string(writingMode[0].Value.Raw))
+
+			readFromSecondary := dynamicConfig.Values[v1beta1.EnableReadFromSecondaryVisibilityDynamicConfigKey]
+			require.Len(tt, readFromSecondary, 1)
+			assert.Equal(tt, test.expectedReadFromSecondary, string(readFromSecondary[0].Value.Raw))
+		})
+	}
+}
@@ -95,7 +95,7 @@ func buildClusterClientOptions(ctx context.Context, client client.Client, cluste
 		HostPort: cluster.GetPublicClientAddress(),
 		Logger:   temporallog.NewTemporalSDKLogFromContext(ctx),
 	}
-	if cluster.MTLSWithCertManagerEnabled() && cluster.Spec.MTLS.FrontendEnabled() {
+	if cluster.MTLSWithCertificatesEnabled() && cluster.Spec.MTLS.FrontendEnabled() {
 		tlsConfig, err := GetClusterClientTLSConfig(ctx, client, cluster)
 		if err != nil {
 			return opts, fmt.Errorf("can't get cluster TLS config: %w", err)
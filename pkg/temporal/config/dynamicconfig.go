@@ -19,8 +19,11 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
+	"go.temporal.io/server/common/primitives/timestamp"
 )
 
 type YamlDynamicConfig map[string][]YamlConstrainedValue
@@ -91,3 +94,39 @@ func constrainedValueToYamlConstrainedValue(cv *v1beta1.ConstrainedValue) (YamlC
 		Value:       value,
 	}, nil
 }
+
+// DynamicConfigDuration returns the unconstrained duration value of the provided dynamic config key.
+// Like temporal does, numeric values are treated as seconds.
+// It returns false if the key has no unconstrained value.
+func DynamicConfigDuration(dc *v1beta1.DynamicConfigSpec, key string) (time.Duration, bool, error) {
+	if dc == nil {
+		return 0, false, nil
+	}
+
+	for _, cv := range dc.Values[key] {
+		if cv.Constraints != (v1beta1.Constraints{}) || cv.Value == nil {
+			continue
+		}
+
+		var value any
+		err := json.Unmarshal(cv.Value.Raw, &value)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed parsing %s value: %w", key, err)
+		}
+
+		switch v := value.(type) {
+		case string:
+			d, err := timestamp.ParseDurationDefaultSeconds(v)
+			if err != nil {
+				return 0, false, fmt.Errorf("failed parsing %s value: %w", key, err)
+			}
+			return d, true, nil
+		case float64:
+			return time.Duration(v * float64(time.Second)), true, nil
+		default:
+			return 0, false, fmt.Errorf("%s value is not a duration", key)
+		}
+	}
+
+	return 0, false, nil
+}
@@ -19,6 +19,7 @@ package config_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
 	"github.com/alexandrevilain/temporal-operator/pkg/temporal/config"
@@ -161,3 +162,49 @@ func TestDynamicConfigToYamlDynamicConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestDynamicConfigDuration(t *testing.T) {
+	tests := map[string]struct {
+		value            string
+		constraints      v1beta1.Constraints
+		expectedDuration time.Duration
+		expectedFound    bool
+	}{
+		"duration string": {
+			value:            `"30s"`,
+			expectedDuration: 30 * time.Second,
+			expectedFound:    true,
+		},
+		"numeric value in seconds": {
+			value:            `10`,
+			expectedDuration: 10 * time.Second,
+			expectedFound:    true,
+		},
+		"constrained value is ignored": {
+			value: `"30s"`,
+			constraints: v1beta1.Constraints{
+				Namespace: "accounting",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(tt *testing.T) {
+			dc := &v1beta1.DynamicConfigSpec{
+				Values: map[string][]v1beta1.ConstrainedValue{
+					"frontend.shutdownDrainDuration": {
+						{
+							Constraints: test.constraints,
+							Value:       &apiextensionsv1.JSON{Raw: []byte(test.value)},
+						},
+					},
+				},
+			}
+
+			duration, found, err := config.DynamicConfigDuration(dc, "frontend.shutdownDrainDuration")
+			require.NoError(tt, err)
+			assert.Equal(tt, test.expectedFound, found)
+			assert.Equal(tt, test.expectedDuration, duration)
+		})
+	}
+}
@@ -52,17 +52,28 @@ func NewElasticsearchConfigFromDatastoreSpec(spec *v1beta1.DatastoreSpec) (*escl
 	if err != nil {
 		return nil, fmt.Errorf("can't parse elasticsearch url: %w", err)
 	}
-	return &esclient.Config{
+	cfg := &esclient.Config{
 		Version:                      spec.Elasticsearch.Version,
 		URL:                          *parsedURL,
-		Username:                     spec.Elasticsearch.Username,
+		Username:                     spec.Elasticsearch.GetUsername(),
 		Password:                     "",
 		Indices:                      elasticsearchIndicesToMap(spec.Elasticsearch.Indices),
 		LogLevel:                     spec.Elasticsearch.LogLevel,
 		CloseIdleConnectionsInterval: spec.Elasticsearch.CloseIdleConnectionsInterval.Duration,
 		EnableSniff:                  spec.Elasticsearch.EnableSniff,
 		EnableHealthcheck:            spec.Elasticsearch.EnableSniff,
-	}, nil
+	}
+
+	if spec.IsElasticsearchAWSRequestSigning() {
+		cfg.AWSRequestSigning = esclient.ESAWSRequestSigningConfig{
+			Enabled: true,
+			Region:  spec.Elasticsearch.Auth.AWSRequestSigning.Region,
+			// The default credentials chain uses the web identity token mounted by IRSA.
+			CredentialProvider: "aws-sdk-default",
+		}
+	}
+
+	return cfg, nil
 }
 
 func elasticsearchIndicesToMap(indices v1beta1.ElasticsearchIndices) map[string]string {
@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
 
 	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
@@ -29,6 +30,8 @@ import (
 	enumspb "go.temporal.io/api/enums/v1"
 	enumsspb "go.temporal.io/server/api/enums/v1"
 	"go.temporal.io/server/common/primitives"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -96,6 +99,355 @@ func (w *TemporalClusterWebhook) Default(_ context.Context, obj runtime.Object)
 	return nil
 }
 
+func (w *TemporalClusterWebhook) validateFrontendExpose(cluster *v1beta1.TemporalCluster) (admission.Warnings, field.ErrorList) {
+	var warns admission.Warnings
+	var errs field.ErrorList
+
+	if cluster.Spec.Services == nil || cluster.Spec.Services.Frontend == nil || cluster.Spec.Services.Frontend.Expose == nil {
+		return warns, errs
+	}
+
+	expose := cluster.Spec.Services.Frontend.Expose
+	path := field.NewPath("spec", "services", "frontend", "expose")
+	frontendMTLS := cluster.Spec.MTLS != nil && cluster.Spec.MTLS.FrontendEnabled()
+
+	if expose.Service != nil && expose.Service.NodePort != nil && expose.Service.Type != corev1.ServiceTypeNodePort {
+		errs = append(errs, field.Invalid(path.Child("service", "nodePort"), *expose.Service.NodePort, "can only be set when service type is NodePort"))
+	}
+
+	if expose.GRPCRoute != nil {
+		if !w.AvailableAPIs.GatewayAPIGRPCRoute {
+			errs = append(errs, field.Forbidden(path.Child("grpcRoute"), "Can't create a GRPCRoute as it's not available in the cluster"))
+		}
+		if frontendMTLS {
+			warns = append(warns, "Frontend exposed using a GRPCRoute with frontend mTLS enabled: the Gateway terminates TLS so clients certificates can't reach the frontend, consider using a TLSRoute")
+		}
+	}
+
+	if expose.TLSRoute != nil {
+		if !w.AvailableAPIs.GatewayAPITLSRoute {
+			errs = append(errs, field.Forbidden(path.Child("tlsRoute"), "Can't create a TLSRoute as it's not available in the cluster"))
+		}
+		if !frontendMTLS {
+			errs = append(errs, field.Forbidden(path.Child("tlsRoute"), "requires frontend mTLS to be enabled"))
+		}
+	}
+
+	if expose.HTTPRoute != nil {
+		if !w.AvailableAPIs.GatewayAPIHTTPRoute {
+			errs = append(errs, field.Forbidden(path.Child("httpRoute"), "Can't create an HTTPRoute as it's not available in the cluster"))
+		}
+		httpPort := cluster.Spec.Services.Frontend.HTTPPort
+		if httpPort == nil || *httpPort == 0 {
+			errs = append(errs, field.Forbidden(path.Child("httpRoute"), "requires the frontend HTTP port to be enabled"))
+		}
+		if frontendMTLS {
+			warns = append(warns, "Frontend exposed using an HTTPRoute with frontend mTLS enabled: the frontend HTTP API serves TLS, the Gateway must be configured to connect to it using TLS")
+		}
+	}
+
+	return warns, errs
+}
+
+// serviceFieldNames maps temporal services to their field name in the services spec.
+var serviceFieldNames = map[primitives.ServiceName]string{
+	primitives.FrontendService:         "frontend",
+	primitives.InternalFrontendService: "internalFrontend",
+	primitives.HistoryService:          "history",
+	primitives.MatchingService:         "matching",
+	primitives.WorkerService:           "worker",
+}
+
+// validateServices validates the services pods disruption budget and scheduling fields.
+func (w *TemporalClusterWebhook) validateServices(cluster *v1beta1.TemporalCluster) field.ErrorList {
+	var errs field.ErrorList
+
+	if cluster.Spec.Services == nil {
+		return errs
+	}
+
+	for _, service := range []primitives.ServiceName{
+		primitives.FrontendService,
+		primitives.InternalFrontendService,
+		primitives.HistoryService,
+		primitives.MatchingService,
+		primitives.WorkerService,
+	} {
+		spec, err := cluster.Spec.Services.GetServiceSpec(service)
+		if err != nil || spec == nil {
+			continue
+		}
+
+		path := field.NewPath("spec", "services", serviceFieldNames[service])
+
+		if spec.GetWorkloadKind() == v1beta1.StatefulSetWorkloadKind &&
+			service != primitives.HistoryService && service != primitives.MatchingService {
+			errs = append(errs, field.Forbidden(path.Child("workloadKind"), "StatefulSet is only supported for the history and matching services"))
+		}
+
+		errs = append(errs, spec.Validate(path)...)
+	}
+
+	return errs
+}
+
+// validateServiceVersions ensures services and canaries versions overrides are supported
+// and keeps all services versions within one minor version of each other.
+func (w *TemporalClusterWebhook) validateServiceVersions(cluster *v1beta1.TemporalCluster) (admission.Warnings, field.ErrorList) {
+	var warns admission.Warnings
+	var errs field.ErrorList
+
+	if cluster.Spec.Services == nil || cluster.Spec.Version == nil {
+		return warns, errs
+	}
+
+	type serviceVersion struct {
+		name    string
+		path    *field.Path
+		version *version.Version
+	}
+
+	versions := []serviceVersion{}
+	for _, service := range []primitives.ServiceName{
+		primitives.FrontendService,
+		primitives.InternalFrontendService,
+		primitives.HistoryService,
+		primitives.MatchingService,
+		primitives.WorkerService,
+	} {
+		spec, err := cluster.Spec.Services.GetServiceSpec(service)
+		if err != nil || spec == nil {
+			continue
+		}
+
+		path := field.NewPath("spec", "services", serviceFieldNames[service])
+
+		if spec.Version != nil {
+			versions = append(versions, serviceVersion{serviceFieldNames[service], path.Child("version"), spec.Version})
+		}
+		if spec.Canary != nil && spec.Canary.Version != nil {
+			versions = append(versions, serviceVersion{serviceFieldNames[service] + " canary", path.Child("canary", "version"), spec.Canary.Version})
+		}
+	}
+
+	lowest, highest := cluster.Spec.Version, cluster.Spec.Version
+	for _, v := range versions {
+		if v.version.LessThan(lowest) {
+			lowest = v.version
+		}
+		if highest.LessThan(v.version) {
+			highest = v.version
+		}
+	}
+
+	for _, v := range versions {
+		if err := v.version.Validate(); err != nil {
+			errs = append(errs,
+				field.Forbidden(v.path, fmt.Sprintf("Unsupported temporal version (supported: %s)", version.SupportedVersionsRange.String())),
+			)
+			continue
+		}
+
+		if lowest.Major() != highest.Major() || highest.Minor()-lowest.Minor() > 1 {
+			errs = append(errs,
+				field.Invalid(v.path, v.version.String(), fmt.Sprintf("services versions must be within one minor version of each other (cluster version: %s)", cluster.Spec.Version.String())),
+			)
+			continue
+		}
+
+		if v.version.Minor() > cluster.Spec.Version.Minor() {
+			warns = append(warns, fmt.Sprintf("%s runs temporal %s but persistence schemas are only upgraded for the cluster version %s", v.name, v.version.String(), cluster.Spec.Version.String()))
+		}
+	}
+
+	return warns, errs
+}
+
+func (w *TemporalClusterWebhook) validateAutoscaling(cluster *v1beta1.TemporalCluster) field.ErrorList {
+	var errs field.ErrorList
+
+	if cluster.Spec.Services == nil {
+		return errs
+	}
+
+	for _, service := range []primitives.ServiceName{
+		primitives.FrontendService,
+		primitives.InternalFrontendService,
+		primitives.HistoryService,
+		primitives.MatchingService,
+		primitives.WorkerService,
+	} {
+		spec, err := cluster.Spec.Services.GetServiceSpec(service)
+		if err != nil || spec == nil || spec.Autoscaling == nil {
+			continue
+		}
+
+		autoscaling := spec.Autoscaling
+		path := field.NewPath("spec", "services", serviceFieldNames[service], "autoscaling")
+
+		if autoscaling.MinReplicas != nil && *autoscaling.MinReplicas > autoscaling.MaxReplicas {
+			errs = append(errs, field.Invalid(path.Child("minReplicas"), *autoscaling.MinReplicas, "must be less than or equal to maxReplicas"))
+		}
+
+		if len(autoscaling.Prometheus) > 0 && !w.AvailableAPIs.KEDA {
+			errs = append(errs, field.Forbidden(path.Child("prometheus"), "Can't scale on prometheus metrics as KEDA is not available in the cluster"))
+		}
+
+		if w.AvailableAPIs.KEDA {
+			for i, metric := range autoscaling.Metrics {
+				if metric.Type != autoscalingv2.ResourceMetricSourceType {
+					errs = append(errs, field.NotSupported(path.Child("metrics").Index(i).Child("type"), metric.Type, []string{string(autoscalingv2.ResourceMetricSourceType)}))
+				}
+			}
+		}
+
+		for i, prometheus := range autoscaling.Prometheus {
+			promPath := path.Child("prometheus").Index(i)
+			if prometheus.Query == "" && prometheus.Metric == "" {
+				errs = append(errs, field.Required(promPath, "either query or metric is required"))
+			}
+
+			switch prometheus.Metric {
+			case v1beta1.TaskQueueBacklogMetric:
+				if service != primitives.MatchingService {
+					errs = append(errs, field.Invalid(promPath.Child("metric"), prometheus.Metric, "only available for the matching service"))
+				}
+			case v1beta1.RequestRateMetric:
+				if service != primitives.FrontendService && service != primitives.InternalFrontendService {
+					errs = append(errs, field.Invalid(promPath.Child("metric"), prometheus.Metric, "only available for the frontend and internal frontend services"))
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+func (w *TemporalClusterWebhook) validatePersistence(cluster *v1beta1.TemporalCluster) field.ErrorList {
+	var errs field.ErrorList
+
+	stores := cluster.Spec.Persistence.GetDatastoresMap()
+	names := make([]string, 0, len(stores))
+	for name := range stores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		store := stores[name]
+		if store == nil {
+			continue
+		}
+
+		path := field.NewPath("spec", "persistence", name)
+		errs = append(errs, store.Validate(path)...)
+
+		// Volume snapshots can't be taken if the snapshot.storage.k8s.io API isn't served.
+		if store.Backup != nil && store.Backup.VolumeSnapshot != nil && !w.AvailableAPIs.VolumeSnapshot {
+			errs = append(errs,
+				field.Forbidden(
+					path.Child("backup", "volumeSnapshot"),
+					"Can't backup the datastore using a volume snapshot as volume snapshots are not available in the cluster",
+				),
+			)
+		}
+
+		if store.Managed != nil {
+			errs = append(errs, w.validateManagedDatastore(store.Managed, path.Child("managed"))...)
+		}
+	}
+
+	errs = append(errs, w.validateAuthIdentities(cluster, names, stores)...)
+
+	if cluster.Spec.Persistence.VisibilityMigration != nil && cluster.Spec.Persistence.SecondaryVisibilityStore == nil {
+		errs = append(errs,
+			field.Required(
+				field.NewPath("spec", "persistence", "secondaryVisibilityStore"),
+				"the secondary visibility store is required to migrate the visibility",
+			),
+		)
+	}
+
+	return errs
+}
+
+// validateManagedDatastore ensures the operator managing the referenced database cluster is installed.
+func (w *TemporalClusterWebhook) validateManagedDatastore(managed *v1beta1.ManagedDatastoreSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if managed.CloudNativePG != nil && !w.AvailableAPIs.CloudNativePG {
+		errs = append(errs, field.Forbidden(path.Child("cloudNativePG"), "Can't use a CloudNativePG cluster as CloudNativePG is not available in the cluster"))
+	}
+
+	if managed.PerconaXtraDB != nil && !w.AvailableAPIs.PerconaXtraDB {
+		errs = append(errs, field.Forbidden(path.Child("perconaXtraDB"), "Can't use a Percona XtraDB cluster as the Percona XtraDB operator is not available in the cluster"))
+	}
+
+	if managed.K8ssandra != nil && !w.AvailableAPIs.K8ssandra {
+		errs = append(errs, field.Forbidden(path.Child("k8ssandra"), "Can't use a K8ssandra cluster as K8ssandra is not available in the cluster"))
+	}
+
+	return errs
+}
+
+// validateAuthIdentities ensures datastores authenticating with cloud identities share the same identity:
+// it's bound to the service accounts, which are shared by all the datastores.
+func (w *TemporalClusterWebhook) validateAuthIdentities(cluster *v1beta1.TemporalCluster, names []string, stores map[string]*v1beta1.DatastoreSpec) field.ErrorList {
+	var errs field.ErrorList
+
+	identities := map[string]string{}
+	if cluster.Spec.Archival.IsEnabled() && cluster.Spec.Archival.Provider.S3 != nil && cluster.Spec.Archival.Provider.S3.RoleName != nil {
+		identities["awsIAM"] = *cluster.Spec.Archival.Provider.S3.RoleName
+	}
+
+	for _, name := range names {
+		store := stores[name]
+		if store == nil {
+			continue
+		}
+
+		if store.IsElasticsearchAWSRequestSigning() && store.Elasticsearch.Auth.AWSRequestSigning.Role != "" {
+			path := field.NewPath("spec", "persistence", name, "elasticsearch", "auth", "awsRequestSigning", "role")
+			identity := store.Elasticsearch.Auth.AWSRequestSigning.Role
+			if existing, ok := identities["awsIAM"]; ok && existing != identity {
+				errs = append(errs, field.Invalid(path, identity, fmt.Sprintf("must match %s: the services use a single identity", existing)))
+			} else {
+				identities["awsIAM"] = identity
+			}
+		}
+
+		if !store.IsSQLTokenAuth() {
+			continue
+		}
+
+		path := field.NewPath("spec", "persistence", name, "sql", "auth")
+		auth := store.SQL.Auth
+
+		var mode, identity string
+		switch {
+		case auth.AWSIAM != nil:
+			mode, identity = "awsIAM", auth.AWSIAM.Role
+			path = path.Child(mode, "role")
+		case auth.AzureAD != nil:
+			mode, identity = "azureAD", auth.AzureAD.ClientID
+			path = path.Child(mode, "clientId")
+		case auth.GCPIAM != nil:
+			mode, identity = "gcpIAM", auth.GCPIAM.ServiceAccount
+			path = path.Child(mode, "serviceAccount")
+		default:
+			continue
+		}
+
+		if existing, ok := identities[mode]; ok && existing != identity {
+			errs = append(errs, field.Invalid(path, identity, fmt.Sprintf("must match %s: the services use a single identity", existing)))
+			continue
+		}
+		identities[mode] = identity
+	}
+
+	return errs
+}
+
 func (w *TemporalClusterWebhook) validateCluster(cluster *v1beta1.TemporalCluster) (admission.Warnings, field.ErrorList) {
 	var warns admission.Warnings
 	var errs field.ErrorList
@@ -112,10 +464,78 @@ func (w *TemporalClusterWebhook) validateCluster(cluster *v1beta1.TemporalCluste
 		)
 	}
 
+	// Linkerd policies can't be created if the policy.linkerd.io API isn't served.
+	if cluster.LinkerdAuthorizationPoliciesEnabled() && !w.AvailableAPIs.Linkerd {
+		errs = append(errs,
+			field.Invalid(
+				field.NewPath("spec", "mTLS", "linkerd", "authorizationPolicies"),
+				cluster.Spec.MTLS.Linkerd.AuthorizationPolicies,
+				"Can't create linkerd authorization policies as linkerd is not available in the cluster",
+			),
+		)
+	}
+
+	// Waypoints are deployed using a Gateway API Gateway.
+	if cluster.IstioWaypointEnabled() && !w.AvailableAPIs.GatewayAPI {
+		errs = append(errs,
+			field.Invalid(
+				field.NewPath("spec", "mTLS", "istio", "ambient", "waypoint"),
+				cluster.Spec.MTLS.Istio.Ambient.Waypoint,
+				"Can't deploy an istio waypoint as the Gateway API is not available in the cluster",
+			),
+		)
+	}
+
+	if cluster.Spec.UI != nil && cluster.Spec.UI.Gateway != nil && !w.AvailableAPIs.GatewayAPIHTTPRoute {
+		errs = append(errs,
+			field.Forbidden(
+				field.NewPath("spec", "ui", "gateway"),
+				"Can't create an HTTPRoute for the UI as it's not available in the cluster",
+			),
+		)
+	}
+
+	errs = append(errs, w.validateServices(cluster)...)
+
+	versionsWarnings, versionsErrors := w.validateServiceVersions(cluster)
+	warns = append(warns, versionsWarnings...)
+	errs = append(errs, versionsErrors...)
+
+	errs = append(errs, w.validateAutoscaling(cluster)...)
+
+	errs = append(errs, w.validatePersistence(cluster)...)
+
+	exposeWarnings, exposeErrors := w.validateFrontendExpose(cluster)
+	warns = append(warns, exposeWarnings...)
+	errs = append(errs, exposeErrors...)
+
+	// Only the services pods get a SPIFFE identity: the UI, admin tools and the operator's clients
+	// (TemporalNamespace, TemporalSchedule, TemporalClusterClient) couldn't connect to the frontend.
+	if cluster.MTLSWithSpireEnabled() && cluster.Spec.MTLS.FrontendEnabled() {
+		errs = append(errs,
+			field.Forbidden(
+				field.NewPath("spec", "mTLS", "frontend", "enabled"),
+				"Frontend mTLS is not supported by the spire provider, only internode mTLS is",
+			),
+		)
+	}
+
 	mTLSWarnings, mTLSErrors := cluster.Spec.MTLS.Validate()
 	warns = append(warns, mTLSWarnings...)
 	errs = append(errs, mTLSErrors...)
 
+	uiWarnings, uiErrors := cluster.Spec.UI.Validate()
+	warns = append(warns, uiWarnings...)
+	errs = append(errs, uiErrors...)
+
+	codecServerWarnings, codecServerErrors := cluster.Spec.CodecServer.Validate()
+	warns = append(warns, codecServerWarnings...)
+	errs = append(errs, codecServerErrors...)
+
+	if cluster.Spec.UI != nil && cluster.Spec.UI.Codec != nil && cluster.Spec.UI.Codec.Endpoint == "" && !cluster.CodecServerEnabled() {
+		errs = append(errs, field.Required(field.NewPath("spec", "ui", "codec", "endpoint"), "required when the codec server is not enabled"))
+	}
+
 	// Validate that the cluster version is a supported one.
 	err := cluster.Spec.Version.Validate()
 	if err != nil {
@@ -244,6 +664,14 @@ func (w *TemporalClusterWebhook) validateCluster(cluster *v1beta1.TemporalCluste
 					),
 				)
 			}
+			if store != nil && store.Managed != nil && slices.Contains(newStores, string(store.GetType())) {
+				errs = append(errs,
+					field.Forbidden(
+						field.NewPath("spec", "persistence", name, "managed"),
+						fmt.Sprintf("temporal cluster version < 1.20.0 doesn't support %s plugin name", store.GetType()),
+					),
+				)
+			}
 		}
 	}
 
@@ -276,7 +704,7 @@ func (w *TemporalClusterWebhook) validateCluster(cluster *v1beta1.TemporalCluste
 			}
 		}
 
-		if cluster.Spec.Persistence.VisibilityStore != nil && cluster.Spec.Persistence.VisibilityStore.Cassandra != nil {
+		if cluster.Spec.Persistence.VisibilityStore != nil && cluster.Spec.Persistence.VisibilityStore.GetType() == v1beta1.CassandraDatastore {
 			warns = append(warns,
 				"Support for Cassandra as a Visibility database is deprecated beginning with Temporal Server v1.21.",
 			)
@@ -292,7 +720,7 @@ func (w *TemporalClusterWebhook) validateCluster(cluster *v1beta1.TemporalCluste
 			))
 		}
 
-		if cluster.Spec.Persistence.VisibilityStore != nil && cluster.Spec.Persistence.VisibilityStore.Cassandra != nil {
+		if cluster.Spec.Persistence.VisibilityStore != nil && cluster.Spec.Persistence.VisibilityStore.GetType() == v1beta1.CassandraDatastore {
 			errs = append(errs, field.Forbidden(
 				field.NewPath("spec", "persistence", "visibilityStore", "cassandra"),
 				"Support for Cassandra as a Visibility database has been removed with Temporal Server v1.24.",
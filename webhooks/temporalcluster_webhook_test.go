@@ -20,16 +20,20 @@ package webhooks_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/alexandrevilain/temporal-operator/api/v1beta1"
 	"github.com/alexandrevilain/temporal-operator/internal/discovery"
 	"github.com/alexandrevilain/temporal-operator/pkg/version"
 	"github.com/alexandrevilain/temporal-operator/webhooks"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 func TestDefault(t *testing.T) {
@@ -225,6 +229,1021 @@ func TestValidateCreate(t *testing.T) {
 			},
 			expectedErr: "TemporalCluster.temporal.io \"fake\" is invalid: spec.mTLS.provider: Invalid value: \"cert-manager\": Can't use cert-manager as mTLS provider as it's not available in the cluster",
 		},
+		"error when spire provider has no spire spec": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					MTLS: &v1beta1.MTLSSpec{
+						Provider: v1beta1.SpireMTLSProvider,
+						Internode: &v1beta1.InternodeMTLSSpec{
+							Enabled: true,
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "TemporalCluster.temporal.io \"fake\" is invalid: spec.mTLS.spire: Required value: required when mTLS provider is spire",
+		},
+		"error when spire provider enables frontend mTLS": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					MTLS: &v1beta1.MTLSSpec{
+						Provider: v1beta1.SpireMTLSProvider,
+						Frontend: &v1beta1.FrontendMTLSSpec{
+							Enabled: true,
+						},
+						Spire: &v1beta1.SpireMTLSSpec{
+							TrustDomain: "example.org",
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.mTLS.frontend.enabled: Forbidden: Frontend mTLS is not supported by the spire provider, only internode mTLS is",
+		},
+		"error when linkerd authorization policies are enabled without linkerd": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					MTLS: &v1beta1.MTLSSpec{
+						Provider: v1beta1.LinkerdMTLSProvider,
+						Linkerd: &v1beta1.LinkerdMTLSSpec{
+							AuthorizationPolicies: true,
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.mTLS.linkerd.authorizationPolicies: Invalid value: true: Can't create linkerd authorization policies as linkerd is not available in the cluster",
+		},
+		"error when linkerd frontend identity has an invalid wildcard": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					MTLS: &v1beta1.MTLSSpec{
+						Provider: v1beta1.LinkerdMTLSProvider,
+						Linkerd: &v1beta1.LinkerdMTLSSpec{
+							AuthorizationPolicies: true,
+							FrontendIdentities:    []string{"workers.*.serviceaccount.identity.linkerd.cluster.local"},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{Linkerd: true},
+			},
+			expectedErr: "spec.mTLS.linkerd.frontendIdentities[0]: Invalid value: \"workers.*.serviceaccount.identity.linkerd.cluster.local\": must be \"*\" or an identity with an optional \"*.\" prefix",
+		},
+		"error when istio waypoint is enabled without the gateway API": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					MTLS: &v1beta1.MTLSSpec{
+						Provider: v1beta1.IstioMTLSProvider,
+						Istio: &v1beta1.IstioMTLSSpec{
+							Ambient: &v1beta1.IstioAmbientSpec{
+								Enabled:  true,
+								Waypoint: true,
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{Istio: true},
+			},
+			expectedErr: "spec.mTLS.istio.ambient.waypoint: Invalid value: true: Can't deploy an istio waypoint as the Gateway API is not available in the cluster",
+		},
+		"error when istio frontend principal has an invalid wildcard": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					MTLS: &v1beta1.MTLSSpec{
+						Provider: v1beta1.IstioMTLSProvider,
+						Istio: &v1beta1.IstioMTLSSpec{
+							AuthorizationPolicies: true,
+							FrontendPrincipals:    []string{"cluster.local/ns/*/sa/worker"},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{Istio: true},
+			},
+			expectedErr: "spec.mTLS.istio.frontendPrincipals[0]: Invalid value: \"cluster.local/ns/*/sa/worker\": must be \"*\" or a principal with an optional \"*\" prefix or suffix",
+		},
+		"error when frontend is exposed using a TLSRoute without frontend mTLS": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Services: &v1beta1.ServicesSpec{
+						Frontend: &v1beta1.FrontendServiceSpec{
+							Expose: &v1beta1.FrontendExposeSpec{
+								TLSRoute: &v1beta1.FrontendRouteSpec{
+									ParentRefs: []gatewayv1.ParentReference{{Name: "gateway"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{GatewayAPI: true, GatewayAPITLSRoute: true},
+			},
+			expectedErr: "spec.services.frontend.expose.tlsRoute: Forbidden: requires frontend mTLS to be enabled",
+		},
+		"error when frontend is exposed using a GRPCRoute without the gateway API": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Services: &v1beta1.ServicesSpec{
+						Frontend: &v1beta1.FrontendServiceSpec{
+							Expose: &v1beta1.FrontendExposeSpec{
+								GRPCRoute: &v1beta1.FrontendRouteSpec{
+									ParentRefs: []gatewayv1.ParentReference{{Name: "gateway"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.services.frontend.expose.grpcRoute: Forbidden: Can't create a GRPCRoute as it's not available in the cluster",
+		},
+		"error when ui auth has no client secret": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					UI: &v1beta1.TemporalUISpec{
+						Enabled: true,
+						Version: "2.27.3",
+						Auth: &v1beta1.TemporalUIAuthSpec{
+							ProviderURL: "https://accounts.example.com",
+							ClientID:    "temporal-ui",
+							CallbackURL: "https://temporal.example.com/auth/sso/callback",
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.ui.auth.clientSecretRef: Required value: a secret name and key are required when auth is enabled",
+		},
+		"error when ui feature is not supported by the ui version": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					UI: &v1beta1.TemporalUISpec{
+						Enabled: true,
+						Version: "2.21.0",
+						Features: &v1beta1.TemporalUIFeaturesSpec{
+							StartWorkflowDisabled: true,
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.ui.features.startWorkflowDisabled: Forbidden: requires UI version >= 2.25.0",
+		},
+		"error when ui codec endpoint is not an URL": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					UI: &v1beta1.TemporalUISpec{
+						Enabled: true,
+						Version: "2.27.3",
+						Codec: &v1beta1.TemporalUICodecSpec{
+							Endpoint: "codec.example.com",
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.ui.codec.endpoint: Invalid value: \"codec.example.com\": must be an absolute http or https URL",
+		},
+		"error when autoscaling on prometheus metrics without keda": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Services: &v1beta1.ServicesSpec{
+						Matching: &v1beta1.ServiceSpec{
+							Autoscaling: &v1beta1.AutoscalingSpec{
+								MaxReplicas: 5,
+								Prometheus: []v1beta1.AutoscalingPrometheusSpec{
+									{
+										ServerAddress: "http://prometheus:9090",
+										Metric:        v1beta1.TaskQueueBacklogMetric,
+										Threshold:     "100",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.services.matching.autoscaling.prometheus: Forbidden: Can't scale on prometheus metrics as KEDA is not available in the cluster",
+		},
+		"error when autoscaling on a metric not available for the service": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Services: &v1beta1.ServicesSpec{
+						History: &v1beta1.ServiceSpec{
+							Autoscaling: &v1beta1.AutoscalingSpec{
+								MaxReplicas: 5,
+								Prometheus: []v1beta1.AutoscalingPrometheusSpec{
+									{
+										ServerAddress: "http://prometheus:9090",
+										Metric:        v1beta1.RequestRateMetric,
+										Threshold:     "100",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{KEDA: true},
+			},
+			expectedErr: "spec.services.history.autoscaling.prometheus[0].metric: Invalid value: \"RequestRate\": only available for the frontend and internal frontend services",
+		},
+		"error when pod disruption budget sets both minAvailable and maxUnavailable": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Services: &v1beta1.ServicesSpec{
+						History: &v1beta1.ServiceSpec{
+							PodDisruptionBudget: &v1beta1.PodDisruptionBudgetSpec{
+								MinAvailable:   ptr.To(intstr.FromInt32(1)),
+								MaxUnavailable: ptr.To(intstr.FromInt32(1)),
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.services.history.podDisruptionBudget: Forbidden: minAvailable and maxUnavailable are mutually exclusive",
+		},
+		"error when a SQL backup preset has no destination": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{PluginName: "postgres"},
+							Backup: &v1beta1.DatastoreBackupSpec{
+								Preset: &v1beta1.DatastoreBackupPresetSpec{},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.persistence.defaultStore.backup.preset: Invalid value: \"postgres\": exactly one of persistentVolumeClaim or s3 must be set for SQL datastores",
+		},
+		"error when a backup sets several methods": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{PluginName: "postgres"},
+							Backup: &v1beta1.DatastoreBackupSpec{
+								Preset: &v1beta1.DatastoreBackupPresetSpec{
+									PersistentVolumeClaim: &v1beta1.BackupPersistentVolumeClaimDestination{ClaimName: "backups"},
+								},
+								VolumeSnapshot: &v1beta1.DatastoreBackupVolumeSnapshotSpec{PersistentVolumeClaimName: "data"},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{VolumeSnapshot: true},
+			},
+			expectedErr: "spec.persistence.defaultStore.backup: Invalid value: 2: exactly one of job, preset or volumeSnapshot must be set",
+		},
+		"error when backing up using volume snapshots while not available": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{PluginName: "postgres"},
+							Backup: &v1beta1.DatastoreBackupSpec{
+								VolumeSnapshot: &v1beta1.DatastoreBackupVolumeSnapshotSpec{PersistentVolumeClaimName: "data"},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.persistence.defaultStore.backup.volumeSnapshot: Forbidden: Can't backup the datastore using a volume snapshot as volume snapshots are not available in the cluster",
+		},
+		"error when dual credential password rotation has no password secret": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							SQL:              &v1beta1.SQLSpec{PluginName: "postgres"},
+							PasswordRotation: &v1beta1.DatastorePasswordRotationSpec{DualCredential: true},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.persistence.defaultStore.passwordSecretRef: Required value: a password secret is required for dual credential password rotation",
+		},
+		"error when sql auth sets several modes": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{
+								PluginName: "postgres",
+								Auth: &v1beta1.SQLAuthSpec{
+									AWSIAM: &v1beta1.SQLAWSIAMAuthSpec{Region: "eu-west-1", Role: "arn:aws:iam::123456789012:role/temporal"},
+									GCPIAM: &v1beta1.SQLGCPIAMAuthSpec{ServiceAccount: "temporal@project.iam.gserviceaccount.com"},
+								},
+							},
+							TLS: &v1beta1.DatastoreTLSSpec{Enabled: true},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.persistence.defaultStore.sql.auth: Invalid value: 2: exactly one of awsIAM, azureAD or gcpIAM must be set",
+		},
+		"error when sql auth is used without TLS": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{
+								PluginName: "postgres",
+								Auth: &v1beta1.SQLAuthSpec{
+									AzureAD: &v1beta1.SQLAzureADAuthSpec{ClientID: "client"},
+								},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.persistence.defaultStore.tls: Required value: TLS must be enabled to send authentication tokens to the datastore",
+		},
+		"error when sql auth stores use different identities": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{
+								PluginName: "postgres",
+								Auth: &v1beta1.SQLAuthSpec{
+									AWSIAM: &v1beta1.SQLAWSIAMAuthSpec{Region: "eu-west-1", Role: "arn:aws:iam::123456789012:role/temporal"},
+								},
+							},
+							TLS: &v1beta1.DatastoreTLSSpec{Enabled: true},
+						},
+						VisibilityStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{
+								PluginName: "postgres",
+								Auth: &v1beta1.SQLAuthSpec{
+									AWSIAM: &v1beta1.SQLAWSIAMAuthSpec{Region: "eu-west-1", Role: "arn:aws:iam::123456789012:role/visibility"},
+								},
+							},
+							TLS: &v1beta1.DatastoreTLSSpec{Enabled: true},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.persistence.visibilityStore.sql.auth.awsIAM.role: Invalid value: \"arn:aws:iam::123456789012:role/visibility\": must match arn:aws:iam::123456789012:role/temporal: the services use a single identity",
+		},
+		"error when several elasticsearch auth modes are set": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.24.3"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						VisibilityStore: &v1beta1.DatastoreSpec{
+							Elasticsearch: &v1beta1.ElasticsearchSpec{
+								Auth: &v1beta1.ElasticsearchAuthSpec{
+									Basic: &v1beta1.ElasticsearchBasicAuthSpec{Username: "temporal"},
+									APIKey: &v1beta1.ElasticsearchAPIKeyAuthSpec{
+										SecretRef: v1beta1.SecretKeyReference{Name: "es-api-key"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.persistence.visibilityStore.elasticsearch.auth: Invalid value: 2: exactly one of basic, apiKey or awsRequestSigning must be set",
+		},
+		"error when elasticsearch username is set along with auth": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.24.3"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						VisibilityStore: &v1beta1.DatastoreSpec{
+							Elasticsearch: &v1beta1.ElasticsearchSpec{
+								Username: "temporal",
+								Auth: &v1beta1.ElasticsearchAuthSpec{
+									Basic: &v1beta1.ElasticsearchBasicAuthSpec{Username: "temporal"},
+								},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.persistence.visibilityStore.elasticsearch.username: Forbidden: can't be set along with auth, use auth.basic.username instead",
+		},
+		"error when elasticsearch api key auth has a password secret": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.24.3"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						VisibilityStore: &v1beta1.DatastoreSpec{
+							Elasticsearch: &v1beta1.ElasticsearchSpec{
+								Auth: &v1beta1.ElasticsearchAuthSpec{
+									APIKey: &v1beta1.ElasticsearchAPIKeyAuthSpec{
+										SecretRef: v1beta1.SecretKeyReference{Name: "es-api-key"},
+									},
+								},
+							},
+							PasswordSecretRef: &v1beta1.SecretKeyReference{Name: "es-password"},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.persistence.visibilityStore.passwordSecretRef: Forbidden: can't be set when the datastore doesn't authenticate with a password",
+		},
+		"error when elasticsearch aws request signing uses another identity": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.24.3"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{
+								PluginName: "postgres12",
+								Auth: &v1beta1.SQLAuthSpec{
+									AWSIAM: &v1beta1.SQLAWSIAMAuthSpec{Region: "eu-west-1", Role: "arn:aws:iam::123456789012:role/temporal"},
+								},
+							},
+							TLS: &v1beta1.DatastoreTLSSpec{Enabled: true},
+						},
+						VisibilityStore: &v1beta1.DatastoreSpec{
+							Elasticsearch: &v1beta1.ElasticsearchSpec{
+								Auth: &v1beta1.ElasticsearchAuthSpec{
+									AWSRequestSigning: &v1beta1.ElasticsearchAWSRequestSigningSpec{
+										Region: "eu-west-1",
+										Role:   "arn:aws:iam::123456789012:role/opensearch",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.persistence.visibilityStore.elasticsearch.auth.awsRequestSigning.role: Invalid value: \"arn:aws:iam::123456789012:role/opensearch\": must match arn:aws:iam::123456789012:role/temporal: the services use a single identity",
+		},
+		"error when a managed datastore references several database clusters": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.24.3"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							Managed: &v1beta1.ManagedDatastoreSpec{
+								CloudNativePG: &v1beta1.ManagedClusterReference{Name: "postgres"},
+								PerconaXtraDB: &v1beta1.ManagedClusterReference{Name: "mysql"},
+								Database:      "temporal",
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{CloudNativePG: true, PerconaXtraDB: true},
+			},
+			expectedErr: "spec.persistence.defaultStore.managed: Invalid value: 2: exactly one of cloudNativePG, perconaXtraDB or k8ssandra must be set",
+		},
+		"error when a managed datastore sets connection details": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.24.3"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							SQL: &v1beta1.SQLSpec{PluginName: "postgres12"},
+							Managed: &v1beta1.ManagedDatastoreSpec{
+								CloudNativePG: &v1beta1.ManagedClusterReference{Name: "postgres"},
+								Database:      "temporal",
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{CloudNativePG: true},
+			},
+			expectedErr: "spec.persistence.defaultStore.sql: Forbidden: can't be set for managed datastores",
+		},
+		"error when a managed datastore references a K8ssandra cluster while not available": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.24.3"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							Managed: &v1beta1.ManagedDatastoreSpec{
+								K8ssandra: &v1beta1.ManagedClusterReference{Name: "cassandra"},
+								Database:  "temporal",
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.persistence.defaultStore.managed.k8ssandra: Forbidden: Can't use a K8ssandra cluster as K8ssandra is not available in the cluster",
+		},
+		"error when migrating the visibility without a secondary visibility store": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.24.3"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						VisibilityMigration: &v1beta1.VisibilityMigrationSpec{
+							SoakPeriod: metav1.Duration{Duration: time.Hour},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.persistence.secondaryVisibilityStore: Required value: the secondary visibility store is required to migrate the visibility",
+		},
+		"error when a managed datastore uses a new plugin in < 1.20.0": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Persistence: v1beta1.TemporalPersistenceSpec{
+						DefaultStore: &v1beta1.DatastoreSpec{
+							Managed: &v1beta1.ManagedDatastoreSpec{
+								CloudNativePG: &v1beta1.ManagedClusterReference{Name: "postgres"},
+								Database:      "temporal",
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{CloudNativePG: true},
+			},
+			expectedErr: "spec.persistence.defaultStore.managed: Forbidden: temporal cluster version < 1.20.0 doesn't support postgres12 plugin name",
+		},
+		"error when toleration has an invalid operator": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Services: &v1beta1.ServicesSpec{
+						Matching: &v1beta1.ServiceSpec{
+							Tolerations: []corev1.Toleration{
+								{
+									Key:      "dedicated",
+									Operator: "Matches",
+									Value:    "temporal",
+								},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.services.matching.tolerations[0].operator: Unsupported value: \"Matches\": supported values: \"Equal\", \"Exists\"",
+		},
+		"error when topology spread constraint has no topology key": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Services: &v1beta1.ServicesSpec{
+						Worker: &v1beta1.ServiceSpec{
+							TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+								{
+									MaxSkew:           1,
+									WhenUnsatisfiable: corev1.DoNotSchedule,
+								},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.services.worker.topologySpreadConstraints[0].topologyKey: Required value: can not be empty",
+		},
+		"error when priority class name is invalid": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Services: &v1beta1.ServicesSpec{
+						History: &v1beta1.ServiceSpec{
+							PriorityClassName: "High_Priority",
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.services.history.priorityClassName: Invalid value: \"High_Priority\": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')",
+		},
+		"error when termination grace period is shorter than the preStop delay": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Services: &v1beta1.ServicesSpec{
+						Frontend: &v1beta1.FrontendServiceSpec{
+							ServiceSpec: v1beta1.ServiceSpec{
+								Shutdown: &v1beta1.ServiceShutdownSpec{
+									PreStopDelay:           &metav1.Duration{Duration: 10 * time.Second},
+									TerminationGracePeriod: &metav1.Duration{Duration: 5 * time.Second},
+								},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.services.frontend.shutdown.terminationGracePeriod: Invalid value: \"5s\": must be greater than the preStop delay",
+		},
+		"error when running the frontend as a statefulset": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Services: &v1beta1.ServicesSpec{
+						Frontend: &v1beta1.FrontendServiceSpec{
+							ServiceSpec: v1beta1.ServiceSpec{
+								WorkloadKind: v1beta1.StatefulSetWorkloadKind,
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.services.frontend.workloadKind: Forbidden: StatefulSet is only supported for the history and matching services",
+		},
+		"error when a service version is more than one minor away from the cluster version": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.22.4"),
+					Services: &v1beta1.ServicesSpec{
+						History: &v1beta1.ServiceSpec{
+							Version: version.MustNewVersionFromString("1.24.2"),
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.services.history.version: Invalid value: \"1.24.2\": services versions must be within one minor version of each other (cluster version: 1.22.4)",
+		},
+		"error when a service canary version is more than one minor away from the cluster version": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.22.4"),
+					Services: &v1beta1.ServicesSpec{
+						History: &v1beta1.ServiceSpec{
+							Replicas: ptr.To[int32](3),
+							Canary: &v1beta1.ServiceCanarySpec{
+								Version: version.MustNewVersionFromString("1.24.2"),
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.services.history.canary.version: Invalid value: \"1.24.2\": services versions must be within one minor version of each other (cluster version: 1.22.4)",
+		},
+		"error when a service canary has as many replicas as the service": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.22.4"),
+					Services: &v1beta1.ServicesSpec{
+						History: &v1beta1.ServiceSpec{
+							Replicas: ptr.To[int32](2),
+							Canary: &v1beta1.ServiceCanarySpec{
+								Replicas: ptr.To[int32](2),
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.services.history.canary.replicas: Invalid value: 2: must be lower than the service replicas",
+		},
+		"error when codec server has no image": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					CodecServer: &v1beta1.CodecServerSpec{
+						Enabled:  true,
+						Endpoint: "https://codec.example.com",
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.codecServer.image: Required value: required when the codec server is enabled",
+		},
+		"error when ui gateway is set without the gateway API": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					UI: &v1beta1.TemporalUISpec{
+						Enabled: true,
+						Gateway: &v1beta1.GatewayHTTPRouteSpec{
+							ParentRefs: []gatewayv1.ParentReference{{Name: "gateway"}},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.ui.gateway: Forbidden: Can't create an HTTPRoute for the UI as it's not available in the cluster",
+		},
+		"error when frontend is exposed using an HTTPRoute without the HTTP port": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Services: &v1beta1.ServicesSpec{
+						Frontend: &v1beta1.FrontendServiceSpec{
+							ServiceSpec: v1beta1.ServiceSpec{
+								HTTPPort: ptr.To[int32](0),
+							},
+							Expose: &v1beta1.FrontendExposeSpec{
+								HTTPRoute: &v1beta1.GatewayHTTPRouteSpec{
+									ParentRefs: []gatewayv1.ParentReference{{Name: "gateway"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{GatewayAPI: true, GatewayAPIHTTPRoute: true},
+			},
+			expectedErr: "spec.services.frontend.expose.httpRoute: Forbidden: requires the frontend HTTP port to be enabled",
+		},
+		"error when frontend external service sets a node port on a load balancer": {
+			object: &v1beta1.TemporalCluster{
+				TypeMeta: v1beta1.TemporalClusterTypeMeta,
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fake",
+				},
+				Spec: v1beta1.TemporalClusterSpec{
+					Version: version.MustNewVersionFromString("1.18.4"),
+					Services: &v1beta1.ServicesSpec{
+						Frontend: &v1beta1.FrontendServiceSpec{
+							Expose: &v1beta1.FrontendExposeSpec{
+								Service: &v1beta1.FrontendExternalServiceSpec{
+									NodePort: ptr.To[int32](30233),
+								},
+							},
+						},
+					},
+				},
+			},
+			wh: &webhooks.TemporalClusterWebhook{
+				AvailableAPIs: &discovery.AvailableAPIs{},
+			},
+			expectedErr: "spec.services.frontend.expose.service.nodePort: Invalid value: 30233: can only be set when service type is NodePort",
+		},
 		"error with old elastic search version": {
 			object: &v1beta1.TemporalCluster{
 				TypeMeta: v1beta1.TemporalClusterTypeMeta,